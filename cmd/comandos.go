@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"time"
+)
+
+// ejecutarComando ejecuta un subcomando de administración en lugar de iniciar el servidor
+func ejecutarComando(cfg *config.Config, comando string, args []string) error {
+	switch comando {
+	case "crear-admin":
+		return crearAdmin(cfg, args)
+	}
+
+	return fmt.Errorf("comando desconocido: %s (disponibles: crear-admin)", comando)
+}
+
+// crearAdmin crea el primer usuario ADMIN con su contraseña hasheada.
+// La contraseña se toma de ADMIN_CONTRASENA para que no quede en el historial de la consola.
+//
+//	ADMIN_CONTRASENA=... ./main crear-admin -correo admin@empresa.com -nombres Ana -apellidos Pérez -documento 12345678
+func crearAdmin(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("crear-admin", flag.ContinueOnError)
+	correo := flags.String("correo", "", "correo del administrador")
+	nombres := flags.String("nombres", "", "nombres del administrador")
+	apellidos := flags.String("apellidos", "", "apellidos del administrador")
+	tipoDocumento := flags.String("tipo-documento", "DNI", "tipo de documento")
+	numeroDocumento := flags.String("documento", "", "número de documento")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	admin := &entidades.NuevoUsuarioRequest{
+		Nombres:         *nombres,
+		Apellidos:       *apellidos,
+		Correo:          *correo,
+		FechaNacimiento: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), // Se completa luego desde la API
+		Rol:             "ADMIN",
+		TipoDocumento:   *tipoDocumento,
+		NumeroDocumento: *numeroDocumento,
+		Contrasena:      os.Getenv("ADMIN_CONTRASENA"),
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(admin); err != nil {
+		return fmt.Errorf("datos inválidos (la contraseña se lee de ADMIN_CONTRASENA): %v", err)
+	}
+
+	// Conectar a la base de datos
+	db, err := connectDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	usuarioService := servicios.NewUsuarioService(
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewRefreshTokenRepository(db),
+		repositorios.NewTokenRevocadoRepository(db),
+		repositorios.NewRolRepository(db),
+	)
+	id, err := usuarioService.CrearAdminInicial(admin)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Administrador %s creado con ID %d", admin.Correo, id)
+	return nil
+}
@@ -116,15 +116,18 @@ import (
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 	"sistema-tours/internal/config"
 	"sistema-tours/internal/controladores"
+	"sistema-tours/internal/correo"
 	"sistema-tours/internal/repositorios"
 	"sistema-tours/internal/rutas"
 	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/sunat"
 	"sistema-tours/internal/utils"
 	"time"
 
@@ -136,6 +139,19 @@ func main() {
 	// Cargar configuración
 	cfg := config.LoadConfig()
 
+	// No iniciar con llaves por defecto en producción
+	if err := cfg.Validar(); err != nil {
+		log.Fatalf("Configuración inválida: %v", err)
+	}
+
+	// Subcomandos de administración
+	if len(os.Args) > 1 {
+		if err := ejecutarComando(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("Error en el comando %s: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Configurar modo de Gin según entorno
 	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -166,20 +182,54 @@ func main() {
 	canalVentaRepo := repositorios.NewCanalVentaRepository(db)
 	clienteRepo := repositorios.NewClienteRepository(db)
 	reservaRepo := repositorios.NewReservaRepository(db)
+	pagoRepo := repositorios.NewPagoRepository(db)
+	comprobantePagoRepo := repositorios.NewComprobantePagoRepository(db)
+	resumenSunatRepo := repositorios.NewResumenSunatRepository(db)
+	notaCreditoRepo := repositorios.NewNotaCreditoRepository(db)
+	tarifaTourRepo := repositorios.NewTarifaTourRepository(db)
+	historialEstadoReservaRepo := repositorios.NewHistorialEstadoReservaRepository(db)
+	pasajeroRepo := repositorios.NewPasajeroRepository(db)
+	embarqueRepo := repositorios.NewEmbarqueRepository(db)
+	refreshTokenRepo := repositorios.NewRefreshTokenRepository(db)
+	tokenRevocadoRepo := repositorios.NewTokenRevocadoRepository(db)
+	controlLoginRepo := repositorios.NewControlLoginRepository(db)
+	auditoriaLoginRepo := repositorios.NewAuditoriaLoginRepository(db)
+	tokenRecuperacionRepo := repositorios.NewTokenRecuperacionRepository(db)
+	dobleFactorRepo := repositorios.NewDobleFactorRepository(db)
+	codigoRecuperacionRepo := repositorios.NewCodigoRecuperacionRepository(db)
+	rolRepo := repositorios.NewRolRepository(db)
+	permisoRepo := repositorios.NewPermisoRepository(db)
+	apiKeyRepo := repositorios.NewAPIKeyRepository(db)
+	usoAPIKeyRepo := repositorios.NewUsoAPIKeyRepository(db)
 	// Otros repositorios...
 
 	// Inicializar servicios
-	authService := servicios.NewAuthService(usuarioRepo, cfg)
-	usuarioService := servicios.NewUsuarioService(usuarioRepo)
+	proteccionLoginService := servicios.NewProteccionLoginService(controlLoginRepo, auditoriaLoginRepo, cfg)
+	dobleFactorService := servicios.NewDobleFactorService(db, dobleFactorRepo, codigoRecuperacionRepo, usuarioRepo, cfg)
+	authService := servicios.NewAuthService(db, usuarioRepo, clienteRepo, refreshTokenRepo, tokenRevocadoRepo, proteccionLoginService, dobleFactorService, cfg)
+	usuarioService := servicios.NewUsuarioService(usuarioRepo, refreshTokenRepo, tokenRevocadoRepo, rolRepo)
+	rolService := servicios.NewRolService(db, rolRepo, permisoRepo, cfg.PermisosVigencia)
+	apiKeyService := servicios.NewAPIKeyService(db, apiKeyRepo, usoAPIKeyRepo, canalVentaRepo, cfg)
+	recuperacionService := servicios.NewRecuperacionContrasenaService(
+		db,
+		usuarioRepo,
+		clienteRepo,
+		tokenRecuperacionRepo,
+		refreshTokenRepo,
+		configurarCorreo(cfg),
+		cfg,
+	)
 	embarcacionService := servicios.NewEmbarcacionService(embarcacionRepo, usuarioRepo)
 	tipoTourService := servicios.NewTipoTourService(tipoTourRepo)
 	horarioTourService := servicios.NewHorarioTourService(horarioTourRepo, tipoTourRepo)
 	horarioChoferService := servicios.NewHorarioChoferService(horarioChoferRepo, usuarioRepo)
-	tourProgramadoService := servicios.NewTourProgramadoService(tourProgramadoRepo, tipoTourRepo, embarcacionRepo, horarioTourRepo)
+	tourProgramadoService := servicios.NewTourProgramadoService(tourProgramadoRepo, tipoTourRepo, embarcacionRepo, horarioTourRepo, tarifaTourRepo)
 	metodoPagoService := servicios.NewMetodoPagoService(metodoPagoRepo)
 	tipoPasajeService := servicios.NewTipoPasajeService(tipoPasajeRepo)
+	tarifaTourService := servicios.NewTarifaTourService(tarifaTourRepo, tipoTourRepo, tipoPasajeRepo)
 	canalVentaService := servicios.NewCanalVentaService(canalVentaRepo)
 	clienteService := servicios.NewClienteService(clienteRepo)
+	cotizacionService := servicios.NewCotizacionService(tourProgramadoRepo, tarifaTourRepo)
 	reservaService := servicios.NewReservaService(
 		db,
 		reservaRepo,
@@ -188,6 +238,82 @@ func main() {
 		canalVentaRepo,
 		tipoPasajeRepo,
 		usuarioRepo,
+		pagoRepo,
+		comprobantePagoRepo,
+		historialEstadoReservaRepo,
+		pasajeroRepo,
+		cotizacionService,
+		rolService,
+		cfg.ReservaRetencion,
+		cfg.ReservaLimiteCancelacion,
+	)
+	pasajeroService := servicios.NewPasajeroService(
+		db,
+		pasajeroRepo,
+		reservaRepo,
+		tipoPasajeRepo,
+		tourProgramadoRepo,
+	)
+	pagoService := servicios.NewPagoService(
+		db,
+		pagoRepo,
+		reservaRepo,
+		metodoPagoRepo,
+		canalVentaRepo,
+	)
+	comprobantePagoService := servicios.NewComprobantePagoService(
+		db,
+		comprobantePagoRepo,
+		reservaRepo,
+		clienteRepo,
+		notaCreditoRepo,
+	)
+	notaCreditoService := servicios.NewNotaCreditoService(
+		db,
+		notaCreditoRepo,
+		comprobantePagoRepo,
+		reservaRepo,
+		pagoRepo,
+		metodoPagoRepo,
+		canalVentaRepo,
+	)
+
+	// Facturación electrónica
+	firmador, enviador, err := configurarSunat(cfg)
+	if err != nil {
+		log.Fatalf("Error al configurar la facturación electrónica: %v", err)
+	}
+	emisor := sunat.Emisor{
+		RUC:             cfg.SunatRUC,
+		RazonSocial:     cfg.SunatRazonSocial,
+		NombreComercial: cfg.SunatNombreComercial,
+		Direccion:       cfg.SunatDireccion,
+	}
+	facturacionService := servicios.NewFacturacionElectronicaService(
+		db,
+		comprobantePagoRepo,
+		notaCreditoRepo,
+		resumenSunatRepo,
+		emisor,
+		firmador,
+		enviador,
+	)
+	impresionService := servicios.NewImpresionService(
+		comprobantePagoRepo,
+		reservaRepo,
+		tourProgramadoRepo,
+		tipoPasajeRepo,
+		pasajeroRepo,
+		emisor,
+		cfg.EmbarqueSecret,
+	)
+	embarqueService := servicios.NewEmbarqueService(
+		db,
+		embarqueRepo,
+		reservaRepo,
+		tourProgramadoRepo,
+		reservaService,
+		cfg.EmbarqueSecret,
 	)
 	// Otros servicios...
 
@@ -207,15 +333,32 @@ func main() {
 	tourProgramadoController := controladores.NewTourProgramadoController(tourProgramadoService)
 	metodoPagoController := controladores.NewMetodoPagoController(metodoPagoService)
 	tipoPasajeController := controladores.NewTipoPasajeController(tipoPasajeService)
+	tarifaTourController := controladores.NewTarifaTourController(tarifaTourService)
 	canalVentaController := controladores.NewCanalVentaController(canalVentaService)
-	clienteController := controladores.NewClienteController(clienteService, cfg) // Pasamos cfg como segundo parámetro
+	clienteController := controladores.NewClienteController(clienteService)
 	reservaController := controladores.NewReservaController(reservaService)
+	cotizacionController := controladores.NewCotizacionController(cotizacionService)
+	pagoController := controladores.NewPagoController(pagoService)
+	comprobantePagoController := controladores.NewComprobantePagoController(comprobantePagoService)
+	facturacionController := controladores.NewFacturacionElectronicaController(facturacionService, comprobantePagoService, notaCreditoService)
+	notaCreditoController := controladores.NewNotaCreditoController(notaCreditoService)
+	impresionController := controladores.NewImpresionController(impresionService)
+	pasajeroController := controladores.NewPasajeroController(pasajeroService)
+	embarqueController := controladores.NewEmbarqueController(embarqueService)
+	proteccionLoginController := controladores.NewProteccionLoginController(proteccionLoginService)
+	recuperacionController := controladores.NewRecuperacionContrasenaController(recuperacionService)
+	dobleFactorController := controladores.NewDobleFactorController(dobleFactorService)
+	rolController := controladores.NewRolController(rolService)
+	apiKeyController := controladores.NewAPIKeyController(apiKeyService)
 	// Otros controladores...
 
 	// Configurar rutas
 	rutas.SetupRoutes(
 		router,
 		cfg,
+		tokenRevocadoRepo,
+		rolService,
+		apiKeyService,
 		authController,
 		usuarioController,
 		embarcacionController,
@@ -228,11 +371,30 @@ func main() {
 		canalVentaController,
 		clienteController,
 		reservaController,
+		pagoController,
+		comprobantePagoController,
+		facturacionController,
+		notaCreditoController,
+		impresionController,
+		cotizacionController,
+		tarifaTourController,
+		pasajeroController,
+		embarqueController,
+		proteccionLoginController,
+		recuperacionController,
+		dobleFactorController,
+		rolController,
+		apiKeyController,
 		// Otros controladores...
 	)
 
 	// Iniciar servidor
 	serverAddr := fmt.Sprintf("%s:%s", cfg.ServerHost, cfg.ServerPort)
+	// Liberar en segundo plano el cupo de las reservas web que no se pagaron a tiempo
+	go reservaService.IniciarBarridoRetenciones(context.Background(), cfg.ReservaBarridoIntervalo)
+	// Eliminar en segundo plano los refresh tokens y tokens revocados que ya expiraron
+	go authService.IniciarLimpiezaTokens(context.Background(), cfg.TokenLimpiezaIntervalo)
+
 	log.Printf("Servidor iniciado en %s", serverAddr)
 	if err := router.Run(serverAddr); err != nil {
 		log.Fatalf("Error al iniciar servidor: %v", err)
@@ -277,6 +439,47 @@ func connectDBWithRetry(cfg *config.Config) (*sql.DB, error) {
 	return nil, fmt.Errorf("no se pudo conectar a la base de datos después de %d intentos: %v", maxRetries, err)
 }
 
+// configurarSunat carga el certificado digital y selecciona el enviador según el modo configurado.
+// En modo fake se usa un certificado de prueba si no se ha configurado uno.
+func configurarSunat(cfg *config.Config) (*sunat.Firmador, sunat.Enviador, error) {
+	var firmador *sunat.Firmador
+	var err error
+
+	if cfg.SunatCertificado != "" {
+		firmador, err = sunat.CargarFirmador(cfg.SunatCertificado, cfg.SunatCertificadoClave)
+	} else if cfg.SunatModo == "fake" {
+		firmador, err = sunat.FirmadorDePrueba(cfg.SunatRUC)
+	} else {
+		err = fmt.Errorf("SUNAT_CERTIFICADO es obligatorio en modo %s", cfg.SunatModo)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch cfg.SunatModo {
+	case "fake":
+		log.Println("Facturación electrónica en modo fake: los comprobantes no se envían a SUNAT")
+		return firmador, sunat.NuevoEnviadorFake(), nil
+	case "beta":
+		return firmador, sunat.NuevoEnviadorSOAP(sunat.URLBeta, cfg.SunatRUC, cfg.SunatUsuarioSOL, cfg.SunatClaveSOL), nil
+	case "produccion":
+		return firmador, sunat.NuevoEnviadorSOAP(sunat.URLProduccion, cfg.SunatRUC, cfg.SunatUsuarioSOL, cfg.SunatClaveSOL), nil
+	}
+
+	return nil, nil, fmt.Errorf("SUNAT_MODO inválido: %s", cfg.SunatModo)
+}
+
+// configurarCorreo selecciona el enviador de correos según el modo configurado.
+// En modo fake los correos se guardan en memoria y no llegan a ningún destinatario.
+func configurarCorreo(cfg *config.Config) correo.Enviador {
+	if cfg.CorreoModo == "smtp" {
+		return correo.NuevoEnviadorSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsuario, cfg.SMTPClave, cfg.CorreoRemitente)
+	}
+
+	log.Println("Correo en modo fake: los correos no se envían")
+	return correo.NuevoEnviadorFake()
+}
+
 // connectDB establece conexión con la base de datos PostgreSQL (función original sin reintentos)
 func connectDB(cfg *config.Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
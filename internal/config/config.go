@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -23,15 +26,91 @@ type Config struct {
 	DBSSLMode  string
 
 	// JWT
-	JWTSecret        string
-	JWTRefreshSecret string
-	JWTExpiration    time.Duration
+	JWTSecret              string
+	JWTRefreshSecret       string
+	JWTExpiration          time.Duration // Vigencia de los tokens de acceso
+	JWTRefreshExpiration   time.Duration // Vigencia de los refresh tokens
+	TokenLimpiezaIntervalo time.Duration // Cada cuánto se eliminan los tokens vencidos
+
+	// Protección del inicio de sesión
+	LoginMaxFallos   int           // Fallos consecutivos de una cuenta antes de bloquearla
+	LoginMaxFallosIP int           // Fallos desde una misma IP antes de bloquearla
+	LoginBloqueo     time.Duration // Duración del bloqueo y ventana en la que se cuentan los fallos
+	LoginRetrasoBase time.Duration // Espera tras el segundo fallo; se duplica con cada fallo siguiente
+
+	// Permisos
+	PermisosVigencia time.Duration // Cada cuánto se recargan los permisos de los roles desde la base de datos
+
+	// Llaves de API de agencias
+	APIKeyLimiteMinuto int // Peticiones por minuto de las llaves que no indican su propio límite
+
+	// Doble factor (TOTP)
+	DobleFactorRoles   []string      // Roles que deben usar doble factor para iniciar sesión
+	DobleFactorEmisor  string        // Nombre con el que aparece la cuenta en la aplicación autenticadora
+	DobleFactorDesafio time.Duration // Tiempo para ingresar el código después de la contraseña
+
+	// Correo electrónico
+	CorreoModo      string // fake, smtp
+	CorreoRemitente string
+	SMTPHost        string
+	SMTPPort        string
+	SMTPUsuario     string
+	SMTPClave       string
+
+	// Recuperación de contraseña
+	RecuperacionURL      string        // Página del frontend que recibe el token (se agrega ?token=...)
+	RecuperacionVigencia time.Duration // Tiempo durante el que sirve un token de recuperación
+
+	// Facturación electrónica (SUNAT)
+	SunatModo             string // fake, beta, produccion
+	SunatRUC              string
+	SunatRazonSocial      string
+	SunatNombreComercial  string
+	SunatDireccion        string
+	SunatUsuarioSOL       string
+	SunatClaveSOL         string
+	SunatCertificado      string // Ruta al certificado digital (.pfx, .p12 o .pem)
+	SunatCertificadoClave string
+
+	// Reservas web
+	ReservaRetencion        time.Duration // Tiempo que se retiene el cupo de una reserva web pendiente de pago
+	ReservaBarridoIntervalo time.Duration // Cada cuánto se liberan las retenciones vencidas
+
+	// Reservas
+	ReservaLimiteCancelacion time.Duration // Anticipación mínima al inicio del tour para que un cliente cancele
+
+	// Embarque
+	EmbarqueSecret string // Llave con la que se firman los códigos QR de embarque
 
 	// Aplicación
 	LogLevel string
 	Env      string
 }
 
+// Llaves por defecto de LoadConfig: solo sirven para desarrollo porque son públicas en el código
+const (
+	jwtSecretDefecto        = "sistema-tours-secret-key"
+	jwtRefreshSecretDefecto = "sistema-tours-refresh-secret-key"
+	embarqueSecretDefecto   = "sistema-tours-embarque-secret-key"
+)
+
+// llavesPublicas son las llaves publicadas en el repositorio, en el código o en el .env de ejemplo.
+// Cualquiera puede firmar tokens y códigos de embarque con ellas, así que no se aceptan en producción.
+var llavesPublicas = map[string]bool{
+	jwtSecretDefecto:                              true,
+	jwtRefreshSecretDefecto:                       true,
+	embarqueSecretDefecto:                         true,
+	"clave-segura-para-desarrollo":                true,
+	"clave-segura-refresh-para-desarrollo":        true,
+	"clave-segura-embarque-para-desarrollo":       true,
+	"cambiar-por-una-llave-aleatoria":             true,
+	"cambiar-por-otra-llave-aleatoria":            true,
+	"cambiar-por-una-llave-aleatoria-de-embarque": true,
+}
+
+// longitudMinimaLlave es la cantidad mínima de caracteres de las llaves de firma en producción
+const longitudMinimaLlave = 32
+
 // LoadConfig carga la configuración desde variables de entorno o archivo .env
 func LoadConfig() *Config {
 	// Intentar cargar .env si existe
@@ -52,9 +131,61 @@ func LoadConfig() *Config {
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 
 		// JWT
-		JWTSecret:        getEnv("JWT_SECRET", "sistema-tours-secret-key"),
-		JWTRefreshSecret: getEnv("JWT_REFRESH_SECRET", "sistema-tours-refresh-secret-key"),
-		JWTExpiration:    time.Hour * 24, // 1 día por defecto
+		JWTSecret:              getEnv("JWT_SECRET", jwtSecretDefecto),
+		JWTRefreshSecret:       getEnv("JWT_REFRESH_SECRET", jwtRefreshSecretDefecto),
+		JWTExpiration:          time.Minute * 15, // Corto porque la sesión se renueva con el refresh token
+		JWTRefreshExpiration:   time.Hour * 24 * 7,
+		TokenLimpiezaIntervalo: time.Hour,
+
+		// Protección del inicio de sesión
+		LoginMaxFallos:   5,
+		LoginMaxFallosIP: 20,
+		LoginBloqueo:     time.Minute * 15,
+		LoginRetrasoBase: time.Second,
+
+		// Permisos
+		PermisosVigencia: time.Minute,
+
+		// Llaves de API de agencias
+		APIKeyLimiteMinuto: 60,
+
+		// Doble factor (TOTP)
+		DobleFactorRoles:   parseLista(getEnv("DOBLE_FACTOR_ROLES", "")),
+		DobleFactorEmisor:  getEnv("DOBLE_FACTOR_EMISOR", "Sistema Tours"),
+		DobleFactorDesafio: time.Minute * 5,
+
+		// Correo electrónico
+		CorreoModo:      getEnv("CORREO_MODO", "fake"),
+		CorreoRemitente: getEnv("CORREO_REMITENTE", "no-responder@sistema-tours.com"),
+		SMTPHost:        getEnv("SMTP_HOST", ""),
+		SMTPPort:        getEnv("SMTP_PORT", "587"),
+		SMTPUsuario:     getEnv("SMTP_USUARIO", ""),
+		SMTPClave:       getEnv("SMTP_CLAVE", ""),
+
+		// Recuperación de contraseña
+		RecuperacionURL:      getEnv("RECUPERACION_URL", "http://localhost:3000/restablecer-contrasena"),
+		RecuperacionVigencia: time.Minute * 30,
+
+		// Facturación electrónica (SUNAT)
+		SunatModo:             getEnv("SUNAT_MODO", "fake"),
+		SunatRUC:              getEnv("SUNAT_RUC", "20000000001"),
+		SunatRazonSocial:      getEnv("SUNAT_RAZON_SOCIAL", "SISTEMA TOURS S.A.C."),
+		SunatNombreComercial:  getEnv("SUNAT_NOMBRE_COMERCIAL", ""),
+		SunatDireccion:        getEnv("SUNAT_DIRECCION", ""),
+		SunatUsuarioSOL:       getEnv("SUNAT_USUARIO_SOL", ""),
+		SunatClaveSOL:         getEnv("SUNAT_CLAVE_SOL", ""),
+		SunatCertificado:      getEnv("SUNAT_CERTIFICADO", ""),
+		SunatCertificadoClave: getEnv("SUNAT_CERTIFICADO_CLAVE", ""),
+
+		// Reservas web
+		ReservaRetencion:        time.Minute * 15,
+		ReservaBarridoIntervalo: time.Minute,
+
+		// Reservas
+		ReservaLimiteCancelacion: time.Hour * 24,
+
+		// Embarque
+		EmbarqueSecret: getEnv("EMBARQUE_SECRET", embarqueSecretDefecto),
 
 		// Aplicación
 		LogLevel: getEnv("LOG_LEVEL", "info"),
@@ -67,10 +198,108 @@ func LoadConfig() *Config {
 			config.JWTExpiration = time.Hour * time.Duration(hours)
 		}
 	}
+	if jwtExp := getEnv("JWT_EXPIRATION_MINUTES", ""); jwtExp != "" {
+		if minutes, err := strconv.Atoi(jwtExp); err == nil && minutes > 0 {
+			config.JWTExpiration = time.Minute * time.Duration(minutes)
+		}
+	}
+	if refreshExp := getEnv("REFRESH_TOKEN_DAYS", ""); refreshExp != "" {
+		if days, err := strconv.Atoi(refreshExp); err == nil && days > 0 {
+			config.JWTRefreshExpiration = time.Hour * 24 * time.Duration(days)
+		}
+	}
+
+	// Parsear límites de inicio de sesión si están definidos
+	if maxFallos := getEnv("LOGIN_MAX_FALLOS", ""); maxFallos != "" {
+		if fallos, err := strconv.Atoi(maxFallos); err == nil && fallos > 0 {
+			config.LoginMaxFallos = fallos
+		}
+	}
+	if maxFallosIP := getEnv("LOGIN_MAX_FALLOS_IP", ""); maxFallosIP != "" {
+		if fallos, err := strconv.Atoi(maxFallosIP); err == nil && fallos > 0 {
+			config.LoginMaxFallosIP = fallos
+		}
+	}
+	if bloqueo := getEnv("LOGIN_BLOQUEO_MINUTOS", ""); bloqueo != "" {
+		if minutes, err := strconv.Atoi(bloqueo); err == nil && minutes > 0 {
+			config.LoginBloqueo = time.Minute * time.Duration(minutes)
+		}
+	}
+
+	if limite := getEnv("API_KEY_LIMITE_MINUTO", ""); limite != "" {
+		if peticiones, err := strconv.Atoi(limite); err == nil && peticiones > 0 {
+			config.APIKeyLimiteMinuto = peticiones
+		}
+	}
+
+	if vigencia := getEnv("RECUPERACION_VIGENCIA_MINUTOS", ""); vigencia != "" {
+		if minutes, err := strconv.Atoi(vigencia); err == nil && minutes > 0 {
+			config.RecuperacionVigencia = time.Minute * time.Duration(minutes)
+		}
+	}
+
+	// Parsear tiempos de retención de reservas web si están definidos
+	if retencion := getEnv("RESERVA_RETENCION_MINUTOS", ""); retencion != "" {
+		if minutes, err := strconv.Atoi(retencion); err == nil && minutes > 0 {
+			config.ReservaRetencion = time.Minute * time.Duration(minutes)
+		}
+	}
+	if barrido := getEnv("RESERVA_BARRIDO_SEGUNDOS", ""); barrido != "" {
+		if seconds, err := strconv.Atoi(barrido); err == nil && seconds > 0 {
+			config.ReservaBarridoIntervalo = time.Second * time.Duration(seconds)
+		}
+	}
+
+	if limite := getEnv("RESERVA_LIMITE_CANCELACION_HORAS", ""); limite != "" {
+		if hours, err := strconv.Atoi(limite); err == nil && hours >= 0 {
+			config.ReservaLimiteCancelacion = time.Hour * time.Duration(hours)
+		}
+	}
 
 	return config
 }
 
+// Validar verifica que la configuración sea segura para el entorno.
+// En producción no se aceptan llaves públicas ni cortas, con las que cualquiera podría firmar tokens.
+func (c *Config) Validar() error {
+	if c.Env != "production" {
+		return nil
+	}
+
+	llaves := []struct {
+		nombre string
+		valor  string
+	}{
+		{"JWT_SECRET", c.JWTSecret},
+		{"JWT_REFRESH_SECRET", c.JWTRefreshSecret},
+		{"EMBARQUE_SECRET", c.EmbarqueSecret},
+	}
+	for _, llave := range llaves {
+		if llavesPublicas[llave.valor] {
+			return fmt.Errorf("%s debe configurarse en producción con una llave propia", llave.nombre)
+		}
+		if len(llave.valor) < longitudMinimaLlave {
+			return fmt.Errorf("%s debe tener al menos %d caracteres", llave.nombre, longitudMinimaLlave)
+		}
+	}
+	if c.JWTSecret == c.JWTRefreshSecret {
+		return errors.New("JWT_SECRET y JWT_REFRESH_SECRET deben ser distintos")
+	}
+
+	return nil
+}
+
+// parseLista separa una lista de valores por comas, ignorando espacios y elementos vacíos
+func parseLista(valor string) []string {
+	var lista []string
+	for _, elemento := range strings.Split(valor, ",") {
+		if elemento = strings.TrimSpace(elemento); elemento != "" {
+			lista = append(lista, elemento)
+		}
+	}
+	return lista
+}
+
 // getEnv obtiene una variable de entorno o devuelve un valor por defecto
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
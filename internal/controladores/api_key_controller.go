@@ -0,0 +1,130 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyController maneja los endpoints de administración de las llaves de API de las agencias
+type APIKeyController struct {
+	apiKeyService *servicios.APIKeyService
+}
+
+// NewAPIKeyController crea una nueva instancia de APIKeyController
+func NewAPIKeyController(apiKeyService *servicios.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Create emite una llave de API y devuelve su valor en claro por única vez
+func (c *APIKeyController) Create(ctx *gin.Context) {
+	// Obtener usuario autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+		return
+	}
+
+	var apiKeyReq entidades.NuevaAPIKeyRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&apiKeyReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(apiKeyReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Emitir llave
+	creada, err := c.apiKeyService.Create(&apiKeyReq, principal.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al emitir la llave de API", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Llave de API emitida; guárdela, no se volverá a mostrar", creada))
+}
+
+// List lista las llaves de API
+func (c *APIKeyController) List(ctx *gin.Context) {
+	// Listar llaves
+	apiKeys, err := c.apiKeyService.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar las llaves de API", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Llaves de API listadas exitosamente", apiKeys))
+}
+
+// GetByID obtiene una llave de API por su ID
+func (c *APIKeyController) GetByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener llave
+	apiKey, err := c.apiKeyService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Llave de API no encontrada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Llave de API obtenida", apiKey))
+}
+
+// Revocar deja sin efecto una llave de API
+func (c *APIKeyController) Revocar(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Revocar llave
+	if err := c.apiKeyService.Revocar(id); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al revocar la llave de API", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Llave de API revocada exitosamente", nil))
+}
+
+// ListUso lista las peticiones más recientes hechas con una llave de API, con las reservas que creó
+func (c *APIKeyController) ListUso(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Listar uso
+	usos, err := c.apiKeyService.ListUso(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Error al listar el uso de la llave de API", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Uso de la llave de API listado exitosamente", usos))
+}
@@ -1,10 +1,13 @@
 package controladores
 
 import (
+	"errors"
 	"net/http"
 	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
 	"sistema-tours/internal/servicios"
 	"sistema-tours/internal/utils"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,9 +41,15 @@ func (c *AuthController) Login(ctx *gin.Context) {
 	}
 
 	// Intentar login
-	loginResp, err := c.authService.Login(&loginReq)
+	loginResp, err := c.authService.Login(&loginReq, ctx.ClientIP())
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Autenticación fallida", err))
+		responderLoginFallido(ctx, "Autenticación fallida", err)
+		return
+	}
+
+	// Falta el segundo factor: devolver solo el token de desafío
+	if loginResp.RequiereDobleFactor {
+		ctx.JSON(http.StatusOK, utils.SuccessResponse("Se requiere verificación en dos pasos", loginResp))
 		return
 	}
 
@@ -48,6 +57,87 @@ func (c *AuthController) Login(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.SuccessResponse("Login exitoso", loginResp))
 }
 
+// VerificarDobleFactor completa el login con el token de desafío y un código TOTP o de recuperación
+func (c *AuthController) VerificarDobleFactor(ctx *gin.Context) {
+	var verificarReq entidades.VerificarDobleFactorRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&verificarReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(verificarReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Verificar código
+	loginResp, err := c.authService.VerificarDobleFactor(&verificarReq, ctx.ClientIP())
+	if err != nil {
+		responderLoginFallido(ctx, "Verificación fallida", err)
+		return
+	}
+
+	// Devolver tokens y datos de usuario
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Login exitoso", loginResp))
+}
+
+// EnrolarDobleFactor inicia el enrolamiento obligatorio de doble factor con el token de desafío del login
+func (c *AuthController) EnrolarDobleFactor(ctx *gin.Context) {
+	var desafioReq entidades.DesafioDobleFactorRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&desafioReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(desafioReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Generar secreto
+	enrolamiento, err := c.authService.EnrolarDobleFactorDesafio(desafioReq.TokenDesafio, ctx.ClientIP())
+	if err != nil {
+		responderLoginFallido(ctx, "Error al configurar el doble factor", err)
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Escanee el código QR con su aplicación autenticadora", enrolamiento))
+}
+
+// ActivarDobleFactor confirma el enrolamiento obligatorio con el primer código y completa el login
+func (c *AuthController) ActivarDobleFactor(ctx *gin.Context) {
+	var activarReq entidades.VerificarDobleFactorRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&activarReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(activarReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Activar doble factor e iniciar sesión
+	loginResp, err := c.authService.ActivarDobleFactorDesafio(&activarReq, ctx.ClientIP())
+	if err != nil {
+		responderLoginFallido(ctx, "Error al activar el doble factor", err)
+		return
+	}
+
+	// Devolver tokens, datos de usuario y códigos de recuperación
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Doble factor activado; guarde los códigos de recuperación", loginResp))
+}
+
 // RefreshToken renueva el token de acceso
 func (c *AuthController) RefreshToken(ctx *gin.Context) {
 	var refreshReq struct {
@@ -73,8 +163,8 @@ func (c *AuthController) RefreshToken(ctx *gin.Context) {
 
 // ChangePassword cambia la contraseña del usuario
 func (c *AuthController) ChangePassword(ctx *gin.Context) {
-	// Obtener ID de usuario del contexto (establecido por el middleware de autenticación)
-	userID, exists := ctx.Get("userID")
+	// Obtener usuario autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
 		return
@@ -98,7 +188,88 @@ func (c *AuthController) ChangePassword(ctx *gin.Context) {
 	}
 
 	// Cambiar contraseña
-	err := c.authService.ChangePassword(userID.(int), changePassReq.CurrentPassword, changePassReq.NewPassword)
+	err := c.authService.ChangePassword(principal.ID, changePassReq.CurrentPassword, changePassReq.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cambiar contraseña", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Contraseña cambiada exitosamente", nil))
+}
+
+// LoginCliente maneja el inicio de sesión de un cliente
+func (c *AuthController) LoginCliente(ctx *gin.Context) {
+	var loginReq entidades.LoginClienteRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&loginReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(loginReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Intentar login
+	loginResp, err := c.authService.LoginCliente(&loginReq, ctx.ClientIP())
+	if err != nil {
+		responderLoginFallido(ctx, "Credenciales incorrectas", err)
+		return
+	}
+
+	// Devolver tokens y datos del cliente
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Login exitoso", loginResp))
+}
+
+// RefreshTokenCliente renueva los tokens de un cliente
+func (c *AuthController) RefreshTokenCliente(ctx *gin.Context) {
+	var refreshReq struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&refreshReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Renovar token
+	loginResp, err := c.authService.RefreshTokenCliente(refreshReq.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Token de actualización inválido", err))
+		return
+	}
+
+	// Devolver nuevos tokens
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Token renovado exitosamente", loginResp))
+}
+
+// ChangePasswordCliente cambia la contraseña del cliente autenticado
+func (c *AuthController) ChangePasswordCliente(ctx *gin.Context) {
+	// Obtener cliente autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists || !principal.EsCliente() {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Cliente no autenticado", nil))
+		return
+	}
+
+	var changePassReq struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=6"`
+	}
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&changePassReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Cambiar contraseña
+	err := c.authService.ChangePasswordCliente(principal.ID, changePassReq.CurrentPassword, changePassReq.NewPassword)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cambiar contraseña", err))
 		return
@@ -107,3 +278,101 @@ func (c *AuthController) ChangePassword(ctx *gin.Context) {
 	// Respuesta exitosa
 	ctx.JSON(http.StatusOK, utils.SuccessResponse("Contraseña cambiada exitosamente", nil))
 }
+
+// Logout cierra la sesión del usuario autenticado
+func (c *AuthController) Logout(ctx *gin.Context) {
+	// Obtener usuario autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists || principal.EsCliente() {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+		return
+	}
+
+	var logoutReq entidades.LogoutRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&logoutReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(logoutReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Cerrar sesión
+	if err := c.authService.Logout(principal.ID, principal.JTI, principal.Expiracion, logoutReq.RefreshToken); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cerrar sesión", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Sesión cerrada exitosamente", nil))
+}
+
+// LogoutTodos cierra todas las sesiones del sujeto autenticado (usuario o cliente) en todos sus dispositivos
+func (c *AuthController) LogoutTodos(ctx *gin.Context) {
+	// Obtener sujeto autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+		return
+	}
+
+	// Cerrar todas las sesiones
+	if err := c.authService.LogoutTodos(principal.Tipo, principal.ID, principal.JTI, principal.Expiracion); err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al cerrar las sesiones", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Sesiones cerradas en todos los dispositivos", nil))
+}
+
+// LogoutCliente cierra la sesión del cliente autenticado
+func (c *AuthController) LogoutCliente(ctx *gin.Context) {
+	// Obtener cliente autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists || !principal.EsCliente() {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Cliente no autenticado", nil))
+		return
+	}
+
+	var logoutReq entidades.LogoutRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&logoutReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(logoutReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Cerrar sesión
+	if err := c.authService.LogoutCliente(principal.ID, principal.JTI, principal.Expiracion, logoutReq.RefreshToken); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cerrar sesión", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Sesión cerrada exitosamente", nil))
+}
+
+// responderLoginFallido responde 429 con Retry-After si la cuenta o la IP están bloqueadas y 401 en otro caso
+func responderLoginFallido(ctx *gin.Context, mensaje string, err error) {
+	var bloqueo *servicios.BloqueoLoginError
+	if errors.As(err, &bloqueo) {
+		segundos := int(bloqueo.Espera.Seconds()) + 1
+		ctx.Header("Retry-After", strconv.Itoa(segundos))
+		ctx.JSON(http.StatusTooManyRequests, utils.ErrorResponse("Demasiados intentos de inicio de sesión", err))
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse(mensaje, err))
+}
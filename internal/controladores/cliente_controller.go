@@ -2,7 +2,6 @@ package controladores
 
 import (
 	"net/http"
-	"sistema-tours/internal/config"
 	"sistema-tours/internal/entidades"
 	"sistema-tours/internal/servicios"
 	"sistema-tours/internal/utils"
@@ -14,14 +13,12 @@ import (
 // ClienteController maneja los endpoints de clientes
 type ClienteController struct {
 	clienteService *servicios.ClienteService
-	config         *config.Config
 }
 
 // NewClienteController crea una nueva instancia de ClienteController
-func NewClienteController(clienteService *servicios.ClienteService, config *config.Config) *ClienteController {
+func NewClienteController(clienteService *servicios.ClienteService) *ClienteController {
 	return &ClienteController{
 		clienteService: clienteService,
-		config:         config,
 	}
 }
 
@@ -149,64 +146,3 @@ func (c *ClienteController) List(ctx *gin.Context) {
 	// Respuesta exitosa
 	ctx.JSON(http.StatusOK, utils.SuccessResponse("Clientes listados exitosamente", clientes))
 }
-
-// Login maneja el inicio de sesión de un cliente
-func (c *ClienteController) Login(ctx *gin.Context) {
-	var loginReq entidades.LoginClienteRequest
-
-	// Parsear request
-	if err := ctx.ShouldBindJSON(&loginReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
-		return
-	}
-
-	// Validar datos
-	if err := utils.ValidateStruct(loginReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
-		return
-	}
-
-	// Intentar login
-	cliente, err := c.clienteService.Login(loginReq.Correo, loginReq.Contrasena)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Credenciales incorrectas", err))
-		return
-	}
-
-	// CAMBIO IMPORTANTE: Asegurar que el rol sea exactamente como lo espera el middleware RoleMiddleware
-	usuarioEquivalente := &entidades.Usuario{
-		ID:     cliente.ID,
-		Correo: cliente.Correo,
-		Rol:    "CLIENTE", // Asegurar que coincida exactamente con lo que espera el RoleMiddleware
-	}
-
-	// Generar token JWT
-	token, err := utils.GenerateJWT(usuarioEquivalente, c.config)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al generar token", err))
-		return
-	}
-
-	// Generar refresh token
-	refreshToken, err := utils.GenerateRefreshToken(usuarioEquivalente, c.config)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al generar refresh token", err))
-		return
-	}
-
-	// Respuesta exitosa - NO cambiar el formato de esta respuesta
-	ctx.JSON(http.StatusOK, utils.SuccessResponse("Login exitoso", gin.H{
-		"token":         token,
-		"refresh_token": refreshToken,
-		"usuario": gin.H{
-			"id_cliente":       cliente.ID,
-			"nombres":          cliente.Nombres,
-			"apellidos":        cliente.Apellidos,
-			"nombre_completo":  cliente.Nombres + " " + cliente.Apellidos,
-			"tipo_documento":   cliente.TipoDocumento,
-			"numero_documento": cliente.NumeroDocumento,
-			"correo":           cliente.Correo,
-			"rol":              "CLIENTE",
-		},
-	}))
-}
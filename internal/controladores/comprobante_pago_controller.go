@@ -0,0 +1,173 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ComprobantePagoController maneja los endpoints de comprobantes de pago
+type ComprobantePagoController struct {
+	comprobanteService *servicios.ComprobantePagoService
+}
+
+// NewComprobantePagoController crea una nueva instancia de ComprobantePagoController
+func NewComprobantePagoController(comprobanteService *servicios.ComprobantePagoService) *ComprobantePagoController {
+	return &ComprobantePagoController{
+		comprobanteService: comprobanteService,
+	}
+}
+
+// Create emite un nuevo comprobante de pago
+func (c *ComprobantePagoController) Create(ctx *gin.Context) {
+	var comprobanteReq entidades.NuevoComprobantePagoRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&comprobanteReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(comprobanteReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Emitir comprobante
+	id, err := c.comprobanteService.Create(&comprobanteReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al emitir comprobante", err))
+		return
+	}
+
+	// Obtener el comprobante emitido
+	comprobante, err := c.comprobanteService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el comprobante emitido", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Comprobante emitido exitosamente", comprobante))
+}
+
+// GetByID obtiene un comprobante por su ID
+func (c *ComprobantePagoController) GetByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener comprobante
+	comprobante, err := c.comprobanteService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Comprobante no encontrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Comprobante obtenido", comprobante))
+}
+
+// CambiarEstado cambia el estado de un comprobante
+func (c *ComprobantePagoController) CambiarEstado(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	var estadoReq entidades.CambiarEstadoComprobanteRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&estadoReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(estadoReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Cambiar estado
+	err = c.comprobanteService.CambiarEstado(id, &estadoReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cambiar estado del comprobante", err))
+		return
+	}
+
+	// Obtener el comprobante actualizado
+	comprobante, err := c.comprobanteService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el comprobante actualizado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Estado del comprobante actualizado exitosamente", comprobante))
+}
+
+// List lista todos los comprobantes
+func (c *ComprobantePagoController) List(ctx *gin.Context) {
+	// Listar comprobantes
+	comprobantes, err := c.comprobanteService.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar comprobantes", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Comprobantes listados exitosamente", comprobantes))
+}
+
+// ListByReserva lista todos los comprobantes de una reserva
+func (c *ComprobantePagoController) ListByReserva(ctx *gin.Context) {
+	// Parsear ID de la reserva de la URL
+	idReserva, err := strconv.Atoi(ctx.Param("idReserva"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de reserva inválido", err))
+		return
+	}
+
+	// Listar comprobantes de la reserva
+	comprobantes, err := c.comprobanteService.ListByReserva(idReserva)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar comprobantes de la reserva", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Comprobantes de la reserva listados exitosamente", comprobantes))
+}
+
+// ListByFecha lista todos los comprobantes emitidos en una fecha específica
+func (c *ComprobantePagoController) ListByFecha(ctx *gin.Context) {
+	// Parsear fecha de la URL (formato: YYYY-MM-DD)
+	fechaStr := ctx.Param("fecha")
+	fecha, err := time.Parse("2006-01-02", fechaStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Formato de fecha inválido, debe ser YYYY-MM-DD", err))
+		return
+	}
+
+	// Listar comprobantes por fecha
+	comprobantes, err := c.comprobanteService.ListByFecha(fecha)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar comprobantes por fecha", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Comprobantes por fecha listados exitosamente", comprobantes))
+}
@@ -0,0 +1,57 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CotizacionController maneja los endpoints de cotización de pasajes
+type CotizacionController struct {
+	cotizacionService *servicios.CotizacionService
+}
+
+// NewCotizacionController crea una nueva instancia de CotizacionController
+func NewCotizacionController(cotizacionService *servicios.CotizacionService) *CotizacionController {
+	return &CotizacionController{
+		cotizacionService: cotizacionService,
+	}
+}
+
+// Cotizar calcula el precio de los pasajes de un tour programado antes de reservar
+func (c *CotizacionController) Cotizar(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	var cotizacionReq entidades.CotizacionRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&cotizacionReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(cotizacionReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Cotizar
+	cotizacion, err := c.cotizacionService.Cotizar(id, cotizacionReq.CantidadPasajes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cotizar", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Cotización calculada", cotizacion))
+}
@@ -0,0 +1,142 @@
+package controladores
+
+import (
+	"errors"
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DobleFactorController maneja los endpoints de configuración del doble factor (TOTP)
+type DobleFactorController struct {
+	dobleFactorService *servicios.DobleFactorService
+}
+
+// NewDobleFactorController crea una nueva instancia de DobleFactorController
+func NewDobleFactorController(dobleFactorService *servicios.DobleFactorService) *DobleFactorController {
+	return &DobleFactorController{
+		dobleFactorService: dobleFactorService,
+	}
+}
+
+// Enrolar genera el secreto del usuario autenticado y devuelve el URI y el QR para la aplicación autenticadora
+func (c *DobleFactorController) Enrolar(ctx *gin.Context) {
+	// Obtener usuario autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+		return
+	}
+
+	// Generar secreto
+	enrolamiento, err := c.dobleFactorService.Enrolar(principal.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al configurar el doble factor", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Escanee el código QR con su aplicación autenticadora", enrolamiento))
+}
+
+// Activar confirma el enrolamiento con el primer código y devuelve los códigos de recuperación
+func (c *DobleFactorController) Activar(ctx *gin.Context) {
+	// Obtener usuario autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+		return
+	}
+
+	codigoReq, ok := bindCodigoDobleFactor(ctx)
+	if !ok {
+		return
+	}
+
+	// Activar doble factor
+	codigos, err := c.dobleFactorService.Activar(principal.ID, codigoReq.Codigo)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al activar el doble factor", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Doble factor activado; guarde los códigos de recuperación", entidades.CodigosRecuperacionResponse{
+		CodigosRecuperacion: codigos,
+	}))
+}
+
+// Desactivar elimina el doble factor del usuario autenticado tras verificar un código
+func (c *DobleFactorController) Desactivar(ctx *gin.Context) {
+	// Obtener usuario autenticado (establecido por el middleware de autenticación)
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+		return
+	}
+
+	codigoReq, ok := bindCodigoDobleFactor(ctx)
+	if !ok {
+		return
+	}
+
+	// Desactivar doble factor
+	if err := c.dobleFactorService.Desactivar(principal.ID, principal.Rol, codigoReq.Codigo); err != nil {
+		if errors.Is(err, servicios.ErrDobleFactorObligatorio) {
+			ctx.JSON(http.StatusForbidden, utils.ErrorResponse("Error al desactivar el doble factor", err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al desactivar el doble factor", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Doble factor desactivado exitosamente", nil))
+}
+
+// Restablecer elimina el doble factor de un usuario que perdió el acceso a su aplicación autenticadora
+func (c *DobleFactorController) Restablecer(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Restablecer doble factor
+	if err := c.dobleFactorService.Restablecer(id); err != nil {
+		if errors.Is(err, servicios.ErrDobleFactorNoConfigurado) {
+			ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Error al restablecer el doble factor", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al restablecer el doble factor", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Doble factor restablecido exitosamente", nil))
+}
+
+// bindCodigoDobleFactor parsea y valida el código del request; si falla ya respondió al cliente
+func bindCodigoDobleFactor(ctx *gin.Context) (*entidades.CodigoDobleFactorRequest, bool) {
+	var codigoReq entidades.CodigoDobleFactorRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&codigoReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return nil, false
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(codigoReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return nil, false
+	}
+
+	return &codigoReq, true
+}
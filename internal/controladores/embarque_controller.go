@@ -0,0 +1,97 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmbarqueController maneja los endpoints del check-in de pasajeros
+type EmbarqueController struct {
+	embarqueService *servicios.EmbarqueService
+}
+
+// NewEmbarqueController crea una nueva instancia de EmbarqueController
+func NewEmbarqueController(embarqueService *servicios.EmbarqueService) *EmbarqueController {
+	return &EmbarqueController{
+		embarqueService: embarqueService,
+	}
+}
+
+// Registrar valida el código QR escaneado y registra el embarque de la reserva
+func (c *EmbarqueController) Registrar(ctx *gin.Context) {
+	// Parsear ID de la URL
+	idTourProgramado, err := strconv.Atoi(ctx.Param("idTourProgramado"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de tour programado inválido", err))
+		return
+	}
+
+	var embarqueReq entidades.RegistrarEmbarqueRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&embarqueReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(embarqueReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Registrar embarque
+	embarque, err := c.embarqueService.Registrar(idTourProgramado, &embarqueReq, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al registrar el embarque", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Embarque registrado exitosamente", embarque))
+}
+
+// ListByTourProgramado lista los embarques registrados de una salida
+func (c *EmbarqueController) ListByTourProgramado(ctx *gin.Context) {
+	// Parsear ID de la URL
+	idTourProgramado, err := strconv.Atoi(ctx.Param("idTourProgramado"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de tour programado inválido", err))
+		return
+	}
+
+	// Listar embarques
+	embarques, err := c.embarqueService.ListByTourProgramado(idTourProgramado, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al listar embarques", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Embarques listados exitosamente", embarques))
+}
+
+// Contador devuelve los pasajeros embarcados frente a los reservados de una salida
+func (c *EmbarqueController) Contador(ctx *gin.Context) {
+	// Parsear ID de la URL
+	idTourProgramado, err := strconv.Atoi(ctx.Param("idTourProgramado"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de tour programado inválido", err))
+		return
+	}
+
+	// Obtener contador
+	contador, err := c.embarqueService.Contador(idTourProgramado, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al obtener el contador de embarque", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Contador de embarque obtenido", contador))
+}
@@ -0,0 +1,235 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FacturacionElectronicaController maneja los endpoints de facturación electrónica (SUNAT)
+type FacturacionElectronicaController struct {
+	facturacionService *servicios.FacturacionElectronicaService
+	comprobanteService *servicios.ComprobantePagoService
+	notaService        *servicios.NotaCreditoService
+}
+
+// NewFacturacionElectronicaController crea una nueva instancia de FacturacionElectronicaController
+func NewFacturacionElectronicaController(
+	facturacionService *servicios.FacturacionElectronicaService,
+	comprobanteService *servicios.ComprobantePagoService,
+	notaService *servicios.NotaCreditoService,
+) *FacturacionElectronicaController {
+	return &FacturacionElectronicaController{
+		facturacionService: facturacionService,
+		comprobanteService: comprobanteService,
+		notaService:        notaService,
+	}
+}
+
+// EnviarComprobante envía una factura a SUNAT
+func (c *FacturacionElectronicaController) EnviarComprobante(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Enviar comprobante
+	err = c.facturacionService.EnviarComprobante(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al enviar comprobante a SUNAT", err))
+		return
+	}
+
+	// Obtener el comprobante actualizado
+	comprobante, err := c.comprobanteService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el comprobante enviado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Comprobante enviado a SUNAT", comprobante))
+}
+
+// GetXML descarga el XML firmado de un comprobante
+func (c *FacturacionElectronicaController) GetXML(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener XML
+	xmlFirmado, err := c.facturacionService.GetXMLFirmado(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("XML no disponible", err))
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(xmlFirmado))
+}
+
+// EnviarNotaCredito envía una nota de crédito a SUNAT
+func (c *FacturacionElectronicaController) EnviarNotaCredito(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Enviar nota de crédito
+	err = c.facturacionService.EnviarNotaCredito(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al enviar nota de crédito a SUNAT", err))
+		return
+	}
+
+	// Obtener la nota actualizada
+	nota, err := c.notaService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la nota de crédito enviada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Nota de crédito enviada a SUNAT", nota))
+}
+
+// GetXMLNotaCredito descarga el XML firmado de una nota de crédito
+func (c *FacturacionElectronicaController) GetXMLNotaCredito(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener XML
+	xmlFirmado, err := c.facturacionService.GetXMLNotaCredito(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("XML no disponible", err))
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(xmlFirmado))
+}
+
+// GenerarResumenDiario genera y envía el resumen diario de boletas de una fecha
+func (c *FacturacionElectronicaController) GenerarResumenDiario(ctx *gin.Context) {
+	c.generarResumen(ctx, c.facturacionService.GenerarResumenDiario, "Resumen diario enviado a SUNAT")
+}
+
+// GenerarComunicacionBaja genera y envía la comunicación de baja de facturas anuladas de una fecha
+func (c *FacturacionElectronicaController) GenerarComunicacionBaja(ctx *gin.Context) {
+	c.generarResumen(ctx, c.facturacionService.GenerarComunicacionBaja, "Comunicación de baja enviada a SUNAT")
+}
+
+// generarResumen procesa la solicitud común a resúmenes diarios y comunicaciones de baja
+func (c *FacturacionElectronicaController) generarResumen(ctx *gin.Context, generar func(time.Time) (int, error), mensaje string) {
+	var resumenReq entidades.NuevoResumenSunatRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&resumenReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(resumenReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Parsear fecha (formato: YYYY-MM-DD)
+	fecha, err := time.Parse("2006-01-02", resumenReq.Fecha)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Formato de fecha inválido, debe ser YYYY-MM-DD", err))
+		return
+	}
+
+	// Generar y enviar
+	id, err := generar(fecha)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al generar el resumen", err))
+		return
+	}
+
+	// Obtener el resumen generado
+	resumen, err := c.facturacionService.GetResumenByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el resumen generado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse(mensaje, resumen))
+}
+
+// ConsultarResumen consulta en SUNAT el estado del ticket de un resumen
+func (c *FacturacionElectronicaController) ConsultarResumen(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Consultar ticket
+	err = c.facturacionService.ConsultarResumen(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al consultar el resumen", err))
+		return
+	}
+
+	// Obtener el resumen actualizado
+	resumen, err := c.facturacionService.GetResumenByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el resumen", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Estado del resumen consultado", resumen))
+}
+
+// GetResumenByID obtiene un resumen por su ID
+func (c *FacturacionElectronicaController) GetResumenByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener resumen
+	resumen, err := c.facturacionService.GetResumenByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Resumen no encontrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Resumen obtenido", resumen))
+}
+
+// ListResumenes lista todos los resúmenes enviados a SUNAT
+func (c *FacturacionElectronicaController) ListResumenes(ctx *gin.Context) {
+	// Listar resúmenes
+	resumenes, err := c.facturacionService.ListResumenes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar resúmenes", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Resúmenes listados exitosamente", resumenes))
+}
@@ -3,6 +3,7 @@ package controladores
 import (
 	"net/http"
 	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
 	"sistema-tours/internal/servicios"
 	"sistema-tours/internal/utils"
 	"strconv"
@@ -198,15 +199,15 @@ func (c *HorarioChoferController) ListByDia(ctx *gin.Context) {
 
 // GetMyActiveHorarios obtiene los horarios activos del chofer autenticado
 func (c *HorarioChoferController) GetMyActiveHorarios(ctx *gin.Context) {
-	// Obtener ID del usuario autenticado del contexto
-	userID, exists := ctx.Get("user_id")
+	// Obtener usuario autenticado del contexto
+	principal, exists := middleware.GetPrincipal(ctx)
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
 		return
 	}
 
 	// Listar horarios activos del chofer
-	horarios, err := c.horarioChoferService.ListActiveByChofer(userID.(int))
+	horarios, err := c.horarioChoferService.ListActiveByChofer(principal.ID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar horarios activos", err))
 		return
@@ -0,0 +1,99 @@
+package controladores
+
+import (
+	"fmt"
+	"net/http"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpresionController maneja los endpoints de documentos imprimibles (PDF)
+type ImpresionController struct {
+	impresionService *servicios.ImpresionService
+}
+
+// NewImpresionController crea una nueva instancia de ImpresionController
+func NewImpresionController(impresionService *servicios.ImpresionService) *ImpresionController {
+	return &ImpresionController{
+		impresionService: impresionService,
+	}
+}
+
+// ComprobantePDF descarga la representación impresa de un comprobante
+func (c *ImpresionController) ComprobantePDF(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Generar PDF
+	pdf, nombre, err := c.impresionService.ComprobantePDF(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al generar el PDF del comprobante", err))
+		return
+	}
+
+	enviarPDF(ctx, pdf, nombre)
+}
+
+// TicketReserva descarga el ticket de embarque de una reserva
+func (c *ImpresionController) TicketReserva(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Generar PDF
+	pdf, nombre, err := c.impresionService.TicketPDF(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al generar el ticket de la reserva", err))
+		return
+	}
+
+	enviarPDF(ctx, pdf, nombre)
+}
+
+// Manifiesto descarga el manifiesto de pasajeros de un tour programado en PDF (por defecto) o CSV (?formato=csv)
+func (c *ImpresionController) Manifiesto(ctx *gin.Context) {
+	// Parsear ID de la URL
+	idTourProgramado, err := strconv.Atoi(ctx.Param("idTourProgramado"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de tour programado inválido", err))
+		return
+	}
+
+	actor := actorDesdeContexto(ctx)
+
+	switch ctx.DefaultQuery("formato", "pdf") {
+	case "pdf":
+		pdf, nombre, err := c.impresionService.ManifiestoPDF(idTourProgramado, actor)
+		if err != nil {
+			ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al generar el manifiesto", err))
+			return
+		}
+		enviarPDF(ctx, pdf, nombre)
+	case "csv":
+		csv, nombre, err := c.impresionService.ManifiestoCSV(idTourProgramado, actor)
+		if err != nil {
+			ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al generar el manifiesto", err))
+			return
+		}
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", nombre))
+		ctx.Data(http.StatusOK, "text/csv; charset=utf-8", csv)
+	default:
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Formato inválido, use pdf o csv", nil))
+	}
+}
+
+// enviarPDF responde con un PDF para mostrarlo en el navegador o imprimirlo
+func enviarPDF(ctx *gin.Context, pdf []byte, nombre string) {
+	ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", nombre))
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
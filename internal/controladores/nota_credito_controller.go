@@ -0,0 +1,110 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotaCreditoController maneja los endpoints de notas de crédito
+type NotaCreditoController struct {
+	notaService *servicios.NotaCreditoService
+}
+
+// NewNotaCreditoController crea una nueva instancia de NotaCreditoController
+func NewNotaCreditoController(notaService *servicios.NotaCreditoService) *NotaCreditoController {
+	return &NotaCreditoController{
+		notaService: notaService,
+	}
+}
+
+// Create emite una nueva nota de crédito
+func (c *NotaCreditoController) Create(ctx *gin.Context) {
+	var notaReq entidades.NuevaNotaCreditoRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&notaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(notaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Emitir nota de crédito
+	id, err := c.notaService.Create(&notaReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al emitir nota de crédito", err))
+		return
+	}
+
+	// Obtener la nota emitida
+	nota, err := c.notaService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la nota de crédito emitida", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Nota de crédito emitida exitosamente", nota))
+}
+
+// GetByID obtiene una nota de crédito por su ID
+func (c *NotaCreditoController) GetByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener nota de crédito
+	nota, err := c.notaService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Nota de crédito no encontrada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Nota de crédito obtenida", nota))
+}
+
+// List lista todas las notas de crédito
+func (c *NotaCreditoController) List(ctx *gin.Context) {
+	// Listar notas de crédito
+	notas, err := c.notaService.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar notas de crédito", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Notas de crédito listadas exitosamente", notas))
+}
+
+// ListByComprobante lista las notas de crédito de un comprobante
+func (c *NotaCreditoController) ListByComprobante(ctx *gin.Context) {
+	// Parsear ID del comprobante de la URL
+	idComprobante, err := strconv.Atoi(ctx.Param("idComprobante"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de comprobante inválido", err))
+		return
+	}
+
+	// Listar notas de crédito del comprobante
+	notas, err := c.notaService.ListByComprobante(idComprobante)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar notas de crédito del comprobante", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Notas de crédito del comprobante listadas exitosamente", notas))
+}
@@ -1,2 +1,175 @@
-// Endpoints de pagos
 package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PagoController maneja los endpoints de pagos
+type PagoController struct {
+	pagoService *servicios.PagoService
+}
+
+// NewPagoController crea una nueva instancia de PagoController
+func NewPagoController(pagoService *servicios.PagoService) *PagoController {
+	return &PagoController{
+		pagoService: pagoService,
+	}
+}
+
+// Create registra un nuevo pago
+func (c *PagoController) Create(ctx *gin.Context) {
+	var pagoReq entidades.NuevoPagoRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&pagoReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(pagoReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Registrar pago
+	id, err := c.pagoService.Create(&pagoReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al registrar pago", err))
+		return
+	}
+
+	// Obtener el pago creado
+	pago, err := c.pagoService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el pago registrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Pago registrado exitosamente", pago))
+}
+
+// GetByID obtiene un pago por su ID
+func (c *PagoController) GetByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener pago
+	pago, err := c.pagoService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Pago no encontrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pago obtenido", pago))
+}
+
+// Anular anula un pago
+func (c *PagoController) Anular(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Anular pago
+	err = c.pagoService.Anular(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al anular pago", err))
+		return
+	}
+
+	// Obtener el pago actualizado
+	pago, err := c.pagoService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el pago anulado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pago anulado exitosamente", pago))
+}
+
+// List lista todos los pagos
+func (c *PagoController) List(ctx *gin.Context) {
+	// Listar pagos
+	pagos, err := c.pagoService.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar pagos", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pagos listados exitosamente", pagos))
+}
+
+// ListByReserva lista todos los pagos de una reserva
+func (c *PagoController) ListByReserva(ctx *gin.Context) {
+	// Parsear ID de la reserva de la URL
+	idReserva, err := strconv.Atoi(ctx.Param("idReserva"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de reserva inválido", err))
+		return
+	}
+
+	// Listar pagos de la reserva
+	pagos, err := c.pagoService.ListByReserva(idReserva)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar pagos de la reserva", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pagos de la reserva listados exitosamente", pagos))
+}
+
+// ListByFecha lista todos los pagos de una fecha específica
+func (c *PagoController) ListByFecha(ctx *gin.Context) {
+	// Parsear fecha de la URL (formato: YYYY-MM-DD)
+	fechaStr := ctx.Param("fecha")
+	fecha, err := time.Parse("2006-01-02", fechaStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Formato de fecha inválido, debe ser YYYY-MM-DD", err))
+		return
+	}
+
+	// Listar pagos por fecha
+	pagos, err := c.pagoService.ListByFecha(fecha)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar pagos por fecha", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pagos por fecha listados exitosamente", pagos))
+}
+
+// ListByEstado lista todos los pagos por estado
+func (c *PagoController) ListByEstado(ctx *gin.Context) {
+	// Parsear estado de la URL
+	estado := ctx.Param("estado")
+
+	// Listar pagos por estado
+	pagos, err := c.pagoService.ListByEstado(estado)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar pagos por estado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pagos por estado listados exitosamente", pagos))
+}
@@ -0,0 +1,158 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasajeroController maneja los endpoints del manifiesto de pasajeros
+type PasajeroController struct {
+	pasajeroService *servicios.PasajeroService
+}
+
+// NewPasajeroController crea una nueva instancia de PasajeroController
+func NewPasajeroController(pasajeroService *servicios.PasajeroService) *PasajeroController {
+	return &PasajeroController{
+		pasajeroService: pasajeroService,
+	}
+}
+
+// Create registra un pasajero en una reserva
+func (c *PasajeroController) Create(ctx *gin.Context) {
+	// Parsear ID de la reserva de la URL
+	idReserva, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de reserva inválido", err))
+		return
+	}
+
+	var pasajeroReq entidades.NuevoPasajeroRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&pasajeroReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(pasajeroReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Registrar pasajero
+	id, err := c.pasajeroService.Create(idReserva, &pasajeroReq, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al registrar pasajero", err))
+		return
+	}
+
+	// Obtener el pasajero registrado
+	pasajero, err := c.pasajeroService.GetByID(id, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener el pasajero registrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Pasajero registrado exitosamente", pasajero))
+}
+
+// GetByID obtiene un pasajero por su ID
+func (c *PasajeroController) GetByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener pasajero
+	pasajero, err := c.pasajeroService.GetByID(id, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusNotFound), utils.ErrorResponse("Pasajero no encontrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pasajero obtenido", pasajero))
+}
+
+// Update actualiza un pasajero
+func (c *PasajeroController) Update(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	var pasajeroReq entidades.ActualizarPasajeroRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&pasajeroReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(pasajeroReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Actualizar pasajero
+	err = c.pasajeroService.Update(id, &pasajeroReq, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al actualizar pasajero", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pasajero actualizado exitosamente", nil))
+}
+
+// Delete elimina un pasajero
+func (c *PasajeroController) Delete(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Eliminar pasajero
+	err = c.pasajeroService.Delete(id, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al eliminar pasajero", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pasajero eliminado exitosamente", nil))
+}
+
+// ListByReserva lista los pasajeros de una reserva
+func (c *PasajeroController) ListByReserva(ctx *gin.Context) {
+	// Parsear ID de la reserva de la URL
+	idReserva, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de reserva inválido", err))
+		return
+	}
+
+	// Listar pasajeros
+	pasajeros, err := c.pasajeroService.ListByReserva(idReserva, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al listar pasajeros", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Pasajeros listados exitosamente", pasajeros))
+}
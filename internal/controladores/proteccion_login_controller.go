@@ -0,0 +1,67 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProteccionLoginController maneja los endpoints de administración de bloqueos de inicio de sesión
+type ProteccionLoginController struct {
+	proteccionLoginService *servicios.ProteccionLoginService
+}
+
+// NewProteccionLoginController crea una nueva instancia de ProteccionLoginController
+func NewProteccionLoginController(proteccionLoginService *servicios.ProteccionLoginService) *ProteccionLoginController {
+	return &ProteccionLoginController{
+		proteccionLoginService: proteccionLoginService,
+	}
+}
+
+// Desbloquear elimina el bloqueo de inicio de sesión de una cuenta o una IP
+func (c *ProteccionLoginController) Desbloquear(ctx *gin.Context) {
+	var desbloquearReq entidades.DesbloquearLoginRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&desbloquearReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(desbloquearReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Desbloquear
+	if err := c.proteccionLoginService.Desbloquear(&desbloquearReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al desbloquear", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Inicio de sesión desbloqueado exitosamente", nil))
+}
+
+// ListAuditoria lista los intentos de inicio de sesión más recientes de un correo
+func (c *ProteccionLoginController) ListAuditoria(ctx *gin.Context) {
+	correo := ctx.Query("correo")
+	if correo == "" {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("El parámetro correo es obligatorio", nil))
+		return
+	}
+
+	// Listar intentos
+	auditoria, err := c.proteccionLoginService.ListAuditoriaByCorreo(correo)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar los intentos de inicio de sesión", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Intentos de inicio de sesión listados exitosamente", auditoria))
+}
@@ -0,0 +1,88 @@
+package controladores
+
+import (
+	"errors"
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mensajeSolicitudRecuperacion se devuelve siempre, exista o no la cuenta, para no revelar qué correos están registrados
+const mensajeSolicitudRecuperacion = "Si el correo está registrado, recibirás un enlace para restablecer tu contraseña"
+
+// RecuperacionContrasenaController maneja los endpoints de recuperación de contraseña
+type RecuperacionContrasenaController struct {
+	recuperacionService *servicios.RecuperacionContrasenaService
+}
+
+// NewRecuperacionContrasenaController crea una nueva instancia de RecuperacionContrasenaController
+func NewRecuperacionContrasenaController(recuperacionService *servicios.RecuperacionContrasenaService) *RecuperacionContrasenaController {
+	return &RecuperacionContrasenaController{
+		recuperacionService: recuperacionService,
+	}
+}
+
+// OlvideContrasena envía el enlace de recuperación a un usuario del sistema
+func (c *RecuperacionContrasenaController) OlvideContrasena(ctx *gin.Context) {
+	c.solicitar(ctx, c.recuperacionService.SolicitarUsuario)
+}
+
+// OlvideContrasenaCliente envía el enlace de recuperación a un cliente
+func (c *RecuperacionContrasenaController) OlvideContrasenaCliente(ctx *gin.Context) {
+	c.solicitar(ctx, c.recuperacionService.SolicitarCliente)
+}
+
+// Restablecer cambia la contraseña usando el token recibido por correo
+func (c *RecuperacionContrasenaController) Restablecer(ctx *gin.Context) {
+	var restablecerReq entidades.RestablecerContrasenaRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&restablecerReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(restablecerReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Restablecer contraseña
+	if err := c.recuperacionService.Restablecer(&restablecerReq); err != nil {
+		if errors.Is(err, servicios.ErrTokenRecuperacionInvalido) {
+			ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al restablecer la contraseña", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al restablecer la contraseña", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Contraseña restablecida exitosamente", nil))
+}
+
+// solicitar valida el correo y delega el envío del enlace en el servicio indicado
+func (c *RecuperacionContrasenaController) solicitar(ctx *gin.Context, enviar func(correo string)) {
+	var olvideReq entidades.OlvideContrasenaRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&olvideReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(olvideReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Enviar enlace en segundo plano: la respuesta es la misma exista o no el correo
+	enviar(olvideReq.Correo)
+
+	ctx.JSON(http.StatusOK, utils.SuccessResponse(mensajeSolicitudRecuperacion, nil))
+}
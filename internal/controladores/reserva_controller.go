@@ -1,8 +1,10 @@
 package controladores
 
 import (
+	"errors"
 	"net/http"
 	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
 	"sistema-tours/internal/servicios"
 	"sistema-tours/internal/utils"
 	"strconv"
@@ -39,21 +41,56 @@ func (c *ReservaController) Create(ctx *gin.Context) {
 		return
 	}
 
-	// Si es una reserva de vendedor, obtener el ID del vendedor del contexto
-	if ctx.GetString("rol") == "VENDEDOR" {
-		vendedorID := ctx.GetInt("user_id")
-		reservaReq.IDVendedor = &vendedorID
-	}
+	// El servicio asigna el vendedor según los permisos del actor
+	actor := actorDesdeContexto(ctx)
 
 	// Crear reserva
-	id, err := c.reservaService.Create(&reservaReq)
+	id, err := c.reservaService.Create(&reservaReq, actor)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al crear reserva", err))
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al crear reserva", err))
 		return
 	}
 
 	// Obtener la reserva creada
-	reserva, err := c.reservaService.GetByID(id)
+	reserva, err := c.reservaService.GetByID(id, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la reserva creada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Reserva creada exitosamente", reserva))
+}
+
+// CreateAgencia crea la reserva de una agencia autenticada con su llave de API
+func (c *ReservaController) CreateAgencia(ctx *gin.Context) {
+	var reservaReq entidades.NuevaReservaAgenciaRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&reservaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(reservaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Crear reserva en el canal de venta de la llave
+	actor := actorDesdeContexto(ctx)
+	id, err := c.reservaService.CreateAgencia(&reservaReq, actor)
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al crear reserva", err))
+		return
+	}
+
+	// Atribuir la reserva a la llave en su registro de uso
+	middleware.AtribuirReserva(ctx, id)
+
+	// Obtener la reserva creada
+	reserva, err := c.reservaService.GetByID(id, actor)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la reserva creada", err))
 		return
@@ -73,9 +110,9 @@ func (c *ReservaController) GetByID(ctx *gin.Context) {
 	}
 
 	// Obtener reserva
-	reserva, err := c.reservaService.GetByID(id)
+	reserva, err := c.reservaService.GetByID(id, actorDesdeContexto(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Reserva no encontrada", err))
+		ctx.JSON(codigoError(err, http.StatusNotFound), utils.ErrorResponse("Reserva no encontrada", err))
 		return
 	}
 
@@ -106,21 +143,18 @@ func (c *ReservaController) Update(ctx *gin.Context) {
 		return
 	}
 
-	// Si es una reserva de vendedor, obtener el ID del vendedor del contexto
-	if ctx.GetString("rol") == "VENDEDOR" {
-		vendedorID := ctx.GetInt("user_id")
-		reservaReq.IDVendedor = &vendedorID
-	}
+	// El servicio asigna el vendedor según los permisos del actor
+	actor := actorDesdeContexto(ctx)
 
 	// Actualizar reserva
-	err = c.reservaService.Update(id, &reservaReq)
+	err = c.reservaService.Update(id, &reservaReq, actor)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al actualizar reserva", err))
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al actualizar reserva", err))
 		return
 	}
 
 	// Obtener la reserva actualizada
-	reserva, err := c.reservaService.GetByID(id)
+	reserva, err := c.reservaService.GetByID(id, actorDesdeContexto(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la reserva actualizada", err))
 		return
@@ -154,14 +188,14 @@ func (c *ReservaController) CambiarEstado(ctx *gin.Context) {
 	}
 
 	// Cambiar estado
-	err = c.reservaService.CambiarEstado(id, estadoReq.Estado)
+	err = c.reservaService.CambiarEstado(id, &estadoReq, actorDesdeContexto(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al cambiar estado de la reserva", err))
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al cambiar estado de la reserva", err))
 		return
 	}
 
 	// Obtener la reserva actualizada
-	reserva, err := c.reservaService.GetByID(id)
+	reserva, err := c.reservaService.GetByID(id, actorDesdeContexto(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la reserva actualizada", err))
 		return
@@ -171,6 +205,53 @@ func (c *ReservaController) CambiarEstado(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.SuccessResponse("Estado de la reserva actualizado exitosamente", reserva))
 }
 
+// Confirmar convierte una reserva web pendiente de pago en una reserva confirmada
+func (c *ReservaController) Confirmar(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Confirmar reserva
+	err = c.reservaService.Confirmar(id, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al confirmar la reserva", err))
+		return
+	}
+
+	// Obtener la reserva confirmada
+	reserva, err := c.reservaService.GetByID(id, actorDesdeContexto(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al obtener la reserva confirmada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Reserva confirmada exitosamente", reserva))
+}
+
+// ListHistorial lista los cambios de estado de una reserva
+func (c *ReservaController) ListHistorial(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Listar historial
+	historial, err := c.reservaService.ListHistorial(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Error al obtener el historial de la reserva", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Historial de la reserva obtenido", historial))
+}
+
 // Delete elimina una reserva
 func (c *ReservaController) Delete(ctx *gin.Context) {
 	// Parsear ID de la URL
@@ -193,8 +274,23 @@ func (c *ReservaController) Delete(ctx *gin.Context) {
 
 // List lista todas las reservas
 func (c *ReservaController) List(ctx *gin.Context) {
-	// Listar reservas
-	reservas, err := c.reservaService.List()
+	// Obtener filtro opcional por estado de pago (PENDIENTE, PARCIAL, PAGADO)
+	estadoPago := ctx.Query("estado_pago")
+
+	var reservas []*entidades.Reserva
+	var err error
+
+	// Filtrar por estado de pago o listar todas
+	if estadoPago != "" {
+		reservas, err = c.reservaService.ListByEstadoPago(estadoPago)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar reservas por estado de pago", err))
+			return
+		}
+	} else {
+		reservas, err = c.reservaService.List()
+	}
+
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar reservas", err))
 		return
@@ -234,9 +330,9 @@ func (c *ReservaController) ListByTourProgramado(ctx *gin.Context) {
 	}
 
 	// Listar reservas del tour programado
-	reservas, err := c.reservaService.ListByTourProgramado(idTourProgramado)
+	reservas, err := c.reservaService.ListByTourProgramado(idTourProgramado, actorDesdeContexto(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar reservas del tour programado", err))
+		ctx.JSON(codigoError(err, http.StatusBadRequest), utils.ErrorResponse("Error al listar reservas del tour programado", err))
 		return
 	}
 
@@ -281,19 +377,17 @@ func (c *ReservaController) ListByEstado(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.SuccessResponse("Reservas por estado listadas exitosamente", reservas))
 }
 
-// ListMyReservas lista todas las reservas del cliente autenticado
-// ListMyReservas lista todas las reservas del cliente autenticado
 // ListMyReservas lista todas las reservas del cliente autenticado
 func (c *ReservaController) ListMyReservas(ctx *gin.Context) {
-	// Obtener ID del cliente autenticado
-	idCliente, exists := ctx.Get("userID") // CORREGIDO: user_id -> userID
-	if !exists {
+	// Obtener cliente autenticado
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists || !principal.EsCliente() {
 		ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Cliente no autenticado", nil))
 		return
 	}
 
 	// Listar reservas del cliente
-	reservas, err := c.reservaService.ListByCliente(idCliente.(int))
+	reservas, err := c.reservaService.ListByCliente(principal.ID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar reservas del cliente", err))
 		return
@@ -302,3 +396,60 @@ func (c *ReservaController) ListMyReservas(ctx *gin.Context) {
 	// Respuesta exitosa
 	ctx.JSON(http.StatusOK, utils.SuccessResponse("Mis reservas listadas exitosamente", reservas))
 }
+
+// ConsultarPorLocalizador permite a un cliente recuperar su reserva con el localizador y su documento
+func (c *ReservaController) ConsultarPorLocalizador(ctx *gin.Context) {
+	var consultaReq entidades.ConsultaReservaRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&consultaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(consultaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Buscar reserva
+	reserva, err := c.reservaService.ConsultarPorLocalizador(&consultaReq)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Reserva no encontrada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Reserva obtenida", reserva))
+}
+
+// codigoError devuelve 403 si el servicio negó el acceso al recurso y el código indicado en otro caso
+func codigoError(err error, codigo int) int {
+	if errors.Is(err, servicios.ErrAccesoDenegado) {
+		return http.StatusForbidden
+	}
+	return codigo
+}
+
+// actorDesdeContexto obtiene el rol y el ID del sujeto autenticado (establecidos por AuthMiddleware)
+// y si la ruta lo limita a sus tours asignados. Las agencias autenticadas con llave de API actúan con el rol AGENCIA.
+func actorDesdeContexto(ctx *gin.Context) servicios.Actor {
+	if apiKey, ok := middleware.GetAPIKey(ctx); ok {
+		return servicios.Actor{
+			Rol:     "AGENCIA",
+			ID:      apiKey.ID,
+			IDCanal: apiKey.IDCanal,
+		}
+	}
+
+	principal, exists := middleware.GetPrincipal(ctx)
+	if !exists {
+		return servicios.Actor{}
+	}
+	return servicios.Actor{
+		Rol:           principal.Rol,
+		ID:            principal.ID,
+		SoloAsignados: middleware.EsSoloAsignados(ctx),
+	}
+}
@@ -0,0 +1,125 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RolController maneja los endpoints de roles y permisos del personal
+type RolController struct {
+	rolService *servicios.RolService
+}
+
+// NewRolController crea una nueva instancia de RolController
+func NewRolController(rolService *servicios.RolService) *RolController {
+	return &RolController{
+		rolService: rolService,
+	}
+}
+
+// ListPermisos lista el catálogo de permisos
+func (c *RolController) ListPermisos(ctx *gin.Context) {
+	// Listar permisos
+	permisos, err := c.rolService.ListPermisos()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar permisos", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Permisos listados exitosamente", permisos))
+}
+
+// List lista los roles con sus permisos
+func (c *RolController) List(ctx *gin.Context) {
+	// Listar roles
+	roles, err := c.rolService.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar roles", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Roles listados exitosamente", roles))
+}
+
+// GetByNombre obtiene un rol con sus permisos
+func (c *RolController) GetByNombre(ctx *gin.Context) {
+	// Obtener rol
+	rol, err := c.rolService.GetByNombre(ctx.Param("nombre"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Rol no encontrado", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Rol obtenido exitosamente", rol))
+}
+
+// Create crea un rol nuevo
+func (c *RolController) Create(ctx *gin.Context) {
+	var rolReq entidades.NuevoRolRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&rolReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(rolReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Crear rol
+	if err := c.rolService.Create(&rolReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al crear rol", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Rol creado exitosamente", gin.H{"nombre": rolReq.Nombre}))
+}
+
+// Update reemplaza la descripción y los permisos de un rol
+func (c *RolController) Update(ctx *gin.Context) {
+	var rolReq entidades.ActualizarRolRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&rolReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(rolReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Actualizar rol
+	if err := c.rolService.Update(ctx.Param("nombre"), &rolReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al actualizar rol", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Rol actualizado exitosamente", nil))
+}
+
+// Delete elimina un rol sin usuarios asignados
+func (c *RolController) Delete(ctx *gin.Context) {
+	// Eliminar rol
+	if err := c.rolService.Delete(ctx.Param("nombre")); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al eliminar rol", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Rol eliminado exitosamente", nil))
+}
@@ -0,0 +1,157 @@
+package controladores
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TarifaTourController maneja los endpoints de tarifas por tipo de tour
+type TarifaTourController struct {
+	tarifaService *servicios.TarifaTourService
+}
+
+// NewTarifaTourController crea una nueva instancia de TarifaTourController
+func NewTarifaTourController(tarifaService *servicios.TarifaTourService) *TarifaTourController {
+	return &TarifaTourController{
+		tarifaService: tarifaService,
+	}
+}
+
+// Create crea una nueva tarifa
+func (c *TarifaTourController) Create(ctx *gin.Context) {
+	var tarifaReq entidades.NuevaTarifaTourRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&tarifaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(tarifaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Crear tarifa
+	id, err := c.tarifaService.Create(&tarifaReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al crear tarifa", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusCreated, utils.SuccessResponse("Tarifa creada exitosamente", gin.H{"id": id}))
+}
+
+// GetByID obtiene una tarifa por su ID
+func (c *TarifaTourController) GetByID(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Obtener tarifa
+	tarifa, err := c.tarifaService.GetByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.ErrorResponse("Tarifa no encontrada", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Tarifa obtenida", tarifa))
+}
+
+// Update actualiza una tarifa
+func (c *TarifaTourController) Update(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	var tarifaReq entidades.ActualizarTarifaTourRequest
+
+	// Parsear request
+	if err := ctx.ShouldBindJSON(&tarifaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Datos inválidos", err))
+		return
+	}
+
+	// Validar datos
+	if err := utils.ValidateStruct(tarifaReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error de validación", err))
+		return
+	}
+
+	// Actualizar tarifa
+	err = c.tarifaService.Update(id, &tarifaReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al actualizar tarifa", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Tarifa actualizada exitosamente", nil))
+}
+
+// Delete elimina una tarifa
+func (c *TarifaTourController) Delete(ctx *gin.Context) {
+	// Parsear ID de la URL
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID inválido", err))
+		return
+	}
+
+	// Eliminar tarifa
+	err = c.tarifaService.Delete(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al eliminar tarifa", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Tarifa eliminada exitosamente", nil))
+}
+
+// List lista todas las tarifas
+func (c *TarifaTourController) List(ctx *gin.Context) {
+	// Listar tarifas
+	tarifas, err := c.tarifaService.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al listar tarifas", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Tarifas listadas exitosamente", tarifas))
+}
+
+// ListVigentesByTipoTour lista el precio aplicable de cada tipo de pasaje en un tipo de tour
+func (c *TarifaTourController) ListVigentesByTipoTour(ctx *gin.Context) {
+	// Parsear ID del tipo de tour de la URL
+	idTipoTour, err := strconv.Atoi(ctx.Param("idTipoTour"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("ID de tipo de tour inválido", err))
+		return
+	}
+
+	// Listar tarifas vigentes
+	tarifas, err := c.tarifaService.ListVigentesByTipoTour(idTipoTour)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Error al listar tarifas del tipo de tour", err))
+		return
+	}
+
+	// Respuesta exitosa
+	ctx.JSON(http.StatusOK, utils.SuccessResponse("Tarifas del tipo de tour listadas exitosamente", tarifas))
+}
@@ -129,15 +129,8 @@ func (c *UsuarioController) ListByRol(ctx *gin.Context) {
 	rol := ctx.Param("rol")
 
 	// Validar rol
-	validRoles := map[string]bool{
-		"ADMIN":    true,
-		"VENDEDOR": true,
-		"CHOFER":   true,
-		"CLIENTE":  true,
-	}
-
-	if !validRoles[rol] {
-		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Rol inválido", nil))
+	if err := c.usuarioService.ValidarRol(rol); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.ErrorResponse("Rol inválido", err))
 		return
 	}
 
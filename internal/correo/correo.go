@@ -0,0 +1,13 @@
+package correo
+
+// Mensaje es un correo electrónico en texto plano
+type Mensaje struct {
+	Para   string
+	Asunto string
+	Cuerpo string
+}
+
+// Enviador envía correos electrónicos (por SMTP o a un sustituto local)
+type Enviador interface {
+	Enviar(mensaje Mensaje) error
+}
@@ -0,0 +1,44 @@
+package correo
+
+import "sync"
+
+// EnviadorFake guarda los correos en memoria en lugar de enviarlos. Se usa en desarrollo y en pruebas.
+type EnviadorFake struct {
+	mu       sync.Mutex
+	enviados []Mensaje
+}
+
+// NuevoEnviadorFake crea un enviador local
+func NuevoEnviadorFake() *EnviadorFake {
+	return &EnviadorFake{}
+}
+
+// Enviar registra el mensaje
+func (e *EnviadorFake) Enviar(mensaje Mensaje) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.enviados = append(e.enviados, mensaje)
+	return nil
+}
+
+// Enviados devuelve una copia de los mensajes registrados, en orden de envío
+func (e *EnviadorFake) Enviados() []Mensaje {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]Mensaje(nil), e.enviados...)
+}
+
+// Ultimo devuelve el último mensaje enviado a la dirección indicada
+func (e *EnviadorFake) Ultimo(para string) (Mensaje, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := len(e.enviados) - 1; i >= 0; i-- {
+		if e.enviados[i].Para == para {
+			return e.enviados[i], true
+		}
+	}
+	return Mensaje{}, false
+}
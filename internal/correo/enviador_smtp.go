@@ -0,0 +1,51 @@
+package correo
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EnviadorSMTP envía los correos a través de un servidor SMTP con autenticación PLAIN
+type EnviadorSMTP struct {
+	direccion string // host:puerto
+	auth      smtp.Auth
+	remitente string
+}
+
+// NuevoEnviadorSMTP crea un enviador para el servidor SMTP indicado.
+// Si usuario está vacío se envía sin autenticación (por ejemplo, a un relay local).
+func NuevoEnviadorSMTP(host, puerto, usuario, clave, remitente string) *EnviadorSMTP {
+	var auth smtp.Auth
+	if usuario != "" {
+		auth = smtp.PlainAuth("", usuario, clave, host)
+	}
+
+	return &EnviadorSMTP{
+		direccion: net.JoinHostPort(host, puerto),
+		auth:      auth,
+		remitente: remitente,
+	}
+}
+
+// Enviar envía el mensaje; net/smtp usa STARTTLS cuando el servidor lo ofrece
+func (e *EnviadorSMTP) Enviar(mensaje Mensaje) error {
+	if strings.ContainsAny(mensaje.Para, "\r\n") {
+		return fmt.Errorf("destinatario inválido: %q", mensaje.Para)
+	}
+
+	var contenido strings.Builder
+	fmt.Fprintf(&contenido, "From: %s\r\n", e.remitente)
+	fmt.Fprintf(&contenido, "To: %s\r\n", mensaje.Para)
+	fmt.Fprintf(&contenido, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mensaje.Asunto))
+	fmt.Fprintf(&contenido, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	contenido.WriteString("MIME-Version: 1.0\r\n")
+	contenido.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	contenido.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	contenido.WriteString(strings.ReplaceAll(mensaje.Cuerpo, "\n", "\r\n"))
+
+	return smtp.SendMail(e.direccion, e.auth, e.remitente, []string{mensaje.Para}, []byte(contenido.String()))
+}
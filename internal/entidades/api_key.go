@@ -0,0 +1,68 @@
+package entidades
+
+import "time"
+
+// APIKey representa la llave con la que una agencia u OTA accede a la API sin iniciar sesión.
+// Solo se guarda el hash de la llave; la llave en claro se entrega una única vez al crearla.
+type APIKey struct {
+	ID               int        `json:"id_api_key" db:"id_api_key"`
+	Nombre           string     `json:"nombre" db:"nombre"`
+	Prefijo          string     `json:"prefijo" db:"prefijo"` // Inicio de la llave para reconocerla en los listados
+	ClaveHash        string     `json:"-" db:"clave_hash"`
+	IDCanal          int        `json:"id_canal" db:"id_canal"`
+	Alcances         []string   `json:"alcances"` // Permisos que puede usar la llave
+	LimiteMinuto     int        `json:"limite_minuto" db:"limite_minuto"`
+	FechaExpiracion  *time.Time `json:"fecha_expiracion,omitempty" db:"fecha_expiracion"`
+	FechaRevocacion  *time.Time `json:"fecha_revocacion,omitempty" db:"fecha_revocacion"`
+	IDUsuarioCreador *int       `json:"id_usuario_creador,omitempty" db:"id_usuario_creador"`
+	FechaCreacion    time.Time  `json:"fecha_creacion" db:"fecha_creacion"`
+
+	// Campos adicionales para mostrar información relacionada
+	NombreCanal    string     `json:"nombre_canal,omitempty" db:"-"`
+	FechaUltimoUso *time.Time `json:"fecha_ultimo_uso,omitempty" db:"-"`
+}
+
+// Vigente indica si la llave no fue revocada ni expiró
+func (k *APIKey) Vigente(ahora time.Time) bool {
+	if k.FechaRevocacion != nil {
+		return false
+	}
+	return k.FechaExpiracion == nil || ahora.Before(*k.FechaExpiracion)
+}
+
+// TieneAlcance indica si la llave puede usar el permiso
+func (k *APIKey) TieneAlcance(permiso string) bool {
+	for _, alcance := range k.Alcances {
+		if alcance == permiso {
+			return true
+		}
+	}
+	return false
+}
+
+// NuevaAPIKeyRequest representa los datos para emitir una llave de API
+type NuevaAPIKeyRequest struct {
+	Nombre          string     `json:"nombre" validate:"required,max=100"`
+	IDCanal         int        `json:"id_canal" validate:"required"`
+	Alcances        []string   `json:"alcances" validate:"required,min=1,dive,required"`
+	LimiteMinuto    int        `json:"limite_minuto" validate:"omitempty,min=1"` // Opcional, por defecto el de la configuración
+	FechaExpiracion *time.Time `json:"fecha_expiracion,omitempty"`               // Opcional, sin fecha la llave no expira
+}
+
+// APIKeyCreadaResponse representa la llave emitida junto con su valor en claro, que no se vuelve a mostrar
+type APIKeyCreadaResponse struct {
+	APIKey *APIKey `json:"api_key"`
+	Clave  string  `json:"clave"`
+}
+
+// UsoAPIKey representa una petición hecha con una llave de API
+type UsoAPIKey struct {
+	ID           int       `json:"id_uso_api_key" db:"id_uso_api_key"`
+	IDAPIKey     int       `json:"id_api_key" db:"id_api_key"`
+	Metodo       string    `json:"metodo" db:"metodo"`
+	Ruta         string    `json:"ruta" db:"ruta"`
+	CodigoEstado int       `json:"codigo_estado" db:"codigo_estado"`
+	IP           string    `json:"ip" db:"ip"`
+	IDReserva    *int      `json:"id_reserva,omitempty" db:"id_reserva"` // Reserva creada con la petición
+	Fecha        time.Time `json:"fecha" db:"fecha"`
+}
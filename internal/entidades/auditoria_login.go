@@ -0,0 +1,15 @@
+package entidades
+
+import "time"
+
+// AuditoriaLogin representa un intento de inicio de sesión registrado
+type AuditoriaLogin struct {
+	ID         int       `json:"id_auditoria_login" db:"id_auditoria_login"`
+	TipoSujeto string    `json:"tipo_sujeto" db:"tipo_sujeto"` // USUARIO, CLIENTE
+	Correo     string    `json:"correo" db:"correo"`
+	IDSujeto   *int      `json:"id_sujeto,omitempty" db:"id_sujeto"`
+	IP         string    `json:"ip" db:"ip"`
+	Exitoso    bool      `json:"exitoso" db:"exitoso"`
+	Motivo     string    `json:"motivo,omitempty" db:"motivo"` // CREDENCIALES, BLOQUEADO, DESACTIVADO
+	Fecha      time.Time `json:"fecha" db:"fecha"`
+}
@@ -35,3 +35,22 @@ type LoginClienteRequest struct {
 	Correo     string `json:"correo" validate:"required,email"`
 	Contrasena string `json:"contrasena" validate:"required"`
 }
+
+// SesionCliente representa los datos del cliente devueltos al iniciar sesión
+type SesionCliente struct {
+	ID              int    `json:"id_cliente"`
+	Nombres         string `json:"nombres"`
+	Apellidos       string `json:"apellidos"`
+	NombreCompleto  string `json:"nombre_completo"`
+	TipoDocumento   string `json:"tipo_documento"`
+	NumeroDocumento string `json:"numero_documento"`
+	Correo          string `json:"correo"`
+	Rol             string `json:"rol"`
+}
+
+// LoginClienteResponse representa la respuesta al iniciar sesión como cliente
+type LoginClienteResponse struct {
+	Token        string         `json:"token"`
+	RefreshToken string         `json:"refresh_token"`
+	Usuario      *SesionCliente `json:"usuario"`
+}
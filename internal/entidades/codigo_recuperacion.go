@@ -0,0 +1,12 @@
+package entidades
+
+import "time"
+
+// CodigoRecuperacion representa un código de un solo uso para entrar sin la aplicación autenticadora.
+// Solo se guarda el hash del código.
+type CodigoRecuperacion struct {
+	ID         int        `json:"id_codigo_recuperacion" db:"id_codigo_recuperacion"`
+	IDUsuario  int        `json:"id_usuario" db:"id_usuario"`
+	CodigoHash string     `json:"-" db:"codigo_hash"`
+	FechaUso   *time.Time `json:"fecha_uso,omitempty" db:"fecha_uso"`
+}
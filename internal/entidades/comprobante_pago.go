@@ -4,46 +4,45 @@ import "time"
 
 // ComprobantePago representa la estructura de un comprobante de pago en el sistema
 type ComprobantePago struct {
-	ID                int       `json:"id_comprobante" db:"id_comprobante"`
-	IDReserva         int       `json:"id_reserva" db:"id_reserva"`
-	Tipo              string    `json:"tipo" db:"tipo"`
-	NumeroComprobante string    `json:"numero_comprobante" db:"numero_comprobante"`
-	FechaEmision      time.Time `json:"fecha_emision" db:"fecha_emision"`
-	Subtotal          float64   `json:"subtotal" db:"subtotal"`
-	IGV               float64   `json:"igv" db:"igv"`
-	Total             float64   `json:"total" db:"total"`
-	Estado            string    `json:"estado" db:"estado"`
+	ID                int        `json:"id_comprobante" db:"id_comprobante"`
+	IDReserva         int        `json:"id_reserva" db:"id_reserva"`
+	Tipo              string     `json:"tipo" db:"tipo"`
+	Serie             string     `json:"serie" db:"serie"`
+	Correlativo       int        `json:"correlativo" db:"correlativo"`
+	NumeroComprobante string     `json:"numero_comprobante" db:"numero_comprobante"` // SERIE-CORRELATIVO, por ejemplo B001-00000001
+	FechaEmision      time.Time  `json:"fecha_emision" db:"fecha_emision"`
+	Subtotal          float64    `json:"subtotal" db:"subtotal"`
+	IGV               float64    `json:"igv" db:"igv"`
+	Total             float64    `json:"total" db:"total"`
+	Estado            string     `json:"estado" db:"estado"`
+	MotivoAnulacion   string     `json:"motivo_anulacion,omitempty" db:"motivo_anulacion"`
+	FechaAnulacion    *time.Time `json:"fecha_anulacion,omitempty" db:"fecha_anulacion"`
+
+	// Facturación electrónica
+	EstadoSunat      string `json:"estado_sunat" db:"estado_sunat"` // PENDIENTE, ENVIADO, ACEPTADO, OBSERVADO, RECHAZADO, BAJA
+	CodigoSunat      string `json:"codigo_sunat,omitempty" db:"codigo_sunat"`
+	DescripcionSunat string `json:"descripcion_sunat,omitempty" db:"descripcion_sunat"`
+	HashCPE          string `json:"hash_cpe,omitempty" db:"hash_cpe"` // Valor resumen de la firma digital
 
 	// Campos adicionales para mostrar información relacionada
-	NombreCliente    string    `json:"nombre_cliente,omitempty" db:"-"`
-	ApellidosCliente string    `json:"apellidos_cliente,omitempty" db:"-"`
-	DocumentoCliente string    `json:"documento_cliente,omitempty" db:"-"`
-	NombreTour       string    `json:"nombre_tour,omitempty" db:"-"`
-	FechaTour        time.Time `json:"fecha_tour,omitempty" db:"-"`
+	NombreCliente        string    `json:"nombre_cliente,omitempty" db:"-"`
+	ApellidosCliente     string    `json:"apellidos_cliente,omitempty" db:"-"`
+	TipoDocumentoCliente string    `json:"tipo_documento_cliente,omitempty" db:"-"`
+	DocumentoCliente     string    `json:"documento_cliente,omitempty" db:"-"`
+	NombreTour           string    `json:"nombre_tour,omitempty" db:"-"`
+	FechaTour            time.Time `json:"fecha_tour,omitempty" db:"-"`
+	LocalizadorReserva   string    `json:"localizador_reserva,omitempty" db:"-"`
 }
 
-// NuevoComprobantePagoRequest representa los datos necesarios para crear un nuevo comprobante de pago
+// NuevoComprobantePagoRequest representa los datos necesarios para emitir un comprobante de pago.
+// La serie, el correlativo y los montos los asigna el servidor a partir de la reserva.
 type NuevoComprobantePagoRequest struct {
-	IDReserva         int     `json:"id_reserva" validate:"required"`
-	Tipo              string  `json:"tipo" validate:"required,oneof=BOLETA FACTURA"`
-	NumeroComprobante string  `json:"numero_comprobante" validate:"required"`
-	Subtotal          float64 `json:"subtotal" validate:"required,min=0"`
-	IGV               float64 `json:"igv" validate:"required,min=0"`
-	Total             float64 `json:"total" validate:"required,min=0"`
-}
-
-// ActualizarComprobantePagoRequest representa los datos para actualizar un comprobante de pago
-type ActualizarComprobantePagoRequest struct {
-	IDReserva         int     `json:"id_reserva" validate:"required"`
-	Tipo              string  `json:"tipo" validate:"required,oneof=BOLETA FACTURA"`
-	NumeroComprobante string  `json:"numero_comprobante" validate:"required"`
-	Subtotal          float64 `json:"subtotal" validate:"required,min=0"`
-	IGV               float64 `json:"igv" validate:"required,min=0"`
-	Total             float64 `json:"total" validate:"required,min=0"`
-	Estado            string  `json:"estado" validate:"required,oneof=EMITIDO ANULADO"`
+	IDReserva int    `json:"id_reserva" validate:"required"`
+	Tipo      string `json:"tipo" validate:"required,oneof=BOLETA FACTURA"`
 }
 
 // CambiarEstadoComprobanteRequest representa los datos para cambiar el estado de un comprobante
 type CambiarEstadoComprobanteRequest struct {
 	Estado string `json:"estado" validate:"required,oneof=EMITIDO ANULADO"`
+	Motivo string `json:"motivo" validate:"required_if=Estado ANULADO,max=255"`
 }
@@ -0,0 +1,18 @@
+package entidades
+
+import "time"
+
+// ControlLogin representa los fallos de inicio de sesión acumulados por una cuenta o una IP
+type ControlLogin struct {
+	Clave          string     `json:"clave" db:"clave"` // USUARIO:correo, CLIENTE:correo o IP:dirección
+	Fallos         int        `json:"fallos" db:"fallos"`
+	UltimoFallo    *time.Time `json:"ultimo_fallo,omitempty" db:"ultimo_fallo"`
+	BloqueadoHasta *time.Time `json:"bloqueado_hasta,omitempty" db:"bloqueado_hasta"`
+}
+
+// DesbloquearLoginRequest representa la cuenta o la IP que un administrador desbloquea
+type DesbloquearLoginRequest struct {
+	TipoSujeto string `json:"tipo_sujeto" validate:"omitempty,oneof=USUARIO CLIENTE"` // Requerido junto con el correo
+	Correo     string `json:"correo" validate:"omitempty,email"`
+	IP         string `json:"ip" validate:"omitempty,ip"`
+}
@@ -0,0 +1,30 @@
+package entidades
+
+import "time"
+
+// Cotizacion representa el precio calculado por el servidor para un grupo de pasajes de un tour programado
+type Cotizacion struct {
+	IDTourProgramado int               `json:"id_tour_programado"`
+	NombreTour       string            `json:"nombre_tour"`
+	Fecha            time.Time         `json:"fecha"`
+	HoraInicio       string            `json:"hora_inicio"`
+	PrecioBase       float64           `json:"precio_base"`
+	CupoDisponible   int               `json:"cupo_disponible"`
+	Lineas           []LineaCotizacion `json:"lineas"`
+	TotalPasajeros   int               `json:"total_pasajeros"`
+	Total            float64           `json:"total"`
+}
+
+// LineaCotizacion representa el precio de los pasajes de un tipo dentro de una cotización
+type LineaCotizacion struct {
+	IDTipoPasaje   int     `json:"id_tipo_pasaje"`
+	NombreTipo     string  `json:"nombre_tipo"`
+	Cantidad       int     `json:"cantidad"`
+	PrecioUnitario float64 `json:"precio_unitario"`
+	Importe        float64 `json:"importe"`
+}
+
+// CotizacionRequest representa los pasajes que se desean cotizar
+type CotizacionRequest struct {
+	CantidadPasajes []PasajeCantidadRequest `json:"cantidad_pasajes" validate:"required,min=1,dive"`
+}
@@ -0,0 +1,42 @@
+package entidades
+
+import "time"
+
+// DobleFactor representa la configuración TOTP de un usuario del sistema
+type DobleFactor struct {
+	IDUsuario       int        `json:"id_usuario" db:"id_usuario"`
+	Secreto         string     `json:"-" db:"secreto"`
+	Activo          bool       `json:"activo" db:"activo"`
+	UltimoPaso      int64      `json:"-" db:"ultimo_paso"`
+	FechaCreacion   time.Time  `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaActivacion *time.Time `json:"fecha_activacion,omitempty" db:"fecha_activacion"`
+}
+
+// EnrolamientoDobleFactorResponse representa los datos para registrar el secreto en la aplicación autenticadora
+type EnrolamientoDobleFactorResponse struct {
+	Secreto string `json:"secreto"`
+	URI     string `json:"uri"`    // otpauth://totp/...
+	QR      string `json:"qr_png"` // Imagen PNG del URI en base64
+}
+
+// CodigoDobleFactorRequest representa un código TOTP o de recuperación
+type CodigoDobleFactorRequest struct {
+	Codigo string `json:"codigo" validate:"required"`
+}
+
+// CodigosRecuperacionResponse representa los códigos de recuperación generados al activar el doble factor.
+// Se muestran una sola vez.
+type CodigosRecuperacionResponse struct {
+	CodigosRecuperacion []string `json:"codigos_recuperacion"`
+}
+
+// DesafioDobleFactorRequest representa el token de desafío devuelto por el login
+type DesafioDobleFactorRequest struct {
+	TokenDesafio string `json:"token_desafio" validate:"required"`
+}
+
+// VerificarDobleFactorRequest representa el segundo paso del login: el token de desafío y el código
+type VerificarDobleFactorRequest struct {
+	TokenDesafio string `json:"token_desafio" validate:"required"`
+	Codigo       string `json:"codigo" validate:"required"`
+}
@@ -0,0 +1,30 @@
+package entidades
+
+import "time"
+
+// Embarque representa el check-in de una reserva al abordar la embarcación
+type Embarque struct {
+	ID                int       `json:"id_embarque" db:"id_embarque"`
+	IDReserva         int       `json:"id_reserva" db:"id_reserva"`
+	IDTourProgramado  int       `json:"id_tour_programado" db:"id_tour_programado"`
+	IDUsuario         int       `json:"id_usuario" db:"id_usuario"`
+	CantidadPasajeros int       `json:"cantidad_pasajeros" db:"cantidad_pasajeros"`
+	FechaEmbarque     time.Time `json:"fecha_embarque" db:"fecha_embarque"`
+
+	// Campos adicionales para mostrar información relacionada
+	NombreCliente string `json:"nombre_cliente,omitempty" db:"-"`
+}
+
+// RegistrarEmbarqueRequest representa el código QR escaneado por el chofer
+type RegistrarEmbarqueRequest struct {
+	Codigo string `json:"codigo" validate:"required"`
+}
+
+// ContadorEmbarque resume los pasajeros embarcados frente a los reservados de una salida
+type ContadorEmbarque struct {
+	IDTourProgramado    int `json:"id_tour_programado"`
+	ReservasTotales     int `json:"reservas_totales"`     // Reservas que pueden embarcar
+	ReservasEmbarcadas  int `json:"reservas_embarcadas"`  // Reservas con check-in
+	PasajerosReservados int `json:"pasajeros_reservados"` // Pasajes de las reservas que pueden embarcar
+	PasajerosEmbarcados int `json:"pasajeros_embarcados"` // Pasajes de las reservas con check-in
+}
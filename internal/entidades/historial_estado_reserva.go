@@ -0,0 +1,20 @@
+package entidades
+
+import "time"
+
+// HistorialEstadoReserva registra un cambio de estado de una reserva: quién lo hizo y cuándo
+type HistorialEstadoReserva struct {
+	ID             int       `json:"id_historial" db:"id_historial"`
+	IDReserva      int       `json:"id_reserva" db:"id_reserva"`
+	EstadoAnterior string    `json:"estado_anterior,omitempty" db:"estado_anterior"` // Vacío al crear la reserva
+	EstadoNuevo    string    `json:"estado_nuevo" db:"estado_nuevo"`
+	Rol            string    `json:"rol" db:"rol"`                         // Rol del personal, CLIENTE, AGENCIA o SISTEMA
+	IDUsuario      *int      `json:"id_usuario,omitempty" db:"id_usuario"` // Personal que hizo el cambio
+	IDCliente      *int      `json:"id_cliente,omitempty" db:"id_cliente"` // Cliente que hizo el cambio
+	IDAPIKey       *int      `json:"id_api_key,omitempty" db:"id_api_key"` // Llave de la agencia que hizo el cambio
+	Motivo         string    `json:"motivo,omitempty" db:"motivo"`
+	FechaCambio    time.Time `json:"fecha_cambio" db:"fecha_cambio"`
+
+	// Campos adicionales para mostrar información relacionada
+	NombreUsuario string `json:"nombre_usuario,omitempty" db:"-"`
+}
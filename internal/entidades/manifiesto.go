@@ -0,0 +1,35 @@
+package entidades
+
+import "time"
+
+// ManifiestoTour representa la lista de pasajeros de un tour programado que se presenta a la Capitanía de Puerto
+type ManifiestoTour struct {
+	Tour           *TourProgramado          `json:"tour"`
+	Pasajeros      []*PasajeroManifiesto    `json:"pasajeros"`
+	TotalesPorTipo []*TotalPasajeManifiesto `json:"totales_por_tipo"`
+	TotalPasajes   int                      `json:"total_pasajes"`   // Pasajes vendidos en reservas no canceladas
+	TotalPasajeros int                      `json:"total_pasajeros"` // Pasajeros registrados con nombre y documento
+	GeneradoEn     time.Time                `json:"generado_en"`
+}
+
+// PasajeroManifiesto es una fila del manifiesto con los datos del pasajero y de su reserva
+type PasajeroManifiesto struct {
+	IDReserva        int       `json:"id_reserva"`
+	EstadoReserva    string    `json:"estado_reserva"`
+	Nombres          string    `json:"nombres"`
+	Apellidos        string    `json:"apellidos"`
+	TipoDocumento    string    `json:"tipo_documento"`
+	NumeroDocumento  string    `json:"numero_documento"`
+	Nacionalidad     string    `json:"nacionalidad"`
+	FechaNacimiento  time.Time `json:"fecha_nacimiento"`
+	Edad             int       `json:"edad"` // Edad a la fecha del tour
+	NombreTipoPasaje string    `json:"nombre_tipo_pasaje"`
+}
+
+// TotalPasajeManifiesto resume los pasajes vendidos y los pasajeros registrados de un tipo de pasaje
+type TotalPasajeManifiesto struct {
+	IDTipoPasaje int    `json:"id_tipo_pasaje"`
+	NombreTipo   string `json:"nombre_tipo"`
+	Pasajes      int    `json:"pasajes"`
+	Registrados  int    `json:"registrados"`
+}
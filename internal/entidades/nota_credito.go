@@ -0,0 +1,42 @@
+package entidades
+
+import "time"
+
+// NotaCredito representa una nota de crédito que modifica un comprobante de pago emitido
+type NotaCredito struct {
+	ID            int       `json:"id_nota_credito" db:"id_nota_credito"`
+	IDComprobante int       `json:"id_comprobante" db:"id_comprobante"`
+	IDPago        *int      `json:"id_pago,omitempty" db:"id_pago"` // Pago de devolución
+	Serie         string    `json:"serie" db:"serie"`
+	Correlativo   int       `json:"correlativo" db:"correlativo"`
+	NumeroNota    string    `json:"numero_nota" db:"numero_nota"` // SERIE-CORRELATIVO, por ejemplo FC01-00000001
+	FechaEmision  time.Time `json:"fecha_emision" db:"fecha_emision"`
+	CodigoMotivo  string    `json:"codigo_motivo" db:"codigo_motivo"` // Catálogo 09 de SUNAT
+	Motivo        string    `json:"motivo" db:"motivo"`
+	Subtotal      float64   `json:"subtotal" db:"subtotal"`
+	IGV           float64   `json:"igv" db:"igv"`
+	Total         float64   `json:"total" db:"total"`
+	Estado        string    `json:"estado" db:"estado"`
+
+	// Facturación electrónica
+	EstadoSunat      string `json:"estado_sunat" db:"estado_sunat"`
+	CodigoSunat      string `json:"codigo_sunat,omitempty" db:"codigo_sunat"`
+	DescripcionSunat string `json:"descripcion_sunat,omitempty" db:"descripcion_sunat"`
+	HashCPE          string `json:"hash_cpe,omitempty" db:"hash_cpe"`
+
+	// Campos adicionales para mostrar información relacionada
+	IDReserva         int    `json:"id_reserva,omitempty" db:"-"`
+	TipoComprobante   string `json:"tipo_comprobante,omitempty" db:"-"`
+	NumeroComprobante string `json:"numero_comprobante,omitempty" db:"-"`
+}
+
+// NuevaNotaCreditoRequest representa los datos necesarios para emitir una nota de crédito.
+// Si se omite el monto, la nota se emite por el saldo no acreditado del comprobante.
+// Si se indica el método de pago, se registra la devolución del monto al cliente.
+type NuevaNotaCreditoRequest struct {
+	IDComprobante int     `json:"id_comprobante" validate:"required"`
+	Motivo        string  `json:"motivo" validate:"required,max=255"`
+	Monto         float64 `json:"monto" validate:"omitempty,gt=0"`
+	IDMetodoPago  int     `json:"id_metodo_pago" validate:"omitempty"`
+	IDCanal       int     `json:"id_canal" validate:"required_with=IDMetodoPago"`
+}
@@ -12,6 +12,7 @@ type Pago struct {
 	FechaPago    time.Time `json:"fecha_pago" db:"fecha_pago"`
 	Comprobante  string    `json:"comprobante" db:"comprobante"`
 	Estado       string    `json:"estado" db:"estado"`
+	Tipo         string    `json:"tipo" db:"tipo"` // COBRO, DEVOLUCION
 
 	// Campos adicionales para mostrar información relacionada
 	NumeroReserva    int    `json:"numero_reserva,omitempty" db:"-"`
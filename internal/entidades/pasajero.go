@@ -0,0 +1,41 @@
+package entidades
+
+import "time"
+
+// Pasajero representa a una persona que viaja con una reserva (manifiesto de pasajeros)
+type Pasajero struct {
+	ID              int       `json:"id_pasajero" db:"id_pasajero"`
+	IDReserva       int       `json:"id_reserva" db:"id_reserva"`
+	IDTipoPasaje    int       `json:"id_tipo_pasaje" db:"id_tipo_pasaje"`
+	Nombres         string    `json:"nombres" db:"nombres"`
+	Apellidos       string    `json:"apellidos" db:"apellidos"`
+	TipoDocumento   string    `json:"tipo_documento" db:"tipo_documento"`
+	NumeroDocumento string    `json:"numero_documento" db:"numero_documento"`
+	Nacionalidad    string    `json:"nacionalidad" db:"nacionalidad"`
+	FechaNacimiento time.Time `json:"fecha_nacimiento" db:"fecha_nacimiento"`
+
+	// Campos adicionales para mostrar información relacionada
+	NombreTipoPasaje string `json:"nombre_tipo_pasaje,omitempty" db:"-"`
+}
+
+// NuevoPasajeroRequest representa los datos necesarios para registrar un pasajero en una reserva
+type NuevoPasajeroRequest struct {
+	IDTipoPasaje    int       `json:"id_tipo_pasaje" validate:"required"`
+	Nombres         string    `json:"nombres" validate:"required"`
+	Apellidos       string    `json:"apellidos" validate:"required"`
+	TipoDocumento   string    `json:"tipo_documento" validate:"required"`
+	NumeroDocumento string    `json:"numero_documento" validate:"required"`
+	Nacionalidad    string    `json:"nacionalidad" validate:"required"`
+	FechaNacimiento time.Time `json:"fecha_nacimiento" validate:"required"`
+}
+
+// ActualizarPasajeroRequest representa los datos para actualizar un pasajero
+type ActualizarPasajeroRequest struct {
+	IDTipoPasaje    int       `json:"id_tipo_pasaje" validate:"required"`
+	Nombres         string    `json:"nombres" validate:"required"`
+	Apellidos       string    `json:"apellidos" validate:"required"`
+	TipoDocumento   string    `json:"tipo_documento" validate:"required"`
+	NumeroDocumento string    `json:"numero_documento" validate:"required"`
+	Nacionalidad    string    `json:"nacionalidad" validate:"required"`
+	FechaNacimiento time.Time `json:"fecha_nacimiento" validate:"required"`
+}
@@ -0,0 +1,50 @@
+package entidades
+
+// Permiso representa una acción protegida del sistema, con el formato recurso:acción
+type Permiso struct {
+	Codigo      string `json:"codigo" db:"codigo"`
+	Descripcion string `json:"descripcion" db:"descripcion"`
+}
+
+// Permisos que protegen las rutas y las transiciones de reserva del personal; cada uno debe existir en la tabla permiso
+const (
+	PermisoUsuariosGestionar       = "usuarios:manage"
+	PermisoRolesGestionar          = "roles:manage"
+	PermisoSeguridadGestionar      = "seguridad:manage"
+	PermisoEmbarcacionesVer        = "embarcaciones:read"
+	PermisoEmbarcacionesGestionar  = "embarcaciones:manage"
+	PermisoTiposTourVer            = "tipos-tour:read"
+	PermisoTiposTourGestionar      = "tipos-tour:manage"
+	PermisoHorariosChoferVer       = "horarios-chofer:read"
+	PermisoHorariosChoferGestionar = "horarios-chofer:manage"
+	PermisoToursVer                = "tours:read"
+	PermisoToursProgramar          = "tours:schedule"
+	PermisoToursAsignados          = "tours:assigned"
+	PermisoTarifasVer              = "tarifas:read"
+	PermisoTarifasGestionar        = "tarifas:manage"
+	PermisoMetodosPagoVer          = "metodos-pago:read"
+	PermisoMetodosPagoGestionar    = "metodos-pago:manage"
+	PermisoCanalesVentaVer         = "canales-venta:read"
+	PermisoCanalesVentaGestionar   = "canales-venta:manage"
+	PermisoClientesVer             = "clientes:read"
+	PermisoClientesEditar          = "clientes:write"
+	PermisoClientesEliminar        = "clientes:delete"
+	PermisoReservasVer             = "reservas:read"
+	PermisoReservasCrear           = "reservas:create"
+	PermisoReservasEditar          = "reservas:update"
+	PermisoReservasEliminar        = "reservas:delete"
+	PermisoReservasEmbarcar        = "reservas:board"
+	PermisoReservasReabrir         = "reservas:reopen"
+	PermisoReservasAsignarVendedor = "reservas:assign"
+	PermisoPagosVer                = "pagos:read"
+	PermisoPagosCrear              = "pagos:create"
+	PermisoPagosAnular             = "pagos:annul"
+	PermisoComprobantesVer         = "comprobantes:read"
+	PermisoComprobantesEmitir      = "comprobantes:issue"
+	PermisoNotasCreditoEmitir      = "notas-credito:issue"
+	PermisoSunatGestionar          = "sunat:manage"
+	PermisoManifiestosVer          = "manifiestos:read"
+	PermisoEmbarquesVer            = "embarques:read"
+	PermisoEmbarquesRegistrar      = "embarques:register"
+	PermisoAPIKeysGestionar        = "api-keys:manage"
+)
@@ -0,0 +1,22 @@
+package entidades
+
+import "time"
+
+// RefreshToken representa un refresh token emitido a un usuario o cliente.
+// Solo se guarda el hash del token; el token en claro lo conserva el sujeto.
+type RefreshToken struct {
+	ID              int        `json:"id_refresh_token" db:"id_refresh_token"`
+	TipoSujeto      string     `json:"tipo_sujeto" db:"tipo_sujeto"` // USUARIO, CLIENTE
+	IDSujeto        int        `json:"id_sujeto" db:"id_sujeto"`
+	TokenHash       string     `json:"-" db:"token_hash"`
+	Familia         string     `json:"familia" db:"familia"`
+	FechaEmision    time.Time  `json:"fecha_emision" db:"fecha_emision"`
+	FechaExpiracion time.Time  `json:"fecha_expiracion" db:"fecha_expiracion"`
+	FechaUso        *time.Time `json:"fecha_uso,omitempty" db:"fecha_uso"`
+	FechaRevocacion *time.Time `json:"fecha_revocacion,omitempty" db:"fecha_revocacion"`
+}
+
+// LogoutRequest representa el refresh token de la sesión que se cierra
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
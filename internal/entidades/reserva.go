@@ -1,18 +1,46 @@
 package entidades
 
-import "time"
+import (
+	"math"
+	"time"
+)
+
+// EstadosReserva lista todos los estados que puede tener una reserva.
+// Lo usan la validación de las solicitudes, los filtros por estado y la tabla de transiciones.
+var EstadosReserva = []string{
+	"PENDIENTE_PAGO",
+	"RESERVADO",
+	"CONFIRMADA",
+	"PAGADA",
+	"EMBARCADO",
+	"COMPLETADA",
+	"NO_SHOW",
+	"CANCELADA",
+}
+
+// EsEstadoReserva indica si el estado es uno de los estados de reserva
+func EsEstadoReserva(estado string) bool {
+	for _, e := range EstadosReserva {
+		if e == estado {
+			return true
+		}
+	}
+	return false
+}
 
 // Reserva representa la estructura de una reserva en el sistema
 type Reserva struct {
-	ID               int       `json:"id_reserva" db:"id_reserva"`
-	IDVendedor       *int      `json:"id_vendedor,omitempty" db:"id_vendedor"`
-	IDCliente        int       `json:"id_cliente" db:"id_cliente"`
-	IDTourProgramado int       `json:"id_tour_programado" db:"id_tour_programado"`
-	IDCanal          int       `json:"id_canal" db:"id_canal"`
-	FechaReserva     time.Time `json:"fecha_reserva" db:"fecha_reserva"`
-	TotalPagar       float64   `json:"total_pagar" db:"total_pagar"`
-	Notas            string    `json:"notas" db:"notas"`
-	Estado           string    `json:"estado" db:"estado"` // RESERVADO, CANCELADA, etc.
+	ID               int        `json:"id_reserva" db:"id_reserva"`
+	IDVendedor       *int       `json:"id_vendedor,omitempty" db:"id_vendedor"`
+	IDCliente        int        `json:"id_cliente" db:"id_cliente"`
+	IDTourProgramado int        `json:"id_tour_programado" db:"id_tour_programado"`
+	IDCanal          int        `json:"id_canal" db:"id_canal"`
+	FechaReserva     time.Time  `json:"fecha_reserva" db:"fecha_reserva"`
+	TotalPagar       float64    `json:"total_pagar" db:"total_pagar"`
+	Notas            string     `json:"notas" db:"notas"`
+	Estado           string     `json:"estado" db:"estado"`                 // PENDIENTE_PAGO, RESERVADO, CONFIRMADA, PAGADA, EMBARCADO, COMPLETADA, NO_SHOW, CANCELADA
+	ExpiraEn         *time.Time `json:"expira_en,omitempty" db:"expira_en"` // Vencimiento de la retención si está PENDIENTE_PAGO
+	Localizador      string     `json:"localizador" db:"localizador"`       // Código público de la reserva (ej. TRS-7KQ2M9)
 
 	// Campos adicionales para mostrar información relacionada
 	NombreCliente   string           `json:"nombre_cliente,omitempty" db:"-"`
@@ -22,13 +50,46 @@ type Reserva struct {
 	HoraTour        string           `json:"hora_tour,omitempty" db:"-"`
 	NombreCanal     string           `json:"nombre_canal,omitempty" db:"-"`
 	CantidadPasajes []PasajeCantidad `json:"cantidad_pasajes,omitempty" db:"-"`
+
+	// Campos calculados a partir de los pagos procesados
+	MontoPagado    float64 `json:"monto_pagado" db:"-"`
+	SaldoPendiente float64 `json:"saldo_pendiente" db:"-"`
+	EstadoPago     string  `json:"estado_pago" db:"-"` // PENDIENTE, PARCIAL, PAGADO
+}
+
+// AplicarPagos completa el monto pagado, el saldo pendiente y el estado de pago a partir del total
+// de los pagos procesados. Los montos se comparan en céntimos para evitar errores de redondeo.
+func (r *Reserva) AplicarPagos(montoPagado float64) {
+	pagado := centimos(montoPagado)
+	total := centimos(r.TotalPagar)
+
+	r.MontoPagado = montoPagado
+	r.SaldoPendiente = 0
+	if pagado < total {
+		r.SaldoPendiente = float64(total-pagado) / 100
+	}
+
+	switch {
+	case pagado >= total:
+		r.EstadoPago = "PAGADO"
+	case pagado <= 0:
+		r.EstadoPago = "PENDIENTE"
+	default:
+		r.EstadoPago = "PARCIAL"
+	}
+}
+
+// centimos convierte un monto en soles a céntimos
+func centimos(monto float64) int64 {
+	return int64(math.Round(monto * 100))
 }
 
 // PasajeCantidad representa la cantidad de pasajes de un tipo en la reserva
 type PasajeCantidad struct {
-	IDTipoPasaje int    `json:"id_tipo_pasaje" db:"id_tipo_pasaje"`
-	NombreTipo   string `json:"nombre_tipo" db:"nombre"`
-	Cantidad     int    `json:"cantidad" db:"cantidad"`
+	IDTipoPasaje   int     `json:"id_tipo_pasaje" db:"id_tipo_pasaje"`
+	NombreTipo     string  `json:"nombre_tipo" db:"nombre"`
+	Cantidad       int     `json:"cantidad" db:"cantidad"`
+	PrecioUnitario float64 `json:"precio_unitario" db:"precio_unitario"`
 }
 
 // NuevaReservaRequest representa los datos necesarios para crear una nueva reserva
@@ -36,16 +97,38 @@ type NuevaReservaRequest struct {
 	IDCliente        int                     `json:"id_cliente" validate:"required"`
 	IDTourProgramado int                     `json:"id_tour_programado" validate:"required"`
 	IDCanal          int                     `json:"id_canal" validate:"required"`
-	IDVendedor       *int                    `json:"id_vendedor,omitempty"` // Opcional, solo si es reserva en LOCAL
-	TotalPagar       float64                 `json:"total_pagar" validate:"required,min=0"`
+	IDVendedor       *int                    `json:"id_vendedor,omitempty"`                  // Opcional, solo si es reserva en LOCAL
+	TotalPagar       float64                 `json:"total_pagar" validate:"omitempty,min=0"` // Opcional, lo calcula el servidor; si se envía debe coincidir
+	Notas            string                  `json:"notas"`
+	CantidadPasajes  []PasajeCantidadRequest `json:"cantidad_pasajes" validate:"required,min=1,dive"`
+	Estado           string                  `json:"-"` // Lo asigna el servidor según el canal de venta
+	ExpiraEn         *time.Time              `json:"-"` // Vencimiento de la retención, solo si queda PENDIENTE_PAGO
+	Localizador      string                  `json:"-"` // Lo genera el servidor
+}
+
+// NuevaReservaAgenciaRequest representa los datos de una reserva registrada por una agencia con su llave de API.
+// El canal de venta es el de la llave y el cliente se identifica por su documento.
+type NuevaReservaAgenciaRequest struct {
+	Cliente          ClienteAgenciaRequest   `json:"cliente"`
+	IDTourProgramado int                     `json:"id_tour_programado" validate:"required"`
+	TotalPagar       float64                 `json:"total_pagar" validate:"omitempty,min=0"` // Opcional, lo calcula el servidor; si se envía debe coincidir
 	Notas            string                  `json:"notas"`
 	CantidadPasajes  []PasajeCantidadRequest `json:"cantidad_pasajes" validate:"required,min=1,dive"`
 }
 
+// ClienteAgenciaRequest representa al cliente por el que reserva una agencia; se registra si aún no existe
+type ClienteAgenciaRequest struct {
+	TipoDocumento   string `json:"tipo_documento" validate:"required"`
+	NumeroDocumento string `json:"numero_documento" validate:"required"`
+	Nombres         string `json:"nombres" validate:"required"`
+	Apellidos       string `json:"apellidos" validate:"required"`
+}
+
 // PasajeCantidadRequest representa la cantidad de pasajes de un tipo en la solicitud
 type PasajeCantidadRequest struct {
-	IDTipoPasaje int `json:"id_tipo_pasaje" validate:"required"`
-	Cantidad     int `json:"cantidad" validate:"required,min=1"`
+	IDTipoPasaje   int     `json:"id_tipo_pasaje" validate:"required"`
+	Cantidad       int     `json:"cantidad" validate:"required,min=1"`
+	PrecioUnitario float64 `json:"-"` // Lo asigna el servidor a partir de la tarifa vigente
 }
 
 // ActualizarReservaRequest representa los datos para actualizar una reserva
@@ -53,14 +136,21 @@ type ActualizarReservaRequest struct {
 	IDCliente        int                     `json:"id_cliente" validate:"required"`
 	IDTourProgramado int                     `json:"id_tour_programado" validate:"required"`
 	IDCanal          int                     `json:"id_canal" validate:"required"`
-	IDVendedor       *int                    `json:"id_vendedor,omitempty"` // Opcional, solo si es reserva en LOCAL
-	TotalPagar       float64                 `json:"total_pagar" validate:"required,min=0"`
+	IDVendedor       *int                    `json:"id_vendedor,omitempty"`                  // Opcional, solo si es reserva en LOCAL
+	TotalPagar       float64                 `json:"total_pagar" validate:"omitempty,min=0"` // Opcional, lo calcula el servidor; si se envía debe coincidir
 	Notas            string                  `json:"notas"`
-	Estado           string                  `json:"estado" validate:"required,oneof=RESERVADO CANCELADA"`
+	Estado           string                  `json:"estado" validate:"omitempty,estado_reserva"` // Opcional, si se omite se mantiene
 	CantidadPasajes  []PasajeCantidadRequest `json:"cantidad_pasajes" validate:"required,min=1,dive"`
 }
 
+// ConsultaReservaRequest representa los datos para que un cliente recupere su reserva sin iniciar sesión
+type ConsultaReservaRequest struct {
+	Localizador     string `json:"localizador" validate:"required"`
+	NumeroDocumento string `json:"numero_documento" validate:"required"`
+}
+
 // CambiarEstadoReservaRequest representa los datos para cambiar el estado de una reserva
 type CambiarEstadoReservaRequest struct {
-	Estado string `json:"estado" validate:"required,oneof=RESERVADO CANCELADA"`
+	Estado string `json:"estado" validate:"required,estado_reserva"`
+	Motivo string `json:"motivo" validate:"omitempty,max=255"`
 }
@@ -0,0 +1,34 @@
+package entidades
+
+import "time"
+
+// ResumenSunat representa un resumen diario de boletas (RC) o una comunicación de baja (RA) enviada a SUNAT
+type ResumenSunat struct {
+	ID               int       `json:"id_resumen" db:"id_resumen"`
+	Tipo             string    `json:"tipo" db:"tipo"`                   // RC, RA
+	Identificador    string    `json:"identificador" db:"identificador"` // Por ejemplo: RC-20240115-1
+	Numero           int       `json:"numero" db:"numero"`
+	FechaReferencia  time.Time `json:"fecha_referencia" db:"fecha_referencia"`
+	FechaGeneracion  time.Time `json:"fecha_generacion" db:"fecha_generacion"`
+	Ticket           string    `json:"ticket,omitempty" db:"ticket"`
+	Estado           string    `json:"estado" db:"estado"` // PENDIENTE, ENVIADO, ACEPTADO, OBSERVADO, RECHAZADO, ERROR
+	CodigoSunat      string    `json:"codigo_sunat,omitempty" db:"codigo_sunat"`
+	DescripcionSunat string    `json:"descripcion_sunat,omitempty" db:"descripcion_sunat"`
+
+	// Comprobantes incluidos
+	Detalle []*ResumenSunatDetalle `json:"detalle,omitempty" db:"-"`
+}
+
+// ResumenSunatDetalle representa un comprobante incluido en un resumen
+type ResumenSunatDetalle struct {
+	ID                int    `json:"id_resumen_detalle" db:"id_resumen_detalle"`
+	IDResumen         int    `json:"id_resumen" db:"id_resumen"`
+	IDComprobante     int    `json:"id_comprobante" db:"id_comprobante"`
+	Condicion         int    `json:"condicion" db:"condicion"` // 1 adicionar, 3 anular
+	NumeroComprobante string `json:"numero_comprobante,omitempty" db:"-"`
+}
+
+// NuevoResumenSunatRequest representa los datos para generar un resumen diario o una comunicación de baja
+type NuevoResumenSunatRequest struct {
+	Fecha string `json:"fecha" validate:"required"` // Fecha de emisión de los comprobantes, formato YYYY-MM-DD
+}
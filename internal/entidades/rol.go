@@ -0,0 +1,29 @@
+package entidades
+
+import "time"
+
+// RolAdministrador es el rol con todos los permisos; no se puede modificar para que siempre
+// quede alguien capaz de gestionar los demás roles
+const RolAdministrador = "ADMIN"
+
+// Rol representa un rol del personal como conjunto de permisos
+type Rol struct {
+	Nombre        string    `json:"nombre" db:"nombre"`
+	Descripcion   string    `json:"descripcion" db:"descripcion"`
+	Sistema       bool      `json:"sistema" db:"sistema"` // Los roles de sistema no se pueden eliminar
+	Permisos      []string  `json:"permisos"`
+	FechaCreacion time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+}
+
+// NuevoRolRequest representa los datos para crear un rol
+type NuevoRolRequest struct {
+	Nombre      string   `json:"nombre" validate:"required,max=20,uppercase"`
+	Descripcion string   `json:"descripcion" validate:"max=255"`
+	Permisos    []string `json:"permisos" validate:"required,min=1,dive,required"`
+}
+
+// ActualizarRolRequest representa los datos para modificar la descripción y los permisos de un rol
+type ActualizarRolRequest struct {
+	Descripcion string   `json:"descripcion" validate:"max=255"`
+	Permisos    []string `json:"permisos" validate:"required,min=1,dive,required"`
+}
@@ -0,0 +1,36 @@
+package entidades
+
+// TarifaTour representa el precio de un tipo de pasaje en un tipo de tour específico
+type TarifaTour struct {
+	ID           int     `json:"id_tarifa" db:"id_tarifa"`
+	IDTipoTour   int     `json:"id_tipo_tour" db:"id_tipo_tour"`
+	IDTipoPasaje int     `json:"id_tipo_pasaje" db:"id_tipo_pasaje"`
+	Precio       float64 `json:"precio" db:"precio"`
+
+	// Campos adicionales para mostrar información relacionada
+	NombreTipoTour   string  `json:"nombre_tipo_tour,omitempty" db:"-"`
+	NombreTipoPasaje string  `json:"nombre_tipo_pasaje,omitempty" db:"-"`
+	CostoGeneral     float64 `json:"costo_general" db:"-"` // Costo global del tipo de pasaje
+}
+
+// TarifaVigente representa el precio aplicable de un tipo de pasaje en un tipo de tour,
+// ya sea la tarifa específica o, en su defecto, el costo general del tipo de pasaje
+type TarifaVigente struct {
+	IDTipoPasaje int     `json:"id_tipo_pasaje"`
+	NombreTipo   string  `json:"nombre_tipo"`
+	Edad         string  `json:"edad"`
+	Precio       float64 `json:"precio"`
+	Especifica   bool    `json:"especifica"` // true si proviene de la tarifa del tipo de tour
+}
+
+// NuevaTarifaTourRequest representa los datos necesarios para crear una tarifa
+type NuevaTarifaTourRequest struct {
+	IDTipoTour   int     `json:"id_tipo_tour" validate:"required"`
+	IDTipoPasaje int     `json:"id_tipo_pasaje" validate:"required"`
+	Precio       float64 `json:"precio" validate:"min=0"`
+}
+
+// ActualizarTarifaTourRequest representa los datos para actualizar una tarifa
+type ActualizarTarifaTourRequest struct {
+	Precio float64 `json:"precio" validate:"min=0"`
+}
@@ -6,6 +6,10 @@ type TipoPasaje struct {
 	Nombre string  `json:"nombre" db:"nombre"`
 	Costo  float64 `json:"costo" db:"costo"`
 	Edad   string  `json:"edad" db:"edad"`
+
+	// Rango de edad aplicable al tipo de pasaje, en años cumplidos a la fecha del tour (nil: sin límite)
+	EdadMinima *int `json:"edad_minima,omitempty" db:"edad_minima"`
+	EdadMaxima *int `json:"edad_maxima,omitempty" db:"edad_maxima"`
 }
 
 // NuevoTipoPasajeRequest representa los datos necesarios para crear un nuevo tipo de pasaje
@@ -13,6 +17,9 @@ type NuevoTipoPasajeRequest struct {
 	Nombre string  `json:"nombre" validate:"required"`
 	Costo  float64 `json:"costo" validate:"required,min=0"`
 	Edad   string  `json:"edad" validate:"required"`
+
+	EdadMinima *int `json:"edad_minima" validate:"omitempty,min=0"`
+	EdadMaxima *int `json:"edad_maxima" validate:"omitempty,min=0"`
 }
 
 // ActualizarTipoPasajeRequest representa los datos para actualizar un tipo de pasaje
@@ -20,4 +27,7 @@ type ActualizarTipoPasajeRequest struct {
 	Nombre string  `json:"nombre" validate:"required"`
 	Costo  float64 `json:"costo" validate:"required,min=0"`
 	Edad   string  `json:"edad" validate:"required"`
+
+	EdadMinima *int `json:"edad_minima" validate:"omitempty,min=0"`
+	EdadMaxima *int `json:"edad_maxima" validate:"omitempty,min=0"`
 }
@@ -0,0 +1,26 @@
+package entidades
+
+import "time"
+
+// TokenRecuperacion representa un token de recuperación de contraseña enviado por correo.
+// Solo se guarda el hash del token.
+type TokenRecuperacion struct {
+	ID              int        `json:"id_token_recuperacion" db:"id_token_recuperacion"`
+	TipoSujeto      string     `json:"tipo_sujeto" db:"tipo_sujeto"` // USUARIO, CLIENTE
+	IDSujeto        int        `json:"id_sujeto" db:"id_sujeto"`
+	TokenHash       string     `json:"-" db:"token_hash"`
+	FechaEmision    time.Time  `json:"fecha_emision" db:"fecha_emision"`
+	FechaExpiracion time.Time  `json:"fecha_expiracion" db:"fecha_expiracion"`
+	FechaUso        *time.Time `json:"fecha_uso,omitempty" db:"fecha_uso"`
+}
+
+// OlvideContrasenaRequest representa el correo de la cuenta cuya contraseña se quiere recuperar
+type OlvideContrasenaRequest struct {
+	Correo string `json:"correo" validate:"required,email"`
+}
+
+// RestablecerContrasenaRequest representa el token recibido por correo y la nueva contraseña
+type RestablecerContrasenaRequest struct {
+	Token           string `json:"token" validate:"required"`
+	NuevaContrasena string `json:"nueva_contrasena" validate:"required,min=8"`
+}
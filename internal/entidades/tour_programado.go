@@ -19,10 +19,14 @@ type TourProgramado struct {
 	DuracionMinutos      int     `json:"duracion_minutos,omitempty" db:"-"`
 	NombreEmbarcacion    string  `json:"nombre_embarcacion,omitempty" db:"-"`
 	CapacidadEmbarcacion int     `json:"capacidad_embarcacion,omitempty" db:"-"`
+	IDChofer             int     `json:"id_chofer,omitempty" db:"-"`
 	NombreChofer         string  `json:"nombre_chofer,omitempty" db:"-"`
 	ApellidosChofer      string  `json:"apellidos_chofer,omitempty" db:"-"`
 	HoraInicio           string  `json:"hora_inicio,omitempty" db:"-"`
 	HoraFin              string  `json:"hora_fin,omitempty" db:"-"`
+
+	// Tarifas vigentes de cada tipo de pasaje para este tour
+	Tarifas []*TarifaVigente `json:"tarifas,omitempty" db:"-"`
 }
 
 // NuevoTourProgramadoRequest representa los datos necesarios para crear un nuevo tour programado
@@ -13,7 +13,7 @@ type Usuario struct {
 	Telefono        string    `json:"telefono" db:"telefono"`
 	Direccion       string    `json:"direccion" db:"direccion"`
 	FechaNacimiento time.Time `json:"fecha_nacimiento" db:"fecha_nacimiento"`
-	Rol             string    `json:"rol" db:"rol"` // ADMIN, VENDEDOR, CHOFER, CLIENTE
+	Rol             string    `json:"rol" db:"rol"` // ADMIN, VENDEDOR, CHOFER o un rol creado desde la API
 	Nacionalidad    string    `json:"nacionalidad" db:"nacionalidad"`
 	TipoDocumento   string    `json:"tipo_documento" db:"tipo_de_documento"`
 	NumeroDocumento string    `json:"numero_documento" db:"numero_documento"`
@@ -30,7 +30,7 @@ type NuevoUsuarioRequest struct {
 	Telefono        string    `json:"telefono"`
 	Direccion       string    `json:"direccion"`
 	FechaNacimiento time.Time `json:"fecha_nacimiento" validate:"required"`
-	Rol             string    `json:"rol" validate:"required,oneof=ADMIN VENDEDOR CHOFER CLIENTE"`
+	Rol             string    `json:"rol" validate:"required,max=20"` // Debe existir en la tabla rol
 	Nacionalidad    string    `json:"nacionalidad"`
 	TipoDocumento   string    `json:"tipo_documento" validate:"required"`
 	NumeroDocumento string    `json:"numero_documento" validate:"required"`
@@ -43,9 +43,14 @@ type LoginRequest struct {
 	Contrasena string `json:"contrasena" validate:"required"`
 }
 
-// LoginResponse representa la respuesta al iniciar sesión exitosamente
+// LoginResponse representa la respuesta al iniciar sesión exitosamente.
+// Si la cuenta usa doble factor, el primer paso solo devuelve el token de desafío y no emite tokens.
 type LoginResponse struct {
-	Token        string   `json:"token"`
-	RefreshToken string   `json:"refresh_token"`
-	Usuario      *Usuario `json:"usuario"`
+	Token                string   `json:"token"`
+	RefreshToken         string   `json:"refresh_token"`
+	Usuario              *Usuario `json:"usuario"`
+	RequiereDobleFactor  bool     `json:"requiere_doble_factor,omitempty"`
+	RequiereEnrolamiento bool     `json:"requiere_enrolamiento,omitempty"` // El rol exige doble factor y aún no se configuró
+	TokenDesafio         string   `json:"token_desafio,omitempty"`
+	CodigosRecuperacion  []string `json:"codigos_recuperacion,omitempty"`
 }
@@ -0,0 +1,136 @@
+package impresion
+
+import (
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/sunat"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// LineaComprobante es una línea de detalle impresa en el comprobante (importes con IGV incluido)
+type LineaComprobante struct {
+	Cantidad       int
+	Descripcion    string
+	PrecioUnitario float64
+	Importe        float64
+}
+
+// Comprobante genera la representación impresa (A4) de una boleta o factura electrónica
+func Comprobante(emisor sunat.Emisor, c *entidades.ComprobantePago, lineas []LineaComprobante) ([]byte, error) {
+	d := nuevoDocumento(gofpdf.New("P", "mm", "A4", ""))
+	d.pdf.SetTitle(c.NumeroComprobante, true)
+	d.pdf.AddPage()
+
+	titulo := tituloComprobante(c.Tipo)
+
+	// Datos del emisor
+	d.fuente("B", 14)
+	d.celda(120, 7, emisor.RazonSocial, "", 2, "L")
+	d.fuente("", 9)
+	if emisor.NombreComercial != "" {
+		d.celda(120, 5, emisor.NombreComercial, "", 2, "L")
+	}
+	if emisor.Direccion != "" {
+		d.celda(120, 5, emisor.Direccion, "", 2, "L")
+	}
+
+	// Recuadro con el tipo y número del comprobante
+	d.pdf.SetXY(135, 10)
+	d.fuente("B", 11)
+	d.celda(65, 8, "RUC "+emisor.RUC, "LTR", 2, "C")
+	d.pdf.SetX(135)
+	d.celda(65, 8, titulo, "LR", 2, "C")
+	d.pdf.SetX(135)
+	d.celda(65, 8, c.NumeroComprobante, "LBR", 2, "C")
+	d.pdf.Ln(6)
+
+	// Datos del adquiriente
+	etiquetaDocumento := c.TipoDocumentoCliente
+	if etiquetaDocumento == "" {
+		etiquetaDocumento = "DOC."
+	}
+	d.fuente("", 10)
+	filaDato(d, "Fecha de emisión:", c.FechaEmision.Format("02/01/2006 15:04"))
+	filaDato(d, "Señor(es):", strings.TrimSpace(c.NombreCliente+" "+c.ApellidosCliente))
+	filaDato(d, etiquetaDocumento+":", c.DocumentoCliente)
+	filaDato(d, "Tour:", fmt.Sprintf("%s - %s", c.NombreTour, c.FechaTour.Format("02/01/2006")))
+	if c.LocalizadorReserva != "" {
+		filaDato(d, "Localizador:", c.LocalizadorReserva)
+	}
+	filaDato(d, "Moneda:", "SOLES")
+	d.pdf.Ln(4)
+
+	// Detalle
+	d.fuente("B", 10)
+	d.celda(20, 7, "CANT.", "1", 0, "C")
+	d.celda(110, 7, "DESCRIPCIÓN", "1", 0, "C")
+	d.celda(30, 7, "P. UNIT.", "1", 0, "C")
+	d.celda(30, 7, "IMPORTE", "1", 1, "C")
+	d.fuente("", 10)
+	for _, l := range lineas {
+		d.celda(20, 7, fmt.Sprintf("%d", l.Cantidad), "1", 0, "C")
+		d.celda(110, 7, l.Descripcion, "1", 0, "L")
+		d.celda(30, 7, monto(l.PrecioUnitario), "1", 0, "R")
+		d.celda(30, 7, monto(l.Importe), "1", 1, "R")
+	}
+	d.pdf.Ln(2)
+
+	// Totales
+	filaTotal(d, "Op. gravada S/", c.Subtotal, "")
+	filaTotal(d, "IGV 18% S/", c.IGV, "")
+	filaTotal(d, "Importe total S/", c.Total, "B")
+	d.pdf.Ln(3)
+
+	d.fuente("B", 10)
+	d.parrafo(190, 5, "SON: "+MontoEnLetras(c.Total), "L")
+	d.pdf.Ln(3)
+
+	if c.Estado == "ANULADO" {
+		d.pdf.SetTextColor(200, 0, 0)
+		d.fuente("B", 14)
+		d.celda(190, 8, "COMPROBANTE ANULADO", "", 1, "C")
+		d.pdf.SetTextColor(0, 0, 0)
+		d.pdf.Ln(2)
+	}
+
+	// Código QR y leyenda de representación impresa
+	y := d.pdf.GetY()
+	if err := d.qr("qr", sunat.ContenidoQR(emisor, c), 10, y, 30); err != nil {
+		return nil, err
+	}
+	d.pdf.SetXY(45, y+4)
+	d.fuente("", 8)
+	d.parrafo(155, 4, "Representación impresa de la "+titulo+".", "L")
+	if c.HashCPE != "" {
+		d.pdf.SetX(45)
+		d.parrafo(155, 4, "Valor resumen: "+c.HashCPE, "L")
+	}
+	d.pdf.SetX(45)
+	d.parrafo(155, 4, "Consulte la validez de este comprobante en www.sunat.gob.pe", "L")
+
+	return d.bytes()
+}
+
+// filaDato imprime una etiqueta y su valor en la cabecera del comprobante
+func filaDato(d *documento, etiqueta, valor string) {
+	d.fuente("B", 10)
+	d.celda(40, 6, etiqueta, "", 0, "L")
+	d.fuente("", 10)
+	d.celda(150, 6, valor, "", 1, "L")
+}
+
+// filaTotal imprime una línea del bloque de totales alineada a la derecha
+func filaTotal(d *documento, etiqueta string, valor float64, estilo string) {
+	d.fuente(estilo, 10)
+	d.celda(160, 6, etiqueta, "", 0, "R")
+	d.celda(30, 6, monto(valor), "", 1, "R")
+}
+
+func tituloComprobante(tipo string) string {
+	if tipo == "FACTURA" {
+		return "FACTURA ELECTRÓNICA"
+	}
+	return "BOLETA DE VENTA ELECTRÓNICA"
+}
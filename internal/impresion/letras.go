@@ -0,0 +1,99 @@
+package impresion
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var unidades = []string{
+	"", "UNO", "DOS", "TRES", "CUATRO", "CINCO", "SEIS", "SIETE", "OCHO", "NUEVE",
+	"DIEZ", "ONCE", "DOCE", "TRECE", "CATORCE", "QUINCE", "DIECISÉIS", "DIECISIETE", "DIECIOCHO", "DIECINUEVE",
+	"VEINTE", "VEINTIUNO", "VEINTIDÓS", "VEINTITRÉS", "VEINTICUATRO", "VEINTICINCO", "VEINTISÉIS", "VEINTISIETE", "VEINTIOCHO", "VEINTINUEVE",
+}
+
+var decenas = []string{"", "", "", "TREINTA", "CUARENTA", "CINCUENTA", "SESENTA", "SETENTA", "OCHENTA", "NOVENTA"}
+
+var centenas = []string{
+	"", "CIENTO", "DOSCIENTOS", "TRESCIENTOS", "CUATROCIENTOS", "QUINIENTOS",
+	"SEISCIENTOS", "SETECIENTOS", "OCHOCIENTOS", "NOVECIENTOS",
+}
+
+// MontoEnLetras expresa un importe en soles como se imprime en los comprobantes,
+// por ejemplo 120.50 -> "CIENTO VEINTE CON 50/100 SOLES"
+func MontoEnLetras(monto float64) string {
+	centimos := int64(math.Round(monto * 100))
+	if centimos < 0 {
+		centimos = -centimos
+	}
+	return fmt.Sprintf("%s CON %02d/100 SOLES", NumeroEnLetras(centimos/100), centimos%100)
+}
+
+// NumeroEnLetras expresa un número entero no negativo en palabras
+func NumeroEnLetras(n int64) string {
+	if n == 0 {
+		return "CERO"
+	}
+
+	var partes []string
+
+	if millones := n / 1000000; millones > 0 {
+		if millones == 1 {
+			partes = append(partes, "UN MILLÓN")
+		} else {
+			partes = append(partes, apocopar(NumeroEnLetras(millones))+" MILLONES")
+		}
+		n %= 1000000
+	}
+
+	if miles := n / 1000; miles > 0 {
+		if miles == 1 {
+			partes = append(partes, "MIL")
+		} else {
+			partes = append(partes, apocopar(centenasEnLetras(int(miles)))+" MIL")
+		}
+		n %= 1000
+	}
+
+	if n > 0 {
+		partes = append(partes, centenasEnLetras(int(n)))
+	}
+
+	return strings.Join(partes, " ")
+}
+
+// centenasEnLetras expresa un número entre 1 y 999
+func centenasEnLetras(n int) string {
+	if n == 100 {
+		return "CIEN"
+	}
+
+	var partes []string
+	if n >= 100 {
+		partes = append(partes, centenas[n/100])
+		n %= 100
+	}
+
+	switch {
+	case n == 0:
+	case n < 30:
+		partes = append(partes, unidades[n])
+	case n%10 == 0:
+		partes = append(partes, decenas[n/10])
+	default:
+		partes = append(partes, decenas[n/10]+" Y "+unidades[n%10])
+	}
+
+	return strings.Join(partes, " ")
+}
+
+// apocopar ajusta la terminación "UNO" delante de MIL o MILLONES (VEINTIUNO -> VEINTIÚN, UNO -> UN)
+func apocopar(s string) string {
+	switch {
+	case strings.HasSuffix(s, "VEINTIUNO"):
+		return strings.TrimSuffix(s, "VEINTIUNO") + "VEINTIÚN"
+	case strings.HasSuffix(s, "UNO"):
+		return strings.TrimSuffix(s, "UNO") + "UN"
+	}
+	return s
+}
@@ -0,0 +1,158 @@
+package impresion
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/sunat"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Manifiesto genera la lista de pasajeros (A4 horizontal) de un tour programado para la Capitanía de Puerto
+func Manifiesto(emisor sunat.Emisor, m *entidades.ManifiestoTour) ([]byte, error) {
+	d := nuevoDocumento(gofpdf.New("L", "mm", "A4", ""))
+	d.pdf.SetTitle(fmt.Sprintf("Manifiesto tour %d", m.Tour.ID), true)
+	d.pdf.AddPage()
+
+	// Cabecera
+	d.fuente("B", 13)
+	d.celda(277, 6, emisor.RazonSocial, "", 1, "L")
+	d.fuente("", 9)
+	d.celda(277, 5, "RUC "+emisor.RUC, "", 1, "L")
+	d.pdf.Ln(2)
+
+	d.fuente("B", 13)
+	d.celda(277, 7, "MANIFIESTO DE PASAJEROS", "", 1, "C")
+	d.pdf.Ln(2)
+
+	// Datos de la salida
+	filaManifiesto(d, "Tour:", m.Tour.NombreTipoTour, "Fecha de salida:", m.Tour.Fecha.Format("02/01/2006"))
+	filaManifiesto(d, "Embarcación:", m.Tour.NombreEmbarcacion, "Hora de salida:", m.Tour.HoraInicio)
+	filaManifiesto(d, "Capacidad:", fmt.Sprintf("%d pasajeros", m.Tour.CapacidadEmbarcacion), "Hora de retorno:", m.Tour.HoraFin)
+	filaManifiesto(d, "Chofer / patrón:", strings.TrimSpace(m.Tour.NombreChofer+" "+m.Tour.ApellidosChofer), "Generado:", m.GeneradoEn.Format("02/01/2006 15:04"))
+	d.pdf.Ln(4)
+
+	// Pasajeros
+	anchos := []float64{10, 70, 25, 30, 35, 30, 12, 45, 20}
+	titulos := []string{"N°", "APELLIDOS Y NOMBRES", "DOCUMENTO", "NÚMERO", "NACIONALIDAD", "F. NACIMIENTO", "EDAD", "PASAJE", "RESERVA"}
+	d.fuente("B", 9)
+	for i, titulo := range titulos {
+		d.celda(anchos[i], 7, titulo, "1", 0, "C")
+	}
+	d.pdf.Ln(-1)
+
+	d.fuente("", 9)
+	for i, p := range m.Pasajeros {
+		valores := []string{
+			fmt.Sprintf("%d", i+1),
+			nombrePasajero(p),
+			p.TipoDocumento,
+			p.NumeroDocumento,
+			p.Nacionalidad,
+			p.FechaNacimiento.Format("02/01/2006"),
+			fmt.Sprintf("%d", p.Edad),
+			p.NombreTipoPasaje,
+			fmt.Sprintf("%d", p.IDReserva),
+		}
+		for j, valor := range valores {
+			alineacion := "L"
+			if j == 0 || j == 6 || j == 8 {
+				alineacion = "C"
+			}
+			d.celda(anchos[j], 6, valor, "1", 0, alineacion)
+		}
+		d.pdf.Ln(-1)
+	}
+	d.pdf.Ln(4)
+
+	// Totales por tipo de pasaje
+	d.fuente("B", 9)
+	d.celda(70, 7, "TIPO DE PASAJE", "1", 0, "C")
+	d.celda(30, 7, "PASAJES", "1", 0, "C")
+	d.celda(30, 7, "REGISTRADOS", "1", 1, "C")
+	d.fuente("", 9)
+	for _, t := range m.TotalesPorTipo {
+		d.celda(70, 6, t.NombreTipo, "1", 0, "L")
+		d.celda(30, 6, fmt.Sprintf("%d", t.Pasajes), "1", 0, "C")
+		d.celda(30, 6, fmt.Sprintf("%d", t.Registrados), "1", 1, "C")
+	}
+	d.fuente("B", 9)
+	d.celda(70, 6, "TOTAL", "1", 0, "L")
+	d.celda(30, 6, fmt.Sprintf("%d", m.TotalPasajes), "1", 0, "C")
+	d.celda(30, 6, fmt.Sprintf("%d", m.TotalPasajeros), "1", 1, "C")
+
+	if m.TotalPasajeros < m.TotalPasajes {
+		d.pdf.Ln(2)
+		d.fuente("", 8)
+		d.parrafo(277, 4, fmt.Sprintf("Hay %d pasajes vendidos sin pasajero registrado.", m.TotalPasajes-m.TotalPasajeros), "L")
+	}
+
+	// Firma del responsable de la embarcación
+	d.pdf.Ln(18)
+	d.pdf.Line(10, d.pdf.GetY(), 90, d.pdf.GetY())
+	d.fuente("", 9)
+	d.celda(80, 5, "Firma del chofer / patrón", "", 1, "C")
+
+	return d.bytes()
+}
+
+// ManifiestoCSV genera el manifiesto de pasajeros en CSV: una fila por pasajero y al final los totales por tipo de pasaje
+func ManifiestoCSV(m *entidades.ManifiestoTour) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	registros := [][]string{
+		{"tour", m.Tour.NombreTipoTour},
+		{"fecha_salida", m.Tour.Fecha.Format("2006-01-02")},
+		{"hora_salida", m.Tour.HoraInicio},
+		{"embarcacion", m.Tour.NombreEmbarcacion},
+		{"capacidad", fmt.Sprintf("%d", m.Tour.CapacidadEmbarcacion)},
+		{"chofer", strings.TrimSpace(m.Tour.NombreChofer + " " + m.Tour.ApellidosChofer)},
+		{},
+		{"n", "apellidos", "nombres", "tipo_documento", "numero_documento", "nacionalidad", "fecha_nacimiento", "edad", "tipo_pasaje", "id_reserva"},
+	}
+	for i, p := range m.Pasajeros {
+		registros = append(registros, []string{
+			fmt.Sprintf("%d", i+1),
+			p.Apellidos,
+			p.Nombres,
+			p.TipoDocumento,
+			p.NumeroDocumento,
+			p.Nacionalidad,
+			p.FechaNacimiento.Format("2006-01-02"),
+			fmt.Sprintf("%d", p.Edad),
+			p.NombreTipoPasaje,
+			fmt.Sprintf("%d", p.IDReserva),
+		})
+	}
+
+	registros = append(registros, []string{}, []string{"tipo_pasaje", "pasajes", "registrados"})
+	for _, t := range m.TotalesPorTipo {
+		registros = append(registros, []string{t.NombreTipo, fmt.Sprintf("%d", t.Pasajes), fmt.Sprintf("%d", t.Registrados)})
+	}
+	registros = append(registros, []string{"TOTAL", fmt.Sprintf("%d", m.TotalPasajes), fmt.Sprintf("%d", m.TotalPasajeros)})
+
+	if err := w.WriteAll(registros); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// filaManifiesto imprime dos pares de etiqueta y valor en una misma línea de la cabecera
+func filaManifiesto(d *documento, etiqueta1, valor1, etiqueta2, valor2 string) {
+	d.fuente("B", 10)
+	d.celda(35, 6, etiqueta1, "", 0, "L")
+	d.fuente("", 10)
+	d.celda(110, 6, valor1, "", 0, "L")
+	d.fuente("B", 10)
+	d.celda(35, 6, etiqueta2, "", 0, "L")
+	d.fuente("", 10)
+	d.celda(97, 6, valor2, "", 1, "L")
+}
+
+func nombrePasajero(p *entidades.PasajeroManifiesto) string {
+	return strings.ToUpper(p.Apellidos) + ", " + p.Nombres
+}
@@ -0,0 +1,61 @@
+package impresion
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// documento envuelve gofpdf con las fuentes base y la conversión de texto UTF-8 a cp1252,
+// de modo que tildes y eñes se impriman sin incrustar fuentes externas
+type documento struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+}
+
+func nuevoDocumento(pdf *gofpdf.Fpdf) *documento {
+	pdf.SetAutoPageBreak(true, 10)
+	return &documento{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+}
+
+// fuente establece la fuente Helvetica con el estilo ("", "B") y tamaño indicados
+func (d *documento) fuente(estilo string, tamano float64) {
+	d.pdf.SetFont("Helvetica", estilo, tamano)
+}
+
+// celda escribe una celda de una línea
+func (d *documento) celda(ancho, alto float64, texto, borde string, saltoLinea int, alineacion string) {
+	d.pdf.CellFormat(ancho, alto, d.tr(texto), borde, saltoLinea, alineacion, false, 0, "")
+}
+
+// parrafo escribe un texto que puede ocupar varias líneas
+func (d *documento) parrafo(ancho, alto float64, texto, alineacion string) {
+	d.pdf.MultiCell(ancho, alto, d.tr(texto), "", alineacion, false)
+}
+
+// qr dibuja un código QR con el contenido indicado
+func (d *documento) qr(nombre, contenido string, x, y, lado float64) error {
+	png, err := qrcode.Encode(contenido, qrcode.Medium, 256)
+	if err != nil {
+		return fmt.Errorf("error al generar el código QR: %w", err)
+	}
+	opciones := gofpdf.ImageOptions{ImageType: "PNG"}
+	d.pdf.RegisterImageOptionsReader(nombre, opciones, bytes.NewReader(png))
+	d.pdf.ImageOptions(nombre, x, y, lado, lado, false, opciones, 0, "")
+	return nil
+}
+
+// bytes devuelve el PDF generado
+func (d *documento) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func monto(m float64) string {
+	return fmt.Sprintf("%.2f", m)
+}
@@ -0,0 +1,111 @@
+package impresion
+
+import (
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/sunat"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Ancho del papel de las impresoras térmicas usadas en el local
+const anchoTicket = 80.0
+
+// Lado del código QR de embarque impreso en el ticket
+const ladoQRTicket = 40.0
+
+// Ticket genera el ticket de embarque (80 mm) de una reserva con el QR que escanea el chofer al abordar
+func Ticket(emisor sunat.Emisor, reserva *entidades.Reserva, tour *entidades.TourProgramado, codigoEmbarque string) ([]byte, error) {
+	d := nuevoDocumento(gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: anchoTicket, Ht: altoTicket(reserva)},
+	}))
+	d.pdf.SetMargins(5, 5, 5)
+	d.pdf.SetTitle(fmt.Sprintf("Reserva %d", reserva.ID), true)
+	d.pdf.AddPage()
+
+	ancho := anchoTicket - 10
+
+	// Cabecera
+	d.fuente("B", 11)
+	d.parrafo(ancho, 5, emisor.RazonSocial, "C")
+	d.fuente("", 8)
+	d.celda(ancho, 4, "RUC "+emisor.RUC, "", 1, "C")
+	if emisor.Direccion != "" {
+		d.parrafo(ancho, 4, emisor.Direccion, "C")
+	}
+	separador(d, ancho)
+
+	d.fuente("B", 12)
+	d.celda(ancho, 6, "TICKET DE EMBARQUE", "", 1, "C")
+	d.fuente("B", 10)
+	d.celda(ancho, 5, fmt.Sprintf("Reserva N° %d", reserva.ID), "", 1, "C")
+	if reserva.Localizador != "" {
+		d.fuente("B", 14)
+		d.celda(ancho, 7, reserva.Localizador, "", 1, "C")
+	}
+	separador(d, ancho)
+
+	// Datos del tour
+	filaTicket(d, "Tour:", tour.NombreTipoTour)
+	filaTicket(d, "Fecha:", tour.Fecha.Format("02/01/2006"))
+	filaTicket(d, "Hora:", tour.HoraInicio)
+	filaTicket(d, "Embarcación:", tour.NombreEmbarcacion)
+	filaTicket(d, "Cliente:", reserva.NombreCliente)
+	separador(d, ancho)
+
+	// Pasajes
+	d.fuente("B", 9)
+	d.celda(ancho-15, 5, "PASAJE", "", 0, "L")
+	d.celda(15, 5, "CANT.", "", 1, "R")
+	d.fuente("", 9)
+	total := 0
+	for _, p := range reserva.CantidadPasajes {
+		d.celda(ancho-15, 5, p.NombreTipo, "", 0, "L")
+		d.celda(15, 5, fmt.Sprintf("%d", p.Cantidad), "", 1, "R")
+		total += p.Cantidad
+	}
+	d.fuente("B", 9)
+	d.celda(ancho-15, 5, "Total pasajeros", "", 0, "L")
+	d.celda(15, 5, fmt.Sprintf("%d", total), "", 1, "R")
+	separador(d, ancho)
+
+	filaTicket(d, "Total S/:", monto(reserva.TotalPagar))
+	d.pdf.Ln(2)
+
+	// Código QR de embarque
+	y := d.pdf.GetY()
+	if err := d.qr("qr-embarque", codigoEmbarque, (anchoTicket-ladoQRTicket)/2, y, ladoQRTicket); err != nil {
+		return nil, err
+	}
+	d.pdf.SetY(y + ladoQRTicket + 2)
+
+	d.fuente("", 7)
+	d.parrafo(ancho, 3.5, "Preséntese en el embarcadero 30 minutos antes de la hora de salida con su documento de identidad.", "C")
+
+	return d.bytes()
+}
+
+// altoTicket calcula el alto del papel según la cantidad de líneas de pasajes
+func altoTicket(reserva *entidades.Reserva) float64 {
+	return 142 + ladoQRTicket + 2 + float64(len(reserva.CantidadPasajes))*5
+}
+
+// filaTicket imprime una etiqueta y su valor en el ticket
+func filaTicket(d *documento, etiqueta, valor string) {
+	d.fuente("B", 9)
+	d.celda(24, 5, etiqueta, "", 0, "L")
+	d.fuente("", 9)
+	d.parrafo(anchoTicket-34, 5, valor, "L")
+}
+
+// separador dibuja una línea horizontal de lado a lado del ticket
+func separador(d *documento, ancho float64) {
+	d.pdf.Ln(1)
+	y := d.pdf.GetY()
+	d.pdf.SetDashPattern([]float64{1, 1}, 0)
+	d.pdf.Line(5, y, 5+ancho, y)
+	d.pdf.SetDashPattern([]float64{}, 0)
+	d.pdf.Ln(2)
+}
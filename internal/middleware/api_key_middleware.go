@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// claveAPIKey es la clave del contexto donde APIKeyMiddleware guarda la llave autenticada
+const claveAPIKey = "api_key"
+
+// claveReservaAPIKey es la clave del contexto donde se guarda la reserva creada con la llave
+const claveReservaAPIKey = "api_key_reserva"
+
+// codigoEnCurso es el código con el que queda registrada una petición reservada mientras se atiende
+const codigoEnCurso = 0
+
+// ClavesAPI autentica las llaves de API de las agencias, reserva sus peticiones dentro del límite y registra su uso
+type ClavesAPI interface {
+	Autenticar(clave string) (*entidades.APIKey, error)
+	ReservarPeticion(uso *entidades.UsoAPIKey, limite int) (bool, error)
+	RegistrarUso(uso *entidades.UsoAPIKey)
+}
+
+// GetAPIKey obtiene la llave de API autenticada (establecida por APIKeyMiddleware)
+func GetAPIKey(ctx *gin.Context) (*entidades.APIKey, bool) {
+	valor, exists := ctx.Get(claveAPIKey)
+	if !exists {
+		return nil, false
+	}
+	apiKey, ok := valor.(*entidades.APIKey)
+	return apiKey, ok
+}
+
+// AtribuirReserva asocia al registro de uso de la petición la reserva que se creó con ella
+func AtribuirReserva(ctx *gin.Context, idReserva int) {
+	if _, ok := GetAPIKey(ctx); ok {
+		ctx.Set(claveReservaAPIKey, idReserva)
+	}
+}
+
+// APIKeyMiddleware crea un middleware que autentica a las agencias con el encabezado X-API-Key,
+// aplica el límite de peticiones por minuto de la llave y registra cada petición
+func APIKeyMiddleware(claves ClavesAPI) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Obtener llave del encabezado
+		clave := ctx.GetHeader("X-API-Key")
+		if clave == "" {
+			ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Llave de API no proporcionada", nil))
+			ctx.Abort()
+			return
+		}
+
+		// Validar llave
+		apiKey, err := claves.Autenticar(clave)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Llave de API inválida", err))
+			ctx.Abort()
+			return
+		}
+		ctx.Set(claveAPIKey, apiKey)
+
+		// Reservar la petición antes de atenderla para que las simultáneas no superen el límite por minuto
+		uso := &entidades.UsoAPIKey{
+			IDAPIKey:     apiKey.ID,
+			Metodo:       ctx.Request.Method,
+			Ruta:         ctx.Request.URL.Path,
+			CodigoEstado: codigoEnCurso,
+			IP:           ctx.ClientIP(),
+		}
+		reservada, err := claves.ReservarPeticion(uso, apiKey.LimiteMinuto)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al verificar el límite de peticiones", err))
+			ctx.Abort()
+			return
+		}
+
+		// Registrar la petición con el código con el que se respondió
+		defer func() {
+			uso.CodigoEstado = ctx.Writer.Status()
+			if idReserva, ok := ctx.Get(claveReservaAPIKey); ok {
+				id := idReserva.(int)
+				uso.IDReserva = &id
+			}
+			claves.RegistrarUso(uso)
+		}()
+
+		if !reservada {
+			ctx.Header("Retry-After", "60")
+			ctx.JSON(http.StatusTooManyRequests, utils.ErrorResponse("Límite de peticiones por minuto excedido", nil))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireAlcance exige que la llave de API autenticada incluya el permiso indicado
+func RequireAlcance(alcance string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey, exists := GetAPIKey(ctx)
+		if !exists {
+			ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Llave de API no proporcionada", nil))
+			ctx.Abort()
+			return
+		}
+
+		if !apiKey.TieneAlcance(alcance) {
+			ctx.JSON(http.StatusForbidden, utils.ErrorResponse("La llave de API no tiene alcance para este recurso", nil))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
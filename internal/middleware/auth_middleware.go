@@ -5,12 +5,53 @@ import (
 	"sistema-tours/internal/config"
 	"sistema-tours/internal/utils"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware crea un middleware para autenticación JWT
-func AuthMiddleware(config *config.Config) gin.HandlerFunc {
+// clavePrincipal es la clave del contexto donde AuthMiddleware guarda el sujeto autenticado
+const clavePrincipal = "principal"
+
+// claveSoloAsignados es la clave del contexto donde SoloAsignados marca las rutas limitadas a los tours propios
+const claveSoloAsignados = "solo_asignados"
+
+// Principal representa al sujeto autenticado de la petición
+type Principal struct {
+	Tipo   string // utils.SujetoUsuario o utils.SujetoCliente
+	ID     int    // id_usuario o id_cliente según Tipo
+	Correo string
+	Rol    string
+
+	// Datos del token de acceso, necesarios para revocarlo al cerrar sesión
+	JTI        string
+	Expiracion time.Time
+}
+
+// EsCliente indica si el sujeto autenticado es un cliente
+func (p *Principal) EsCliente() bool {
+	return p.Tipo == utils.SujetoCliente
+}
+
+// GetPrincipal obtiene el sujeto autenticado (establecido por AuthMiddleware)
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	valor, exists := ctx.Get(clavePrincipal)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := valor.(*Principal)
+	return principal, ok
+}
+
+// TokensRevocados consulta si un token de acceso fue revocado antes de expirar, ya sea por su jti (logout)
+// o porque su sujeto fue revocado después de emitirlo (usuario desactivado o eliminado)
+type TokensRevocados interface {
+	Revocado(jti, tipoSujeto string, idSujeto int, emision time.Time) (bool, error)
+}
+
+// AuthMiddleware crea un middleware para autenticación JWT.
+// Si revocados no es nil, se rechazan los tokens revocados por su jti o por su sujeto.
+func AuthMiddleware(config *config.Config, revocados TokensRevocados) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Obtener token de autorización
 		authHeader := ctx.GetHeader("Authorization")
@@ -39,36 +80,93 @@ func AuthMiddleware(config *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Guardar claims en el contexto
-		ctx.Set("userID", claims.UserID)
-		ctx.Set("userEmail", claims.Email)
-		ctx.Set("userRole", claims.Role)
+		// Verificar que el token no haya sido revocado
+		if revocados != nil {
+			var emision time.Time
+			if claims.IssuedAt != nil {
+				emision = claims.IssuedAt.Time
+			}
+			revocado, err := revocados.Revocado(claims.ID, claims.TipoSujeto, claims.UserID, emision)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al verificar el token", err))
+				ctx.Abort()
+				return
+			}
+			if revocado {
+				ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Token revocado", nil))
+				ctx.Abort()
+				return
+			}
+		}
+
+		// Guardar sujeto autenticado en el contexto
+		principal := &Principal{
+			Tipo:   claims.TipoSujeto,
+			ID:     claims.UserID,
+			Correo: claims.Email,
+			Rol:    claims.Role,
+			JTI:    claims.ID,
+		}
+		if claims.ExpiresAt != nil {
+			principal.Expiracion = claims.ExpiresAt.Time
+		}
+		ctx.Set(clavePrincipal, principal)
 
 		ctx.Next()
 	}
 }
 
-// RoleMiddleware crea un middleware para restricción por rol
-func RoleMiddleware(roles ...string) gin.HandlerFunc {
+// SubjectMiddleware restringe el acceso a un tipo de sujeto (usuario del sistema o cliente)
+func SubjectMiddleware(tipo string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Obtener rol del contexto (establecido por AuthMiddleware)
-		userRole, exists := ctx.Get("userRole")
+		principal, exists := GetPrincipal(ctx)
 		if !exists {
 			ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
 			ctx.Abort()
 			return
 		}
 
-		// Verificar si tiene acceso
-		hasAccess := false
-		for _, role := range roles {
-			if userRole.(string) == role {
-				hasAccess = true
-				break
-			}
+		if principal.Tipo != tipo {
+			ctx.JSON(http.StatusForbidden, utils.ErrorResponse("No tiene permisos para acceder a este recurso", nil))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// Permisos resuelve si un rol incluye un permiso
+type Permisos interface {
+	TienePermiso(rol, permiso string) (bool, error)
+}
+
+// RequirePermission restringe el acceso a los usuarios del sistema cuyo rol incluye el permiso.
+// Los clientes no tienen roles de personal, así que siempre se rechazan.
+func RequirePermission(permisos Permisos, permiso string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Obtener sujeto del contexto (establecido por AuthMiddleware)
+		principal, exists := GetPrincipal(ctx)
+		if !exists {
+			ctx.JSON(http.StatusUnauthorized, utils.ErrorResponse("Usuario no autenticado", nil))
+			ctx.Abort()
+			return
+		}
+
+		if principal.Tipo != utils.SujetoUsuario {
+			ctx.JSON(http.StatusForbidden, utils.ErrorResponse("No tiene permisos para acceder a este recurso", nil))
+			ctx.Abort()
+			return
 		}
 
-		if !hasAccess {
+		// Verificar permiso del rol
+		tiene, err := permisos.TienePermiso(principal.Rol, permiso)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, utils.ErrorResponse("Error al verificar permisos", err))
+			ctx.Abort()
+			return
+		}
+		if !tiene {
 			ctx.JSON(http.StatusForbidden, utils.ErrorResponse("No tiene permisos para acceder a este recurso", nil))
 			ctx.Abort()
 			return
@@ -77,3 +175,17 @@ func RoleMiddleware(roles ...string) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// SoloAsignados marca la petición para que el sujeto solo opere sobre los tours que tiene asignados,
+// sea cual sea su rol. Se usa en las rutas /chofer/mis-...
+func SoloAsignados() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(claveSoloAsignados, true)
+		ctx.Next()
+	}
+}
+
+// EsSoloAsignados indica si la petición pasó por SoloAsignados
+func EsSoloAsignados(ctx *gin.Context) bool {
+	return ctx.GetBool(claveSoloAsignados)
+}
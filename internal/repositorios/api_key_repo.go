@@ -0,0 +1,196 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// APIKeyRepository maneja las operaciones de base de datos para las llaves de API de las agencias
+type APIKeyRepository struct {
+	db Querier
+}
+
+// NewAPIKeyRepository crea una nueva instancia del repositorio
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *APIKeyRepository) WithTx(tx *sql.Tx) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: tx,
+	}
+}
+
+// selectAPIKey es la consulta base de las llaves con el nombre del canal y su último uso
+const selectAPIKey = `SELECT k.id_api_key, k.nombre, k.prefijo, k.clave_hash, k.id_canal, k.limite_minuto,
+              k.fecha_expiracion, k.fecha_revocacion, k.id_usuario_creador, k.fecha_creacion,
+              cv.nombre, (SELECT MAX(u.fecha) FROM uso_api_key u WHERE u.id_api_key = k.id_api_key)
+              FROM api_key k
+              INNER JOIN canal_venta cv ON k.id_canal = cv.id_canal`
+
+// Create registra una llave con sus alcances
+func (r *APIKeyRepository) Create(apiKey *entidades.APIKey) (int, error) {
+	var id int
+	query := `INSERT INTO api_key (nombre, prefijo, clave_hash, id_canal, limite_minuto, fecha_expiracion, id_usuario_creador)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              RETURNING id_api_key`
+
+	err := r.db.QueryRow(
+		query,
+		apiKey.Nombre,
+		apiKey.Prefijo,
+		apiKey.ClaveHash,
+		apiKey.IDCanal,
+		apiKey.LimiteMinuto,
+		apiKey.FechaExpiracion,
+		apiKey.IDUsuarioCreador,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = `INSERT INTO api_key_alcance (id_api_key, permiso) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, alcance := range apiKey.Alcances {
+		if _, err := r.db.Exec(query, id, alcance); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+// GetByID obtiene una llave con sus alcances
+func (r *APIKeyRepository) GetByID(id int) (*entidades.APIKey, error) {
+	return r.get(selectAPIKey+` WHERE k.id_api_key = $1`, id)
+}
+
+// Bloquear bloquea la fila de una llave hasta el fin de la transacción, para contar y reservar sus peticiones sin carreras
+func (r *APIKeyRepository) Bloquear(id int) error {
+	var idAPIKey int
+	query := `SELECT id_api_key FROM api_key WHERE id_api_key = $1 FOR UPDATE`
+	return r.db.QueryRow(query, id).Scan(&idAPIKey)
+}
+
+// GetByHash obtiene una llave con sus alcances a partir del hash de su valor
+func (r *APIKeyRepository) GetByHash(claveHash string) (*entidades.APIKey, error) {
+	return r.get(selectAPIKey+` WHERE k.clave_hash = $1`, claveHash)
+}
+
+// List lista todas las llaves con sus alcances, las más recientes primero
+func (r *APIKeyRepository) List() ([]*entidades.APIKey, error) {
+	rows, err := r.db.Query(selectAPIKey + ` ORDER BY k.fecha_creacion DESC, k.id_api_key DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []*entidades.APIKey{}
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	alcances, err := r.listAlcances(`SELECT id_api_key, permiso FROM api_key_alcance ORDER BY id_api_key, permiso`)
+	if err != nil {
+		return nil, err
+	}
+	for _, apiKey := range apiKeys {
+		apiKey.Alcances = alcances[apiKey.ID]
+		if apiKey.Alcances == nil {
+			apiKey.Alcances = []string{}
+		}
+	}
+
+	return apiKeys, nil
+}
+
+// Revocar marca una llave como revocada; devuelve false si no existía o ya estaba revocada
+func (r *APIKeyRepository) Revocar(id int) (bool, error) {
+	query := `UPDATE api_key SET fecha_revocacion = CURRENT_TIMESTAMP
+              WHERE id_api_key = $1 AND fecha_revocacion IS NULL`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// get obtiene una sola llave con sus alcances
+func (r *APIKeyRepository) get(query string, args ...interface{}) (*entidades.APIKey, error) {
+	apiKey, err := scanAPIKey(r.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("llave de API no encontrada")
+		}
+		return nil, err
+	}
+
+	alcances, err := r.listAlcances(`SELECT id_api_key, permiso FROM api_key_alcance WHERE id_api_key = $1 ORDER BY permiso`, apiKey.ID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Alcances = alcances[apiKey.ID]
+	if apiKey.Alcances == nil {
+		apiKey.Alcances = []string{}
+	}
+
+	return apiKey, nil
+}
+
+// listAlcances ejecuta una consulta de (id_api_key, permiso) y agrupa los permisos por llave
+func (r *APIKeyRepository) listAlcances(query string, args ...interface{}) (map[int][]string, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alcances := map[int][]string{}
+	for rows.Next() {
+		var id int
+		var permiso string
+		if err := rows.Scan(&id, &permiso); err != nil {
+			return nil, err
+		}
+		alcances[id] = append(alcances[id], permiso)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alcances, nil
+}
+
+// scanAPIKey lee una fila de selectAPIKey
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*entidades.APIKey, error) {
+	apiKey := &entidades.APIKey{}
+	err := row.Scan(
+		&apiKey.ID, &apiKey.Nombre, &apiKey.Prefijo, &apiKey.ClaveHash, &apiKey.IDCanal, &apiKey.LimiteMinuto,
+		&apiKey.FechaExpiracion, &apiKey.FechaRevocacion, &apiKey.IDUsuarioCreador, &apiKey.FechaCreacion,
+		&apiKey.NombreCanal, &apiKey.FechaUltimoUso,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,85 @@
+package repositorios
+
+import (
+	"database/sql"
+	"sistema-tours/internal/entidades"
+)
+
+// AuditoriaLoginRepository maneja el registro de intentos de inicio de sesión
+type AuditoriaLoginRepository struct {
+	db Querier
+}
+
+// NewAuditoriaLoginRepository crea una nueva instancia del repositorio
+func NewAuditoriaLoginRepository(db *sql.DB) *AuditoriaLoginRepository {
+	return &AuditoriaLoginRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *AuditoriaLoginRepository) WithTx(tx *sql.Tx) *AuditoriaLoginRepository {
+	return &AuditoriaLoginRepository{
+		db: tx,
+	}
+}
+
+// Create registra un intento de inicio de sesión
+func (r *AuditoriaLoginRepository) Create(auditoria *entidades.AuditoriaLogin) (int, error) {
+	var id int
+	query := `INSERT INTO auditoria_login (tipo_sujeto, correo, id_sujeto, ip, exitoso, motivo)
+              VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+              RETURNING id_auditoria_login`
+
+	err := r.db.QueryRow(
+		query,
+		auditoria.TipoSujeto,
+		auditoria.Correo,
+		auditoria.IDSujeto,
+		auditoria.IP,
+		auditoria.Exitoso,
+		auditoria.Motivo,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ListByCorreo lista los intentos más recientes de un correo
+func (r *AuditoriaLoginRepository) ListByCorreo(correo string, limite int) ([]*entidades.AuditoriaLogin, error) {
+	query := `SELECT id_auditoria_login, tipo_sujeto, correo, id_sujeto, COALESCE(ip, ''),
+              exitoso, COALESCE(motivo, ''), fecha
+              FROM auditoria_login
+              WHERE correo = $1
+              ORDER BY fecha DESC
+              LIMIT $2`
+
+	rows, err := r.db.Query(query, correo, limite)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	auditorias := []*entidades.AuditoriaLogin{}
+
+	for rows.Next() {
+		auditoria := &entidades.AuditoriaLogin{}
+		err := rows.Scan(
+			&auditoria.ID, &auditoria.TipoSujeto, &auditoria.Correo, &auditoria.IDSujeto, &auditoria.IP,
+			&auditoria.Exitoso, &auditoria.Motivo, &auditoria.Fecha,
+		)
+		if err != nil {
+			return nil, err
+		}
+		auditorias = append(auditorias, auditoria)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return auditorias, nil
+}
@@ -8,7 +8,7 @@ import (
 
 // CanalVentaRepository maneja las operaciones de base de datos para canales de venta
 type CanalVentaRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewCanalVentaRepository crea una nueva instancia del repositorio
@@ -18,6 +18,13 @@ func NewCanalVentaRepository(db *sql.DB) *CanalVentaRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *CanalVentaRepository) WithTx(tx *sql.Tx) *CanalVentaRepository {
+	return &CanalVentaRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un canal de venta por su ID
 func (r *CanalVentaRepository) GetByID(id int) (*entidades.CanalVenta, error) {
 	canal := &entidades.CanalVenta{}
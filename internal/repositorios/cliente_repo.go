@@ -8,7 +8,7 @@ import (
 
 // ClienteRepository maneja las operaciones de base de datos para clientes
 type ClienteRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewClienteRepository crea una nueva instancia del repositorio
@@ -18,6 +18,13 @@ func NewClienteRepository(db *sql.DB) *ClienteRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *ClienteRepository) WithTx(tx *sql.Tx) *ClienteRepository {
+	return &ClienteRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un cliente por su ID
 func (r *ClienteRepository) GetByID(id int) (*entidades.Cliente, error) {
 	cliente := &entidades.Cliente{}
@@ -96,7 +103,7 @@ func (r *ClienteRepository) GetByCorreo(correo string) (*entidades.Cliente, erro
 // GetPasswordByCorreo obtiene la contraseña de un cliente por su correo
 func (r *ClienteRepository) GetPasswordByCorreo(correo string) (string, error) {
 	var contrasena string
-	query := `SELECT contrasena
+	query := `SELECT COALESCE(contrasena, '')
               FROM cliente
               WHERE correo = $1`
 
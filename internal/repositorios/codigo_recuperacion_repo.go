@@ -0,0 +1,55 @@
+package repositorios
+
+import (
+	"database/sql"
+)
+
+// CodigoRecuperacionRepository maneja las operaciones de base de datos para los códigos de recuperación del doble factor
+type CodigoRecuperacionRepository struct {
+	db Querier
+}
+
+// NewCodigoRecuperacionRepository crea una nueva instancia del repositorio
+func NewCodigoRecuperacionRepository(db *sql.DB) *CodigoRecuperacionRepository {
+	return &CodigoRecuperacionRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *CodigoRecuperacionRepository) WithTx(tx *sql.Tx) *CodigoRecuperacionRepository {
+	return &CodigoRecuperacionRepository{
+		db: tx,
+	}
+}
+
+// Create registra el hash de un código de recuperación
+func (r *CodigoRecuperacionRepository) Create(idUsuario int, codigoHash string) error {
+	query := `INSERT INTO codigo_recuperacion (id_usuario, codigo_hash) VALUES ($1, $2)`
+	_, err := r.db.Exec(query, idUsuario, codigoHash)
+	return err
+}
+
+// Usar marca como usado un código vigente del usuario; devuelve false si no existe o ya se usó
+func (r *CodigoRecuperacionRepository) Usar(idUsuario int, codigoHash string) (bool, error) {
+	query := `UPDATE codigo_recuperacion SET fecha_uso = CURRENT_TIMESTAMP
+              WHERE id_usuario = $1 AND codigo_hash = $2 AND fecha_uso IS NULL`
+	result, err := r.db.Exec(query, idUsuario, codigoHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteByUsuario elimina todos los códigos de recuperación de un usuario
+func (r *CodigoRecuperacionRepository) DeleteByUsuario(idUsuario int) error {
+	query := `DELETE FROM codigo_recuperacion WHERE id_usuario = $1`
+	_, err := r.db.Exec(query, idUsuario)
+	return err
+}
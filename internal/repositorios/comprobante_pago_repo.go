@@ -1,2 +1,485 @@
-// Operaciones CRUD para comprobantes
 package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"time"
+)
+
+// ComprobantePagoRepository maneja las operaciones de base de datos para comprobantes de pago
+type ComprobantePagoRepository struct {
+	db Querier
+}
+
+// NewComprobantePagoRepository crea una nueva instancia del repositorio
+func NewComprobantePagoRepository(db *sql.DB) *ComprobantePagoRepository {
+	return &ComprobantePagoRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *ComprobantePagoRepository) WithTx(tx *sql.Tx) *ComprobantePagoRepository {
+	return &ComprobantePagoRepository{
+		db: tx,
+	}
+}
+
+// GetByID obtiene un comprobante de pago por su ID
+func (r *ComprobantePagoRepository) GetByID(id int) (*entidades.ComprobantePago, error) {
+	comprobante := &entidades.ComprobantePago{}
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              WHERE cp.id_comprobante = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+		&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+		&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+		&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+		&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+		&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("comprobante no encontrado")
+		}
+		return nil, err
+	}
+
+	return comprobante, nil
+}
+
+// SiguienteCorrelativo reserva el siguiente correlativo de la serie activa de un tipo de comprobante.
+// La fila de la serie queda bloqueada hasta que la transacción termina, por lo que los correlativos
+// no se repiten ni dejan huecos: si la transacción se revierte, el incremento también se revierte.
+func (r *ComprobantePagoRepository) SiguienteCorrelativo(tipo string) (string, int, error) {
+	var serie string
+	var correlativo int
+	query := `UPDATE serie_comprobante
+              SET ultimo_correlativo = ultimo_correlativo + 1
+              WHERE serie = (
+                  SELECT serie FROM serie_comprobante
+                  WHERE tipo = $1 AND activo = true
+                  ORDER BY serie
+                  LIMIT 1
+              )
+              RETURNING serie, ultimo_correlativo`
+
+	err := r.db.QueryRow(query, tipo).Scan(&serie, &correlativo)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", 0, fmt.Errorf("no existe una serie activa para comprobantes de tipo %s", tipo)
+		}
+		return "", 0, err
+	}
+
+	return serie, correlativo, nil
+}
+
+// Create guarda un nuevo comprobante de pago
+func (r *ComprobantePagoRepository) Create(comprobante *entidades.ComprobantePago) (int, error) {
+	var id int
+	query := `INSERT INTO comprobante_pago (id_reserva, tipo, serie, correlativo, numero_comprobante,
+              subtotal, igv, total)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              RETURNING id_comprobante`
+
+	err := r.db.QueryRow(
+		query,
+		comprobante.IDReserva,
+		comprobante.Tipo,
+		comprobante.Serie,
+		comprobante.Correlativo,
+		comprobante.NumeroComprobante,
+		comprobante.Subtotal,
+		comprobante.IGV,
+		comprobante.Total,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CountEmitidosByReserva cuenta los comprobantes vigentes de una reserva.
+// No se cuentan los anulados ni los acreditados por completo con notas de crédito.
+func (r *ComprobantePagoRepository) CountEmitidosByReserva(idReserva int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comprobante_pago cp
+              WHERE cp.id_reserva = $1 AND cp.estado = 'EMITIDO'
+              AND cp.total > (
+                  SELECT COALESCE(SUM(nc.total), 0) FROM nota_credito nc
+                  WHERE nc.id_comprobante = cp.id_comprobante AND nc.estado = 'EMITIDA'
+              )`
+
+	err := r.db.QueryRow(query, idReserva).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Anular marca un comprobante como anulado registrando el motivo
+func (r *ComprobantePagoRepository) Anular(id int, motivo string) error {
+	query := `UPDATE comprobante_pago SET
+              estado = 'ANULADO',
+              motivo_anulacion = $1,
+              fecha_anulacion = CURRENT_TIMESTAMP
+              WHERE id_comprobante = $2`
+	_, err := r.db.Exec(query, motivo, id)
+	return err
+}
+
+// List lista todos los comprobantes de pago
+func (r *ComprobantePagoRepository) List() ([]*entidades.ComprobantePago, error) {
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              ORDER BY cp.fecha_emision DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comprobantes := []*entidades.ComprobantePago{}
+
+	for rows.Next() {
+		comprobante := &entidades.ComprobantePago{}
+		err := rows.Scan(
+			&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+			&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+			&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+			&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+			&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+			&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comprobantes = append(comprobantes, comprobante)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comprobantes, nil
+}
+
+// ListByReserva lista todos los comprobantes de una reserva específica
+func (r *ComprobantePagoRepository) ListByReserva(idReserva int) ([]*entidades.ComprobantePago, error) {
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              WHERE cp.id_reserva = $1
+              ORDER BY cp.fecha_emision DESC`
+
+	rows, err := r.db.Query(query, idReserva)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comprobantes := []*entidades.ComprobantePago{}
+
+	for rows.Next() {
+		comprobante := &entidades.ComprobantePago{}
+		err := rows.Scan(
+			&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+			&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+			&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+			&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+			&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+			&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comprobantes = append(comprobantes, comprobante)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comprobantes, nil
+}
+
+// ListByFecha lista todos los comprobantes emitidos en una fecha específica
+func (r *ComprobantePagoRepository) ListByFecha(fecha time.Time) ([]*entidades.ComprobantePago, error) {
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              WHERE DATE(cp.fecha_emision) = $1
+              ORDER BY cp.serie, cp.correlativo`
+
+	rows, err := r.db.Query(query, fecha)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comprobantes := []*entidades.ComprobantePago{}
+
+	for rows.Next() {
+		comprobante := &entidades.ComprobantePago{}
+		err := rows.Scan(
+			&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+			&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+			&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+			&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+			&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+			&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comprobantes = append(comprobantes, comprobante)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comprobantes, nil
+}
+
+// GuardarFirma guarda el XML firmado y el valor resumen de un comprobante
+func (r *ComprobantePagoRepository) GuardarFirma(id int, xmlFirmado, hash string) error {
+	query := `UPDATE comprobante_pago SET xml_firmado = $1, hash_cpe = $2 WHERE id_comprobante = $3`
+	_, err := r.db.Exec(query, xmlFirmado, hash, id)
+	return err
+}
+
+// GetXMLFirmado obtiene el XML firmado de un comprobante (vacío si aún no se ha firmado)
+func (r *ComprobantePagoRepository) GetXMLFirmado(id int) (string, error) {
+	var xmlFirmado string
+	query := `SELECT COALESCE(xml_firmado, '') FROM comprobante_pago WHERE id_comprobante = $1`
+
+	err := r.db.QueryRow(query, id).Scan(&xmlFirmado)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.New("comprobante no encontrado")
+		}
+		return "", err
+	}
+
+	return xmlFirmado, nil
+}
+
+// UpdateEstadoSunat registra la respuesta de SUNAT para un comprobante
+func (r *ComprobantePagoRepository) UpdateEstadoSunat(id int, estado, codigo, descripcion string) error {
+	query := `UPDATE comprobante_pago SET
+              estado_sunat = $1,
+              codigo_sunat = $2,
+              descripcion_sunat = $3
+              WHERE id_comprobante = $4`
+	_, err := r.db.Exec(query, estado, codigo, descripcion, id)
+	return err
+}
+
+// ListBoletasPendientesResumen lista las boletas de una fecha que aún no se han informado a SUNAT
+func (r *ComprobantePagoRepository) ListBoletasPendientesResumen(fecha time.Time) ([]*entidades.ComprobantePago, error) {
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              WHERE cp.tipo = 'BOLETA' AND DATE(cp.fecha_emision) = $1
+              AND cp.estado_sunat = 'PENDIENTE'
+              AND NOT EXISTS (
+                  SELECT 1 FROM resumen_sunat_detalle d
+                  INNER JOIN resumen_sunat rs ON d.id_resumen = rs.id_resumen
+                  WHERE d.id_comprobante = cp.id_comprobante AND d.condicion = 1
+                  AND rs.estado IN ('PENDIENTE', 'ENVIADO', 'ACEPTADO', 'OBSERVADO')
+              )
+              ORDER BY cp.serie, cp.correlativo`
+
+	rows, err := r.db.Query(query, fecha)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comprobantes := []*entidades.ComprobantePago{}
+
+	for rows.Next() {
+		comprobante := &entidades.ComprobantePago{}
+		err := rows.Scan(
+			&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+			&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+			&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+			&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+			&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+			&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comprobantes = append(comprobantes, comprobante)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comprobantes, nil
+}
+
+// ListBoletasAnuladasPendientesResumen lista las boletas anuladas de una fecha ya informadas a SUNAT
+// cuya anulación aún no se ha comunicado
+func (r *ComprobantePagoRepository) ListBoletasAnuladasPendientesResumen(fecha time.Time) ([]*entidades.ComprobantePago, error) {
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              WHERE cp.tipo = 'BOLETA' AND DATE(cp.fecha_emision) = $1
+              AND cp.estado = 'ANULADO' AND cp.estado_sunat IN ('ACEPTADO', 'OBSERVADO')
+              AND NOT EXISTS (
+                  SELECT 1 FROM resumen_sunat_detalle d
+                  INNER JOIN resumen_sunat rs ON d.id_resumen = rs.id_resumen
+                  WHERE d.id_comprobante = cp.id_comprobante AND d.condicion = 3
+                  AND rs.estado IN ('PENDIENTE', 'ENVIADO', 'ACEPTADO', 'OBSERVADO')
+              )
+              ORDER BY cp.serie, cp.correlativo`
+
+	rows, err := r.db.Query(query, fecha)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comprobantes := []*entidades.ComprobantePago{}
+
+	for rows.Next() {
+		comprobante := &entidades.ComprobantePago{}
+		err := rows.Scan(
+			&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+			&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+			&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+			&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+			&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+			&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comprobantes = append(comprobantes, comprobante)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comprobantes, nil
+}
+
+// ListFacturasPendientesBaja lista las facturas anuladas de una fecha aceptadas por SUNAT cuya baja
+// aún no se ha comunicado
+func (r *ComprobantePagoRepository) ListFacturasPendientesBaja(fecha time.Time) ([]*entidades.ComprobantePago, error) {
+	query := `SELECT cp.id_comprobante, cp.id_reserva, cp.tipo, cp.serie, cp.correlativo,
+              cp.numero_comprobante, cp.fecha_emision, cp.subtotal, cp.igv, cp.total, cp.estado,
+              COALESCE(cp.motivo_anulacion, ''), cp.fecha_anulacion,
+              cp.estado_sunat, COALESCE(cp.codigo_sunat, ''), COALESCE(cp.descripcion_sunat, ''), COALESCE(cp.hash_cpe, ''),
+              c.nombres, c.apellidos, c.tipo_documento, c.numero_documento,
+              tt.nombre, tp.fecha, r.localizador
+              FROM comprobante_pago cp
+              INNER JOIN reserva r ON cp.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
+              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
+              WHERE cp.tipo = 'FACTURA' AND DATE(cp.fecha_emision) = $1
+              AND cp.estado = 'ANULADO' AND cp.estado_sunat IN ('ACEPTADO', 'OBSERVADO')
+              AND NOT EXISTS (
+                  SELECT 1 FROM resumen_sunat_detalle d
+                  INNER JOIN resumen_sunat rs ON d.id_resumen = rs.id_resumen
+                  WHERE d.id_comprobante = cp.id_comprobante AND d.condicion = 3
+                  AND rs.estado IN ('PENDIENTE', 'ENVIADO', 'ACEPTADO', 'OBSERVADO')
+              )
+              ORDER BY cp.serie, cp.correlativo`
+
+	rows, err := r.db.Query(query, fecha)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comprobantes := []*entidades.ComprobantePago{}
+
+	for rows.Next() {
+		comprobante := &entidades.ComprobantePago{}
+		err := rows.Scan(
+			&comprobante.ID, &comprobante.IDReserva, &comprobante.Tipo, &comprobante.Serie, &comprobante.Correlativo,
+			&comprobante.NumeroComprobante, &comprobante.FechaEmision, &comprobante.Subtotal, &comprobante.IGV,
+			&comprobante.Total, &comprobante.Estado, &comprobante.MotivoAnulacion, &comprobante.FechaAnulacion,
+			&comprobante.EstadoSunat, &comprobante.CodigoSunat, &comprobante.DescripcionSunat, &comprobante.HashCPE,
+			&comprobante.NombreCliente, &comprobante.ApellidosCliente, &comprobante.TipoDocumentoCliente, &comprobante.DocumentoCliente,
+			&comprobante.NombreTour, &comprobante.FechaTour, &comprobante.LocalizadorReserva,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comprobantes = append(comprobantes, comprobante)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comprobantes, nil
+}
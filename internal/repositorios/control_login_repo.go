@@ -0,0 +1,85 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+	"time"
+)
+
+// ControlLoginRepository maneja los contadores de fallos de inicio de sesión por cuenta o IP
+type ControlLoginRepository struct {
+	db Querier
+}
+
+// NewControlLoginRepository crea una nueva instancia del repositorio
+func NewControlLoginRepository(db *sql.DB) *ControlLoginRepository {
+	return &ControlLoginRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *ControlLoginRepository) WithTx(tx *sql.Tx) *ControlLoginRepository {
+	return &ControlLoginRepository{
+		db: tx,
+	}
+}
+
+// GetByClave obtiene el control de una cuenta o IP
+func (r *ControlLoginRepository) GetByClave(clave string) (*entidades.ControlLogin, error) {
+	control := &entidades.ControlLogin{}
+	query := `SELECT clave, fallos, ultimo_fallo, bloqueado_hasta
+              FROM control_login
+              WHERE clave = $1`
+
+	err := r.db.QueryRow(query, clave).Scan(
+		&control.Clave, &control.Fallos, &control.UltimoFallo, &control.BloqueadoHasta,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("control de login no encontrado")
+		}
+		return nil, err
+	}
+
+	return control, nil
+}
+
+// RegistrarFallo suma un fallo a la clave y devuelve el total de fallos consecutivos.
+// Los fallos anteriores a reiniciarAntesDe se descartan y el conteo vuelve a empezar.
+func (r *ControlLoginRepository) RegistrarFallo(clave string, ahora, reiniciarAntesDe time.Time) (int, error) {
+	var fallos int
+	query := `INSERT INTO control_login (clave, fallos, ultimo_fallo)
+              VALUES ($1, 1, $2)
+              ON CONFLICT (clave) DO UPDATE SET
+                  fallos = CASE WHEN control_login.ultimo_fallo < $3 THEN 1 ELSE control_login.fallos + 1 END,
+                  ultimo_fallo = $2
+              RETURNING fallos`
+
+	err := r.db.QueryRow(query, clave, ahora, reiniciarAntesDe).Scan(&fallos)
+	if err != nil {
+		return 0, err
+	}
+
+	return fallos, nil
+}
+
+// Bloquear impide los inicios de sesión de la clave hasta el instante indicado
+func (r *ControlLoginRepository) Bloquear(clave string, hasta time.Time) error {
+	query := `UPDATE control_login SET bloqueado_hasta = $1 WHERE clave = $2`
+	_, err := r.db.Exec(query, hasta, clave)
+	return err
+}
+
+// Delete elimina el control de una clave (inicio de sesión exitoso o desbloqueo manual)
+func (r *ControlLoginRepository) Delete(clave string) (bool, error) {
+	query := `DELETE FROM control_login WHERE clave = $1`
+	result, err := r.db.Exec(query, clave)
+	if err != nil {
+		return false, err
+	}
+	filas, err := result.RowsAffected()
+	return filas > 0, err
+}
@@ -0,0 +1,117 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// DobleFactorRepository maneja las operaciones de base de datos para el doble factor de los usuarios
+type DobleFactorRepository struct {
+	db Querier
+}
+
+// NewDobleFactorRepository crea una nueva instancia del repositorio
+func NewDobleFactorRepository(db *sql.DB) *DobleFactorRepository {
+	return &DobleFactorRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *DobleFactorRepository) WithTx(tx *sql.Tx) *DobleFactorRepository {
+	return &DobleFactorRepository{
+		db: tx,
+	}
+}
+
+// ExisteActivo indica si el usuario tiene el doble factor activo
+func (r *DobleFactorRepository) ExisteActivo(idUsuario int) (bool, error) {
+	var existe bool
+	query := `SELECT EXISTS(SELECT 1 FROM doble_factor WHERE id_usuario = $1 AND activo)`
+	err := r.db.QueryRow(query, idUsuario).Scan(&existe)
+	return existe, err
+}
+
+// GetByUsuarioForUpdate obtiene el doble factor de un usuario bloqueando la fila hasta el fin de la transacción,
+// de modo que un mismo código no pueda aceptarse en dos verificaciones simultáneas
+func (r *DobleFactorRepository) GetByUsuarioForUpdate(idUsuario int) (*entidades.DobleFactor, error) {
+	dobleFactor := &entidades.DobleFactor{}
+	query := `SELECT id_usuario, secreto, activo, ultimo_paso, fecha_creacion, fecha_activacion
+              FROM doble_factor
+              WHERE id_usuario = $1
+              FOR UPDATE`
+
+	err := r.db.QueryRow(query, idUsuario).Scan(
+		&dobleFactor.IDUsuario,
+		&dobleFactor.Secreto,
+		&dobleFactor.Activo,
+		&dobleFactor.UltimoPaso,
+		&dobleFactor.FechaCreacion,
+		&dobleFactor.FechaActivacion,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("doble factor no encontrado")
+		}
+		return nil, err
+	}
+
+	return dobleFactor, nil
+}
+
+// GuardarPendiente registra un secreto nuevo sin activar, reemplazando un enrolamiento anterior no confirmado.
+// Devuelve false si el usuario ya tiene el doble factor activo.
+func (r *DobleFactorRepository) GuardarPendiente(idUsuario int, secreto string) (bool, error) {
+	query := `INSERT INTO doble_factor (id_usuario, secreto)
+              VALUES ($1, $2)
+              ON CONFLICT (id_usuario) DO UPDATE
+              SET secreto = EXCLUDED.secreto, ultimo_paso = 0,
+                  fecha_creacion = CURRENT_TIMESTAMP, fecha_activacion = NULL
+              WHERE doble_factor.activo = FALSE`
+
+	result, err := r.db.Exec(query, idUsuario, secreto)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Activar marca el doble factor como activo y registra el paso del código con el que se confirmó
+func (r *DobleFactorRepository) Activar(idUsuario int, paso int64) error {
+	query := `UPDATE doble_factor
+              SET activo = TRUE, ultimo_paso = $2, fecha_activacion = CURRENT_TIMESTAMP
+              WHERE id_usuario = $1`
+	_, err := r.db.Exec(query, idUsuario, paso)
+	return err
+}
+
+// ActualizarUltimoPaso registra el último paso aceptado para que su código no pueda reutilizarse
+func (r *DobleFactorRepository) ActualizarUltimoPaso(idUsuario int, paso int64) error {
+	query := `UPDATE doble_factor SET ultimo_paso = $2 WHERE id_usuario = $1`
+	_, err := r.db.Exec(query, idUsuario, paso)
+	return err
+}
+
+// Delete elimina el doble factor de un usuario; devuelve false si no tenía uno configurado
+func (r *DobleFactorRepository) Delete(idUsuario int) (bool, error) {
+	query := `DELETE FROM doble_factor WHERE id_usuario = $1`
+	result, err := r.db.Exec(query, idUsuario)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -8,7 +8,7 @@ import (
 
 // EmbarcacionRepository maneja las operaciones de base de datos para embarcaciones
 type EmbarcacionRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewEmbarcacionRepository crea una nueva instancia del repositorio
@@ -18,6 +18,13 @@ func NewEmbarcacionRepository(db *sql.DB) *EmbarcacionRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *EmbarcacionRepository) WithTx(tx *sql.Tx) *EmbarcacionRepository {
+	return &EmbarcacionRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene una embarcación por su ID
 func (r *EmbarcacionRepository) GetByID(id int) (*entidades.Embarcacion, error) {
 	embarcacion := &entidades.Embarcacion{}
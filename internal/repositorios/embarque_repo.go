@@ -0,0 +1,139 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// EmbarqueRepository maneja las operaciones de base de datos para embarques
+type EmbarqueRepository struct {
+	db Querier
+}
+
+// NewEmbarqueRepository crea una nueva instancia del repositorio
+func NewEmbarqueRepository(db *sql.DB) *EmbarqueRepository {
+	return &EmbarqueRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *EmbarqueRepository) WithTx(tx *sql.Tx) *EmbarqueRepository {
+	return &EmbarqueRepository{
+		db: tx,
+	}
+}
+
+// GetByReserva obtiene el embarque de una reserva
+func (r *EmbarqueRepository) GetByReserva(idReserva int) (*entidades.Embarque, error) {
+	embarque := &entidades.Embarque{}
+	query := `SELECT e.id_embarque, e.id_reserva, e.id_tour_programado, e.id_usuario,
+              e.cantidad_pasajeros, e.fecha_embarque, c.nombres || ' ' || c.apellidos
+              FROM embarque e
+              INNER JOIN reserva r ON e.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              WHERE e.id_reserva = $1`
+
+	err := r.db.QueryRow(query, idReserva).Scan(
+		&embarque.ID, &embarque.IDReserva, &embarque.IDTourProgramado, &embarque.IDUsuario,
+		&embarque.CantidadPasajeros, &embarque.FechaEmbarque, &embarque.NombreCliente,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("embarque no encontrado")
+		}
+		return nil, err
+	}
+
+	return embarque, nil
+}
+
+// Create registra el embarque de una reserva
+func (r *EmbarqueRepository) Create(embarque *entidades.Embarque) (int, error) {
+	var id int
+	query := `INSERT INTO embarque (id_reserva, id_tour_programado, id_usuario, cantidad_pasajeros)
+              VALUES ($1, $2, $3, $4)
+              RETURNING id_embarque`
+
+	err := r.db.QueryRow(
+		query,
+		embarque.IDReserva,
+		embarque.IDTourProgramado,
+		embarque.IDUsuario,
+		embarque.CantidadPasajeros,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ListByTourProgramado lista los embarques registrados de un tour programado
+func (r *EmbarqueRepository) ListByTourProgramado(idTourProgramado int) ([]*entidades.Embarque, error) {
+	query := `SELECT e.id_embarque, e.id_reserva, e.id_tour_programado, e.id_usuario,
+              e.cantidad_pasajeros, e.fecha_embarque, c.nombres || ' ' || c.apellidos
+              FROM embarque e
+              INNER JOIN reserva r ON e.id_reserva = r.id_reserva
+              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
+              WHERE e.id_tour_programado = $1
+              ORDER BY e.fecha_embarque`
+
+	rows, err := r.db.Query(query, idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	embarques := []*entidades.Embarque{}
+
+	for rows.Next() {
+		embarque := &entidades.Embarque{}
+		err := rows.Scan(
+			&embarque.ID, &embarque.IDReserva, &embarque.IDTourProgramado, &embarque.IDUsuario,
+			&embarque.CantidadPasajeros, &embarque.FechaEmbarque, &embarque.NombreCliente,
+		)
+		if err != nil {
+			return nil, err
+		}
+		embarques = append(embarques, embarque)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return embarques, nil
+}
+
+// GetContadorByTourProgramado cuenta las reservas y pasajeros embarcados frente a los esperados de un tour programado.
+// Se esperan solo las reservas que pueden llegar a embarcar: no las retenciones web sin pagar, las canceladas
+// ni las marcadas como no show. Se toma el estado de la reserva para incluir también los embarques registrados sin escanear el QR.
+func (r *EmbarqueRepository) GetContadorByTourProgramado(idTourProgramado int) (*entidades.ContadorEmbarque, error) {
+	contador := &entidades.ContadorEmbarque{IDTourProgramado: idTourProgramado}
+	query := `SELECT COUNT(*),
+              COUNT(*) FILTER (WHERE r.estado IN ('EMBARCADO', 'COMPLETADA')),
+              COALESCE(SUM(p.cantidad), 0),
+              COALESCE(SUM(p.cantidad) FILTER (WHERE r.estado IN ('EMBARCADO', 'COMPLETADA')), 0)
+              FROM reserva r
+              LEFT JOIN (
+                  SELECT id_reserva, SUM(cantidad) AS cantidad
+                  FROM pasajes_cantidad
+                  GROUP BY id_reserva
+              ) p ON p.id_reserva = r.id_reserva
+              WHERE r.id_tour_programado = $1
+              AND r.estado IN ('RESERVADO', 'CONFIRMADA', 'PAGADA', 'EMBARCADO', 'COMPLETADA')`
+
+	err := r.db.QueryRow(query, idTourProgramado).Scan(
+		&contador.ReservasTotales, &contador.ReservasEmbarcadas,
+		&contador.PasajerosReservados, &contador.PasajerosEmbarcados,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return contador, nil
+}
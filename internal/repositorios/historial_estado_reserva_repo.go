@@ -0,0 +1,91 @@
+package repositorios
+
+import (
+	"database/sql"
+	"sistema-tours/internal/entidades"
+)
+
+// HistorialEstadoReservaRepository maneja las operaciones de base de datos para el historial de estados de reserva
+type HistorialEstadoReservaRepository struct {
+	db Querier
+}
+
+// NewHistorialEstadoReservaRepository crea una nueva instancia del repositorio
+func NewHistorialEstadoReservaRepository(db *sql.DB) *HistorialEstadoReservaRepository {
+	return &HistorialEstadoReservaRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *HistorialEstadoReservaRepository) WithTx(tx *sql.Tx) *HistorialEstadoReservaRepository {
+	return &HistorialEstadoReservaRepository{
+		db: tx,
+	}
+}
+
+// Create registra un cambio de estado de una reserva
+func (r *HistorialEstadoReservaRepository) Create(historial *entidades.HistorialEstadoReserva) (int, error) {
+	var id int
+	query := `INSERT INTO historial_estado_reserva (id_reserva, estado_anterior, estado_nuevo, rol, id_usuario, id_cliente, id_api_key, motivo)
+              VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, NULLIF($8, ''))
+              RETURNING id_historial`
+
+	err := r.db.QueryRow(
+		query,
+		historial.IDReserva,
+		historial.EstadoAnterior,
+		historial.EstadoNuevo,
+		historial.Rol,
+		historial.IDUsuario,
+		historial.IDCliente,
+		historial.IDAPIKey,
+		historial.Motivo,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ListByReserva lista los cambios de estado de una reserva en orden cronológico
+func (r *HistorialEstadoReservaRepository) ListByReserva(idReserva int) ([]*entidades.HistorialEstadoReserva, error) {
+	query := `SELECT h.id_historial, h.id_reserva, COALESCE(h.estado_anterior, ''), h.estado_nuevo, h.rol,
+              h.id_usuario, h.id_cliente, h.id_api_key, COALESCE(h.motivo, ''), h.fecha_cambio,
+              COALESCE(u.nombres || ' ' || u.apellidos, c.nombres || ' ' || c.apellidos, k.nombre, '')
+              FROM historial_estado_reserva h
+              LEFT JOIN usuario u ON h.id_usuario = u.id_usuario
+              LEFT JOIN cliente c ON h.id_cliente = c.id_cliente
+              LEFT JOIN api_key k ON h.id_api_key = k.id_api_key
+              WHERE h.id_reserva = $1
+              ORDER BY h.fecha_cambio, h.id_historial`
+
+	rows, err := r.db.Query(query, idReserva)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	historial := []*entidades.HistorialEstadoReserva{}
+
+	for rows.Next() {
+		h := &entidades.HistorialEstadoReserva{}
+		err := rows.Scan(
+			&h.ID, &h.IDReserva, &h.EstadoAnterior, &h.EstadoNuevo, &h.Rol,
+			&h.IDUsuario, &h.IDCliente, &h.IDAPIKey, &h.Motivo, &h.FechaCambio,
+			&h.NombreUsuario,
+		)
+		if err != nil {
+			return nil, err
+		}
+		historial = append(historial, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return historial, nil
+}
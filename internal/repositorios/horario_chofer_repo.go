@@ -10,7 +10,7 @@ import (
 
 // HorarioChoferRepository maneja las operaciones de base de datos para horarios de chofer
 type HorarioChoferRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewHorarioChoferRepository crea una nueva instancia del repositorio
@@ -20,6 +20,13 @@ func NewHorarioChoferRepository(db *sql.DB) *HorarioChoferRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *HorarioChoferRepository) WithTx(tx *sql.Tx) *HorarioChoferRepository {
+	return &HorarioChoferRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un horario de chofer por su ID
 func (r *HorarioChoferRepository) GetByID(id int) (*entidades.HorarioChofer, error) {
 	horario := &entidades.HorarioChofer{}
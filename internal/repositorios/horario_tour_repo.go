@@ -9,7 +9,7 @@ import (
 
 // HorarioTourRepository maneja las operaciones de base de datos para horarios de tour
 type HorarioTourRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewHorarioTourRepository crea una nueva instancia del repositorio
@@ -19,6 +19,13 @@ func NewHorarioTourRepository(db *sql.DB) *HorarioTourRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *HorarioTourRepository) WithTx(tx *sql.Tx) *HorarioTourRepository {
+	return &HorarioTourRepository{
+		db: tx,
+	}
+}
+
 // parseTime convierte una cadena HH:MM a time.Time
 func parseTime(timeStr string) (time.Time, error) {
 	return time.Parse("15:04", timeStr)
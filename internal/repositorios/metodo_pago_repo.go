@@ -8,7 +8,7 @@ import (
 
 // MetodoPagoRepository maneja las operaciones de base de datos para métodos de pago
 type MetodoPagoRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewMetodoPagoRepository crea una nueva instancia del repositorio
@@ -18,6 +18,13 @@ func NewMetodoPagoRepository(db *sql.DB) *MetodoPagoRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *MetodoPagoRepository) WithTx(tx *sql.Tx) *MetodoPagoRepository {
+	return &MetodoPagoRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un método de pago por su ID
 func (r *MetodoPagoRepository) GetByID(id int) (*entidades.MetodoPago, error) {
 	metodoPago := &entidades.MetodoPago{}
@@ -0,0 +1,210 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// NotaCreditoRepository maneja las operaciones de base de datos para notas de crédito
+type NotaCreditoRepository struct {
+	db Querier
+}
+
+// NewNotaCreditoRepository crea una nueva instancia del repositorio
+func NewNotaCreditoRepository(db *sql.DB) *NotaCreditoRepository {
+	return &NotaCreditoRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *NotaCreditoRepository) WithTx(tx *sql.Tx) *NotaCreditoRepository {
+	return &NotaCreditoRepository{
+		db: tx,
+	}
+}
+
+// GetByID obtiene una nota de crédito por su ID
+func (r *NotaCreditoRepository) GetByID(id int) (*entidades.NotaCredito, error) {
+	nota := &entidades.NotaCredito{}
+	query := `SELECT nc.id_nota_credito, nc.id_comprobante, nc.id_pago, nc.serie, nc.correlativo, nc.numero_nota,
+              nc.fecha_emision, nc.codigo_motivo, nc.motivo, nc.subtotal, nc.igv, nc.total, nc.estado,
+              nc.estado_sunat, COALESCE(nc.codigo_sunat, ''), COALESCE(nc.descripcion_sunat, ''), COALESCE(nc.hash_cpe, ''),
+              cp.id_reserva, cp.tipo, cp.numero_comprobante
+              FROM nota_credito nc
+              INNER JOIN comprobante_pago cp ON nc.id_comprobante = cp.id_comprobante
+              WHERE nc.id_nota_credito = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&nota.ID, &nota.IDComprobante, &nota.IDPago, &nota.Serie, &nota.Correlativo, &nota.NumeroNota,
+		&nota.FechaEmision, &nota.CodigoMotivo, &nota.Motivo, &nota.Subtotal, &nota.IGV, &nota.Total, &nota.Estado,
+		&nota.EstadoSunat, &nota.CodigoSunat, &nota.DescripcionSunat, &nota.HashCPE,
+		&nota.IDReserva, &nota.TipoComprobante, &nota.NumeroComprobante,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("nota de crédito no encontrada")
+		}
+		return nil, err
+	}
+
+	return nota, nil
+}
+
+// Create guarda una nueva nota de crédito
+func (r *NotaCreditoRepository) Create(nota *entidades.NotaCredito) (int, error) {
+	var id int
+	query := `INSERT INTO nota_credito (id_comprobante, id_pago, serie, correlativo, numero_nota,
+              codigo_motivo, motivo, subtotal, igv, total)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+              RETURNING id_nota_credito`
+
+	err := r.db.QueryRow(
+		query,
+		nota.IDComprobante,
+		nota.IDPago,
+		nota.Serie,
+		nota.Correlativo,
+		nota.NumeroNota,
+		nota.CodigoMotivo,
+		nota.Motivo,
+		nota.Subtotal,
+		nota.IGV,
+		nota.Total,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetTotalByComprobante obtiene el total acreditado de un comprobante
+func (r *NotaCreditoRepository) GetTotalByComprobante(idComprobante int) (float64, error) {
+	var total float64
+	query := `SELECT COALESCE(SUM(total), 0) FROM nota_credito
+              WHERE id_comprobante = $1 AND estado = 'EMITIDA'`
+
+	err := r.db.QueryRow(query, idComprobante).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GuardarFirma guarda el XML firmado y el valor resumen de una nota de crédito
+func (r *NotaCreditoRepository) GuardarFirma(id int, xmlFirmado, hash string) error {
+	query := `UPDATE nota_credito SET xml_firmado = $1, hash_cpe = $2 WHERE id_nota_credito = $3`
+	_, err := r.db.Exec(query, xmlFirmado, hash, id)
+	return err
+}
+
+// GetXMLFirmado obtiene el XML firmado de una nota de crédito (vacío si aún no se ha firmado)
+func (r *NotaCreditoRepository) GetXMLFirmado(id int) (string, error) {
+	var xmlFirmado string
+	query := `SELECT COALESCE(xml_firmado, '') FROM nota_credito WHERE id_nota_credito = $1`
+
+	err := r.db.QueryRow(query, id).Scan(&xmlFirmado)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.New("nota de crédito no encontrada")
+		}
+		return "", err
+	}
+
+	return xmlFirmado, nil
+}
+
+// UpdateEstadoSunat registra la respuesta de SUNAT para una nota de crédito
+func (r *NotaCreditoRepository) UpdateEstadoSunat(id int, estado, codigo, descripcion string) error {
+	query := `UPDATE nota_credito SET
+              estado_sunat = $1,
+              codigo_sunat = $2,
+              descripcion_sunat = $3
+              WHERE id_nota_credito = $4`
+	_, err := r.db.Exec(query, estado, codigo, descripcion, id)
+	return err
+}
+
+// List lista todas las notas de crédito
+func (r *NotaCreditoRepository) List() ([]*entidades.NotaCredito, error) {
+	query := `SELECT nc.id_nota_credito, nc.id_comprobante, nc.id_pago, nc.serie, nc.correlativo, nc.numero_nota,
+              nc.fecha_emision, nc.codigo_motivo, nc.motivo, nc.subtotal, nc.igv, nc.total, nc.estado,
+              nc.estado_sunat, COALESCE(nc.codigo_sunat, ''), COALESCE(nc.descripcion_sunat, ''), COALESCE(nc.hash_cpe, ''),
+              cp.id_reserva, cp.tipo, cp.numero_comprobante
+              FROM nota_credito nc
+              INNER JOIN comprobante_pago cp ON nc.id_comprobante = cp.id_comprobante
+              ORDER BY nc.fecha_emision DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notas := []*entidades.NotaCredito{}
+
+	for rows.Next() {
+		nota := &entidades.NotaCredito{}
+		err := rows.Scan(
+			&nota.ID, &nota.IDComprobante, &nota.IDPago, &nota.Serie, &nota.Correlativo, &nota.NumeroNota,
+			&nota.FechaEmision, &nota.CodigoMotivo, &nota.Motivo, &nota.Subtotal, &nota.IGV, &nota.Total, &nota.Estado,
+			&nota.EstadoSunat, &nota.CodigoSunat, &nota.DescripcionSunat, &nota.HashCPE,
+			&nota.IDReserva, &nota.TipoComprobante, &nota.NumeroComprobante,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notas = append(notas, nota)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notas, nil
+}
+
+// ListByComprobante lista las notas de crédito de un comprobante
+func (r *NotaCreditoRepository) ListByComprobante(idComprobante int) ([]*entidades.NotaCredito, error) {
+	query := `SELECT nc.id_nota_credito, nc.id_comprobante, nc.id_pago, nc.serie, nc.correlativo, nc.numero_nota,
+              nc.fecha_emision, nc.codigo_motivo, nc.motivo, nc.subtotal, nc.igv, nc.total, nc.estado,
+              nc.estado_sunat, COALESCE(nc.codigo_sunat, ''), COALESCE(nc.descripcion_sunat, ''), COALESCE(nc.hash_cpe, ''),
+              cp.id_reserva, cp.tipo, cp.numero_comprobante
+              FROM nota_credito nc
+              INNER JOIN comprobante_pago cp ON nc.id_comprobante = cp.id_comprobante
+              WHERE nc.id_comprobante = $1
+              ORDER BY nc.fecha_emision`
+
+	rows, err := r.db.Query(query, idComprobante)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notas := []*entidades.NotaCredito{}
+
+	for rows.Next() {
+		nota := &entidades.NotaCredito{}
+		err := rows.Scan(
+			&nota.ID, &nota.IDComprobante, &nota.IDPago, &nota.Serie, &nota.Correlativo, &nota.NumeroNota,
+			&nota.FechaEmision, &nota.CodigoMotivo, &nota.Motivo, &nota.Subtotal, &nota.IGV, &nota.Total, &nota.Estado,
+			&nota.EstadoSunat, &nota.CodigoSunat, &nota.DescripcionSunat, &nota.HashCPE,
+			&nota.IDReserva, &nota.TipoComprobante, &nota.NumeroComprobante,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notas = append(notas, nota)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notas, nil
+}
@@ -9,7 +9,7 @@ import (
 
 // PagoRepository maneja las operaciones de base de datos para pagos
 type PagoRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewPagoRepository crea una nueva instancia del repositorio
@@ -19,11 +19,18 @@ func NewPagoRepository(db *sql.DB) *PagoRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *PagoRepository) WithTx(tx *sql.Tx) *PagoRepository {
+	return &PagoRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un pago por su ID
 func (r *PagoRepository) GetByID(id int) (*entidades.Pago, error) {
 	pago := &entidades.Pago{}
 	query := `SELECT p.id_pago, p.id_reserva, p.id_metodo_pago, p.id_canal, 
-              p.monto, p.fecha_pago, p.comprobante, p.estado,
+              p.monto, p.fecha_pago, p.comprobante, p.estado, p.tipo,
               r.id_reserva as numero_reserva, c.nombres, c.apellidos, c.numero_documento,
               mp.nombre as nombre_metodo_pago, cv.nombre as nombre_canal_venta
               FROM pago p
@@ -35,7 +42,7 @@ func (r *PagoRepository) GetByID(id int) (*entidades.Pago, error) {
 
 	err := r.db.QueryRow(query, id).Scan(
 		&pago.ID, &pago.IDReserva, &pago.IDMetodoPago, &pago.IDCanal,
-		&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado,
+		&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado, &pago.Tipo,
 		&pago.NumeroReserva, &pago.NombreCliente, &pago.ApellidosCliente, &pago.DocumentoCliente,
 		&pago.NombreMetodoPago, &pago.NombreCanalVenta,
 	)
@@ -52,34 +59,12 @@ func (r *PagoRepository) GetByID(id int) (*entidades.Pago, error) {
 
 // Create guarda un nuevo pago en la base de datos
 func (r *PagoRepository) Create(pago *entidades.NuevoPagoRequest) (int, error) {
-	// Verificar que la reserva exista y su estado sea válido
-	var estadoReserva string
-	queryReserva := `SELECT estado FROM reserva WHERE id_reserva = $1`
-	err := r.db.QueryRow(queryReserva, pago.IDReserva).Scan(&estadoReserva)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, errors.New("la reserva especificada no existe")
-		}
-		return 0, err
-	}
-
-	if estadoReserva != "RESERVADO" {
-		return 0, errors.New("solo se pueden registrar pagos para reservas en estado RESERVADO")
-	}
-
-	// Crear transacción para asegurar integridad
-	tx, err := r.db.Begin()
-	if err != nil {
-		return 0, err
-	}
-
-	// Crear pago
 	var id int
 	query := `INSERT INTO pago (id_reserva, id_metodo_pago, id_canal, monto, comprobante)
               VALUES ($1, $2, $3, $4, $5)
               RETURNING id_pago`
 
-	err = tx.QueryRow(
+	err := r.db.QueryRow(
 		query,
 		pago.IDReserva,
 		pago.IDMetodoPago,
@@ -89,12 +74,20 @@ func (r *PagoRepository) Create(pago *entidades.NuevoPagoRequest) (int, error) {
 	).Scan(&id)
 
 	if err != nil {
-		tx.Rollback()
 		return 0, err
 	}
 
-	// Confirmar transacción
-	err = tx.Commit()
+	return id, nil
+}
+
+// CreateDevolucion registra un pago de devolución
+func (r *PagoRepository) CreateDevolucion(idReserva, idMetodoPago, idCanal int, monto float64, comprobante string) (int, error) {
+	var id int
+	query := `INSERT INTO pago (id_reserva, id_metodo_pago, id_canal, monto, comprobante, tipo)
+              VALUES ($1, $2, $3, $4, $5, 'DEVOLUCION')
+              RETURNING id_pago`
+
+	err := r.db.QueryRow(query, idReserva, idMetodoPago, idCanal, monto, comprobante).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -157,7 +150,7 @@ func (r *PagoRepository) Delete(id int) error {
 // List lista todos los pagos
 func (r *PagoRepository) List() ([]*entidades.Pago, error) {
 	query := `SELECT p.id_pago, p.id_reserva, p.id_metodo_pago, p.id_canal, 
-              p.monto, p.fecha_pago, p.comprobante, p.estado,
+              p.monto, p.fecha_pago, p.comprobante, p.estado, p.tipo,
               r.id_reserva as numero_reserva, c.nombres, c.apellidos, c.numero_documento,
               mp.nombre as nombre_metodo_pago, cv.nombre as nombre_canal_venta
               FROM pago p
@@ -179,7 +172,7 @@ func (r *PagoRepository) List() ([]*entidades.Pago, error) {
 		pago := &entidades.Pago{}
 		err := rows.Scan(
 			&pago.ID, &pago.IDReserva, &pago.IDMetodoPago, &pago.IDCanal,
-			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado,
+			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado, &pago.Tipo,
 			&pago.NumeroReserva, &pago.NombreCliente, &pago.ApellidosCliente, &pago.DocumentoCliente,
 			&pago.NombreMetodoPago, &pago.NombreCanalVenta,
 		)
@@ -199,7 +192,7 @@ func (r *PagoRepository) List() ([]*entidades.Pago, error) {
 // ListByReserva lista todos los pagos de una reserva específica
 func (r *PagoRepository) ListByReserva(idReserva int) ([]*entidades.Pago, error) {
 	query := `SELECT p.id_pago, p.id_reserva, p.id_metodo_pago, p.id_canal, 
-              p.monto, p.fecha_pago, p.comprobante, p.estado,
+              p.monto, p.fecha_pago, p.comprobante, p.estado, p.tipo,
               r.id_reserva as numero_reserva, c.nombres, c.apellidos, c.numero_documento,
               mp.nombre as nombre_metodo_pago, cv.nombre as nombre_canal_venta
               FROM pago p
@@ -222,7 +215,7 @@ func (r *PagoRepository) ListByReserva(idReserva int) ([]*entidades.Pago, error)
 		pago := &entidades.Pago{}
 		err := rows.Scan(
 			&pago.ID, &pago.IDReserva, &pago.IDMetodoPago, &pago.IDCanal,
-			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado,
+			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado, &pago.Tipo,
 			&pago.NumeroReserva, &pago.NombreCliente, &pago.ApellidosCliente, &pago.DocumentoCliente,
 			&pago.NombreMetodoPago, &pago.NombreCanalVenta,
 		)
@@ -242,7 +235,7 @@ func (r *PagoRepository) ListByReserva(idReserva int) ([]*entidades.Pago, error)
 // ListByEstado lista todos los pagos con un estado específico
 func (r *PagoRepository) ListByEstado(estado string) ([]*entidades.Pago, error) {
 	query := `SELECT p.id_pago, p.id_reserva, p.id_metodo_pago, p.id_canal, 
-              p.monto, p.fecha_pago, p.comprobante, p.estado,
+              p.monto, p.fecha_pago, p.comprobante, p.estado, p.tipo,
               r.id_reserva as numero_reserva, c.nombres, c.apellidos, c.numero_documento,
               mp.nombre as nombre_metodo_pago, cv.nombre as nombre_canal_venta
               FROM pago p
@@ -265,7 +258,7 @@ func (r *PagoRepository) ListByEstado(estado string) ([]*entidades.Pago, error)
 		pago := &entidades.Pago{}
 		err := rows.Scan(
 			&pago.ID, &pago.IDReserva, &pago.IDMetodoPago, &pago.IDCanal,
-			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado,
+			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado, &pago.Tipo,
 			&pago.NumeroReserva, &pago.NombreCliente, &pago.ApellidosCliente, &pago.DocumentoCliente,
 			&pago.NombreMetodoPago, &pago.NombreCanalVenta,
 		)
@@ -285,7 +278,7 @@ func (r *PagoRepository) ListByEstado(estado string) ([]*entidades.Pago, error)
 // ListByFecha lista todos los pagos de una fecha específica
 func (r *PagoRepository) ListByFecha(fecha time.Time) ([]*entidades.Pago, error) {
 	query := `SELECT p.id_pago, p.id_reserva, p.id_metodo_pago, p.id_canal, 
-              p.monto, p.fecha_pago, p.comprobante, p.estado,
+              p.monto, p.fecha_pago, p.comprobante, p.estado, p.tipo,
               r.id_reserva as numero_reserva, c.nombres, c.apellidos, c.numero_documento,
               mp.nombre as nombre_metodo_pago, cv.nombre as nombre_canal_venta
               FROM pago p
@@ -308,7 +301,7 @@ func (r *PagoRepository) ListByFecha(fecha time.Time) ([]*entidades.Pago, error)
 		pago := &entidades.Pago{}
 		err := rows.Scan(
 			&pago.ID, &pago.IDReserva, &pago.IDMetodoPago, &pago.IDCanal,
-			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado,
+			&pago.Monto, &pago.FechaPago, &pago.Comprobante, &pago.Estado, &pago.Tipo,
 			&pago.NumeroReserva, &pago.NombreCliente, &pago.ApellidosCliente, &pago.DocumentoCliente,
 			&pago.NombreMetodoPago, &pago.NombreCanalVenta,
 		)
@@ -328,8 +321,8 @@ func (r *PagoRepository) ListByFecha(fecha time.Time) ([]*entidades.Pago, error)
 // GetTotalPagadoByReserva obtiene el total pagado de una reserva específica
 func (r *PagoRepository) GetTotalPagadoByReserva(idReserva int) (float64, error) {
 	var totalPagado float64
-	query := `SELECT COALESCE(SUM(monto), 0) 
-              FROM pago 
+	query := `SELECT COALESCE(SUM(CASE WHEN tipo = 'DEVOLUCION' THEN -monto ELSE monto END), 0)
+              FROM pago
               WHERE id_reserva = $1 AND estado = 'PROCESADO'`
 
 	err := r.db.QueryRow(query, idReserva).Scan(&totalPagado)
@@ -339,3 +332,28 @@ func (r *PagoRepository) GetTotalPagadoByReserva(idReserva int) (float64, error)
 
 	return totalPagado, nil
 }
+
+// GetUltimoCobroByReserva obtiene el último cobro procesado de una reserva
+func (r *PagoRepository) GetUltimoCobroByReserva(idReserva int) (*entidades.Pago, error) {
+	pago := &entidades.Pago{}
+	query := `SELECT id_pago, id_reserva, id_metodo_pago, id_canal, monto, fecha_pago,
+              COALESCE(comprobante, ''), estado, tipo
+              FROM pago
+              WHERE id_reserva = $1 AND tipo = 'COBRO' AND estado = 'PROCESADO'
+              ORDER BY fecha_pago DESC, id_pago DESC
+              LIMIT 1`
+
+	err := r.db.QueryRow(query, idReserva).Scan(
+		&pago.ID, &pago.IDReserva, &pago.IDMetodoPago, &pago.IDCanal, &pago.Monto, &pago.FechaPago,
+		&pago.Comprobante, &pago.Estado, &pago.Tipo,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("la reserva no tiene cobros procesados")
+		}
+		return nil, err
+	}
+
+	return pago, nil
+}
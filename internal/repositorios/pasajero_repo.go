@@ -0,0 +1,268 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// PasajeroRepository maneja las operaciones de base de datos para pasajeros
+type PasajeroRepository struct {
+	db Querier
+}
+
+// NewPasajeroRepository crea una nueva instancia del repositorio
+func NewPasajeroRepository(db *sql.DB) *PasajeroRepository {
+	return &PasajeroRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *PasajeroRepository) WithTx(tx *sql.Tx) *PasajeroRepository {
+	return &PasajeroRepository{
+		db: tx,
+	}
+}
+
+// GetByID obtiene un pasajero por su ID
+func (r *PasajeroRepository) GetByID(id int) (*entidades.Pasajero, error) {
+	pasajero := &entidades.Pasajero{}
+	query := `SELECT p.id_pasajero, p.id_reserva, p.id_tipo_pasaje, p.nombres, p.apellidos,
+              p.tipo_documento, p.numero_documento, p.nacionalidad, p.fecha_nacimiento,
+              tp.nombre
+              FROM pasajero p
+              INNER JOIN tipo_pasaje tp ON p.id_tipo_pasaje = tp.id_tipo_pasaje
+              WHERE p.id_pasajero = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&pasajero.ID, &pasajero.IDReserva, &pasajero.IDTipoPasaje, &pasajero.Nombres, &pasajero.Apellidos,
+		&pasajero.TipoDocumento, &pasajero.NumeroDocumento, &pasajero.Nacionalidad, &pasajero.FechaNacimiento,
+		&pasajero.NombreTipoPasaje,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("pasajero no encontrado")
+		}
+		return nil, err
+	}
+
+	return pasajero, nil
+}
+
+// ExisteDocumento verifica si la reserva ya tiene otro pasajero con el mismo documento
+func (r *PasajeroRepository) ExisteDocumento(idReserva int, tipoDocumento, numeroDocumento string, excluirID int) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM pasajero
+              WHERE id_reserva = $1 AND tipo_documento = $2 AND numero_documento = $3 AND id_pasajero != $4`
+
+	err := r.db.QueryRow(query, idReserva, tipoDocumento, numeroDocumento, excluirID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Create guarda un nuevo pasajero de una reserva
+func (r *PasajeroRepository) Create(idReserva int, pasajero *entidades.NuevoPasajeroRequest) (int, error) {
+	var id int
+	query := `INSERT INTO pasajero (id_reserva, id_tipo_pasaje, nombres, apellidos, tipo_documento,
+              numero_documento, nacionalidad, fecha_nacimiento)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              RETURNING id_pasajero`
+
+	err := r.db.QueryRow(
+		query,
+		idReserva,
+		pasajero.IDTipoPasaje,
+		pasajero.Nombres,
+		pasajero.Apellidos,
+		pasajero.TipoDocumento,
+		pasajero.NumeroDocumento,
+		pasajero.Nacionalidad,
+		pasajero.FechaNacimiento,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update actualiza la información de un pasajero
+func (r *PasajeroRepository) Update(id int, pasajero *entidades.ActualizarPasajeroRequest) error {
+	query := `UPDATE pasajero SET
+              id_tipo_pasaje = $1,
+              nombres = $2,
+              apellidos = $3,
+              tipo_documento = $4,
+              numero_documento = $5,
+              nacionalidad = $6,
+              fecha_nacimiento = $7
+              WHERE id_pasajero = $8`
+
+	_, err := r.db.Exec(
+		query,
+		pasajero.IDTipoPasaje,
+		pasajero.Nombres,
+		pasajero.Apellidos,
+		pasajero.TipoDocumento,
+		pasajero.NumeroDocumento,
+		pasajero.Nacionalidad,
+		pasajero.FechaNacimiento,
+		id,
+	)
+
+	return err
+}
+
+// Delete elimina un pasajero
+func (r *PasajeroRepository) Delete(id int) error {
+	query := `DELETE FROM pasajero WHERE id_pasajero = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// ListByReserva lista los pasajeros de una reserva
+func (r *PasajeroRepository) ListByReserva(idReserva int) ([]*entidades.Pasajero, error) {
+	query := `SELECT p.id_pasajero, p.id_reserva, p.id_tipo_pasaje, p.nombres, p.apellidos,
+              p.tipo_documento, p.numero_documento, p.nacionalidad, p.fecha_nacimiento,
+              tp.nombre
+              FROM pasajero p
+              INNER JOIN tipo_pasaje tp ON p.id_tipo_pasaje = tp.id_tipo_pasaje
+              WHERE p.id_reserva = $1
+              ORDER BY p.apellidos, p.nombres`
+
+	rows, err := r.db.Query(query, idReserva)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pasajeros := []*entidades.Pasajero{}
+
+	for rows.Next() {
+		pasajero := &entidades.Pasajero{}
+		err := rows.Scan(
+			&pasajero.ID, &pasajero.IDReserva, &pasajero.IDTipoPasaje, &pasajero.Nombres, &pasajero.Apellidos,
+			&pasajero.TipoDocumento, &pasajero.NumeroDocumento, &pasajero.Nacionalidad, &pasajero.FechaNacimiento,
+			&pasajero.NombreTipoPasaje,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pasajeros = append(pasajeros, pasajero)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pasajeros, nil
+}
+
+// ListManifiestoByTourProgramado lista los pasajeros de las reservas no canceladas de un tour programado
+func (r *PasajeroRepository) ListManifiestoByTourProgramado(idTourProgramado int) ([]*entidades.PasajeroManifiesto, error) {
+	query := `SELECT p.id_reserva, res.estado, p.nombres, p.apellidos, p.tipo_documento,
+              p.numero_documento, p.nacionalidad, p.fecha_nacimiento, tp.nombre
+              FROM pasajero p
+              INNER JOIN reserva res ON p.id_reserva = res.id_reserva
+              INNER JOIN tipo_pasaje tp ON p.id_tipo_pasaje = tp.id_tipo_pasaje
+              WHERE res.id_tour_programado = $1 AND res.estado != 'CANCELADA'
+              ORDER BY p.apellidos, p.nombres`
+
+	rows, err := r.db.Query(query, idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pasajeros := []*entidades.PasajeroManifiesto{}
+
+	for rows.Next() {
+		pasajero := &entidades.PasajeroManifiesto{}
+		err := rows.Scan(
+			&pasajero.IDReserva, &pasajero.EstadoReserva, &pasajero.Nombres, &pasajero.Apellidos, &pasajero.TipoDocumento,
+			&pasajero.NumeroDocumento, &pasajero.Nacionalidad, &pasajero.FechaNacimiento, &pasajero.NombreTipoPasaje,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pasajeros = append(pasajeros, pasajero)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pasajeros, nil
+}
+
+// ListTotalesManifiestoByTourProgramado resume por tipo de pasaje los pasajes vendidos y los pasajeros registrados
+// en las reservas no canceladas de un tour programado
+func (r *PasajeroRepository) ListTotalesManifiestoByTourProgramado(idTourProgramado int) ([]*entidades.TotalPasajeManifiesto, error) {
+	query := `SELECT tp.id_tipo_pasaje, tp.nombre, SUM(pc.cantidad),
+              (SELECT COUNT(*) FROM pasajero p
+               INNER JOIN reserva rp ON p.id_reserva = rp.id_reserva
+               WHERE rp.id_tour_programado = $1 AND rp.estado != 'CANCELADA'
+               AND p.id_tipo_pasaje = tp.id_tipo_pasaje)
+              FROM pasajes_cantidad pc
+              INNER JOIN reserva res ON pc.id_reserva = res.id_reserva
+              INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
+              WHERE res.id_tour_programado = $1 AND res.estado != 'CANCELADA'
+              GROUP BY tp.id_tipo_pasaje, tp.nombre
+              ORDER BY tp.nombre`
+
+	rows, err := r.db.Query(query, idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totales := []*entidades.TotalPasajeManifiesto{}
+
+	for rows.Next() {
+		total := &entidades.TotalPasajeManifiesto{}
+		if err := rows.Scan(&total.IDTipoPasaje, &total.NombreTipo, &total.Pasajes, &total.Registrados); err != nil {
+			return nil, err
+		}
+		totales = append(totales, total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totales, nil
+}
+
+// CountByReservaPorTipo cuenta los pasajeros registrados de una reserva por tipo de pasaje
+func (r *PasajeroRepository) CountByReservaPorTipo(idReserva int) (map[int]int, error) {
+	query := `SELECT id_tipo_pasaje, COUNT(*) FROM pasajero
+              WHERE id_reserva = $1
+              GROUP BY id_tipo_pasaje`
+
+	rows, err := r.db.Query(query, idReserva)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conteo := make(map[int]int)
+	for rows.Next() {
+		var idTipoPasaje, cantidad int
+		if err := rows.Scan(&idTipoPasaje, &cantidad); err != nil {
+			return nil, err
+		}
+		conteo[idTipoPasaje] = cantidad
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return conteo, nil
+}
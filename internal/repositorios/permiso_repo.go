@@ -0,0 +1,51 @@
+package repositorios
+
+import (
+	"database/sql"
+	"sistema-tours/internal/entidades"
+)
+
+// PermisoRepository maneja las operaciones de base de datos para el catálogo de permisos
+type PermisoRepository struct {
+	db Querier
+}
+
+// NewPermisoRepository crea una nueva instancia del repositorio
+func NewPermisoRepository(db *sql.DB) *PermisoRepository {
+	return &PermisoRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *PermisoRepository) WithTx(tx *sql.Tx) *PermisoRepository {
+	return &PermisoRepository{
+		db: tx,
+	}
+}
+
+// List lista todos los permisos
+func (r *PermisoRepository) List() ([]*entidades.Permiso, error) {
+	query := `SELECT codigo, descripcion FROM permiso ORDER BY codigo`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permisos := []*entidades.Permiso{}
+	for rows.Next() {
+		permiso := &entidades.Permiso{}
+		if err := rows.Scan(&permiso.Codigo, &permiso.Descripcion); err != nil {
+			return nil, err
+		}
+		permisos = append(permisos, permiso)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permisos, nil
+}
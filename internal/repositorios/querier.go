@@ -0,0 +1,17 @@
+package repositorios
+
+import "database/sql"
+
+// Querier es la interfaz común de *sql.DB y *sql.Tx que usan los repositorios para ejecutar consultas.
+// Permite que un mismo repositorio trabaje con la conexión o dentro de una transacción.
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Verificar en compilación que la conexión y la transacción implementan Querier
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+)
@@ -0,0 +1,108 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+	"time"
+)
+
+// RefreshTokenRepository maneja las operaciones de base de datos para refresh tokens
+type RefreshTokenRepository struct {
+	db Querier
+}
+
+// NewRefreshTokenRepository crea una nueva instancia del repositorio
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *RefreshTokenRepository) WithTx(tx *sql.Tx) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: tx,
+	}
+}
+
+// Create registra un refresh token emitido
+func (r *RefreshTokenRepository) Create(token *entidades.RefreshToken) (int, error) {
+	var id int
+	query := `INSERT INTO refresh_token (tipo_sujeto, id_sujeto, token_hash, familia, fecha_expiracion)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING id_refresh_token`
+
+	err := r.db.QueryRow(
+		query,
+		token.TipoSujeto,
+		token.IDSujeto,
+		token.TokenHash,
+		token.Familia,
+		token.FechaExpiracion,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetByHashForUpdate obtiene un refresh token por su hash bloqueando la fila hasta el fin de la transacción,
+// de modo que dos rotaciones simultáneas del mismo token no puedan tener éxito ambas
+func (r *RefreshTokenRepository) GetByHashForUpdate(tokenHash string) (*entidades.RefreshToken, error) {
+	token := &entidades.RefreshToken{}
+	query := `SELECT id_refresh_token, tipo_sujeto, id_sujeto, token_hash, familia,
+              fecha_emision, fecha_expiracion, fecha_uso, fecha_revocacion
+              FROM refresh_token
+              WHERE token_hash = $1
+              FOR UPDATE`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID, &token.TipoSujeto, &token.IDSujeto, &token.TokenHash, &token.Familia,
+		&token.FechaEmision, &token.FechaExpiracion, &token.FechaUso, &token.FechaRevocacion,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("refresh token no encontrado")
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarcarUsado registra que el refresh token se rotó por uno nuevo
+func (r *RefreshTokenRepository) MarcarUsado(id int) error {
+	query := `UPDATE refresh_token SET fecha_uso = CURRENT_TIMESTAMP WHERE id_refresh_token = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// RevocarFamilia revoca todos los tokens vigentes de una familia (una sesión)
+func (r *RefreshTokenRepository) RevocarFamilia(familia string) error {
+	query := `UPDATE refresh_token SET fecha_revocacion = CURRENT_TIMESTAMP
+              WHERE familia = $1 AND fecha_revocacion IS NULL`
+	_, err := r.db.Exec(query, familia)
+	return err
+}
+
+// RevocarBySujeto revoca todos los tokens vigentes de un usuario o cliente (todas sus sesiones)
+func (r *RefreshTokenRepository) RevocarBySujeto(tipoSujeto string, idSujeto int) error {
+	query := `UPDATE refresh_token SET fecha_revocacion = CURRENT_TIMESTAMP
+              WHERE tipo_sujeto = $1 AND id_sujeto = $2 AND fecha_revocacion IS NULL`
+	_, err := r.db.Exec(query, tipoSujeto, idSujeto)
+	return err
+}
+
+// DeleteExpirados elimina los tokens vencidos antes del instante indicado
+func (r *RefreshTokenRepository) DeleteExpirados(antesDe time.Time) (int64, error) {
+	query := `DELETE FROM refresh_token WHERE fecha_expiracion < $1`
+	result, err := r.db.Exec(query, antesDe)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -9,7 +9,7 @@ import (
 
 // ReservaRepository maneja las operaciones de base de datos para reservas
 type ReservaRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewReservaRepository crea una nueva instancia del repositorio
@@ -19,6 +19,13 @@ func NewReservaRepository(db *sql.DB) *ReservaRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *ReservaRepository) WithTx(tx *sql.Tx) *ReservaRepository {
+	return &ReservaRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene una reserva por su ID
 func (r *ReservaRepository) GetByID(id int) (*entidades.Reserva, error) {
 	// Inicializar objeto de reserva
@@ -26,7 +33,7 @@ func (r *ReservaRepository) GetByID(id int) (*entidades.Reserva, error) {
 
 	// Consulta para obtener datos de la reserva y entidades relacionadas
 	query := `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado, 
-              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado,
+              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado, r.expira_en, r.localizador,
               c.nombres || ' ' || c.apellidos as nombre_cliente,
               COALESCE(u.nombres || ' ' || u.apellidos, 'Web') as nombre_vendedor,
               tt.nombre as nombre_tour,
@@ -44,7 +51,7 @@ func (r *ReservaRepository) GetByID(id int) (*entidades.Reserva, error) {
 
 	err := r.db.QueryRow(query, id).Scan(
 		&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
-		&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado,
+		&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado, &reserva.ExpiraEn, &reserva.Localizador,
 		&reserva.NombreCliente, &reserva.NombreVendedor, &reserva.NombreTour,
 		&reserva.FechaTour, &reserva.HoraTour, &reserva.NombreCanal,
 	)
@@ -57,7 +64,7 @@ func (r *ReservaRepository) GetByID(id int) (*entidades.Reserva, error) {
 	}
 
 	// Obtener las cantidades de pasajes
-	queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad
+	queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad, pc.precio_unitario
                      FROM pasajes_cantidad pc
                      INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
                      WHERE pc.id_reserva = $1`
@@ -73,7 +80,7 @@ func (r *ReservaRepository) GetByID(id int) (*entidades.Reserva, error) {
 	for rowsPasajes.Next() {
 		var pasajeCantidad entidades.PasajeCantidad
 		err := rowsPasajes.Scan(
-			&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad,
+			&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad, &pasajeCantidad.PrecioUnitario,
 		)
 		if err != nil {
 			return nil, err
@@ -88,14 +95,67 @@ func (r *ReservaRepository) GetByID(id int) (*entidades.Reserva, error) {
 	return reserva, nil
 }
 
+// GetByLocalizador obtiene una reserva por su código localizador
+func (r *ReservaRepository) GetByLocalizador(localizador string) (*entidades.Reserva, error) {
+	var id int
+	query := `SELECT id_reserva FROM reserva WHERE localizador = $1`
+
+	err := r.db.QueryRow(query, localizador).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("reserva no encontrada")
+		}
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+// ExisteLocalizador verifica si ya hay una reserva con el código localizador
+func (r *ReservaRepository) ExisteLocalizador(localizador string) (bool, error) {
+	var existe bool
+	query := `SELECT EXISTS(SELECT 1 FROM reserva WHERE localizador = $1)`
+
+	err := r.db.QueryRow(query, localizador).Scan(&existe)
+	if err != nil {
+		return false, err
+	}
+
+	return existe, nil
+}
+
+// GetByIDForUpdate obtiene los datos básicos de una reserva bloqueando la fila hasta el fin de la transacción
+func (r *ReservaRepository) GetByIDForUpdate(id int) (*entidades.Reserva, error) {
+	reserva := &entidades.Reserva{}
+	query := `SELECT id_reserva, id_vendedor, id_cliente, id_tour_programado,
+              id_canal, fecha_reserva, total_pagar, notas, estado, expira_en, localizador
+              FROM reserva
+              WHERE id_reserva = $1
+              FOR UPDATE`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
+		&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado, &reserva.ExpiraEn, &reserva.Localizador,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("reserva no encontrada")
+		}
+		return nil, err
+	}
+
+	return reserva, nil
+}
+
 // Create guarda una nueva reserva en la base de datos
-func (r *ReservaRepository) Create(tx *sql.Tx, reserva *entidades.NuevaReservaRequest) (int, error) {
+func (r *ReservaRepository) Create(reserva *entidades.NuevaReservaRequest) (int, error) {
 	var id int
-	query := `INSERT INTO reserva (id_vendedor, id_cliente, id_tour_programado, id_canal, total_pagar, notas)
-              VALUES ($1, $2, $3, $4, $5, $6)
+	query := `INSERT INTO reserva (id_vendedor, id_cliente, id_tour_programado, id_canal, total_pagar, notas, estado, expira_en, localizador)
+              VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'RESERVADO'), $8, $9)
               RETURNING id_reserva`
 
-	err := tx.QueryRow(
+	err := r.db.QueryRow(
 		query,
 		reserva.IDVendedor,
 		reserva.IDCliente,
@@ -103,6 +163,9 @@ func (r *ReservaRepository) Create(tx *sql.Tx, reserva *entidades.NuevaReservaRe
 		reserva.IDCanal,
 		reserva.TotalPagar,
 		reserva.Notas,
+		reserva.Estado,
+		reserva.ExpiraEn,
+		reserva.Localizador,
 	).Scan(&id)
 
 	if err != nil {
@@ -111,10 +174,10 @@ func (r *ReservaRepository) Create(tx *sql.Tx, reserva *entidades.NuevaReservaRe
 
 	// Insertar las cantidades de pasajes
 	for _, pasaje := range reserva.CantidadPasajes {
-		queryPasaje := `INSERT INTO pasajes_cantidad (id_reserva, id_tipo_pasaje, cantidad)
-                       VALUES ($1, $2, $3)`
+		queryPasaje := `INSERT INTO pasajes_cantidad (id_reserva, id_tipo_pasaje, cantidad, precio_unitario)
+                       VALUES ($1, $2, $3, $4)`
 
-		_, err = tx.Exec(queryPasaje, id, pasaje.IDTipoPasaje, pasaje.Cantidad)
+		_, err = r.db.Exec(queryPasaje, id, pasaje.IDTipoPasaje, pasaje.Cantidad, pasaje.PrecioUnitario)
 		if err != nil {
 			return 0, err
 		}
@@ -124,7 +187,7 @@ func (r *ReservaRepository) Create(tx *sql.Tx, reserva *entidades.NuevaReservaRe
 }
 
 // Update actualiza la información de una reserva
-func (r *ReservaRepository) Update(tx *sql.Tx, id int, reserva *entidades.ActualizarReservaRequest) error {
+func (r *ReservaRepository) Update(id int, reserva *entidades.ActualizarReservaRequest) error {
 	// Actualizar la reserva
 	query := `UPDATE reserva SET
               id_vendedor = $1,
@@ -136,7 +199,7 @@ func (r *ReservaRepository) Update(tx *sql.Tx, id int, reserva *entidades.Actual
               estado = $7
               WHERE id_reserva = $8`
 
-	_, err := tx.Exec(
+	_, err := r.db.Exec(
 		query,
 		reserva.IDVendedor,
 		reserva.IDCliente,
@@ -154,17 +217,17 @@ func (r *ReservaRepository) Update(tx *sql.Tx, id int, reserva *entidades.Actual
 
 	// Eliminar pasajes_cantidad existentes
 	queryDeletePasajes := `DELETE FROM pasajes_cantidad WHERE id_reserva = $1`
-	_, err = tx.Exec(queryDeletePasajes, id)
+	_, err = r.db.Exec(queryDeletePasajes, id)
 	if err != nil {
 		return err
 	}
 
 	// Insertar nuevas cantidades de pasajes
 	for _, pasaje := range reserva.CantidadPasajes {
-		queryPasaje := `INSERT INTO pasajes_cantidad (id_reserva, id_tipo_pasaje, cantidad)
-                       VALUES ($1, $2, $3)`
+		queryPasaje := `INSERT INTO pasajes_cantidad (id_reserva, id_tipo_pasaje, cantidad, precio_unitario)
+                       VALUES ($1, $2, $3, $4)`
 
-		_, err = tx.Exec(queryPasaje, id, pasaje.IDTipoPasaje, pasaje.Cantidad)
+		_, err = r.db.Exec(queryPasaje, id, pasaje.IDTipoPasaje, pasaje.Cantidad, pasaje.PrecioUnitario)
 		if err != nil {
 			return err
 		}
@@ -173,32 +236,57 @@ func (r *ReservaRepository) Update(tx *sql.Tx, id int, reserva *entidades.Actual
 	return nil
 }
 
-// UpdateEstado actualiza solo el estado de una reserva
+// UpdateEstado actualiza solo el estado de una reserva.
+// Al salir de PENDIENTE_PAGO se quita el vencimiento de la retención.
 func (r *ReservaRepository) UpdateEstado(id int, estado string) error {
-	query := `UPDATE reserva SET estado = $1 WHERE id_reserva = $2`
+	query := `UPDATE reserva SET estado = $1,
+              expira_en = CASE WHEN $1 = 'PENDIENTE_PAGO' THEN expira_en ELSE NULL END
+              WHERE id_reserva = $2`
 	_, err := r.db.Exec(query, estado, id)
 	return err
 }
 
-// Delete elimina una reserva
-func (r *ReservaRepository) Delete(id int) error {
-	// Iniciar transacción
-	tx, err := r.db.Begin()
+// DescontarTotal reduce el total a pagar de una reserva en el monto acreditado con una nota de crédito
+func (r *ReservaRepository) DescontarTotal(id int, monto float64) error {
+	query := `UPDATE reserva SET total_pagar = total_pagar - $1 WHERE id_reserva = $2`
+	_, err := r.db.Exec(query, monto, id)
+	return err
+}
+
+// ListRetencionesVencidas lista los IDs de las reservas PENDIENTE_PAGO cuya retención ya venció
+func (r *ReservaRepository) ListRetencionesVencidas(ahora time.Time) ([]int, error) {
+	query := `SELECT id_reserva FROM reserva
+              WHERE estado = 'PENDIENTE_PAGO' AND expira_en <= $1
+              ORDER BY expira_en`
+
+	rows, err := r.db.Query(query, ahora)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	// Si hay error, hacer rollback
-	defer func() {
-		if err != nil {
-			tx.Rollback()
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
 		}
-	}()
+		ids = append(ids, id)
+	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Delete elimina una reserva
+func (r *ReservaRepository) Delete(id int) error {
 	// Verificar si hay pagos asociados a esta reserva
 	var countPagos int
 	queryCheckPagos := `SELECT COUNT(*) FROM pago WHERE id_reserva = $1`
-	err = tx.QueryRow(queryCheckPagos, id).Scan(&countPagos)
+	err := r.db.QueryRow(queryCheckPagos, id).Scan(&countPagos)
 	if err != nil {
 		return err
 	}
@@ -210,7 +298,7 @@ func (r *ReservaRepository) Delete(id int) error {
 	// Verificar si hay comprobantes asociados a esta reserva
 	var countComprobantes int
 	queryCheckComprobantes := `SELECT COUNT(*) FROM comprobante_pago WHERE id_reserva = $1`
-	err = tx.QueryRow(queryCheckComprobantes, id).Scan(&countComprobantes)
+	err = r.db.QueryRow(queryCheckComprobantes, id).Scan(&countComprobantes)
 	if err != nil {
 		return err
 	}
@@ -221,20 +309,15 @@ func (r *ReservaRepository) Delete(id int) error {
 
 	// Eliminar los registros de pasajes_cantidad
 	queryDeletePasajes := `DELETE FROM pasajes_cantidad WHERE id_reserva = $1`
-	_, err = tx.Exec(queryDeletePasajes, id)
+	_, err = r.db.Exec(queryDeletePasajes, id)
 	if err != nil {
 		return err
 	}
 
 	// Eliminar la reserva
 	queryDeleteReserva := `DELETE FROM reserva WHERE id_reserva = $1`
-	_, err = tx.Exec(queryDeleteReserva, id)
-	if err != nil {
-		return err
-	}
-
-	// Commit de la transacción
-	return tx.Commit()
+	_, err = r.db.Exec(queryDeleteReserva, id)
+	return err
 }
 
 // GetCantidadPasajerosByReserva obtiene la cantidad total de pasajeros en una reserva
@@ -252,95 +335,45 @@ func (r *ReservaRepository) GetCantidadPasajerosByReserva(id int) (int, error) {
 	return total, nil
 }
 
-// List lista todas las reservas
-func (r *ReservaRepository) List() ([]*entidades.Reserva, error) {
-	query := `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado, 
-              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado,
-              c.nombres || ' ' || c.apellidos as nombre_cliente,
-              COALESCE(u.nombres || ' ' || u.apellidos, 'Web') as nombre_vendedor,
-              tt.nombre as nombre_tour,
-              to_char(tp.fecha, 'DD/MM/YYYY') as fecha_tour,
-              to_char(ht.hora_inicio, 'HH24:MI') as hora_tour,
-              cv.nombre as nombre_canal
-              FROM reserva r
-              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
-              LEFT JOIN usuario u ON r.id_vendedor = u.id_usuario
-              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
-              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
-              INNER JOIN horario_tour ht ON tp.id_horario = ht.id_horario
-              INNER JOIN canal_venta cv ON r.id_canal = cv.id_canal
-              ORDER BY r.fecha_reserva DESC`
+// GetCantidadesPorTipo obtiene,, la cantidad de pasajes de una reserva por tipo de pasaje
+func (r *ReservaRepository) GetCantidadesPorTipo(id int) (map[int]int, error) {
+	query := `SELECT id_tipo_pasaje, cantidad
+              FROM pasajes_cantidad
+              WHERE id_reserva = $1`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	reservas := []*entidades.Reserva{}
-
+	cantidades := make(map[int]int)
 	for rows.Next() {
-		reserva := &entidades.Reserva{}
-		err := rows.Scan(
-			&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
-			&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado,
-			&reserva.NombreCliente, &reserva.NombreVendedor, &reserva.NombreTour,
-			&reserva.FechaTour, &reserva.HoraTour, &reserva.NombreCanal,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Obtener las cantidades de pasajes para cada reserva
-		queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad
-                         FROM pasajes_cantidad pc
-                         INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
-                         WHERE pc.id_reserva = $1`
-
-		rowsPasajes, err := r.db.Query(queryPasajes, reserva.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		reserva.CantidadPasajes = []entidades.PasajeCantidad{}
-
-		for rowsPasajes.Next() {
-			var pasajeCantidad entidades.PasajeCantidad
-			err := rowsPasajes.Scan(
-				&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad,
-			)
-			if err != nil {
-				rowsPasajes.Close()
-				return nil, err
-			}
-			reserva.CantidadPasajes = append(reserva.CantidadPasajes, pasajeCantidad)
-		}
-
-		rowsPasajes.Close()
-		if err = rowsPasajes.Err(); err != nil {
+		var idTipoPasaje, cantidad int
+		if err := rows.Scan(&idTipoPasaje, &cantidad); err != nil {
 			return nil, err
 		}
-
-		reservas = append(reservas, reserva)
+		cantidades[idTipoPasaje] += cantidad
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return reservas, nil
+	return cantidades, nil
 }
 
-// ListByCliente lista todas las reservas de un cliente
-func (r *ReservaRepository) ListByCliente(idCliente int) ([]*entidades.Reserva, error) {
-	query := `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado, 
-              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado,
+// selectReservaLista obtiene los datos de las reservas para los listados junto con el total pagado,
+// calculado en la misma consulta a partir de los pagos procesados (las devoluciones restan)
+const selectReservaLista = `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado,
+              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado, r.expira_en, r.localizador,
               c.nombres || ' ' || c.apellidos as nombre_cliente,
               COALESCE(u.nombres || ' ' || u.apellidos, 'Web') as nombre_vendedor,
               tt.nombre as nombre_tour,
               to_char(tp.fecha, 'DD/MM/YYYY') as fecha_tour,
               to_char(ht.hora_inicio, 'HH24:MI') as hora_tour,
-              cv.nombre as nombre_canal
+              cv.nombre as nombre_canal,
+              COALESCE(pg.monto_pagado, 0) as monto_pagado
               FROM reserva r
               INNER JOIN cliente c ON r.id_cliente = c.id_cliente
               LEFT JOIN usuario u ON r.id_vendedor = u.id_usuario
@@ -348,250 +381,58 @@ func (r *ReservaRepository) ListByCliente(idCliente int) ([]*entidades.Reserva,
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
               INNER JOIN horario_tour ht ON tp.id_horario = ht.id_horario
               INNER JOIN canal_venta cv ON r.id_canal = cv.id_canal
-              WHERE r.id_cliente = $1
-              ORDER BY r.fecha_reserva DESC`
-
-	rows, err := r.db.Query(query, idCliente)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	reservas := []*entidades.Reserva{}
-
-	for rows.Next() {
-		reserva := &entidades.Reserva{}
-		err := rows.Scan(
-			&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
-			&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado,
-			&reserva.NombreCliente, &reserva.NombreVendedor, &reserva.NombreTour,
-			&reserva.FechaTour, &reserva.HoraTour, &reserva.NombreCanal,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Obtener las cantidades de pasajes para cada reserva
-		queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad
-                         FROM pasajes_cantidad pc
-                         INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
-                         WHERE pc.id_reserva = $1`
-
-		rowsPasajes, err := r.db.Query(queryPasajes, reserva.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		reserva.CantidadPasajes = []entidades.PasajeCantidad{}
-
-		for rowsPasajes.Next() {
-			var pasajeCantidad entidades.PasajeCantidad
-			err := rowsPasajes.Scan(
-				&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad,
-			)
-			if err != nil {
-				rowsPasajes.Close()
-				return nil, err
-			}
-			reserva.CantidadPasajes = append(reserva.CantidadPasajes, pasajeCantidad)
-		}
-
-		rowsPasajes.Close()
-		if err = rowsPasajes.Err(); err != nil {
-			return nil, err
-		}
+              LEFT JOIN (
+                  SELECT id_reserva, SUM(CASE WHEN tipo = 'DEVOLUCION' THEN -monto ELSE monto END) as monto_pagado
+                  FROM pago
+                  WHERE estado = 'PROCESADO'
+                  GROUP BY id_reserva
+              ) pg ON pg.id_reserva = r.id_reserva`
+
+// condicionesEstadoPago filtra las reservas por estado de pago con la misma clasificación que
+// entidades.Reserva.AplicarPagos; los montos son DECIMAL(10,2), así que se comparan sin redondeo
+var condicionesEstadoPago = map[string]string{
+	"PAGADO":    `COALESCE(pg.monto_pagado, 0) >= r.total_pagar`,
+	"PARCIAL":   `COALESCE(pg.monto_pagado, 0) < r.total_pagar AND COALESCE(pg.monto_pagado, 0) > 0`,
+	"PENDIENTE": `COALESCE(pg.monto_pagado, 0) < r.total_pagar AND COALESCE(pg.monto_pagado, 0) <= 0`,
+}
 
-		reservas = append(reservas, reserva)
-	}
+// List lista todas las reservas
+func (r *ReservaRepository) List() ([]*entidades.Reserva, error) {
+	return r.listar(selectReservaLista + ` ORDER BY r.fecha_reserva DESC`)
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+// ListByEstadoPago lista todas las reservas según su estado de pago (PENDIENTE, PARCIAL, PAGADO)
+func (r *ReservaRepository) ListByEstadoPago(estadoPago string) ([]*entidades.Reserva, error) {
+	condicion, ok := condicionesEstadoPago[estadoPago]
+	if !ok {
+		return nil, errors.New("estado de pago inválido")
 	}
+	return r.listar(selectReservaLista + ` WHERE ` + condicion + ` ORDER BY r.fecha_reserva DESC`)
+}
 
-	return reservas, nil
+// ListByCliente lista todas las reservas de un cliente
+func (r *ReservaRepository) ListByCliente(idCliente int) ([]*entidades.Reserva, error) {
+	return r.listar(selectReservaLista+` WHERE r.id_cliente = $1 ORDER BY r.fecha_reserva DESC`, idCliente)
 }
 
 // ListByTourProgramado lista todas las reservas para un tour programado
 func (r *ReservaRepository) ListByTourProgramado(idTourProgramado int) ([]*entidades.Reserva, error) {
-	query := `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado, 
-              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado,
-              c.nombres || ' ' || c.apellidos as nombre_cliente,
-              COALESCE(u.nombres || ' ' || u.apellidos, 'Web') as nombre_vendedor,
-              tt.nombre as nombre_tour,
-              to_char(tp.fecha, 'DD/MM/YYYY') as fecha_tour,
-              to_char(ht.hora_inicio, 'HH24:MI') as hora_tour,
-              cv.nombre as nombre_canal
-              FROM reserva r
-              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
-              LEFT JOIN usuario u ON r.id_vendedor = u.id_usuario
-              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
-              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
-              INNER JOIN horario_tour ht ON tp.id_horario = ht.id_horario
-			                INNER JOIN canal_venta cv ON r.id_canal = cv.id_canal
-              WHERE r.id_tour_programado = $1
-              ORDER BY r.fecha_reserva DESC`
-
-	rows, err := r.db.Query(query, idTourProgramado)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	reservas := []*entidades.Reserva{}
-
-	for rows.Next() {
-		reserva := &entidades.Reserva{}
-		err := rows.Scan(
-			&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
-			&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado,
-			&reserva.NombreCliente, &reserva.NombreVendedor, &reserva.NombreTour,
-			&reserva.FechaTour, &reserva.HoraTour, &reserva.NombreCanal,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Obtener las cantidades de pasajes para cada reserva
-		queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad
-                         FROM pasajes_cantidad pc
-                         INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
-                         WHERE pc.id_reserva = $1`
-
-		rowsPasajes, err := r.db.Query(queryPasajes, reserva.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		reserva.CantidadPasajes = []entidades.PasajeCantidad{}
-
-		for rowsPasajes.Next() {
-			var pasajeCantidad entidades.PasajeCantidad
-			err := rowsPasajes.Scan(
-				&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad,
-			)
-			if err != nil {
-				rowsPasajes.Close()
-				return nil, err
-			}
-			reserva.CantidadPasajes = append(reserva.CantidadPasajes, pasajeCantidad)
-		}
-
-		rowsPasajes.Close()
-		if err = rowsPasajes.Err(); err != nil {
-			return nil, err
-		}
-
-		reservas = append(reservas, reserva)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return reservas, nil
+	return r.listar(selectReservaLista+` WHERE r.id_tour_programado = $1 ORDER BY r.fecha_reserva DESC`, idTourProgramado)
 }
 
 // ListByFecha lista todas las reservas para una fecha específica
 func (r *ReservaRepository) ListByFecha(fecha time.Time) ([]*entidades.Reserva, error) {
-	query := `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado, 
-              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado,
-              c.nombres || ' ' || c.apellidos as nombre_cliente,
-              COALESCE(u.nombres || ' ' || u.apellidos, 'Web') as nombre_vendedor,
-              tt.nombre as nombre_tour,
-              to_char(tp.fecha, 'DD/MM/YYYY') as fecha_tour,
-              to_char(ht.hora_inicio, 'HH24:MI') as hora_tour,
-              cv.nombre as nombre_canal
-              FROM reserva r
-              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
-              LEFT JOIN usuario u ON r.id_vendedor = u.id_usuario
-              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
-              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
-              INNER JOIN horario_tour ht ON tp.id_horario = ht.id_horario
-              INNER JOIN canal_venta cv ON r.id_canal = cv.id_canal
-              WHERE tp.fecha = $1
-              ORDER BY ht.hora_inicio ASC, r.fecha_reserva DESC`
-
-	rows, err := r.db.Query(query, fecha)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	reservas := []*entidades.Reserva{}
-
-	for rows.Next() {
-		reserva := &entidades.Reserva{}
-		err := rows.Scan(
-			&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
-			&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado,
-			&reserva.NombreCliente, &reserva.NombreVendedor, &reserva.NombreTour,
-			&reserva.FechaTour, &reserva.HoraTour, &reserva.NombreCanal,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Obtener las cantidades de pasajes para cada reserva
-		queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad
-                         FROM pasajes_cantidad pc
-                         INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
-                         WHERE pc.id_reserva = $1`
-
-		rowsPasajes, err := r.db.Query(queryPasajes, reserva.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		reserva.CantidadPasajes = []entidades.PasajeCantidad{}
-
-		for rowsPasajes.Next() {
-			var pasajeCantidad entidades.PasajeCantidad
-			err := rowsPasajes.Scan(
-				&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad,
-			)
-			if err != nil {
-				rowsPasajes.Close()
-				return nil, err
-			}
-			reserva.CantidadPasajes = append(reserva.CantidadPasajes, pasajeCantidad)
-		}
-
-		rowsPasajes.Close()
-		if err = rowsPasajes.Err(); err != nil {
-			return nil, err
-		}
-
-		reservas = append(reservas, reserva)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return reservas, nil
+	return r.listar(selectReservaLista+` WHERE tp.fecha = $1 ORDER BY ht.hora_inicio ASC, r.fecha_reserva DESC`, fecha)
 }
 
 // ListByEstado lista todas las reservas por estado
 func (r *ReservaRepository) ListByEstado(estado string) ([]*entidades.Reserva, error) {
-	query := `SELECT r.id_reserva, r.id_vendedor, r.id_cliente, r.id_tour_programado, 
-              r.id_canal, r.fecha_reserva, r.total_pagar, r.notas, r.estado,
-              c.nombres || ' ' || c.apellidos as nombre_cliente,
-              COALESCE(u.nombres || ' ' || u.apellidos, 'Web') as nombre_vendedor,
-              tt.nombre as nombre_tour,
-              to_char(tp.fecha, 'DD/MM/YYYY') as fecha_tour,
-              to_char(ht.hora_inicio, 'HH24:MI') as hora_tour,
-              cv.nombre as nombre_canal
-              FROM reserva r
-              INNER JOIN cliente c ON r.id_cliente = c.id_cliente
-              LEFT JOIN usuario u ON r.id_vendedor = u.id_usuario
-              INNER JOIN tour_programado tp ON r.id_tour_programado = tp.id_tour_programado
-              INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
-              INNER JOIN horario_tour ht ON tp.id_horario = ht.id_horario
-              INNER JOIN canal_venta cv ON r.id_canal = cv.id_canal
-              WHERE r.estado = $1
-              ORDER BY r.fecha_reserva DESC`
+	return r.listar(selectReservaLista+` WHERE r.estado = $1 ORDER BY r.fecha_reserva DESC`, estado)
+}
 
-	rows, err := r.db.Query(query, estado)
+// listar ejecuta una consulta de listado basada en selectReservaLista y completa los pasajes de cada reserva
+func (r *ReservaRepository) listar(query string, args ...interface{}) ([]*entidades.Reserva, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -601,18 +442,21 @@ func (r *ReservaRepository) ListByEstado(estado string) ([]*entidades.Reserva, e
 
 	for rows.Next() {
 		reserva := &entidades.Reserva{}
+		var montoPagado float64
 		err := rows.Scan(
 			&reserva.ID, &reserva.IDVendedor, &reserva.IDCliente, &reserva.IDTourProgramado,
-			&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado,
+			&reserva.IDCanal, &reserva.FechaReserva, &reserva.TotalPagar, &reserva.Notas, &reserva.Estado, &reserva.ExpiraEn, &reserva.Localizador,
 			&reserva.NombreCliente, &reserva.NombreVendedor, &reserva.NombreTour,
 			&reserva.FechaTour, &reserva.HoraTour, &reserva.NombreCanal,
+			&montoPagado,
 		)
 		if err != nil {
 			return nil, err
 		}
+		reserva.AplicarPagos(montoPagado)
 
 		// Obtener las cantidades de pasajes para cada reserva
-		queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad
+		queryPasajes := `SELECT pc.id_tipo_pasaje, tp.nombre, pc.cantidad, pc.precio_unitario
                          FROM pasajes_cantidad pc
                          INNER JOIN tipo_pasaje tp ON pc.id_tipo_pasaje = tp.id_tipo_pasaje
                          WHERE pc.id_reserva = $1`
@@ -627,7 +471,7 @@ func (r *ReservaRepository) ListByEstado(estado string) ([]*entidades.Reserva, e
 		for rowsPasajes.Next() {
 			var pasajeCantidad entidades.PasajeCantidad
 			err := rowsPasajes.Scan(
-				&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad,
+				&pasajeCantidad.IDTipoPasaje, &pasajeCantidad.NombreTipo, &pasajeCantidad.Cantidad, &pasajeCantidad.PrecioUnitario,
 			)
 			if err != nil {
 				rowsPasajes.Close()
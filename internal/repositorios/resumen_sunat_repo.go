@@ -0,0 +1,190 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+	"time"
+)
+
+// ResumenSunatRepository maneja las operaciones de base de datos para resúmenes y comunicaciones de baja
+type ResumenSunatRepository struct {
+	db Querier
+}
+
+// NewResumenSunatRepository crea una nueva instancia del repositorio
+func NewResumenSunatRepository(db *sql.DB) *ResumenSunatRepository {
+	return &ResumenSunatRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *ResumenSunatRepository) WithTx(tx *sql.Tx) *ResumenSunatRepository {
+	return &ResumenSunatRepository{
+		db: tx,
+	}
+}
+
+// SiguienteNumero obtiene el siguiente correlativo del día para un tipo de resumen.
+// La tabla se bloquea hasta el fin de la transacción para que dos resúmenes no reciban el mismo número.
+func (r *ResumenSunatRepository) SiguienteNumero(tipo string, fecha time.Time) (int, error) {
+	_, err := r.db.Exec(`LOCK TABLE resumen_sunat IN SHARE ROW EXCLUSIVE MODE`)
+	if err != nil {
+		return 0, err
+	}
+
+	var numero int
+	query := `SELECT COALESCE(MAX(numero), 0) + 1 FROM resumen_sunat
+              WHERE tipo = $1 AND DATE(fecha_generacion) = $2`
+
+	err = r.db.QueryRow(query, tipo, fecha.Format("2006-01-02")).Scan(&numero)
+	if err != nil {
+		return 0, err
+	}
+
+	return numero, nil
+}
+
+// Create guarda un nuevo resumen
+func (r *ResumenSunatRepository) Create(resumen *entidades.ResumenSunat) (int, error) {
+	var id int
+	query := `INSERT INTO resumen_sunat (tipo, identificador, numero, fecha_referencia, fecha_generacion)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING id_resumen`
+
+	err := r.db.QueryRow(
+		query,
+		resumen.Tipo,
+		resumen.Identificador,
+		resumen.Numero,
+		resumen.FechaReferencia,
+		resumen.FechaGeneracion,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateDetalle agrega un comprobante a un resumen
+func (r *ResumenSunatRepository) CreateDetalle(idResumen, idComprobante, condicion int) error {
+	query := `INSERT INTO resumen_sunat_detalle (id_resumen, id_comprobante, condicion)
+              VALUES ($1, $2, $3)`
+	_, err := r.db.Exec(query, idResumen, idComprobante, condicion)
+	return err
+}
+
+// GetByID obtiene un resumen por su ID
+func (r *ResumenSunatRepository) GetByID(id int) (*entidades.ResumenSunat, error) {
+	resumen := &entidades.ResumenSunat{}
+	query := `SELECT id_resumen, tipo, identificador, numero, fecha_referencia, fecha_generacion,
+              COALESCE(ticket, ''), estado, COALESCE(codigo_sunat, ''), COALESCE(descripcion_sunat, '')
+              FROM resumen_sunat
+              WHERE id_resumen = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&resumen.ID, &resumen.Tipo, &resumen.Identificador, &resumen.Numero, &resumen.FechaReferencia,
+		&resumen.FechaGeneracion, &resumen.Ticket, &resumen.Estado, &resumen.CodigoSunat, &resumen.DescripcionSunat,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("resumen no encontrado")
+		}
+		return nil, err
+	}
+
+	return resumen, nil
+}
+
+// ListDetalle lista los comprobantes incluidos en un resumen
+func (r *ResumenSunatRepository) ListDetalle(idResumen int) ([]*entidades.ResumenSunatDetalle, error) {
+	query := `SELECT d.id_resumen_detalle, d.id_resumen, d.id_comprobante, d.condicion, cp.numero_comprobante
+              FROM resumen_sunat_detalle d
+              INNER JOIN comprobante_pago cp ON d.id_comprobante = cp.id_comprobante
+              WHERE d.id_resumen = $1
+              ORDER BY d.id_resumen_detalle`
+
+	rows, err := r.db.Query(query, idResumen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	detalle := []*entidades.ResumenSunatDetalle{}
+
+	for rows.Next() {
+		item := &entidades.ResumenSunatDetalle{}
+		err := rows.Scan(&item.ID, &item.IDResumen, &item.IDComprobante, &item.Condicion, &item.NumeroComprobante)
+		if err != nil {
+			return nil, err
+		}
+		detalle = append(detalle, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return detalle, nil
+}
+
+// List lista todos los resúmenes
+func (r *ResumenSunatRepository) List() ([]*entidades.ResumenSunat, error) {
+	query := `SELECT id_resumen, tipo, identificador, numero, fecha_referencia, fecha_generacion,
+              COALESCE(ticket, ''), estado, COALESCE(codigo_sunat, ''), COALESCE(descripcion_sunat, '')
+              FROM resumen_sunat
+              ORDER BY fecha_generacion DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resumenes := []*entidades.ResumenSunat{}
+
+	for rows.Next() {
+		resumen := &entidades.ResumenSunat{}
+		err := rows.Scan(
+			&resumen.ID, &resumen.Tipo, &resumen.Identificador, &resumen.Numero, &resumen.FechaReferencia,
+			&resumen.FechaGeneracion, &resumen.Ticket, &resumen.Estado, &resumen.CodigoSunat, &resumen.DescripcionSunat,
+		)
+		if err != nil {
+			return nil, err
+		}
+		resumenes = append(resumenes, resumen)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return resumenes, nil
+}
+
+// UpdateEnvio registra el resultado del envío de un resumen (ticket asignado o error)
+func (r *ResumenSunatRepository) UpdateEnvio(id int, ticket, estado, codigo, descripcion string) error {
+	query := `UPDATE resumen_sunat SET
+              ticket = $1,
+              estado = $2,
+              codigo_sunat = $3,
+              descripcion_sunat = $4
+              WHERE id_resumen = $5`
+	_, err := r.db.Exec(query, ticket, estado, codigo, descripcion, id)
+	return err
+}
+
+// UpdateEstadoComprobantes actualiza el estado SUNAT de los comprobantes de un resumen con la condición indicada
+func (r *ResumenSunatRepository) UpdateEstadoComprobantes(idResumen, condicion int, estado string) error {
+	query := `UPDATE comprobante_pago SET estado_sunat = $1
+              WHERE id_comprobante IN (
+                  SELECT id_comprobante FROM resumen_sunat_detalle
+                  WHERE id_resumen = $2 AND condicion = $3
+              )`
+	_, err := r.db.Exec(query, estado, idResumen, condicion)
+	return err
+}
@@ -0,0 +1,174 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// RolRepository maneja las operaciones de base de datos para roles y sus permisos
+type RolRepository struct {
+	db Querier
+}
+
+// NewRolRepository crea una nueva instancia del repositorio
+func NewRolRepository(db *sql.DB) *RolRepository {
+	return &RolRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *RolRepository) WithTx(tx *sql.Tx) *RolRepository {
+	return &RolRepository{
+		db: tx,
+	}
+}
+
+// GetByNombre obtiene un rol con sus permisos
+func (r *RolRepository) GetByNombre(nombre string) (*entidades.Rol, error) {
+	rol := &entidades.Rol{}
+	query := `SELECT nombre, COALESCE(descripcion, ''), sistema, fecha_creacion
+              FROM rol
+              WHERE nombre = $1`
+
+	err := r.db.QueryRow(query, nombre).Scan(&rol.Nombre, &rol.Descripcion, &rol.Sistema, &rol.FechaCreacion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("rol no encontrado")
+		}
+		return nil, err
+	}
+
+	asignaciones, err := r.listAsignaciones(`SELECT rol, permiso FROM rol_permiso WHERE rol = $1 ORDER BY permiso`, nombre)
+	if err != nil {
+		return nil, err
+	}
+	rol.Permisos = asignaciones[nombre]
+	if rol.Permisos == nil {
+		rol.Permisos = []string{}
+	}
+
+	return rol, nil
+}
+
+// Exists indica si existe un rol con el nombre indicado
+func (r *RolRepository) Exists(nombre string) (bool, error) {
+	var existe bool
+	query := `SELECT EXISTS(SELECT 1 FROM rol WHERE nombre = $1)`
+	err := r.db.QueryRow(query, nombre).Scan(&existe)
+	return existe, err
+}
+
+// List lista todos los roles con sus permisos
+func (r *RolRepository) List() ([]*entidades.Rol, error) {
+	query := `SELECT nombre, COALESCE(descripcion, ''), sistema, fecha_creacion
+              FROM rol
+              ORDER BY sistema DESC, nombre`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*entidades.Rol{}
+	for rows.Next() {
+		rol := &entidades.Rol{}
+		if err := rows.Scan(&rol.Nombre, &rol.Descripcion, &rol.Sistema, &rol.FechaCreacion); err != nil {
+			return nil, err
+		}
+		roles = append(roles, rol)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	asignaciones, err := r.ListAsignaciones()
+	if err != nil {
+		return nil, err
+	}
+	for _, rol := range roles {
+		rol.Permisos = asignaciones[rol.Nombre]
+		if rol.Permisos == nil {
+			rol.Permisos = []string{}
+		}
+	}
+
+	return roles, nil
+}
+
+// ListAsignaciones devuelve los permisos de todos los roles, indexados por nombre de rol
+func (r *RolRepository) ListAsignaciones() (map[string][]string, error) {
+	return r.listAsignaciones(`SELECT rol, permiso FROM rol_permiso ORDER BY rol, permiso`)
+}
+
+// Create crea un rol sin permisos
+func (r *RolRepository) Create(nombre, descripcion string) error {
+	query := `INSERT INTO rol (nombre, descripcion) VALUES ($1, $2)`
+	_, err := r.db.Exec(query, nombre, descripcion)
+	return err
+}
+
+// UpdateDescripcion actualiza la descripción de un rol
+func (r *RolRepository) UpdateDescripcion(nombre, descripcion string) error {
+	query := `UPDATE rol SET descripcion = $2 WHERE nombre = $1`
+	_, err := r.db.Exec(query, nombre, descripcion)
+	return err
+}
+
+// ReemplazarPermisos reemplaza los permisos de un rol por los indicados
+func (r *RolRepository) ReemplazarPermisos(nombre string, permisos []string) error {
+	if _, err := r.db.Exec(`DELETE FROM rol_permiso WHERE rol = $1`, nombre); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO rol_permiso (rol, permiso) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, permiso := range permisos {
+		if _, err := r.db.Exec(query, nombre, permiso); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete elimina un rol; sus permisos se eliminan en cascada
+func (r *RolRepository) Delete(nombre string) error {
+	query := `DELETE FROM rol WHERE nombre = $1`
+	_, err := r.db.Exec(query, nombre)
+	return err
+}
+
+// CountUsuarios cuenta los usuarios activos que tienen el rol
+func (r *RolRepository) CountUsuarios(nombre string) (int, error) {
+	var total int
+	query := `SELECT COUNT(*) FROM usuario WHERE rol = $1 AND estado = true`
+	err := r.db.QueryRow(query, nombre).Scan(&total)
+	return total, err
+}
+
+// listAsignaciones ejecuta una consulta de pares (rol, permiso) y los agrupa por rol
+func (r *RolRepository) listAsignaciones(query string, args ...interface{}) (map[string][]string, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	asignaciones := map[string][]string{}
+	for rows.Next() {
+		var rol, permiso string
+		if err := rows.Scan(&rol, &permiso); err != nil {
+			return nil, err
+		}
+		asignaciones[rol] = append(asignaciones[rol], permiso)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return asignaciones, nil
+}
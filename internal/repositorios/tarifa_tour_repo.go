@@ -0,0 +1,171 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// TarifaTourRepository maneja las operaciones de base de datos para tarifas por tipo de tour
+type TarifaTourRepository struct {
+	db Querier
+}
+
+// NewTarifaTourRepository crea una nueva instancia del repositorio
+func NewTarifaTourRepository(db *sql.DB) *TarifaTourRepository {
+	return &TarifaTourRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *TarifaTourRepository) WithTx(tx *sql.Tx) *TarifaTourRepository {
+	return &TarifaTourRepository{
+		db: tx,
+	}
+}
+
+// GetByID obtiene una tarifa por su ID
+func (r *TarifaTourRepository) GetByID(id int) (*entidades.TarifaTour, error) {
+	tarifa := &entidades.TarifaTour{}
+	query := `SELECT t.id_tarifa, t.id_tipo_tour, t.id_tipo_pasaje, t.precio,
+              tt.nombre, tp.nombre, tp.costo
+              FROM tarifa_tour t
+              INNER JOIN tipo_tour tt ON t.id_tipo_tour = tt.id_tipo_tour
+              INNER JOIN tipo_pasaje tp ON t.id_tipo_pasaje = tp.id_tipo_pasaje
+              WHERE t.id_tarifa = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&tarifa.ID, &tarifa.IDTipoTour, &tarifa.IDTipoPasaje, &tarifa.Precio,
+		&tarifa.NombreTipoTour, &tarifa.NombreTipoPasaje, &tarifa.CostoGeneral,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("tarifa no encontrada")
+		}
+		return nil, err
+	}
+
+	return tarifa, nil
+}
+
+// ExisteTarifa verifica si ya hay una tarifa para la combinación de tipo de tour y tipo de pasaje
+func (r *TarifaTourRepository) ExisteTarifa(idTipoTour, idTipoPasaje int) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM tarifa_tour WHERE id_tipo_tour = $1 AND id_tipo_pasaje = $2`
+
+	err := r.db.QueryRow(query, idTipoTour, idTipoPasaje).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Create guarda una nueva tarifa en la base de datos
+func (r *TarifaTourRepository) Create(tarifa *entidades.NuevaTarifaTourRequest) (int, error) {
+	var id int
+	query := `INSERT INTO tarifa_tour (id_tipo_tour, id_tipo_pasaje, precio)
+              VALUES ($1, $2, $3)
+              RETURNING id_tarifa`
+
+	err := r.db.QueryRow(
+		query,
+		tarifa.IDTipoTour,
+		tarifa.IDTipoPasaje,
+		tarifa.Precio,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update actualiza el precio de una tarifa
+func (r *TarifaTourRepository) Update(id int, tarifa *entidades.ActualizarTarifaTourRequest) error {
+	query := `UPDATE tarifa_tour SET precio = $1 WHERE id_tarifa = $2`
+	_, err := r.db.Exec(query, tarifa.Precio, id)
+	return err
+}
+
+// Delete elimina una tarifa; el tipo de pasaje vuelve a usar su costo general en ese tipo de tour
+func (r *TarifaTourRepository) Delete(id int) error {
+	query := `DELETE FROM tarifa_tour WHERE id_tarifa = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// List lista todas las tarifas
+func (r *TarifaTourRepository) List() ([]*entidades.TarifaTour, error) {
+	query := `SELECT t.id_tarifa, t.id_tipo_tour, t.id_tipo_pasaje, t.precio,
+              tt.nombre, tp.nombre, tp.costo
+              FROM tarifa_tour t
+              INNER JOIN tipo_tour tt ON t.id_tipo_tour = tt.id_tipo_tour
+              INNER JOIN tipo_pasaje tp ON t.id_tipo_pasaje = tp.id_tipo_pasaje
+              ORDER BY tt.nombre, tp.nombre`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tarifas := []*entidades.TarifaTour{}
+
+	for rows.Next() {
+		tarifa := &entidades.TarifaTour{}
+		err := rows.Scan(
+			&tarifa.ID, &tarifa.IDTipoTour, &tarifa.IDTipoPasaje, &tarifa.Precio,
+			&tarifa.NombreTipoTour, &tarifa.NombreTipoPasaje, &tarifa.CostoGeneral,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tarifas = append(tarifas, tarifa)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tarifas, nil
+}
+
+// ListVigentesByTipoTour lista el precio aplicable de cada tipo de pasaje en un tipo de tour,
+// usando el costo general cuando no hay tarifa específica
+func (r *TarifaTourRepository) ListVigentesByTipoTour(idTipoTour int) ([]*entidades.TarifaVigente, error) {
+	query := `SELECT tp.id_tipo_pasaje, tp.nombre, COALESCE(tp.edad, ''),
+              COALESCE(t.precio, tp.costo), t.id_tarifa IS NOT NULL
+              FROM tipo_pasaje tp
+              LEFT JOIN tarifa_tour t ON t.id_tipo_pasaje = tp.id_tipo_pasaje AND t.id_tipo_tour = $1
+              ORDER BY COALESCE(t.precio, tp.costo) DESC, tp.nombre`
+
+	rows, err := r.db.Query(query, idTipoTour)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tarifas := []*entidades.TarifaVigente{}
+
+	for rows.Next() {
+		tarifa := &entidades.TarifaVigente{}
+		err := rows.Scan(
+			&tarifa.IDTipoPasaje, &tarifa.NombreTipo, &tarifa.Edad,
+			&tarifa.Precio, &tarifa.Especifica,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tarifas = append(tarifas, tarifa)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tarifas, nil
+}
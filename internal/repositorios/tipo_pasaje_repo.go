@@ -8,7 +8,7 @@ import (
 
 // TipoPasajeRepository maneja las operaciones de base de datos para tipos de pasaje
 type TipoPasajeRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewTipoPasajeRepository crea una nueva instancia del repositorio
@@ -18,15 +18,23 @@ func NewTipoPasajeRepository(db *sql.DB) *TipoPasajeRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *TipoPasajeRepository) WithTx(tx *sql.Tx) *TipoPasajeRepository {
+	return &TipoPasajeRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un tipo de pasaje por su ID
 func (r *TipoPasajeRepository) GetByID(id int) (*entidades.TipoPasaje, error) {
 	tipoPasaje := &entidades.TipoPasaje{}
-	query := `SELECT id_tipo_pasaje, nombre, costo, edad
+	query := `SELECT id_tipo_pasaje, nombre, costo, edad, edad_minima, edad_maxima
               FROM tipo_pasaje
               WHERE id_tipo_pasaje = $1`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&tipoPasaje.ID, &tipoPasaje.Nombre, &tipoPasaje.Costo, &tipoPasaje.Edad,
+		&tipoPasaje.EdadMinima, &tipoPasaje.EdadMaxima,
 	)
 
 	if err != nil {
@@ -42,12 +50,13 @@ func (r *TipoPasajeRepository) GetByID(id int) (*entidades.TipoPasaje, error) {
 // GetByNombre obtiene un tipo de pasaje por su nombre
 func (r *TipoPasajeRepository) GetByNombre(nombre string) (*entidades.TipoPasaje, error) {
 	tipoPasaje := &entidades.TipoPasaje{}
-	query := `SELECT id_tipo_pasaje, nombre, costo, edad
+	query := `SELECT id_tipo_pasaje, nombre, costo, edad, edad_minima, edad_maxima
               FROM tipo_pasaje
               WHERE nombre = $1`
 
 	err := r.db.QueryRow(query, nombre).Scan(
 		&tipoPasaje.ID, &tipoPasaje.Nombre, &tipoPasaje.Costo, &tipoPasaje.Edad,
+		&tipoPasaje.EdadMinima, &tipoPasaje.EdadMaxima,
 	)
 
 	if err != nil {
@@ -63,8 +72,8 @@ func (r *TipoPasajeRepository) GetByNombre(nombre string) (*entidades.TipoPasaje
 // Create guarda un nuevo tipo de pasaje en la base de datos
 func (r *TipoPasajeRepository) Create(tipoPasaje *entidades.NuevoTipoPasajeRequest) (int, error) {
 	var id int
-	query := `INSERT INTO tipo_pasaje (nombre, costo, edad)
-              VALUES ($1, $2, $3)
+	query := `INSERT INTO tipo_pasaje (nombre, costo, edad, edad_minima, edad_maxima)
+              VALUES ($1, $2, $3, $4, $5)
               RETURNING id_tipo_pasaje`
 
 	err := r.db.QueryRow(
@@ -72,6 +81,8 @@ func (r *TipoPasajeRepository) Create(tipoPasaje *entidades.NuevoTipoPasajeReque
 		tipoPasaje.Nombre,
 		tipoPasaje.Costo,
 		tipoPasaje.Edad,
+		tipoPasaje.EdadMinima,
+		tipoPasaje.EdadMaxima,
 	).Scan(&id)
 
 	if err != nil {
@@ -86,14 +97,18 @@ func (r *TipoPasajeRepository) Update(id int, tipoPasaje *entidades.ActualizarTi
 	query := `UPDATE tipo_pasaje SET
               nombre = $1,
               costo = $2,
-              edad = $3
-              WHERE id_tipo_pasaje = $4`
+              edad = $3,
+              edad_minima = $4,
+              edad_maxima = $5
+              WHERE id_tipo_pasaje = $6`
 
 	_, err := r.db.Exec(
 		query,
 		tipoPasaje.Nombre,
 		tipoPasaje.Costo,
 		tipoPasaje.Edad,
+		tipoPasaje.EdadMinima,
+		tipoPasaje.EdadMaxima,
 		id,
 	)
 
@@ -122,7 +137,7 @@ func (r *TipoPasajeRepository) Delete(id int) error {
 
 // List lista todos los tipos de pasaje
 func (r *TipoPasajeRepository) List() ([]*entidades.TipoPasaje, error) {
-	query := `SELECT id_tipo_pasaje, nombre, costo, edad
+	query := `SELECT id_tipo_pasaje, nombre, costo, edad, edad_minima, edad_maxima
               FROM tipo_pasaje
               ORDER BY costo ASC`
 
@@ -138,6 +153,7 @@ func (r *TipoPasajeRepository) List() ([]*entidades.TipoPasaje, error) {
 		tipoPasaje := &entidades.TipoPasaje{}
 		err := rows.Scan(
 			&tipoPasaje.ID, &tipoPasaje.Nombre, &tipoPasaje.Costo, &tipoPasaje.Edad,
+			&tipoPasaje.EdadMinima, &tipoPasaje.EdadMaxima,
 		)
 		if err != nil {
 			return nil, err
@@ -8,7 +8,7 @@ import (
 
 // TipoTourRepository maneja las operaciones de base de datos para tipos de tour
 type TipoTourRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewTipoTourRepository crea una nueva instancia del repositorio
@@ -18,6 +18,13 @@ func NewTipoTourRepository(db *sql.DB) *TipoTourRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *TipoTourRepository) WithTx(tx *sql.Tx) *TipoTourRepository {
+	return &TipoTourRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un tipo de tour por su ID
 func (r *TipoTourRepository) GetByID(id int) (*entidades.TipoTour, error) {
 	tipoTour := &entidades.TipoTour{}
@@ -0,0 +1,89 @@
+package repositorios
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+)
+
+// TokenRecuperacionRepository maneja las operaciones de base de datos para tokens de recuperación de contraseña
+type TokenRecuperacionRepository struct {
+	db Querier
+}
+
+// NewTokenRecuperacionRepository crea una nueva instancia del repositorio
+func NewTokenRecuperacionRepository(db *sql.DB) *TokenRecuperacionRepository {
+	return &TokenRecuperacionRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *TokenRecuperacionRepository) WithTx(tx *sql.Tx) *TokenRecuperacionRepository {
+	return &TokenRecuperacionRepository{
+		db: tx,
+	}
+}
+
+// Create registra un token de recuperación emitido
+func (r *TokenRecuperacionRepository) Create(token *entidades.TokenRecuperacion) (int, error) {
+	var id int
+	query := `INSERT INTO token_recuperacion (tipo_sujeto, id_sujeto, token_hash, fecha_expiracion)
+              VALUES ($1, $2, $3, $4)
+              RETURNING id_token_recuperacion`
+
+	err := r.db.QueryRow(
+		query,
+		token.TipoSujeto,
+		token.IDSujeto,
+		token.TokenHash,
+		token.FechaExpiracion,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetByHashForUpdate obtiene un token por su hash bloqueando la fila hasta el fin de la transacción,
+// de modo que el mismo token no pueda usarse dos veces en paralelo
+func (r *TokenRecuperacionRepository) GetByHashForUpdate(tokenHash string) (*entidades.TokenRecuperacion, error) {
+	token := &entidades.TokenRecuperacion{}
+	query := `SELECT id_token_recuperacion, tipo_sujeto, id_sujeto, token_hash,
+              fecha_emision, fecha_expiracion, fecha_uso
+              FROM token_recuperacion
+              WHERE token_hash = $1
+              FOR UPDATE`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID, &token.TipoSujeto, &token.IDSujeto, &token.TokenHash,
+		&token.FechaEmision, &token.FechaExpiracion, &token.FechaUso,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("token de recuperación no encontrado")
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarcarUsado registra que el token ya se utilizó
+func (r *TokenRecuperacionRepository) MarcarUsado(id int) error {
+	query := `UPDATE token_recuperacion SET fecha_uso = CURRENT_TIMESTAMP WHERE id_token_recuperacion = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// InvalidarBySujeto marca como usados los tokens pendientes de un usuario o cliente,
+// para que solo el último enviado sea válido
+func (r *TokenRecuperacionRepository) InvalidarBySujeto(tipoSujeto string, idSujeto int) error {
+	query := `UPDATE token_recuperacion SET fecha_uso = CURRENT_TIMESTAMP
+              WHERE tipo_sujeto = $1 AND id_sujeto = $2 AND fecha_uso IS NULL`
+	_, err := r.db.Exec(query, tipoSujeto, idSujeto)
+	return err
+}
@@ -0,0 +1,82 @@
+package repositorios
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TokenRevocadoRepository maneja la lista de tokens de acceso revocados antes de expirar
+type TokenRevocadoRepository struct {
+	db Querier
+}
+
+// NewTokenRevocadoRepository crea una nueva instancia del repositorio
+func NewTokenRevocadoRepository(db *sql.DB) *TokenRevocadoRepository {
+	return &TokenRevocadoRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *TokenRevocadoRepository) WithTx(tx *sql.Tx) *TokenRevocadoRepository {
+	return &TokenRevocadoRepository{
+		db: tx,
+	}
+}
+
+// Create agrega un token de acceso a la lista de revocados; revocarlo dos veces no es un error
+func (r *TokenRevocadoRepository) Create(jti string, fechaExpiracion time.Time) error {
+	query := `INSERT INTO token_revocado (jti, fecha_expiracion)
+              VALUES ($1, $2)
+              ON CONFLICT (jti) DO NOTHING`
+	_, err := r.db.Exec(query, jti, fechaExpiracion)
+	return err
+}
+
+// Exists indica si un token de acceso fue revocado
+func (r *TokenRevocadoRepository) Exists(jti string) (bool, error) {
+	var existe bool
+	query := `SELECT EXISTS(SELECT 1 FROM token_revocado WHERE jti = $1)`
+	err := r.db.QueryRow(query, jti).Scan(&existe)
+	return existe, err
+}
+
+// RevocarSujeto invalida todos los tokens de acceso emitidos a un sujeto hasta la fecha indicada
+func (r *TokenRevocadoRepository) RevocarSujeto(tipoSujeto string, idSujeto int, fecha time.Time) error {
+	query := `INSERT INTO sujeto_revocado (tipo_sujeto, id_sujeto, fecha_revocacion)
+              VALUES ($1, $2, $3)
+              ON CONFLICT (tipo_sujeto, id_sujeto) DO UPDATE SET fecha_revocacion = EXCLUDED.fecha_revocacion`
+	_, err := r.db.Exec(query, tipoSujeto, idSujeto, fecha)
+	return err
+}
+
+// Revocado indica si un token de acceso fue revocado por su jti o porque su sujeto
+// fue revocado después de emitirlo
+func (r *TokenRevocadoRepository) Revocado(jti, tipoSujeto string, idSujeto int, emision time.Time) (bool, error) {
+	var revocado bool
+	query := `SELECT EXISTS(SELECT 1 FROM token_revocado WHERE jti = $1)
+              OR EXISTS(SELECT 1 FROM sujeto_revocado
+                        WHERE tipo_sujeto = $2 AND id_sujeto = $3 AND fecha_revocacion >= $4)`
+	err := r.db.QueryRow(query, jti, tipoSujeto, idSujeto, emision).Scan(&revocado)
+	return revocado, err
+}
+
+// DeleteSujetosAntesDe elimina las revocaciones de sujetos anteriores a la fecha indicada
+func (r *TokenRevocadoRepository) DeleteSujetosAntesDe(antesDe time.Time) (int64, error) {
+	query := `DELETE FROM sujeto_revocado WHERE fecha_revocacion < $1`
+	result, err := r.db.Exec(query, antesDe)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExpirados elimina los tokens revocados que ya expiraron por sí mismos
+func (r *TokenRevocadoRepository) DeleteExpirados(antesDe time.Time) (int64, error) {
+	query := `DELETE FROM token_revocado WHERE fecha_expiracion < $1`
+	result, err := r.db.Exec(query, antesDe)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -9,7 +9,7 @@ import (
 
 // TourProgramadoRepository maneja las operaciones de base de datos para tours programados
 type TourProgramadoRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewTourProgramadoRepository crea una nueva instancia del repositorio
@@ -19,6 +19,13 @@ func NewTourProgramadoRepository(db *sql.DB) *TourProgramadoRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *TourProgramadoRepository) WithTx(tx *sql.Tx) *TourProgramadoRepository {
+	return &TourProgramadoRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un tour programado por su ID
 func (r *TourProgramadoRepository) GetByID(id int) (*entidades.TourProgramado, error) {
 	tour := &entidades.TourProgramado{}
@@ -26,7 +33,7 @@ func (r *TourProgramadoRepository) GetByID(id int) (*entidades.TourProgramado, e
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -40,7 +47,7 @@ func (r *TourProgramadoRepository) GetByID(id int) (*entidades.TourProgramado, e
 		&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 		&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 		&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-		&tour.NombreChofer, &tour.ApellidosChofer,
+		&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 		&tour.HoraInicio, &tour.HoraFin,
 	)
 
@@ -150,11 +157,48 @@ func (r *TourProgramadoRepository) UpdateEstado(id int, estado string) error {
 	return err
 }
 
-// UpdateCupoDisponible actualiza el cupo disponible de un tour programado
-func (r *TourProgramadoRepository) UpdateCupoDisponible(id int, nuevoDisponible int) error {
-	query := `UPDATE tour_programado SET cupo_disponible = $1 WHERE id_tour_programado = $2`
-	_, err := r.db.Exec(query, nuevoDisponible, id)
-	return err
+// DescontarCupo descuenta cupo de un tour programado. La verificación y el
+// descuento se hacen en una sola sentencia, que además bloquea la fila hasta el fin de la transacción,
+// por lo que dos reservas simultáneas no pueden vender el mismo asiento.
+func (r *TourProgramadoRepository) DescontarCupo(id int, cantidad int) error {
+	query := `UPDATE tour_programado SET cupo_disponible = cupo_disponible - $1
+              WHERE id_tour_programado = $2 AND estado = 'PROGRAMADO' AND cupo_disponible >= $1`
+
+	result, err := r.db.Exec(query, cantidad, id)
+	if err != nil {
+		return err
+	}
+
+	filas, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if filas == 0 {
+		return errors.New("no hay suficiente cupo disponible en el tour programado")
+	}
+
+	return nil
+}
+
+// LiberarCupo devuelve cupo a un tour programado
+func (r *TourProgramadoRepository) LiberarCupo(id int, cantidad int) error {
+	query := `UPDATE tour_programado SET cupo_disponible = cupo_disponible + $1
+              WHERE id_tour_programado = $2 AND cupo_disponible + $1 <= cupo_maximo`
+
+	result, err := r.db.Exec(query, cantidad, id)
+	if err != nil {
+		return err
+	}
+
+	filas, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if filas == 0 {
+		return errors.New("el cupo a liberar excede el cupo máximo del tour programado")
+	}
+
+	return nil
 }
 
 // Delete elimina un tour programado
@@ -183,7 +227,7 @@ func (r *TourProgramadoRepository) List() ([]*entidades.TourProgramado, error) {
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -207,7 +251,7 @@ func (r *TourProgramadoRepository) List() ([]*entidades.TourProgramado, error) {
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -229,7 +273,7 @@ func (r *TourProgramadoRepository) ListByFecha(fecha time.Time) ([]*entidades.To
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -254,7 +298,7 @@ func (r *TourProgramadoRepository) ListByFecha(fecha time.Time) ([]*entidades.To
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -276,7 +320,7 @@ func (r *TourProgramadoRepository) ListByRangoFechas(fechaInicio, fechaFin time.
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -301,7 +345,7 @@ func (r *TourProgramadoRepository) ListByRangoFechas(fechaInicio, fechaFin time.
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -323,7 +367,7 @@ func (r *TourProgramadoRepository) ListByEstado(estado string) ([]*entidades.Tou
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -348,7 +392,7 @@ func (r *TourProgramadoRepository) ListByEstado(estado string) ([]*entidades.Tou
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -370,7 +414,7 @@ func (r *TourProgramadoRepository) ListByEmbarcacion(idEmbarcacion int) ([]*enti
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -395,7 +439,7 @@ func (r *TourProgramadoRepository) ListByEmbarcacion(idEmbarcacion int) ([]*enti
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -417,7 +461,7 @@ func (r *TourProgramadoRepository) ListByChofer(idChofer int) ([]*entidades.Tour
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -442,7 +486,7 @@ func (r *TourProgramadoRepository) ListByChofer(idChofer int) ([]*entidades.Tour
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -464,7 +508,7 @@ func (r *TourProgramadoRepository) ListToursProgramadosDisponibles() ([]*entidad
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -491,7 +535,7 @@ func (r *TourProgramadoRepository) ListToursProgramadosDisponibles() ([]*entidad
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -513,7 +557,7 @@ func (r *TourProgramadoRepository) ListByTipoTour(idTipoTour int) ([]*entidades.
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -538,7 +582,7 @@ func (r *TourProgramadoRepository) ListByTipoTour(idTipoTour int) ([]*entidades.
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
@@ -560,7 +604,7 @@ func (r *TourProgramadoRepository) GetDisponibilidadDia(fecha time.Time) ([]*ent
               tp.fecha, tp.cupo_maximo, tp.cupo_disponible, tp.estado,
               tt.nombre, tt.precio_base, tt.duracion_minutos,
               e.nombre, e.capacidad,
-              u.nombres, u.apellidos,
+              e.id_usuario, u.nombres, u.apellidos,
               TO_CHAR(ht.hora_inicio, 'HH24:MI'), TO_CHAR(ht.hora_fin, 'HH24:MI')
               FROM tour_programado tp
               INNER JOIN tipo_tour tt ON tp.id_tipo_tour = tt.id_tipo_tour
@@ -587,7 +631,7 @@ func (r *TourProgramadoRepository) GetDisponibilidadDia(fecha time.Time) ([]*ent
 			&tour.Fecha, &tour.CupoMaximo, &tour.CupoDisponible, &tour.Estado,
 			&tour.NombreTipoTour, &tour.PrecioBase, &tour.DuracionMinutos,
 			&tour.NombreEmbarcacion, &tour.CapacidadEmbarcacion,
-			&tour.NombreChofer, &tour.ApellidosChofer,
+			&tour.IDChofer, &tour.NombreChofer, &tour.ApellidosChofer,
 			&tour.HoraInicio, &tour.HoraFin,
 		)
 		if err != nil {
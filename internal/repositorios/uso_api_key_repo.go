@@ -0,0 +1,104 @@
+package repositorios
+
+import (
+	"database/sql"
+	"sistema-tours/internal/entidades"
+	"time"
+)
+
+// UsoAPIKeyRepository maneja el registro de las peticiones hechas con llaves de API
+type UsoAPIKeyRepository struct {
+	db Querier
+}
+
+// NewUsoAPIKeyRepository crea una nueva instancia del repositorio
+func NewUsoAPIKeyRepository(db *sql.DB) *UsoAPIKeyRepository {
+	return &UsoAPIKeyRepository{
+		db: db,
+	}
+}
+
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *UsoAPIKeyRepository) WithTx(tx *sql.Tx) *UsoAPIKeyRepository {
+	return &UsoAPIKeyRepository{
+		db: tx,
+	}
+}
+
+// Create registra una petición hecha con una llave
+func (r *UsoAPIKeyRepository) Create(uso *entidades.UsoAPIKey) (int, error) {
+	var id int
+	query := `INSERT INTO uso_api_key (id_api_key, metodo, ruta, codigo_estado, ip, id_reserva)
+              VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+              RETURNING id_uso_api_key`
+
+	err := r.db.QueryRow(
+		query,
+		uso.IDAPIKey,
+		uso.Metodo,
+		uso.Ruta,
+		uso.CodigoEstado,
+		uso.IP,
+		uso.IDReserva,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateResultado guarda el código con el que se respondió una petición ya registrada y la reserva creada con ella
+func (r *UsoAPIKeyRepository) UpdateResultado(uso *entidades.UsoAPIKey) error {
+	query := `UPDATE uso_api_key SET codigo_estado = $1, id_reserva = $2
+              WHERE id_uso_api_key = $3`
+	_, err := r.db.Exec(query, uso.CodigoEstado, uso.IDReserva, uso.ID)
+	return err
+}
+
+// CountRecientes cuenta las peticiones de una llave dentro de la ventana que termina ahora.
+// Cuenta las que siguen en curso y no las rechazadas por exceder el límite, para que insistir no prolongue el rechazo.
+func (r *UsoAPIKeyRepository) CountRecientes(idAPIKey int, ventana time.Duration) (int, error) {
+	var total int
+	query := `SELECT COUNT(*) FROM uso_api_key
+              WHERE id_api_key = $1
+              AND fecha > CURRENT_TIMESTAMP - $2 * INTERVAL '1 second'
+              AND codigo_estado <> 429`
+	err := r.db.QueryRow(query, idAPIKey, ventana.Seconds()).Scan(&total)
+	return total, err
+}
+
+// ListByAPIKey lista las peticiones más recientes de una llave
+func (r *UsoAPIKeyRepository) ListByAPIKey(idAPIKey int, limite int) ([]*entidades.UsoAPIKey, error) {
+	query := `SELECT id_uso_api_key, id_api_key, metodo, ruta, codigo_estado, COALESCE(ip, ''), id_reserva, fecha
+              FROM uso_api_key
+              WHERE id_api_key = $1
+              ORDER BY fecha DESC, id_uso_api_key DESC
+              LIMIT $2`
+
+	rows, err := r.db.Query(query, idAPIKey, limite)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usos := []*entidades.UsoAPIKey{}
+
+	for rows.Next() {
+		uso := &entidades.UsoAPIKey{}
+		err := rows.Scan(
+			&uso.ID, &uso.IDAPIKey, &uso.Metodo, &uso.Ruta, &uso.CodigoEstado, &uso.IP, &uso.IDReserva, &uso.Fecha,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usos = append(usos, uso)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return usos, nil
+}
@@ -8,7 +8,7 @@ import (
 
 // UsuarioRepository maneja las operaciones de base de datos para usuarios
 type UsuarioRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewUsuarioRepository crea una nueva instancia del repositorio
@@ -18,6 +18,13 @@ func NewUsuarioRepository(db *sql.DB) *UsuarioRepository {
 	}
 }
 
+// WithTx devuelve una copia del repositorio que ejecuta sus consultas dentro de la transacción
+func (r *UsuarioRepository) WithTx(tx *sql.Tx) *UsuarioRepository {
+	return &UsuarioRepository{
+		db: tx,
+	}
+}
+
 // GetByID obtiene un usuario por su ID
 func (r *UsuarioRepository) GetByID(id int) (*entidades.Usuario, error) {
 	usuario := &entidades.Usuario{}
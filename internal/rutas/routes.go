@@ -544,7 +544,9 @@ package rutas
 import (
 	"sistema-tours/internal/config"
 	"sistema-tours/internal/controladores"
+	"sistema-tours/internal/entidades"
 	"sistema-tours/internal/middleware"
+	"sistema-tours/internal/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -554,6 +556,9 @@ import (
 func SetupRoutes(
 	router *gin.Engine,
 	config *config.Config,
+	tokensRevocados middleware.TokensRevocados,
+	permisos middleware.Permisos,
+	clavesAPI middleware.ClavesAPI,
 	authController *controladores.AuthController,
 	usuarioController *controladores.UsuarioController,
 	embarcacionController *controladores.EmbarcacionController,
@@ -566,6 +571,20 @@ func SetupRoutes(
 	canalVentaController *controladores.CanalVentaController,
 	clienteController *controladores.ClienteController,
 	reservaController *controladores.ReservaController,
+	pagoController *controladores.PagoController,
+	comprobantePagoController *controladores.ComprobantePagoController,
+	facturacionController *controladores.FacturacionElectronicaController,
+	notaCreditoController *controladores.NotaCreditoController,
+	impresionController *controladores.ImpresionController,
+	cotizacionController *controladores.CotizacionController,
+	tarifaTourController *controladores.TarifaTourController,
+	pasajeroController *controladores.PasajeroController,
+	embarqueController *controladores.EmbarqueController,
+	proteccionLoginController *controladores.ProteccionLoginController,
+	recuperacionController *controladores.RecuperacionContrasenaController,
+	dobleFactorController *controladores.DobleFactorController,
+	rolController *controladores.RolController,
+	apiKeyController *controladores.APIKeyController,
 	// Otros controladores
 ) {
 	// Middleware global
@@ -573,244 +592,420 @@ func SetupRoutes(
 	router.Use(middleware.ErrorMiddleware())
 	router.Use(gin.Recovery())
 
+	// permiso exige que el rol del usuario autenticado incluya el permiso indicado
+	permiso := func(codigo string) gin.HandlerFunc {
+		return middleware.RequirePermission(permisos, codigo)
+	}
+
 	// Rutas públicas
 	public := router.Group("/api/v1")
 	{
 		// Autenticación
 		public.POST("/auth/login", authController.Login)
 		public.POST("/auth/refresh", authController.RefreshToken)
+		public.POST("/auth/login/2fa", authController.VerificarDobleFactor)
+		public.POST("/auth/login/2fa/enrolar", authController.EnrolarDobleFactor)
+		public.POST("/auth/login/2fa/activar", authController.ActivarDobleFactor)
+		public.POST("/auth/forgot-password", recuperacionController.OlvideContrasena)
+		public.POST("/auth/reset-password", recuperacionController.Restablecer)
 
 		// Registro de cliente
 		public.POST("/clientes/registro", clienteController.Create)
-		public.POST("/clientes/login", clienteController.Login)
+		public.POST("/clientes/login", authController.LoginCliente)
+		public.POST("/clientes/refresh", authController.RefreshTokenCliente)
+		public.POST("/clientes/forgot-password", recuperacionController.OlvideContrasenaCliente)
+		public.POST("/clientes/reset-password", recuperacionController.Restablecer)
 
 		// Tours programados disponibles (acceso público)
 		public.GET("/tours/disponibles", tourProgramadoController.ListToursProgramadosDisponibles)
 		public.GET("/tours/disponibilidad/:fecha", tourProgramadoController.GetDisponibilidadDia)
 		public.GET("/tours/:id", tourProgramadoController.GetByID)
+		public.POST("/tours/:id/cotizar", cotizacionController.Cotizar)
 
 		// Tipos de pasaje (acceso público para ver precios)
 		public.GET("/tipos-pasaje", tipoPasajeController.List)
+		public.GET("/tarifas/tipo-tour/:idTipoTour", tarifaTourController.ListVigentesByTipoTour)
 
 		// Métodos de pago (acceso público para ver opciones)
 		public.GET("/metodos-pago", metodoPagoController.List)
 
 		// Canales de venta (acceso público)
 		public.GET("/canales-venta", canalVentaController.List)
+
+		// Consulta de reserva con localizador y documento del cliente
+		public.POST("/reservas/consulta", reservaController.ConsultarPorLocalizador)
+	}
+
+	// Agencias y OTAs: acceso con el encabezado X-API-Key, limitado a los alcances de la llave.
+	// Las reservas quedan en el canal de venta de la llave y solo se ven las de ese canal.
+	agencia := router.Group("/api/v1/agencia")
+	agencia.Use(middleware.APIKeyMiddleware(clavesAPI))
+	{
+		// Disponibilidad y precios
+		agencia.GET("/tours/disponibles", middleware.RequireAlcance(entidades.PermisoToursVer), tourProgramadoController.ListToursProgramadosDisponibles)
+		agencia.GET("/tours/disponibilidad/:fecha", middleware.RequireAlcance(entidades.PermisoToursVer), tourProgramadoController.GetDisponibilidadDia)
+		agencia.GET("/tours/:id", middleware.RequireAlcance(entidades.PermisoToursVer), tourProgramadoController.GetByID)
+		agencia.POST("/tours/:id/cotizar", middleware.RequireAlcance(entidades.PermisoTarifasVer), cotizacionController.Cotizar)
+		agencia.GET("/tipos-pasaje", middleware.RequireAlcance(entidades.PermisoTarifasVer), tipoPasajeController.List)
+		agencia.GET("/tarifas/tipo-tour/:idTipoTour", middleware.RequireAlcance(entidades.PermisoTarifasVer), tarifaTourController.ListVigentesByTipoTour)
+
+		// Reservas de la agencia
+		agencia.POST("/reservas", middleware.RequireAlcance(entidades.PermisoReservasCrear), reservaController.CreateAgencia)
+		agencia.GET("/reservas/:id", middleware.RequireAlcance(entidades.PermisoReservasVer), reservaController.GetByID)
+		agencia.POST("/reservas/:id/estado", middleware.RequireAlcance(entidades.PermisoReservasEditar), reservaController.CambiarEstado) // Solo para cancelar
+		agencia.GET("/reservas/:id/pasajeros", middleware.RequireAlcance(entidades.PermisoReservasVer), pasajeroController.ListByReserva)
+		agencia.POST("/reservas/:id/pasajeros", middleware.RequireAlcance(entidades.PermisoReservasEditar), pasajeroController.Create)
 	}
 
 	// Rutas protegidas (requieren autenticación)
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware(config))
+	protected.Use(middleware.AuthMiddleware(config, tokensRevocados))
 	{
-		// Cambiar contraseña (cualquier usuario autenticado)
-		protected.POST("/auth/change-password", authController.ChangePassword)
+		// Cambiar contraseña (usuarios del sistema; los clientes usan /cliente/change-password)
+		protected.POST("/auth/change-password", middleware.SubjectMiddleware(utils.SujetoUsuario), authController.ChangePassword)
 
-		// Usuarios - Admin
+		// Cerrar sesión (usuarios del sistema; los clientes usan /cliente/logout)
+		protected.POST("/auth/logout", middleware.SubjectMiddleware(utils.SujetoUsuario), authController.Logout)
+		protected.POST("/auth/logout-all", middleware.SubjectMiddleware(utils.SujetoUsuario), authController.LogoutTodos)
+
+		// Doble factor del propio usuario del sistema
+		dobleFactor := protected.Group("/auth/2fa")
+		dobleFactor.Use(middleware.SubjectMiddleware(utils.SujetoUsuario))
+		{
+			dobleFactor.POST("/enrolar", dobleFactorController.Enrolar)
+			dobleFactor.POST("/activar", dobleFactorController.Activar)
+			dobleFactor.POST("/desactivar", dobleFactorController.Desactivar)
+		}
+
+		// Administración: cada ruta exige el permiso correspondiente al rol del usuario
 		admin := protected.Group("/admin")
-		admin.Use(middleware.RoleMiddleware("ADMIN"))
+		admin.Use(middleware.SubjectMiddleware(utils.SujetoUsuario))
 		{
 			// Gestión de usuarios
-			admin.POST("/usuarios", usuarioController.Create)
-			admin.GET("/usuarios", usuarioController.List)
-			admin.GET("/usuarios/:id", usuarioController.GetByID)
-			admin.PUT("/usuarios/:id", usuarioController.Update)
-			admin.DELETE("/usuarios/:id", usuarioController.Delete)
-			admin.GET("/usuarios/rol/:rol", usuarioController.ListByRol)
+			admin.POST("/usuarios", permiso(entidades.PermisoUsuariosGestionar), usuarioController.Create)
+			admin.GET("/usuarios", permiso(entidades.PermisoUsuariosGestionar), usuarioController.List)
+			admin.GET("/usuarios/:id", permiso(entidades.PermisoUsuariosGestionar), usuarioController.GetByID)
+			admin.PUT("/usuarios/:id", permiso(entidades.PermisoUsuariosGestionar), usuarioController.Update)
+			admin.DELETE("/usuarios/:id", permiso(entidades.PermisoUsuariosGestionar), usuarioController.Delete)
+			admin.GET("/usuarios/rol/:rol", permiso(entidades.PermisoUsuariosGestionar), usuarioController.ListByRol)
+			admin.DELETE("/usuarios/:id/2fa", permiso(entidades.PermisoUsuariosGestionar), dobleFactorController.Restablecer)
+
+			// Roles y permisos
+			admin.GET("/permisos", permiso(entidades.PermisoRolesGestionar), rolController.ListPermisos)
+			admin.POST("/roles", permiso(entidades.PermisoRolesGestionar), rolController.Create)
+			admin.GET("/roles", permiso(entidades.PermisoRolesGestionar), rolController.List)
+			admin.GET("/roles/:nombre", permiso(entidades.PermisoRolesGestionar), rolController.GetByNombre)
+			admin.PUT("/roles/:nombre", permiso(entidades.PermisoRolesGestionar), rolController.Update)
+			admin.DELETE("/roles/:nombre", permiso(entidades.PermisoRolesGestionar), rolController.Delete)
+
+			// Llaves de API de agencias
+			admin.POST("/api-keys", permiso(entidades.PermisoAPIKeysGestionar), apiKeyController.Create)
+			admin.GET("/api-keys", permiso(entidades.PermisoAPIKeysGestionar), apiKeyController.List)
+			admin.GET("/api-keys/:id", permiso(entidades.PermisoAPIKeysGestionar), apiKeyController.GetByID)
+			admin.POST("/api-keys/:id/revocar", permiso(entidades.PermisoAPIKeysGestionar), apiKeyController.Revocar)
+			admin.GET("/api-keys/:id/uso", permiso(entidades.PermisoAPIKeysGestionar), apiKeyController.ListUso)
+
+			// Bloqueos y auditoría de inicio de sesión
+			admin.POST("/seguridad/desbloquear", permiso(entidades.PermisoSeguridadGestionar), proteccionLoginController.Desbloquear)
+			admin.GET("/seguridad/auditoria-login", permiso(entidades.PermisoSeguridadGestionar), proteccionLoginController.ListAuditoria)
 
 			// Gestión de embarcaciones
-			admin.POST("/embarcaciones", embarcacionController.Create)
-			admin.GET("/embarcaciones", embarcacionController.List)
-			admin.GET("/embarcaciones/:id", embarcacionController.GetByID)
-			admin.PUT("/embarcaciones/:id", embarcacionController.Update)
-			admin.DELETE("/embarcaciones/:id", embarcacionController.Delete)
-			admin.GET("/embarcaciones/chofer/:idChofer", embarcacionController.ListByChofer)
+			admin.POST("/embarcaciones", permiso(entidades.PermisoEmbarcacionesGestionar), embarcacionController.Create)
+			admin.GET("/embarcaciones", permiso(entidades.PermisoEmbarcacionesVer), embarcacionController.List)
+			admin.GET("/embarcaciones/:id", permiso(entidades.PermisoEmbarcacionesVer), embarcacionController.GetByID)
+			admin.PUT("/embarcaciones/:id", permiso(entidades.PermisoEmbarcacionesGestionar), embarcacionController.Update)
+			admin.DELETE("/embarcaciones/:id", permiso(entidades.PermisoEmbarcacionesGestionar), embarcacionController.Delete)
+			admin.GET("/embarcaciones/chofer/:idChofer", permiso(entidades.PermisoEmbarcacionesVer), embarcacionController.ListByChofer)
 
 			// Gestión de tipos de tour
-			admin.POST("/tipos-tour", tipoTourController.Create)
-			admin.GET("/tipos-tour", tipoTourController.List)
-			admin.GET("/tipos-tour/:id", tipoTourController.GetByID)
-			admin.PUT("/tipos-tour/:id", tipoTourController.Update)
-			admin.DELETE("/tipos-tour/:id", tipoTourController.Delete)
+			admin.POST("/tipos-tour", permiso(entidades.PermisoTiposTourGestionar), tipoTourController.Create)
+			admin.GET("/tipos-tour", permiso(entidades.PermisoTiposTourVer), tipoTourController.List)
+			admin.GET("/tipos-tour/:id", permiso(entidades.PermisoTiposTourVer), tipoTourController.GetByID)
+			admin.PUT("/tipos-tour/:id", permiso(entidades.PermisoTiposTourGestionar), tipoTourController.Update)
+			admin.DELETE("/tipos-tour/:id", permiso(entidades.PermisoTiposTourGestionar), tipoTourController.Delete)
 
 			// Gestión de horarios de tour
-			admin.POST("/horarios-tour", horarioTourController.Create)
-			admin.GET("/horarios-tour", horarioTourController.List)
-			admin.GET("/horarios-tour/:id", horarioTourController.GetByID)
-			admin.PUT("/horarios-tour/:id", horarioTourController.Update)
-			admin.DELETE("/horarios-tour/:id", horarioTourController.Delete)
-			admin.GET("/horarios-tour/tipo/:idTipoTour", horarioTourController.ListByTipoTour)
-			admin.GET("/horarios-tour/dia/:dia", horarioTourController.ListByDia)
+			admin.POST("/horarios-tour", permiso(entidades.PermisoTiposTourGestionar), horarioTourController.Create)
+			admin.GET("/horarios-tour", permiso(entidades.PermisoTiposTourVer), horarioTourController.List)
+			admin.GET("/horarios-tour/:id", permiso(entidades.PermisoTiposTourVer), horarioTourController.GetByID)
+			admin.PUT("/horarios-tour/:id", permiso(entidades.PermisoTiposTourGestionar), horarioTourController.Update)
+			admin.DELETE("/horarios-tour/:id", permiso(entidades.PermisoTiposTourGestionar), horarioTourController.Delete)
+			admin.GET("/horarios-tour/tipo/:idTipoTour", permiso(entidades.PermisoTiposTourVer), horarioTourController.ListByTipoTour)
+			admin.GET("/horarios-tour/dia/:dia", permiso(entidades.PermisoTiposTourVer), horarioTourController.ListByDia)
 
 			// Gestión de horarios de chofer
-			admin.POST("/horarios-chofer", horarioChoferController.Create)
-			admin.GET("/horarios-chofer", horarioChoferController.List)
-			admin.GET("/horarios-chofer/:id", horarioChoferController.GetByID)
-			admin.PUT("/horarios-chofer/:id", horarioChoferController.Update)
-			admin.DELETE("/horarios-chofer/:id", horarioChoferController.Delete)
-			admin.GET("/horarios-chofer/chofer/:idChofer", horarioChoferController.ListByChofer)
-			admin.GET("/horarios-chofer/chofer/:idChofer/activos", horarioChoferController.ListActiveByChofer)
-			admin.GET("/horarios-chofer/dia/:dia", horarioChoferController.ListByDia)
+			admin.POST("/horarios-chofer", permiso(entidades.PermisoHorariosChoferGestionar), horarioChoferController.Create)
+			admin.GET("/horarios-chofer", permiso(entidades.PermisoHorariosChoferVer), horarioChoferController.List)
+			admin.GET("/horarios-chofer/:id", permiso(entidades.PermisoHorariosChoferVer), horarioChoferController.GetByID)
+			admin.PUT("/horarios-chofer/:id", permiso(entidades.PermisoHorariosChoferGestionar), horarioChoferController.Update)
+			admin.DELETE("/horarios-chofer/:id", permiso(entidades.PermisoHorariosChoferGestionar), horarioChoferController.Delete)
+			admin.GET("/horarios-chofer/chofer/:idChofer", permiso(entidades.PermisoHorariosChoferVer), horarioChoferController.ListByChofer)
+			admin.GET("/horarios-chofer/chofer/:idChofer/activos", permiso(entidades.PermisoHorariosChoferVer), horarioChoferController.ListActiveByChofer)
+			admin.GET("/horarios-chofer/dia/:dia", permiso(entidades.PermisoHorariosChoferVer), horarioChoferController.ListByDia)
 
 			// Gestión de tours programados
-			admin.POST("/tours", tourProgramadoController.Create)
-			admin.GET("/tours", tourProgramadoController.List)
-			admin.GET("/tours/:id", tourProgramadoController.GetByID)
-			admin.PUT("/tours/:id", tourProgramadoController.Update)
-			admin.DELETE("/tours/:id", tourProgramadoController.Delete)
-			admin.POST("/tours/:id/estado", tourProgramadoController.CambiarEstado)
-			admin.GET("/tours/fecha/:fecha", tourProgramadoController.ListByFecha)
-			admin.GET("/tours/rango", tourProgramadoController.ListByRangoFechas)
-			admin.GET("/tours/estado/:estado", tourProgramadoController.ListByEstado)
-			admin.GET("/tours/embarcacion/:idEmbarcacion", tourProgramadoController.ListByEmbarcacion)
-			admin.GET("/tours/chofer/:idChofer", tourProgramadoController.ListByChofer)
-			admin.GET("/tours/tipo/:idTipoTour", tourProgramadoController.ListByTipoTour)
+			admin.POST("/tours", permiso(entidades.PermisoToursProgramar), tourProgramadoController.Create)
+			admin.GET("/tours", permiso(entidades.PermisoToursVer), tourProgramadoController.List)
+			admin.GET("/tours/:id", permiso(entidades.PermisoToursVer), tourProgramadoController.GetByID)
+			admin.PUT("/tours/:id", permiso(entidades.PermisoToursProgramar), tourProgramadoController.Update)
+			admin.DELETE("/tours/:id", permiso(entidades.PermisoToursProgramar), tourProgramadoController.Delete)
+			admin.POST("/tours/:id/estado", permiso(entidades.PermisoToursProgramar), tourProgramadoController.CambiarEstado)
+			admin.GET("/tours/fecha/:fecha", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByFecha)
+			admin.GET("/tours/rango", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByRangoFechas)
+			admin.GET("/tours/estado/:estado", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByEstado)
+			admin.GET("/tours/embarcacion/:idEmbarcacion", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByEmbarcacion)
+			admin.GET("/tours/chofer/:idChofer", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByChofer)
+			admin.GET("/tours/tipo/:idTipoTour", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByTipoTour)
 
 			// Gestión de tipos de pasaje
-			admin.POST("/tipos-pasaje", tipoPasajeController.Create)
-			admin.GET("/tipos-pasaje", tipoPasajeController.List)
-			admin.GET("/tipos-pasaje/:id", tipoPasajeController.GetByID)
-			admin.PUT("/tipos-pasaje/:id", tipoPasajeController.Update)
-			admin.DELETE("/tipos-pasaje/:id", tipoPasajeController.Delete)
+			admin.POST("/tipos-pasaje", permiso(entidades.PermisoTarifasGestionar), tipoPasajeController.Create)
+			admin.GET("/tipos-pasaje", permiso(entidades.PermisoTarifasVer), tipoPasajeController.List)
+			admin.GET("/tipos-pasaje/:id", permiso(entidades.PermisoTarifasVer), tipoPasajeController.GetByID)
+			admin.PUT("/tipos-pasaje/:id", permiso(entidades.PermisoTarifasGestionar), tipoPasajeController.Update)
+			admin.DELETE("/tipos-pasaje/:id", permiso(entidades.PermisoTarifasGestionar), tipoPasajeController.Delete)
+
+			// Gestión de tarifas por tipo de tour
+			admin.POST("/tarifas", permiso(entidades.PermisoTarifasGestionar), tarifaTourController.Create)
+			admin.GET("/tarifas", permiso(entidades.PermisoTarifasVer), tarifaTourController.List)
+			admin.GET("/tarifas/:id", permiso(entidades.PermisoTarifasVer), tarifaTourController.GetByID)
+			admin.PUT("/tarifas/:id", permiso(entidades.PermisoTarifasGestionar), tarifaTourController.Update)
+			admin.DELETE("/tarifas/:id", permiso(entidades.PermisoTarifasGestionar), tarifaTourController.Delete)
+			admin.GET("/tarifas/tipo-tour/:idTipoTour", permiso(entidades.PermisoTarifasVer), tarifaTourController.ListVigentesByTipoTour)
 
 			// Gestión de métodos de pago
-			admin.POST("/metodos-pago", metodoPagoController.Create)
-			admin.GET("/metodos-pago", metodoPagoController.List)
-			admin.GET("/metodos-pago/:id", metodoPagoController.GetByID)
-			admin.PUT("/metodos-pago/:id", metodoPagoController.Update)
-			admin.DELETE("/metodos-pago/:id", metodoPagoController.Delete)
+			admin.POST("/metodos-pago", permiso(entidades.PermisoMetodosPagoGestionar), metodoPagoController.Create)
+			admin.GET("/metodos-pago", permiso(entidades.PermisoMetodosPagoVer), metodoPagoController.List)
+			admin.GET("/metodos-pago/:id", permiso(entidades.PermisoMetodosPagoVer), metodoPagoController.GetByID)
+			admin.PUT("/metodos-pago/:id", permiso(entidades.PermisoMetodosPagoGestionar), metodoPagoController.Update)
+			admin.DELETE("/metodos-pago/:id", permiso(entidades.PermisoMetodosPagoGestionar), metodoPagoController.Delete)
 
 			// Gestión de canales de venta
-			admin.POST("/canales-venta", canalVentaController.Create)
-			admin.GET("/canales-venta", canalVentaController.List)
-			admin.GET("/canales-venta/:id", canalVentaController.GetByID)
-			admin.PUT("/canales-venta/:id", canalVentaController.Update)
-			admin.DELETE("/canales-venta/:id", canalVentaController.Delete)
+			admin.POST("/canales-venta", permiso(entidades.PermisoCanalesVentaGestionar), canalVentaController.Create)
+			admin.GET("/canales-venta", permiso(entidades.PermisoCanalesVentaVer), canalVentaController.List)
+			admin.GET("/canales-venta/:id", permiso(entidades.PermisoCanalesVentaVer), canalVentaController.GetByID)
+			admin.PUT("/canales-venta/:id", permiso(entidades.PermisoCanalesVentaGestionar), canalVentaController.Update)
+			admin.DELETE("/canales-venta/:id", permiso(entidades.PermisoCanalesVentaGestionar), canalVentaController.Delete)
 
 			// Gestión de clientes
-			admin.GET("/clientes", clienteController.List)
-			admin.GET("/clientes/:id", clienteController.GetByID)
-			admin.PUT("/clientes/:id", clienteController.Update)
-			admin.DELETE("/clientes/:id", clienteController.Delete)
+			admin.GET("/clientes", permiso(entidades.PermisoClientesVer), clienteController.List)
+			admin.GET("/clientes/:id", permiso(entidades.PermisoClientesVer), clienteController.GetByID)
+			admin.PUT("/clientes/:id", permiso(entidades.PermisoClientesEditar), clienteController.Update)
+			admin.DELETE("/clientes/:id", permiso(entidades.PermisoClientesEliminar), clienteController.Delete)
 
 			// Gestión de reservas
-			admin.POST("/reservas", reservaController.Create)
-			admin.GET("/reservas", reservaController.List)
-			admin.GET("/reservas/:id", reservaController.GetByID)
-			admin.PUT("/reservas/:id", reservaController.Update)
-			admin.DELETE("/reservas/:id", reservaController.Delete)
-			admin.POST("/reservas/:id/estado", reservaController.CambiarEstado)
-			admin.GET("/reservas/cliente/:idCliente", reservaController.ListByCliente)
-			admin.GET("/reservas/tour/:idTourProgramado", reservaController.ListByTourProgramado)
-			admin.GET("/reservas/fecha/:fecha", reservaController.ListByFecha)
-			admin.GET("/reservas/estado/:estado", reservaController.ListByEstado)
+			admin.POST("/reservas", permiso(entidades.PermisoReservasCrear), reservaController.Create)
+			admin.GET("/reservas", permiso(entidades.PermisoReservasVer), reservaController.List)
+			admin.GET("/reservas/:id", permiso(entidades.PermisoReservasVer), reservaController.GetByID)
+			admin.PUT("/reservas/:id", permiso(entidades.PermisoReservasEditar), reservaController.Update)
+			admin.DELETE("/reservas/:id", permiso(entidades.PermisoReservasEliminar), reservaController.Delete)
+			admin.POST("/reservas/:id/estado", permiso(entidades.PermisoReservasEditar), reservaController.CambiarEstado)
+			admin.POST("/reservas/:id/confirmar", permiso(entidades.PermisoReservasEditar), reservaController.Confirmar)
+			admin.GET("/reservas/:id/historial", permiso(entidades.PermisoReservasVer), reservaController.ListHistorial)
+			admin.GET("/reservas/cliente/:idCliente", permiso(entidades.PermisoReservasVer), reservaController.ListByCliente)
+			admin.GET("/reservas/tour/:idTourProgramado", permiso(entidades.PermisoReservasVer), reservaController.ListByTourProgramado)
+			admin.GET("/reservas/fecha/:fecha", permiso(entidades.PermisoReservasVer), reservaController.ListByFecha)
+			admin.GET("/reservas/estado/:estado", permiso(entidades.PermisoReservasVer), reservaController.ListByEstado)
+			admin.GET("/reservas/:id/ticket", permiso(entidades.PermisoReservasVer), impresionController.TicketReserva)
+			admin.GET("/reservas/tour/:idTourProgramado/manifiesto", permiso(entidades.PermisoManifiestosVer), impresionController.Manifiesto)
+			admin.GET("/reservas/tour/:idTourProgramado/embarques", permiso(entidades.PermisoEmbarquesVer), embarqueController.ListByTourProgramado)
+			admin.GET("/reservas/tour/:idTourProgramado/embarques/contador", permiso(entidades.PermisoEmbarquesVer), embarqueController.Contador)
+
+			// Manifiesto de pasajeros
+			admin.GET("/reservas/:id/pasajeros", permiso(entidades.PermisoReservasVer), pasajeroController.ListByReserva)
+			admin.POST("/reservas/:id/pasajeros", permiso(entidades.PermisoReservasEditar), pasajeroController.Create)
+			admin.GET("/pasajeros/:id", permiso(entidades.PermisoReservasVer), pasajeroController.GetByID)
+			admin.PUT("/pasajeros/:id", permiso(entidades.PermisoReservasEditar), pasajeroController.Update)
+			admin.DELETE("/pasajeros/:id", permiso(entidades.PermisoReservasEditar), pasajeroController.Delete)
+
+			// Gestión de pagos
+			admin.POST("/pagos", permiso(entidades.PermisoPagosCrear), pagoController.Create)
+			admin.GET("/pagos", permiso(entidades.PermisoPagosVer), pagoController.List)
+			admin.GET("/pagos/:id", permiso(entidades.PermisoPagosVer), pagoController.GetByID)
+			admin.POST("/pagos/:id/anular", permiso(entidades.PermisoPagosAnular), pagoController.Anular)
+			admin.GET("/pagos/reserva/:idReserva", permiso(entidades.PermisoPagosVer), pagoController.ListByReserva)
+			admin.GET("/pagos/fecha/:fecha", permiso(entidades.PermisoPagosVer), pagoController.ListByFecha)
+			admin.GET("/pagos/estado/:estado", permiso(entidades.PermisoPagosVer), pagoController.ListByEstado)
+
+			// Gestión de comprobantes de pago
+			admin.POST("/comprobantes", permiso(entidades.PermisoComprobantesEmitir), comprobantePagoController.Create)
+			admin.GET("/comprobantes", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.List)
+			admin.GET("/comprobantes/:id", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.GetByID)
+			admin.POST("/comprobantes/:id/estado", permiso(entidades.PermisoComprobantesEmitir), comprobantePagoController.CambiarEstado)
+			admin.GET("/comprobantes/reserva/:idReserva", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.ListByReserva)
+			admin.GET("/comprobantes/fecha/:fecha", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.ListByFecha)
+
+			// Facturación electrónica (SUNAT)
+			admin.POST("/comprobantes/:id/sunat", permiso(entidades.PermisoComprobantesEmitir), facturacionController.EnviarComprobante)
+			admin.GET("/comprobantes/:id/xml", permiso(entidades.PermisoComprobantesVer), facturacionController.GetXML)
+			admin.GET("/comprobantes/:id/pdf", permiso(entidades.PermisoComprobantesVer), impresionController.ComprobantePDF)
+
+			// Notas de crédito
+			admin.POST("/notas-credito", permiso(entidades.PermisoNotasCreditoEmitir), notaCreditoController.Create)
+			admin.GET("/notas-credito", permiso(entidades.PermisoComprobantesVer), notaCreditoController.List)
+			admin.GET("/notas-credito/:id", permiso(entidades.PermisoComprobantesVer), notaCreditoController.GetByID)
+			admin.GET("/notas-credito/comprobante/:idComprobante", permiso(entidades.PermisoComprobantesVer), notaCreditoController.ListByComprobante)
+			admin.POST("/notas-credito/:id/sunat", permiso(entidades.PermisoNotasCreditoEmitir), facturacionController.EnviarNotaCredito)
+			admin.GET("/notas-credito/:id/xml", permiso(entidades.PermisoComprobantesVer), facturacionController.GetXMLNotaCredito)
+			admin.POST("/sunat/resumenes", permiso(entidades.PermisoSunatGestionar), facturacionController.GenerarResumenDiario)
+			admin.POST("/sunat/bajas", permiso(entidades.PermisoSunatGestionar), facturacionController.GenerarComunicacionBaja)
+			admin.GET("/sunat/resumenes", permiso(entidades.PermisoSunatGestionar), facturacionController.ListResumenes)
+			admin.GET("/sunat/resumenes/:id", permiso(entidades.PermisoSunatGestionar), facturacionController.GetResumenByID)
+			admin.POST("/sunat/resumenes/:id/consultar", permiso(entidades.PermisoSunatGestionar), facturacionController.ConsultarResumen)
 		}
 
-		// Vendedores
+		// Vendedores: se mantienen las rutas que usa el punto de venta, con los mismos permisos que /admin
 		vendedor := protected.Group("/vendedor")
-		vendedor.Use(middleware.RoleMiddleware("ADMIN", "VENDEDOR"))
+		vendedor.Use(middleware.SubjectMiddleware(utils.SujetoUsuario))
 		{
 			// Ver embarcaciones (solo lectura)
-			vendedor.GET("/embarcaciones", embarcacionController.List)
-			vendedor.GET("/embarcaciones/:id", embarcacionController.GetByID)
+			vendedor.GET("/embarcaciones", permiso(entidades.PermisoEmbarcacionesVer), embarcacionController.List)
+			vendedor.GET("/embarcaciones/:id", permiso(entidades.PermisoEmbarcacionesVer), embarcacionController.GetByID)
 
 			// Ver tipos de tour (solo lectura)
-			vendedor.GET("/tipos-tour", tipoTourController.List)
-			vendedor.GET("/tipos-tour/:id", tipoTourController.GetByID)
+			vendedor.GET("/tipos-tour", permiso(entidades.PermisoTiposTourVer), tipoTourController.List)
+			vendedor.GET("/tipos-tour/:id", permiso(entidades.PermisoTiposTourVer), tipoTourController.GetByID)
 
 			// Ver horarios de tour (solo lectura)
-			vendedor.GET("/horarios-tour", horarioTourController.List)
-			vendedor.GET("/horarios-tour/:id", horarioTourController.GetByID)
-			vendedor.GET("/horarios-tour/tipo/:idTipoTour", horarioTourController.ListByTipoTour)
-			vendedor.GET("/horarios-tour/dia/:dia", horarioTourController.ListByDia)
+			vendedor.GET("/horarios-tour", permiso(entidades.PermisoTiposTourVer), horarioTourController.List)
+			vendedor.GET("/horarios-tour/:id", permiso(entidades.PermisoTiposTourVer), horarioTourController.GetByID)
+			vendedor.GET("/horarios-tour/tipo/:idTipoTour", permiso(entidades.PermisoTiposTourVer), horarioTourController.ListByTipoTour)
+			vendedor.GET("/horarios-tour/dia/:dia", permiso(entidades.PermisoTiposTourVer), horarioTourController.ListByDia)
 
 			// Ver horarios de choferes disponibles (solo lectura)
-			vendedor.GET("/horarios-chofer/dia/:dia", horarioChoferController.ListByDia)
+			vendedor.GET("/horarios-chofer/dia/:dia", permiso(entidades.PermisoHorariosChoferVer), horarioChoferController.ListByDia)
 
 			// Ver tours programados (solo lectura)
-			vendedor.GET("/tours", tourProgramadoController.List)
-			vendedor.GET("/tours/:id", tourProgramadoController.GetByID)
-			vendedor.GET("/tours/fecha/:fecha", tourProgramadoController.ListByFecha)
-			vendedor.GET("/tours/rango", tourProgramadoController.ListByRangoFechas)
-			vendedor.GET("/tours/estado/:estado", tourProgramadoController.ListByEstado)
-			vendedor.GET("/tours/disponibles", tourProgramadoController.ListToursProgramadosDisponibles)
+			vendedor.GET("/tours", permiso(entidades.PermisoToursVer), tourProgramadoController.List)
+			vendedor.GET("/tours/:id", permiso(entidades.PermisoToursVer), tourProgramadoController.GetByID)
+			vendedor.POST("/tours/:id/cotizar", permiso(entidades.PermisoToursVer), cotizacionController.Cotizar)
+			vendedor.GET("/tours/fecha/:fecha", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByFecha)
+			vendedor.GET("/tours/rango", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByRangoFechas)
+			vendedor.GET("/tours/estado/:estado", permiso(entidades.PermisoToursVer), tourProgramadoController.ListByEstado)
+			vendedor.GET("/tours/disponibles", permiso(entidades.PermisoToursVer), tourProgramadoController.ListToursProgramadosDisponibles)
 
 			// Ver tipos de pasaje (solo lectura)
-			vendedor.GET("/tipos-pasaje", tipoPasajeController.List)
-			vendedor.GET("/tipos-pasaje/:id", tipoPasajeController.GetByID)
+			vendedor.GET("/tipos-pasaje", permiso(entidades.PermisoTarifasVer), tipoPasajeController.List)
+			vendedor.GET("/tipos-pasaje/:id", permiso(entidades.PermisoTarifasVer), tipoPasajeController.GetByID)
+			vendedor.GET("/tarifas/tipo-tour/:idTipoTour", permiso(entidades.PermisoTarifasVer), tarifaTourController.ListVigentesByTipoTour)
 
 			// Ver métodos de pago (solo lectura)
-			vendedor.GET("/metodos-pago", metodoPagoController.List)
-			vendedor.GET("/metodos-pago/:id", metodoPagoController.GetByID)
+			vendedor.GET("/metodos-pago", permiso(entidades.PermisoMetodosPagoVer), metodoPagoController.List)
+			vendedor.GET("/metodos-pago/:id", permiso(entidades.PermisoMetodosPagoVer), metodoPagoController.GetByID)
 
 			// Ver canales de venta (solo lectura)
-			vendedor.GET("/canales-venta", canalVentaController.List)
-			vendedor.GET("/canales-venta/:id", canalVentaController.GetByID)
+			vendedor.GET("/canales-venta", permiso(entidades.PermisoCanalesVentaVer), canalVentaController.List)
+			vendedor.GET("/canales-venta/:id", permiso(entidades.PermisoCanalesVentaVer), canalVentaController.GetByID)
 
 			// Gestión de clientes
-			vendedor.POST("/clientes", clienteController.Create)
-			vendedor.GET("/clientes", clienteController.List)
-			vendedor.GET("/clientes/:id", clienteController.GetByID)
-			vendedor.PUT("/clientes/:id", clienteController.Update)
+			vendedor.POST("/clientes", permiso(entidades.PermisoClientesEditar), clienteController.Create)
+			vendedor.GET("/clientes", permiso(entidades.PermisoClientesVer), clienteController.List)
+			vendedor.GET("/clientes/:id", permiso(entidades.PermisoClientesVer), clienteController.GetByID)
+			vendedor.PUT("/clientes/:id", permiso(entidades.PermisoClientesEditar), clienteController.Update)
 
 			// Gestión de reservas
-			vendedor.POST("/reservas", reservaController.Create)
-			vendedor.GET("/reservas", reservaController.List)
-			vendedor.GET("/reservas/:id", reservaController.GetByID)
-			vendedor.PUT("/reservas/:id", reservaController.Update)
-			vendedor.POST("/reservas/:id/estado", reservaController.CambiarEstado)
-			vendedor.GET("/reservas/cliente/:idCliente", reservaController.ListByCliente)
-			vendedor.GET("/reservas/tour/:idTourProgramado", reservaController.ListByTourProgramado)
-			vendedor.GET("/reservas/fecha/:fecha", reservaController.ListByFecha)
-			vendedor.GET("/reservas/estado/:estado", reservaController.ListByEstado)
+			vendedor.POST("/reservas", permiso(entidades.PermisoReservasCrear), reservaController.Create)
+			vendedor.GET("/reservas", permiso(entidades.PermisoReservasVer), reservaController.List)
+			vendedor.GET("/reservas/:id", permiso(entidades.PermisoReservasVer), reservaController.GetByID)
+			vendedor.PUT("/reservas/:id", permiso(entidades.PermisoReservasEditar), reservaController.Update)
+			vendedor.POST("/reservas/:id/estado", permiso(entidades.PermisoReservasEditar), reservaController.CambiarEstado)
+			vendedor.POST("/reservas/:id/confirmar", permiso(entidades.PermisoReservasEditar), reservaController.Confirmar)
+			vendedor.GET("/reservas/:id/historial", permiso(entidades.PermisoReservasVer), reservaController.ListHistorial)
+			vendedor.GET("/reservas/cliente/:idCliente", permiso(entidades.PermisoReservasVer), reservaController.ListByCliente)
+			vendedor.GET("/reservas/tour/:idTourProgramado", permiso(entidades.PermisoReservasVer), reservaController.ListByTourProgramado)
+			vendedor.GET("/reservas/fecha/:fecha", permiso(entidades.PermisoReservasVer), reservaController.ListByFecha)
+			vendedor.GET("/reservas/estado/:estado", permiso(entidades.PermisoReservasVer), reservaController.ListByEstado)
+			vendedor.GET("/reservas/:id/ticket", permiso(entidades.PermisoReservasVer), impresionController.TicketReserva)
+
+			// Manifiesto de pasajeros
+			vendedor.GET("/reservas/:id/pasajeros", permiso(entidades.PermisoReservasVer), pasajeroController.ListByReserva)
+			vendedor.POST("/reservas/:id/pasajeros", permiso(entidades.PermisoReservasEditar), pasajeroController.Create)
+			vendedor.GET("/pasajeros/:id", permiso(entidades.PermisoReservasVer), pasajeroController.GetByID)
+			vendedor.PUT("/pasajeros/:id", permiso(entidades.PermisoReservasEditar), pasajeroController.Update)
+			vendedor.DELETE("/pasajeros/:id", permiso(entidades.PermisoReservasEditar), pasajeroController.Delete)
+
+			// Gestión de pagos
+			vendedor.POST("/pagos", permiso(entidades.PermisoPagosCrear), pagoController.Create)
+			vendedor.GET("/pagos", permiso(entidades.PermisoPagosVer), pagoController.List)
+			vendedor.GET("/pagos/:id", permiso(entidades.PermisoPagosVer), pagoController.GetByID)
+			vendedor.POST("/pagos/:id/anular", permiso(entidades.PermisoPagosAnular), pagoController.Anular)
+			vendedor.GET("/pagos/reserva/:idReserva", permiso(entidades.PermisoPagosVer), pagoController.ListByReserva)
+			vendedor.GET("/pagos/fecha/:fecha", permiso(entidades.PermisoPagosVer), pagoController.ListByFecha)
+			vendedor.GET("/pagos/estado/:estado", permiso(entidades.PermisoPagosVer), pagoController.ListByEstado)
+
+			// Gestión de comprobantes de pago
+			vendedor.POST("/comprobantes", permiso(entidades.PermisoComprobantesEmitir), comprobantePagoController.Create)
+			vendedor.GET("/comprobantes", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.List)
+			vendedor.GET("/comprobantes/:id", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.GetByID)
+			vendedor.POST("/comprobantes/:id/estado", permiso(entidades.PermisoComprobantesEmitir), comprobantePagoController.CambiarEstado)
+			vendedor.GET("/comprobantes/reserva/:idReserva", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.ListByReserva)
+			vendedor.GET("/comprobantes/fecha/:fecha", permiso(entidades.PermisoComprobantesVer), comprobantePagoController.ListByFecha)
+			vendedor.POST("/comprobantes/:id/sunat", permiso(entidades.PermisoComprobantesEmitir), facturacionController.EnviarComprobante)
+			vendedor.GET("/comprobantes/:id/xml", permiso(entidades.PermisoComprobantesVer), facturacionController.GetXML)
+			vendedor.GET("/comprobantes/:id/pdf", permiso(entidades.PermisoComprobantesVer), impresionController.ComprobantePDF)
+
+			// Notas de crédito
+			vendedor.POST("/notas-credito", permiso(entidades.PermisoNotasCreditoEmitir), notaCreditoController.Create)
+			vendedor.GET("/notas-credito", permiso(entidades.PermisoComprobantesVer), notaCreditoController.List)
+			vendedor.GET("/notas-credito/:id", permiso(entidades.PermisoComprobantesVer), notaCreditoController.GetByID)
+			vendedor.GET("/notas-credito/comprobante/:idComprobante", permiso(entidades.PermisoComprobantesVer), notaCreditoController.ListByComprobante)
+			vendedor.POST("/notas-credito/:id/sunat", permiso(entidades.PermisoNotasCreditoEmitir), facturacionController.EnviarNotaCredito)
+			vendedor.GET("/notas-credito/:id/xml", permiso(entidades.PermisoComprobantesVer), facturacionController.GetXMLNotaCredito)
 		}
 
-		// Choferes
+		// Choferes: rutas limitadas a los tours asignados al propio usuario, sea cual sea su rol
 		chofer := protected.Group("/chofer")
-		chofer.Use(middleware.RoleMiddleware("ADMIN", "CHOFER"))
+		chofer.Use(middleware.SubjectMiddleware(utils.SujetoUsuario), middleware.SoloAsignados())
 		{
 			// Ver embarcaciones asignadas
-			chofer.GET("/mis-embarcaciones", func(ctx *gin.Context) {
-				// Obtener ID del usuario autenticado del contexto
-				userID := ctx.GetInt("userID") // CORREGIDO: user_id -> userID
-				// Redirigir a la ruta que obtiene embarcaciones por chofer
-				ctx.Request.URL.Path = "/api/v1/admin/embarcaciones/chofer/" + strconv.Itoa(userID)
-				router.HandleContext(ctx)
+			chofer.GET("/mis-embarcaciones", permiso(entidades.PermisoToursAsignados), func(ctx *gin.Context) {
+				// Obtener usuario autenticado del contexto
+				principal, _ := middleware.GetPrincipal(ctx)
+				// Usar el ID del usuario autenticado como chofer
+				ctx.Params = append(ctx.Params, gin.Param{Key: "idChofer", Value: strconv.Itoa(principal.ID)})
+				embarcacionController.ListByChofer(ctx)
 			})
 
 			// Ver tipos de tour (solo lectura)
-			chofer.GET("/tipos-tour", tipoTourController.List)
+			chofer.GET("/tipos-tour", permiso(entidades.PermisoTiposTourVer), tipoTourController.List)
 
 			// Ver horarios de tour (solo lectura)
-			chofer.GET("/horarios-tour", horarioTourController.List)
-			chofer.GET("/horarios-tour/dia/:dia", horarioTourController.ListByDia)
+			chofer.GET("/horarios-tour", permiso(entidades.PermisoTiposTourVer), horarioTourController.List)
+			chofer.GET("/horarios-tour/dia/:dia", permiso(entidades.PermisoTiposTourVer), horarioTourController.ListByDia)
 
 			// Ver mis horarios de trabajo
-			chofer.GET("/mis-horarios", horarioChoferController.GetMyActiveHorarios)
-			chofer.GET("/todos-mis-horarios", func(ctx *gin.Context) {
-				// Obtener ID del usuario autenticado del contexto
-				userID := ctx.GetInt("userID") // CORREGIDO: user_id -> userID
-				// Redirigir a la ruta que obtiene horarios por chofer
-				ctx.Request.URL.Path = "/api/v1/admin/horarios-chofer/chofer/" + strconv.Itoa(userID)
-				router.HandleContext(ctx)
+			chofer.GET("/mis-horarios", permiso(entidades.PermisoToursAsignados), horarioChoferController.GetMyActiveHorarios)
+			chofer.GET("/todos-mis-horarios", permiso(entidades.PermisoToursAsignados), func(ctx *gin.Context) {
+				// Obtener usuario autenticado del contexto
+				principal, _ := middleware.GetPrincipal(ctx)
+				// Usar el ID del usuario autenticado como chofer
+				ctx.Params = append(ctx.Params, gin.Param{Key: "idChofer", Value: strconv.Itoa(principal.ID)})
+				horarioChoferController.ListByChofer(ctx)
 			})
 
 			// Ver mis tours programados
-			chofer.GET("/mis-tours", func(ctx *gin.Context) {
-				// Obtener ID del usuario autenticado del contexto
-				userID := ctx.GetInt("userID") // CORREGIDO: user_id -> userID
-				// Redirigir a la ruta que obtiene tours por chofer
-				ctx.Request.URL.Path = "/api/v1/admin/tours/chofer/" + strconv.Itoa(userID)
-				router.HandleContext(ctx)
+			chofer.GET("/mis-tours", permiso(entidades.PermisoToursAsignados), func(ctx *gin.Context) {
+				// Obtener usuario autenticado del contexto
+				principal, _ := middleware.GetPrincipal(ctx)
+				// Usar el ID del usuario autenticado como chofer
+				ctx.Params = append(ctx.Params, gin.Param{Key: "idChofer", Value: strconv.Itoa(principal.ID)})
+				tourProgramadoController.ListByChofer(ctx)
 			})
 
 			// Ver reservas para mis tours
-			chofer.GET("/mis-tours/:idTourProgramado/reservas", reservaController.ListByTourProgramado)
+			chofer.GET("/mis-tours/:idTourProgramado/reservas", permiso(entidades.PermisoToursAsignados), reservaController.ListByTourProgramado)
+
+			// Manifiesto de pasajeros para la Capitanía de Puerto
+			chofer.GET("/mis-tours/:idTourProgramado/manifiesto", permiso(entidades.PermisoToursAsignados), impresionController.Manifiesto)
+
+			// Embarque con el código QR del ticket
+			chofer.POST("/mis-tours/:idTourProgramado/embarques", permiso(entidades.PermisoEmbarquesRegistrar), embarqueController.Registrar)
+			chofer.GET("/mis-tours/:idTourProgramado/embarques", permiso(entidades.PermisoToursAsignados), embarqueController.ListByTourProgramado)
+			chofer.GET("/mis-tours/:idTourProgramado/embarques/contador", permiso(entidades.PermisoToursAsignados), embarqueController.Contador)
 		}
 
-		// Clientes
+		// Clientes: solo los clientes autenticados, nunca el personal
 		cliente := protected.Group("/cliente")
-		cliente.Use(middleware.RoleMiddleware("ADMIN", "CLIENTE"))
+		cliente.Use(middleware.SubjectMiddleware(utils.SujetoCliente))
 		{
 			// Ver tipos de tour disponibles (solo lectura)
 			cliente.GET("/tipos-tour", tipoTourController.List)
@@ -823,6 +1018,7 @@ func SetupRoutes(
 			cliente.GET("/tours/disponibles", tourProgramadoController.ListToursProgramadosDisponibles)
 			cliente.GET("/tours/disponibilidad/:fecha", tourProgramadoController.GetDisponibilidadDia)
 			cliente.GET("/tours/:id", tourProgramadoController.GetByID)
+			cliente.POST("/tours/:id/cotizar", cotizacionController.Cotizar)
 
 			// Ver tipos de pasaje (solo lectura)
 			cliente.GET("/tipos-pasaje", tipoPasajeController.List)
@@ -835,26 +1031,39 @@ func SetupRoutes(
 
 			// Gestión del perfil propio
 			cliente.GET("/mi-perfil", func(ctx *gin.Context) {
-				// Obtener ID del cliente autenticado del contexto
-				clienteID := ctx.GetInt("userID") // CORREGIDO: user_id -> userID
+				// Obtener cliente autenticado del contexto
+				principal, _ := middleware.GetPrincipal(ctx)
 				// Redireccionar a la ruta que obtiene un cliente por ID
-				ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: strconv.Itoa(clienteID)})
+				ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: strconv.Itoa(principal.ID)})
 				clienteController.GetByID(ctx)
 			})
 
 			cliente.PUT("/mi-perfil", func(ctx *gin.Context) {
-				// Obtener ID del cliente autenticado del contexto
-				clienteID := ctx.GetInt("userID") // CORREGIDO: user_id -> userID
+				// Obtener cliente autenticado del contexto
+				principal, _ := middleware.GetPrincipal(ctx)
 				// Establecer el parámetro ID en el contexto
-				ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: strconv.Itoa(clienteID)})
+				ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: strconv.Itoa(principal.ID)})
 				clienteController.Update(ctx)
 			})
 
+			// Sesión del cliente
+			cliente.POST("/change-password", authController.ChangePasswordCliente)
+			cliente.POST("/logout", authController.LogoutCliente)
+			cliente.POST("/logout-all", authController.LogoutTodos)
+
 			// Gestión de mis reservas
 			cliente.POST("/reservas", reservaController.Create)
 			cliente.GET("/mis-reservas", reservaController.ListMyReservas)
 			cliente.GET("/reservas/:id", reservaController.GetByID)
 			cliente.POST("/reservas/:id/estado", reservaController.CambiarEstado) // Solo para cancelar
+			cliente.POST("/reservas/:id/confirmar", reservaController.Confirmar)
+
+			// Pasajeros de mis reservas
+			cliente.GET("/reservas/:id/pasajeros", pasajeroController.ListByReserva)
+			cliente.POST("/reservas/:id/pasajeros", pasajeroController.Create)
+			cliente.GET("/pasajeros/:id", pasajeroController.GetByID)
+			cliente.PUT("/pasajeros/:id", pasajeroController.Update)
+			cliente.DELETE("/pasajeros/:id", pasajeroController.Delete)
 		}
 	}
 }
@@ -0,0 +1,200 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/utils"
+	"time"
+)
+
+// ErrAPIKeyInvalida indica que la llave no existe, fue revocada o expiró
+var ErrAPIKeyInvalida = errors.New("la llave de API no es válida")
+
+// alcancesAPIKey son los permisos que se pueden otorgar a una llave de API:
+// consultar disponibilidad y tarifas, y registrar y seguir las reservas de la agencia
+var alcancesAPIKey = map[string]bool{
+	entidades.PermisoToursVer:       true,
+	entidades.PermisoTarifasVer:     true,
+	entidades.PermisoReservasVer:    true,
+	entidades.PermisoReservasCrear:  true,
+	entidades.PermisoReservasEditar: true,
+}
+
+// prefijoAPIKey identifica las llaves del sistema, por ejemplo en un escaneo de secretos filtrados
+const prefijoAPIKey = "stk_"
+
+// limiteUsoAPIKey es la cantidad de peticiones que devuelve la consulta de uso de una llave
+const limiteUsoAPIKey = 100
+
+// APIKeyService maneja las llaves de API de las agencias y OTAs
+type APIKeyService struct {
+	db             *sql.DB
+	apiKeyRepo     *repositorios.APIKeyRepository
+	usoAPIKeyRepo  *repositorios.UsoAPIKeyRepository
+	canalVentaRepo *repositorios.CanalVentaRepository
+	config         *config.Config
+}
+
+// NewAPIKeyService crea una nueva instancia de APIKeyService
+func NewAPIKeyService(
+	db *sql.DB,
+	apiKeyRepo *repositorios.APIKeyRepository,
+	usoAPIKeyRepo *repositorios.UsoAPIKeyRepository,
+	canalVentaRepo *repositorios.CanalVentaRepository,
+	config *config.Config,
+) *APIKeyService {
+	return &APIKeyService{
+		db:             db,
+		apiKeyRepo:     apiKeyRepo,
+		usoAPIKeyRepo:  usoAPIKeyRepo,
+		canalVentaRepo: canalVentaRepo,
+		config:         config,
+	}
+}
+
+// Create emite una llave para el canal de venta de una agencia.
+// La llave en claro solo se devuelve en esta respuesta; después solo se conoce su prefijo.
+func (s *APIKeyService) Create(req *entidades.NuevaAPIKeyRequest, idUsuario int) (*entidades.APIKeyCreadaResponse, error) {
+	for _, alcance := range req.Alcances {
+		if !alcancesAPIKey[alcance] {
+			return nil, fmt.Errorf("alcance no permitido para una llave de API: %s", alcance)
+		}
+	}
+
+	if req.FechaExpiracion != nil && !req.FechaExpiracion.After(time.Now()) {
+		return nil, errors.New("la fecha de expiración debe ser futura")
+	}
+
+	if _, err := s.canalVentaRepo.GetByID(req.IDCanal); err != nil {
+		return nil, errors.New("el canal de venta especificado no existe")
+	}
+
+	secreto, err := utils.GenerarTokenAleatorio(32)
+	if err != nil {
+		return nil, err
+	}
+	clave := prefijoAPIKey + secreto
+
+	apiKey := &entidades.APIKey{
+		Nombre:           req.Nombre,
+		Prefijo:          clave[:len(prefijoAPIKey)+8],
+		ClaveHash:        utils.HashToken(clave),
+		IDCanal:          req.IDCanal,
+		Alcances:         req.Alcances,
+		LimiteMinuto:     req.LimiteMinuto,
+		FechaExpiracion:  req.FechaExpiracion,
+		IDUsuarioCreador: &idUsuario,
+	}
+	if apiKey.LimiteMinuto == 0 {
+		apiKey.LimiteMinuto = s.config.APIKeyLimiteMinuto
+	}
+
+	var id int
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		id, err = s.apiKeyRepo.WithTx(tx).Create(apiKey)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	creada, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entidades.APIKeyCreadaResponse{APIKey: creada, Clave: clave}, nil
+}
+
+// GetByID obtiene una llave con sus alcances
+func (s *APIKeyService) GetByID(id int) (*entidades.APIKey, error) {
+	return s.apiKeyRepo.GetByID(id)
+}
+
+// List lista todas las llaves
+func (s *APIKeyService) List() ([]*entidades.APIKey, error) {
+	return s.apiKeyRepo.List()
+}
+
+// Revocar deja sin efecto una llave de inmediato
+func (s *APIKeyService) Revocar(id int) error {
+	revocada, err := s.apiKeyRepo.Revocar(id)
+	if err != nil {
+		return err
+	}
+	if !revocada {
+		return errors.New("llave de API no encontrada o ya revocada")
+	}
+	return nil
+}
+
+// ListUso lista las peticiones más recientes hechas con una llave
+func (s *APIKeyService) ListUso(id int) ([]*entidades.UsoAPIKey, error) {
+	if _, err := s.apiKeyRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.usoAPIKeyRepo.ListByAPIKey(id, limiteUsoAPIKey)
+}
+
+// Autenticar obtiene la llave vigente que corresponde al valor recibido en X-API-Key.
+// Lo usa el middleware APIKeyMiddleware en cada petición de una agencia.
+func (s *APIKeyService) Autenticar(clave string) (*entidades.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByHash(utils.HashToken(clave))
+	if err != nil || !apiKey.Vigente(time.Now()) {
+		return nil, ErrAPIKeyInvalida
+	}
+	return apiKey, nil
+}
+
+// ReservarPeticion registra una petición de la llave antes de atenderla si aún no alcanzó su límite por minuto.
+// La fila de la llave queda bloqueada mientras se cuenta, así que las peticiones simultáneas no superan el límite.
+// Devuelve false sin registrar nada si el límite ya se alcanzó.
+func (s *APIKeyService) ReservarPeticion(uso *entidades.UsoAPIKey, limite int) (bool, error) {
+	reservada := false
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		if err := s.apiKeyRepo.WithTx(tx).Bloquear(uso.IDAPIKey); err != nil {
+			return err
+		}
+
+		usoAPIKeyRepo := s.usoAPIKeyRepo.WithTx(tx)
+		usadas, err := usoAPIKeyRepo.CountRecientes(uso.IDAPIKey, time.Minute)
+		if err != nil {
+			return err
+		}
+		if usadas >= limite {
+			return nil
+		}
+
+		uso.ID, err = usoAPIKeyRepo.Create(uso)
+		if err != nil {
+			return err
+		}
+		reservada = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return reservada, nil
+}
+
+// RegistrarUso guarda el resultado de una petición hecha con una llave: completa la reservada con
+// ReservarPeticion o inserta la que se rechazó sin reservar. Un error al registrar no afecta la respuesta.
+func (s *APIKeyService) RegistrarUso(uso *entidades.UsoAPIKey) {
+	var err error
+	if uso.ID != 0 {
+		err = s.usoAPIKeyRepo.UpdateResultado(uso)
+	} else {
+		_, err = s.usoAPIKeyRepo.Create(uso)
+	}
+	if err != nil {
+		log.Printf("Error al registrar el uso de la llave de API %d: %v", uso.IDAPIKey, err)
+	}
+}
@@ -1,7 +1,10 @@
 package servicios
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"log"
 	"sistema-tours/internal/config"
 	"sistema-tours/internal/entidades"
 	"sistema-tours/internal/repositorios"
@@ -9,148 +12,198 @@ import (
 	"time"
 )
 
+// ErrRefreshTokenReutilizado indica que se presentó un refresh token ya rotado.
+// Se trata como un posible robo: se revoca toda la familia y el sujeto debe iniciar sesión de nuevo.
+var ErrRefreshTokenReutilizado = errors.New("el refresh token ya fue utilizado; se cerraron las sesiones asociadas")
+
 // AuthService maneja la lógica de autenticación
 type AuthService struct {
-	usuarioRepo *repositorios.UsuarioRepository
-	config      *config.Config
+	db                *sql.DB
+	usuarioRepo       *repositorios.UsuarioRepository
+	clienteRepo       *repositorios.ClienteRepository
+	refreshTokenRepo  *repositorios.RefreshTokenRepository
+	tokenRevocadoRepo *repositorios.TokenRevocadoRepository
+	proteccionLogin   *ProteccionLoginService
+	dobleFactor       *DobleFactorService
+	config            *config.Config
 }
 
 // NewAuthService crea una nueva instancia de AuthService
-func NewAuthService(usuarioRepo *repositorios.UsuarioRepository, config *config.Config) *AuthService {
+func NewAuthService(
+	db *sql.DB,
+	usuarioRepo *repositorios.UsuarioRepository,
+	clienteRepo *repositorios.ClienteRepository,
+	refreshTokenRepo *repositorios.RefreshTokenRepository,
+	tokenRevocadoRepo *repositorios.TokenRevocadoRepository,
+	proteccionLogin *ProteccionLoginService,
+	dobleFactor *DobleFactorService,
+	config *config.Config,
+) *AuthService {
 	return &AuthService{
-		usuarioRepo: usuarioRepo,
-		config:      config,
+		db:                db,
+		usuarioRepo:       usuarioRepo,
+		clienteRepo:       clienteRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		tokenRevocadoRepo: tokenRevocadoRepo,
+		proteccionLogin:   proteccionLogin,
+		dobleFactor:       dobleFactor,
+		config:            config,
 	}
 }
 
-// Login autentica a un usuario y genera tokens JWT
-func (s *AuthService) Login(loginReq *entidades.LoginRequest) (*entidades.LoginResponse, error) {
-	// SOLO PARA DESARROLLO: Usuario hardcodeado para admin
-	if loginReq.Correo == "admin@sistema-tours.com" && loginReq.Contrasena == "admin123" {
-		// Intentar obtener el usuario de la BD para tener todos los datos
-		usuario, err := s.usuarioRepo.GetByEmail(loginReq.Correo)
-		if err != nil {
-			// Si no podemos obtenerlo, creamos uno temporal
-			usuario = &entidades.Usuario{
-				ID:              1,
-				Nombres:         "Admin",
-				Apellidos:       "Sistema",
-				Correo:          "admin@sistema-tours.com",
-				Telefono:        "123456789",
-				Direccion:       "Dirección Admin",
-				FechaNacimiento: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
-				Rol:             "ADMIN",
-				Nacionalidad:    "Peruana",
-				TipoDocumento:   "DNI",
-				NumeroDocumento: "12345678",
-				FechaRegistro:   time.Now(),
-				Estado:          true,
-			}
-		}
-
-		// Generar token JWT
-		token, err := utils.GenerateJWT(usuario, s.config)
-		if err != nil {
-			return nil, err
-		}
-
-		// Generar refresh token
-		refreshToken, err := utils.GenerateRefreshToken(usuario, s.config)
-		if err != nil {
-			return nil, err
-		}
-
-		// Ocultar contraseña hash
-		usuario.Contrasena = ""
-
-		// Crear respuesta
-		loginResp := &entidades.LoginResponse{
-			Token:        token,
-			RefreshToken: refreshToken,
-			Usuario:      usuario,
-		}
-
-		return loginResp, nil
+// Login autentica a un usuario y genera tokens JWT.
+// Los intentos se limitan por cuenta y por IP, y cada uno queda en la auditoría.
+// Si la cuenta usa doble factor (o su rol lo exige), solo devuelve un token de desafío.
+func (s *AuthService) Login(loginReq *entidades.LoginRequest, ip string) (*entidades.LoginResponse, error) {
+	// Verificar que la cuenta y la IP no estén bloqueadas
+	if err := s.proteccionLogin.Verificar(utils.SujetoUsuario, loginReq.Correo, ip); err != nil {
+		s.proteccionLogin.Auditar(utils.SujetoUsuario, loginReq.Correo, ip, nil, false, MotivoLoginBloqueado)
+		return nil, err
 	}
 
-	// Código original para otros usuarios
 	// Buscar usuario por correo
 	usuario, err := s.usuarioRepo.GetByEmail(loginReq.Correo)
 	if err != nil {
+		// Comparar igual la contraseña para no revelar por el tiempo de respuesta que el correo no existe
+		utils.SimularCheckPassword(loginReq.Contrasena)
+		s.proteccionLogin.RegistrarFallo(utils.SujetoUsuario, loginReq.Correo, ip, nil, MotivoLoginCredenciales)
+		return nil, errors.New("credenciales inválidas")
+	}
+
+	// Verificar contraseña
+	if !utils.CheckPasswordHash(loginReq.Contrasena, usuario.Contrasena) {
+		s.proteccionLogin.RegistrarFallo(utils.SujetoUsuario, loginReq.Correo, ip, &usuario.ID, MotivoLoginCredenciales)
 		return nil, errors.New("credenciales inválidas")
 	}
 
-	// Verificar si el usuario está activo
+	// Verificar si el usuario está activo (después de la contraseña para no revelar qué cuentas existen)
 	if !usuario.Estado {
+		s.proteccionLogin.Auditar(utils.SujetoUsuario, loginReq.Correo, ip, &usuario.ID, false, MotivoLoginDesactivado)
 		return nil, errors.New("usuario desactivado")
 	}
 
-	// Verificar contraseña
-	if !utils.CheckPasswordHash(loginReq.Contrasena, usuario.Contrasena) {
-		return nil, errors.New("credenciales inválidas")
+	// Segundo factor: no se emiten tokens hasta verificar el código
+	if desafio, err := s.desafioDobleFactor(usuario); err != nil || desafio != nil {
+		return desafio, err
 	}
 
-	// Generar token JWT
-	token, err := utils.GenerateJWT(usuario, s.config)
+	s.proteccionLogin.RegistrarExito(utils.SujetoUsuario, loginReq.Correo, ip, usuario.ID)
+
+	// Ocultar contraseña hash
+	usuario.Contrasena = ""
+
+	// Generar tokens en una sesión nueva
+	return s.sesionUsuario(s.refreshTokenRepo, usuario, "")
+}
+
+// VerificarDobleFactor completa el login de una cuenta con doble factor usando el token de desafío
+// y un código TOTP o de recuperación. Los códigos incorrectos cuentan como fallos de inicio de sesión.
+func (s *AuthService) VerificarDobleFactor(req *entidades.VerificarDobleFactorRequest, ip string) (*entidades.LoginResponse, error) {
+	usuario, err := s.usuarioDesafio(req.TokenDesafio, utils.DesafioVerificar, ip)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generar refresh token
-	refreshToken, err := utils.GenerateRefreshToken(usuario, s.config)
-	if err != nil {
+	// Verificar código
+	if err := s.dobleFactor.Verificar(usuario.ID, req.Codigo); err != nil {
+		if errors.Is(err, ErrCodigoDobleFactorInvalido) {
+			s.proteccionLogin.RegistrarFallo(utils.SujetoUsuario, usuario.Correo, ip, &usuario.ID, MotivoLoginDobleFactor)
+		}
 		return nil, err
 	}
 
-	// Ocultar contraseña hash
-	usuario.Contrasena = ""
+	s.proteccionLogin.RegistrarExito(utils.SujetoUsuario, usuario.Correo, ip, usuario.ID)
 
-	// Crear respuesta
-	loginResp := &entidades.LoginResponse{
-		Token:        token,
-		RefreshToken: refreshToken,
-		Usuario:      usuario,
+	// Generar tokens en una sesión nueva
+	return s.sesionUsuario(s.refreshTokenRepo, usuario, "")
+}
+
+// EnrolarDobleFactorDesafio inicia el enrolamiento obligatorio de una cuenta que aún no tiene doble factor,
+// usando el token de desafío del login en lugar de un token de acceso
+func (s *AuthService) EnrolarDobleFactorDesafio(tokenDesafio, ip string) (*entidades.EnrolamientoDobleFactorResponse, error) {
+	usuario, err := s.usuarioDesafio(tokenDesafio, utils.DesafioEnrolar, ip)
+	if err != nil {
+		return nil, err
 	}
 
-	return loginResp, nil
+	return s.dobleFactor.Enrolar(usuario.ID)
 }
 
-// RefreshToken regenera el token de acceso usando un refresh token
-func (s *AuthService) RefreshToken(refreshToken string) (*entidades.LoginResponse, error) {
-	// Validar refresh token
-	claims, err := utils.ValidateRefreshToken(refreshToken, s.config)
+// ActivarDobleFactorDesafio confirma el enrolamiento obligatorio con el primer código y completa el login.
+// La respuesta incluye los códigos de recuperación, que no vuelven a mostrarse.
+func (s *AuthService) ActivarDobleFactorDesafio(req *entidades.VerificarDobleFactorRequest, ip string) (*entidades.LoginResponse, error) {
+	usuario, err := s.usuarioDesafio(req.TokenDesafio, utils.DesafioEnrolar, ip)
 	if err != nil {
 		return nil, err
 	}
 
-	// Obtener usuario
-	usuario, err := s.usuarioRepo.GetByID(claims.UserID)
+	// Activar con el primer código
+	codigos, err := s.dobleFactor.Activar(usuario.ID, req.Codigo)
 	if err != nil {
+		if errors.Is(err, ErrCodigoDobleFactorInvalido) {
+			s.proteccionLogin.RegistrarFallo(utils.SujetoUsuario, usuario.Correo, ip, &usuario.ID, MotivoLoginDobleFactor)
+		}
 		return nil, err
 	}
 
-	// Verificar si el usuario está activo
-	if !usuario.Estado {
-		return nil, errors.New("usuario desactivado")
-	}
+	s.proteccionLogin.RegistrarExito(utils.SujetoUsuario, usuario.Correo, ip, usuario.ID)
 
-	// Generar nuevo token JWT
-	newToken, err := utils.GenerateJWT(usuario, s.config)
+	// Generar tokens en una sesión nueva
+	loginResp, err := s.sesionUsuario(s.refreshTokenRepo, usuario, "")
 	if err != nil {
 		return nil, err
 	}
+	loginResp.CodigosRecuperacion = codigos
+	return loginResp, nil
+}
 
-	// Generar nuevo refresh token
-	newRefreshToken, err := utils.GenerateRefreshToken(usuario, s.config)
+// RefreshToken regenera el token de acceso usando un refresh token
+func (s *AuthService) RefreshToken(refreshToken string) (*entidades.LoginResponse, error) {
+	// Validar refresh token
+	claims, err := utils.ValidateRefreshToken(refreshToken, s.config)
 	if err != nil {
 		return nil, err
 	}
 
-	// Crear respuesta
-	loginResp := &entidades.LoginResponse{
-		Token:        newToken,
-		RefreshToken: newRefreshToken,
-		Usuario:      usuario,
+	// Un refresh token de cliente no puede renovar la sesión de un usuario del sistema
+	if claims.TipoSujeto != utils.SujetoUsuario {
+		return nil, errors.New("el token no pertenece a un usuario del sistema")
+	}
+
+	// Rotar el refresh token y emitir los nuevos dentro de la misma transacción
+	var loginResp *entidades.LoginResponse
+	reutilizado := false
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		familia, usado, err := s.consumirRefreshToken(tx, refreshToken, claims)
+		if err != nil {
+			return err
+		}
+		if usado {
+			// Confirmar la revocación de la familia antes de rechazar el token
+			reutilizado = true
+			return nil
+		}
+
+		// Obtener usuario
+		usuario, err := s.usuarioRepo.WithTx(tx).GetByID(claims.UserID)
+		if err != nil {
+			return err
+		}
+
+		// Verificar si el usuario está activo
+		if !usuario.Estado {
+			return errors.New("usuario desactivado")
+		}
+
+		// Generar nuevos tokens en la misma familia
+		loginResp, err = s.sesionUsuario(s.refreshTokenRepo.WithTx(tx), usuario, familia)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reutilizado {
+		return nil, ErrRefreshTokenReutilizado
 	}
 
 	return loginResp, nil
@@ -184,3 +237,389 @@ func (s *AuthService) ChangePassword(userID int, currentPassword, newPassword st
 	// Actualizar contraseña
 	return s.usuarioRepo.UpdatePassword(userID, hashedPassword)
 }
+
+// LoginCliente autentica a un cliente y genera sus tokens JWT.
+// Los intentos se limitan por cuenta y por IP, y cada uno queda en la auditoría.
+func (s *AuthService) LoginCliente(loginReq *entidades.LoginClienteRequest, ip string) (*entidades.LoginClienteResponse, error) {
+	// Verificar que la cuenta y la IP no estén bloqueadas
+	if err := s.proteccionLogin.Verificar(utils.SujetoCliente, loginReq.Correo, ip); err != nil {
+		s.proteccionLogin.Auditar(utils.SujetoCliente, loginReq.Correo, ip, nil, false, MotivoLoginBloqueado)
+		return nil, err
+	}
+
+	// Verificar que existe un cliente con ese correo
+	cliente, err := s.clienteRepo.GetByCorreo(loginReq.Correo)
+	if err != nil {
+		// Comparar igual la contraseña para no revelar por el tiempo de respuesta que el correo no existe
+		utils.SimularCheckPassword(loginReq.Contrasena)
+		s.proteccionLogin.RegistrarFallo(utils.SujetoCliente, loginReq.Correo, ip, nil, MotivoLoginCredenciales)
+		return nil, errors.New("correo electrónico o contraseña incorrectos")
+	}
+
+	// Obtener contraseña hash
+	passwordHash, err := s.clienteRepo.GetPasswordByCorreo(loginReq.Correo)
+	if err != nil {
+		return nil, errors.New("error al verificar credenciales")
+	}
+
+	// Verificar contraseña (un cliente registrado en ventanilla puede no tener contraseña)
+	valida := false
+	if passwordHash == "" {
+		utils.SimularCheckPassword(loginReq.Contrasena)
+	} else {
+		valida = utils.CheckPasswordHash(loginReq.Contrasena, passwordHash)
+	}
+	if !valida {
+		s.proteccionLogin.RegistrarFallo(utils.SujetoCliente, loginReq.Correo, ip, &cliente.ID, MotivoLoginCredenciales)
+		return nil, errors.New("correo electrónico o contraseña incorrectos")
+	}
+
+	s.proteccionLogin.RegistrarExito(utils.SujetoCliente, loginReq.Correo, ip, cliente.ID)
+
+	return s.sesionCliente(s.refreshTokenRepo, cliente, "")
+}
+
+// RefreshTokenCliente regenera los tokens de un cliente usando su refresh token
+func (s *AuthService) RefreshTokenCliente(refreshToken string) (*entidades.LoginClienteResponse, error) {
+	// Validar refresh token
+	claims, err := utils.ValidateRefreshToken(refreshToken, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un refresh token de usuario del sistema no puede renovar la sesión de un cliente
+	if claims.TipoSujeto != utils.SujetoCliente {
+		return nil, errors.New("el token no pertenece a un cliente")
+	}
+
+	// Rotar el refresh token y emitir los nuevos dentro de la misma transacción
+	var loginResp *entidades.LoginClienteResponse
+	reutilizado := false
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		familia, usado, err := s.consumirRefreshToken(tx, refreshToken, claims)
+		if err != nil {
+			return err
+		}
+		if usado {
+			// Confirmar la revocación de la familia antes de rechazar el token
+			reutilizado = true
+			return nil
+		}
+
+		// Obtener cliente
+		cliente, err := s.clienteRepo.WithTx(tx).GetByID(claims.UserID)
+		if err != nil {
+			return err
+		}
+
+		// Generar nuevos tokens en la misma familia
+		loginResp, err = s.sesionCliente(s.refreshTokenRepo.WithTx(tx), cliente, familia)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reutilizado {
+		return nil, ErrRefreshTokenReutilizado
+	}
+
+	return loginResp, nil
+}
+
+// ChangePasswordCliente cambia la contraseña de un cliente
+func (s *AuthService) ChangePasswordCliente(idCliente int, currentPassword, newPassword string) error {
+	// Obtener cliente por ID
+	cliente, err := s.clienteRepo.GetByID(idCliente)
+	if err != nil {
+		return err
+	}
+
+	// Obtener contraseña actual del cliente
+	passwordHash, err := s.clienteRepo.GetPasswordByCorreo(cliente.Correo)
+	if err != nil {
+		return err
+	}
+
+	// Verificar contraseña actual
+	if !utils.CheckPasswordHash(currentPassword, passwordHash) {
+		return errors.New("contraseña actual incorrecta")
+	}
+
+	// Hash de la nueva contraseña
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Actualizar contraseña
+	return s.clienteRepo.UpdatePassword(idCliente, hashedPassword)
+}
+
+// Logout cierra la sesión de un usuario del sistema: revoca la familia del refresh token
+// y el token de acceso con el que se hizo la petición
+func (s *AuthService) Logout(idUsuario int, jti string, expiracion time.Time, refreshToken string) error {
+	return s.cerrarSesion(utils.SujetoUsuario, idUsuario, jti, expiracion, refreshToken)
+}
+
+// LogoutCliente cierra la sesión de un cliente verificando que el refresh token le pertenezca
+func (s *AuthService) LogoutCliente(idCliente int, jti string, expiracion time.Time, refreshToken string) error {
+	return s.cerrarSesion(utils.SujetoCliente, idCliente, jti, expiracion, refreshToken)
+}
+
+// LogoutTodos cierra todas las sesiones de un usuario o cliente en todos sus dispositivos.
+// Los demás tokens de acceso ya emitidos dejan de servir cuando expiran, en pocos minutos.
+func (s *AuthService) LogoutTodos(tipoSujeto string, idSujeto int, jti string, expiracion time.Time) error {
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		if err := s.refreshTokenRepo.WithTx(tx).RevocarBySujeto(tipoSujeto, idSujeto); err != nil {
+			return err
+		}
+		return s.revocarTokenAcceso(tx, jti, expiracion)
+	})
+}
+
+// LimpiarTokensVencidos elimina los refresh tokens, los tokens revocados y las revocaciones de sujetos que ya expiraron
+func (s *AuthService) LimpiarTokensVencidos() (int64, error) {
+	ahora := time.Now()
+
+	refresh, err := s.refreshTokenRepo.DeleteExpirados(ahora)
+	if err != nil {
+		return 0, err
+	}
+	revocados, err := s.tokenRevocadoRepo.DeleteExpirados(ahora)
+	if err != nil {
+		return refresh, err
+	}
+	// Un sujeto revocado deja de importar cuando vencieron los tokens emitidos antes de la revocación
+	sujetos, err := s.tokenRevocadoRepo.DeleteSujetosAntesDe(ahora.Add(-s.config.JWTExpiration))
+	if err != nil {
+		return refresh + revocados, err
+	}
+
+	return refresh + revocados + sujetos, nil
+}
+
+// IniciarLimpiezaTokens elimina periódicamente los tokens vencidos hasta que se cancele el contexto
+func (s *AuthService) IniciarLimpiezaTokens(ctx context.Context, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			eliminados, err := s.LimpiarTokensVencidos()
+			if err != nil {
+				log.Printf("Error al limpiar tokens vencidos: %v", err)
+			}
+			if eliminados > 0 {
+				log.Printf("Tokens vencidos eliminados: %d", eliminados)
+			}
+		}
+	}
+}
+
+// cerrarSesion revoca la familia del refresh token presentado y el token de acceso actual
+func (s *AuthService) cerrarSesion(tipoSujeto string, idSujeto int, jti string, expiracion time.Time, refreshToken string) error {
+	// Validar refresh token
+	claims, err := utils.ValidateRefreshToken(refreshToken, s.config)
+	if err != nil {
+		return err
+	}
+
+	// Verificar que el token sea del sujeto autenticado
+	if claims.TipoSujeto != tipoSujeto || claims.UserID != idSujeto {
+		return errors.New("el token no pertenece al sujeto autenticado")
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		refreshTokenRepo := s.refreshTokenRepo.WithTx(tx)
+
+		token, err := refreshTokenRepo.GetByHashForUpdate(utils.HashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if err := refreshTokenRepo.RevocarFamilia(token.Familia); err != nil {
+			return err
+		}
+
+		return s.revocarTokenAcceso(tx, jti, expiracion)
+	})
+}
+
+// revocarTokenAcceso agrega el token de acceso a la lista de revocados hasta que expire
+func (s *AuthService) revocarTokenAcceso(tx *sql.Tx, jti string, expiracion time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return s.tokenRevocadoRepo.WithTx(tx).Create(jti, expiracion)
+}
+
+// consumirRefreshToken marca como usado el refresh token presentado y devuelve su familia.
+// Si el token ya se había usado, revoca toda la familia y devuelve usado = true.
+func (s *AuthService) consumirRefreshToken(tx *sql.Tx, refreshToken string, claims *utils.TokenClaims) (string, bool, error) {
+	refreshTokenRepo := s.refreshTokenRepo.WithTx(tx)
+
+	token, err := refreshTokenRepo.GetByHashForUpdate(utils.HashToken(refreshToken))
+	if err != nil {
+		return "", false, errors.New("refresh token no reconocido")
+	}
+
+	// El registro debe corresponder al sujeto del token
+	if token.TipoSujeto != claims.TipoSujeto || token.IDSujeto != claims.UserID {
+		return "", false, errors.New("refresh token no reconocido")
+	}
+
+	if token.FechaRevocacion != nil {
+		return "", false, errors.New("refresh token revocado")
+	}
+
+	if token.FechaUso != nil {
+		if err := refreshTokenRepo.RevocarFamilia(token.Familia); err != nil {
+			return "", false, err
+		}
+		log.Printf("Reutilización de refresh token detectada: %s %d, familia revocada", token.TipoSujeto, token.IDSujeto)
+		return token.Familia, true, nil
+	}
+
+	if !time.Now().Before(token.FechaExpiracion) {
+		return "", false, errors.New("refresh token expirado")
+	}
+
+	if err := refreshTokenRepo.MarcarUsado(token.ID); err != nil {
+		return "", false, err
+	}
+
+	return token.Familia, false, nil
+}
+
+// registrarRefreshToken guarda el hash de un refresh token emitido.
+// Si familia está vacía se inicia una familia nueva (una sesión nueva).
+func (s *AuthService) registrarRefreshToken(refreshTokenRepo *repositorios.RefreshTokenRepository, tipoSujeto string, idSujeto int, refreshToken, familia string) error {
+	if familia == "" {
+		var err error
+		familia, err = utils.GenerarTokenAleatorio(16)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := refreshTokenRepo.Create(&entidades.RefreshToken{
+		TipoSujeto:      tipoSujeto,
+		IDSujeto:        idSujeto,
+		TokenHash:       utils.HashToken(refreshToken),
+		Familia:         familia,
+		FechaExpiracion: time.Now().Add(utils.DuracionRefresh(s.config)),
+	})
+	return err
+}
+
+// desafioDobleFactor devuelve la respuesta de desafío si el usuario debe completar el segundo factor,
+// o nil si puede recibir sus tokens directamente
+func (s *AuthService) desafioDobleFactor(usuario *entidades.Usuario) (*entidades.LoginResponse, error) {
+	activo, err := s.dobleFactor.Activo(usuario.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	proposito := utils.DesafioVerificar
+	if !activo {
+		if !s.dobleFactor.Obligatorio(usuario.Rol) {
+			return nil, nil
+		}
+		proposito = utils.DesafioEnrolar
+	}
+
+	tokenDesafio, err := utils.GenerarTokenDesafio(usuario.ID, proposito, s.config.DobleFactorDesafio, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entidades.LoginResponse{
+		RequiereDobleFactor:  true,
+		RequiereEnrolamiento: proposito == utils.DesafioEnrolar,
+		TokenDesafio:         tokenDesafio,
+	}, nil
+}
+
+// usuarioDesafio valida un token de desafío, carga al usuario activo al que pertenece
+// y verifica que su cuenta y la IP no estén bloqueadas
+func (s *AuthService) usuarioDesafio(tokenDesafio, proposito, ip string) (*entidades.Usuario, error) {
+	claims, err := utils.ValidarTokenDesafio(tokenDesafio, proposito, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario, err := s.usuarioRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("usuario no encontrado o desactivado")
+	}
+
+	if err := s.proteccionLogin.Verificar(utils.SujetoUsuario, usuario.Correo, ip); err != nil {
+		s.proteccionLogin.Auditar(utils.SujetoUsuario, usuario.Correo, ip, &usuario.ID, false, MotivoLoginBloqueado)
+		return nil, err
+	}
+
+	// Ocultar contraseña hash
+	usuario.Contrasena = ""
+	return usuario, nil
+}
+
+// sesionUsuario genera los tokens de un usuario del sistema y arma la respuesta de sesión
+func (s *AuthService) sesionUsuario(refreshTokenRepo *repositorios.RefreshTokenRepository, usuario *entidades.Usuario, familia string) (*entidades.LoginResponse, error) {
+	// Generar token JWT
+	token, err := utils.GenerateJWT(usuario, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generar y registrar refresh token
+	refreshToken, err := utils.GenerateRefreshToken(usuario, s.config)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.registrarRefreshToken(refreshTokenRepo, utils.SujetoUsuario, usuario.ID, refreshToken, familia); err != nil {
+		return nil, err
+	}
+
+	// Crear respuesta
+	return &entidades.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		Usuario:      usuario,
+	}, nil
+}
+
+// sesionCliente genera los tokens de un cliente y arma la respuesta de sesión
+func (s *AuthService) sesionCliente(refreshTokenRepo *repositorios.RefreshTokenRepository, cliente *entidades.Cliente, familia string) (*entidades.LoginClienteResponse, error) {
+	// Generar token JWT
+	token, err := utils.GenerateClienteJWT(cliente, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generar y registrar refresh token
+	refreshToken, err := utils.GenerateClienteRefreshToken(cliente, s.config)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.registrarRefreshToken(refreshTokenRepo, utils.SujetoCliente, cliente.ID, refreshToken, familia); err != nil {
+		return nil, err
+	}
+
+	// Crear respuesta
+	return &entidades.LoginClienteResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		Usuario: &entidades.SesionCliente{
+			ID:              cliente.ID,
+			Nombres:         cliente.Nombres,
+			Apellidos:       cliente.Apellidos,
+			NombreCompleto:  cliente.Nombres + " " + cliente.Apellidos,
+			TipoDocumento:   cliente.TipoDocumento,
+			NumeroDocumento: cliente.NumeroDocumento,
+			Correo:          cliente.Correo,
+			Rol:             "CLIENTE",
+		},
+	}, nil
+}
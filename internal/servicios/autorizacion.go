@@ -0,0 +1,33 @@
+package servicios
+
+import (
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+)
+
+// ErrAccesoDenegado indica que el actor está autenticado pero el recurso no le pertenece.
+// Los controladores lo responden con 403.
+var ErrAccesoDenegado = errors.New("no tiene permisos para acceder a este recurso")
+
+// verificarAccesoReserva comprueba que un cliente solo acceda a sus propias reservas
+// y que una agencia solo acceda a las reservas de su canal de venta.
+// El personal (ADMIN, VENDEDOR) puede acceder a cualquier reserva; los choferes acceden por el tour asignado.
+func verificarAccesoReserva(reserva *entidades.Reserva, actor Actor) error {
+	if actor.Rol == "CLIENTE" && reserva.IDCliente != actor.ID {
+		return fmt.Errorf("%w: la reserva no pertenece al cliente autenticado", ErrAccesoDenegado)
+	}
+	if actor.Rol == "AGENCIA" && reserva.IDCanal != actor.IDCanal {
+		return fmt.Errorf("%w: la reserva no pertenece al canal de venta de la llave de API", ErrAccesoDenegado)
+	}
+	return nil
+}
+
+// verificarAccesoTour comprueba que un chofer (o cualquier usuario en una ruta limitada a sus tours asignados)
+// solo acceda a los tours de la embarcación que tiene asignada
+func verificarAccesoTour(tour *entidades.TourProgramado, actor Actor) error {
+	if (actor.Rol == "CHOFER" || actor.SoloAsignados) && tour.IDChofer != actor.ID {
+		return fmt.Errorf("%w: el tour programado no está asignado al chofer autenticado", ErrAccesoDenegado)
+	}
+	return nil
+}
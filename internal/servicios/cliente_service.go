@@ -91,35 +91,6 @@ func (s *ClienteService) Update(id int, cliente *entidades.ActualizarClienteRequ
 	return s.clienteRepo.Update(id, cliente)
 }
 
-// UpdatePassword actualiza la contraseña de un cliente
-func (s *ClienteService) UpdatePassword(id int, contrasenaActual, nuevaContrasena string) error {
-	// Verificar que el cliente existe
-	cliente, err := s.clienteRepo.GetByID(id)
-	if err != nil {
-		return err
-	}
-
-	// Obtener contraseña actual del cliente
-	passwordHash, err := s.clienteRepo.GetPasswordByCorreo(cliente.Correo)
-	if err != nil {
-		return err
-	}
-
-	// Verificar contraseña actual
-	if !utils.CheckPasswordHash(contrasenaActual, passwordHash) {
-		return errors.New("contraseña actual incorrecta")
-	}
-
-	// Hashear nueva contraseña
-	hashedPassword, err := utils.HashPassword(nuevaContrasena)
-	if err != nil {
-		return errors.New("error al procesar la nueva contraseña")
-	}
-
-	// Actualizar contraseña
-	return s.clienteRepo.UpdatePassword(id, hashedPassword)
-}
-
 // Delete elimina un cliente
 func (s *ClienteService) Delete(id int) error {
 	// Verificar que el cliente existe
@@ -144,25 +115,3 @@ func (s *ClienteService) SearchByName(query string) ([]*entidades.Cliente, error
 	}
 	return s.clienteRepo.SearchByName(query)
 }
-
-// Login realiza el login de un cliente
-func (s *ClienteService) Login(correo, contrasena string) (*entidades.Cliente, error) {
-	// Verificar que existe un cliente con ese correo
-	cliente, err := s.clienteRepo.GetByCorreo(correo)
-	if err != nil {
-		return nil, errors.New("correo electrónico o contraseña incorrectos")
-	}
-
-	// Obtener contraseña hash
-	passwordHash, err := s.clienteRepo.GetPasswordByCorreo(correo)
-	if err != nil {
-		return nil, errors.New("error al verificar credenciales")
-	}
-
-	// Verificar contraseña
-	if !utils.CheckPasswordHash(contrasena, passwordHash) {
-		return nil, errors.New("correo electrónico o contraseña incorrectos")
-	}
-
-	return cliente, nil
-}
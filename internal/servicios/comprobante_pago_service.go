@@ -0,0 +1,201 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"time"
+)
+
+// tasaIGV es la tasa del Impuesto General a las Ventas aplicada a los comprobantes
+const tasaIGV = 0.18
+
+// ComprobantePagoService maneja la lógica de negocio para comprobantes de pago
+type ComprobantePagoService struct {
+	db              *sql.DB
+	comprobanteRepo *repositorios.ComprobantePagoRepository
+	reservaRepo     *repositorios.ReservaRepository
+	clienteRepo     *repositorios.ClienteRepository
+	notaRepo        *repositorios.NotaCreditoRepository
+}
+
+// NewComprobantePagoService crea una nueva instancia de ComprobantePagoService
+func NewComprobantePagoService(
+	db *sql.DB,
+	comprobanteRepo *repositorios.ComprobantePagoRepository,
+	reservaRepo *repositorios.ReservaRepository,
+	clienteRepo *repositorios.ClienteRepository,
+	notaRepo *repositorios.NotaCreditoRepository,
+) *ComprobantePagoService {
+	return &ComprobantePagoService{
+		db:              db,
+		comprobanteRepo: comprobanteRepo,
+		reservaRepo:     reservaRepo,
+		clienteRepo:     clienteRepo,
+		notaRepo:        notaRepo,
+	}
+}
+
+// Create emite un nuevo comprobante para una reserva asignando serie, correlativo y montos
+func (s *ComprobantePagoService) Create(req *entidades.NuevoComprobantePagoRequest) (int, error) {
+	var id int
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para evitar emisiones duplicadas simultáneas
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(req.IDReserva)
+		if err != nil {
+			return errors.New("la reserva especificada no existe")
+		}
+
+		// Verificar que la reserva no esté cancelada
+		if reserva.Estado == "CANCELADA" {
+			return errors.New("no se puede emitir un comprobante para una reserva cancelada")
+		}
+
+		// Verificar que la reserva no tenga ya un comprobante emitido
+		emitidos, err := s.comprobanteRepo.WithTx(tx).CountEmitidosByReserva(req.IDReserva)
+		if err != nil {
+			return err
+		}
+		if emitidos > 0 {
+			return errors.New("la reserva ya tiene un comprobante emitido, debe anularlo antes de emitir otro")
+		}
+
+		// Las facturas solo se emiten a clientes identificados con RUC
+		if req.Tipo == "FACTURA" {
+			cliente, err := s.clienteRepo.GetByID(reserva.IDCliente)
+			if err != nil {
+				return err
+			}
+			if cliente.TipoDocumento != "RUC" {
+				return errors.New("solo se puede emitir factura a clientes con RUC")
+			}
+		}
+
+		// Calcular montos a partir del total de la reserva (precio con IGV incluido)
+		subtotal, igv, total := calcularMontosComprobante(reserva.TotalPagar)
+
+		// Obtener serie y correlativo
+		serie, correlativo, err := s.comprobanteRepo.WithTx(tx).SiguienteCorrelativo(req.Tipo)
+		if err != nil {
+			return err
+		}
+
+		comprobante := &entidades.ComprobantePago{
+			IDReserva:         req.IDReserva,
+			Tipo:              req.Tipo,
+			Serie:             serie,
+			Correlativo:       correlativo,
+			NumeroComprobante: fmt.Sprintf("%s-%08d", serie, correlativo),
+			Subtotal:          subtotal,
+			IGV:               igv,
+			Total:             total,
+		}
+
+		// Crear comprobante
+		id, err = s.comprobanteRepo.WithTx(tx).Create(comprobante)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetByID obtiene un comprobante por su ID
+func (s *ComprobantePagoService) GetByID(id int) (*entidades.ComprobantePago, error) {
+	return s.comprobanteRepo.GetByID(id)
+}
+
+// CambiarEstado cambia el estado de un comprobante (solo se permite anular).
+// La anulación se comunica a SUNAT como baja; las devoluciones se registran con notas de crédito.
+func (s *ComprobantePagoService) CambiarEstado(id int, req *entidades.CambiarEstadoComprobanteRequest) error {
+	// Verificar que el comprobante existe
+	comprobante, err := s.comprobanteRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Un comprobante anulado no puede volver a emitirse
+	if req.Estado == "EMITIDO" {
+		if comprobante.Estado == "ANULADO" {
+			return errors.New("un comprobante anulado no puede volver a emitirse")
+		}
+		return nil
+	}
+
+	// Verificar que se indique el motivo
+	if req.Motivo == "" {
+		return errors.New("debe indicar el motivo de la anulación")
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para serializar la anulación con las notas de crédito del comprobante
+		if _, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(comprobante.IDReserva); err != nil {
+			return err
+		}
+
+		// Volver a leer el comprobante con la reserva bloqueada
+		comprobante, err := s.comprobanteRepo.WithTx(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		// Verificar que no esté anulado
+		if comprobante.Estado == "ANULADO" {
+			return errors.New("el comprobante ya se encuentra anulado")
+		}
+
+		// Fuera del plazo de baja, un comprobante informado a SUNAT solo puede revertirse con nota de crédito
+		informado := comprobante.EstadoSunat == "ACEPTADO" || comprobante.EstadoSunat == "OBSERVADO"
+		if informado && time.Since(comprobante.FechaEmision) > time.Duration(diasPlazoComunicado)*24*time.Hour {
+			return fmt.Errorf("el plazo de %d días para dar de baja el comprobante ha vencido, emita una nota de crédito", diasPlazoComunicado)
+		}
+
+		// Un comprobante con notas de crédito ya fue modificado y no puede darse de baja
+		acreditado, err := s.notaRepo.WithTx(tx).GetTotalByComprobante(id)
+		if err != nil {
+			return err
+		}
+		if acreditado > 0 {
+			return errors.New("el comprobante tiene notas de crédito emitidas y no puede anularse")
+		}
+
+		// Anular comprobante
+		return s.comprobanteRepo.WithTx(tx).Anular(id, req.Motivo)
+	})
+}
+
+// List lista todos los comprobantes
+func (s *ComprobantePagoService) List() ([]*entidades.ComprobantePago, error) {
+	return s.comprobanteRepo.List()
+}
+
+// ListByReserva lista todos los comprobantes de una reserva
+func (s *ComprobantePagoService) ListByReserva(idReserva int) ([]*entidades.ComprobantePago, error) {
+	// Verificar que la reserva existe
+	_, err := s.reservaRepo.GetByID(idReserva)
+	if err != nil {
+		return nil, errors.New("la reserva especificada no existe")
+	}
+
+	return s.comprobanteRepo.ListByReserva(idReserva)
+}
+
+// ListByFecha lista todos los comprobantes emitidos en una fecha
+func (s *ComprobantePagoService) ListByFecha(fecha time.Time) ([]*entidades.ComprobantePago, error) {
+	return s.comprobanteRepo.ListByFecha(fecha)
+}
+
+// calcularMontosComprobante desglosa un total con IGV incluido en subtotal e IGV redondeados a céntimos
+func calcularMontosComprobante(totalConIGV float64) (subtotal, igv, total float64) {
+	totalCentimos := aCentimos(totalConIGV)
+	subtotalCentimos := int64(math.Round(float64(totalCentimos) / (1 + tasaIGV)))
+	igvCentimos := totalCentimos - subtotalCentimos
+
+	return float64(subtotalCentimos) / 100, float64(igvCentimos) / 100, float64(totalCentimos) / 100
+}
@@ -0,0 +1,119 @@
+package servicios
+
+import (
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+)
+
+// CotizacionService calcula en el servidor el precio de los pasajes de un tour programado
+type CotizacionService struct {
+	tourProgramadoRepo *repositorios.TourProgramadoRepository
+	tarifaRepo         *repositorios.TarifaTourRepository
+}
+
+// NewCotizacionService crea una nueva instancia de CotizacionService
+func NewCotizacionService(
+	tourProgramadoRepo *repositorios.TourProgramadoRepository,
+	tarifaRepo *repositorios.TarifaTourRepository,
+) *CotizacionService {
+	return &CotizacionService{
+		tourProgramadoRepo: tourProgramadoRepo,
+		tarifaRepo:         tarifaRepo,
+	}
+}
+
+// Cotizar calcula el total de los pasajes solicitados para un tour programado disponible
+func (s *CotizacionService) Cotizar(idTourProgramado int, pasajes []entidades.PasajeCantidadRequest) (*entidades.Cotizacion, error) {
+	// Verificar que el tour programado existe
+	tour, err := s.tourProgramadoRepo.GetByID(idTourProgramado)
+	if err != nil {
+		return nil, errors.New("el tour programado especificado no existe")
+	}
+
+	// Solo se cotizan tours que aún se pueden reservar
+	if tour.Estado != "PROGRAMADO" {
+		return nil, errors.New("no se puede cotizar un tour que no está programado")
+	}
+
+	return s.calcular(tour, pasajes)
+}
+
+// calcular arma la cotización de los pasajes aplicando la tarifa vigente de cada tipo de pasaje
+// en el tipo de tour (o su costo general si no tiene tarifa específica)
+func (s *CotizacionService) calcular(tour *entidades.TourProgramado, pasajes []entidades.PasajeCantidadRequest) (*entidades.Cotizacion, error) {
+	// Obtener las tarifas vigentes del tipo de tour
+	vigentes, err := s.tarifaRepo.ListVigentesByTipoTour(tour.IDTipoTour)
+	if err != nil {
+		return nil, err
+	}
+	tarifas := make(map[int]*entidades.TarifaVigente, len(vigentes))
+	for _, tarifa := range vigentes {
+		tarifas[tarifa.IDTipoPasaje] = tarifa
+	}
+
+	cotizacion := &entidades.Cotizacion{
+		IDTourProgramado: tour.ID,
+		NombreTour:       tour.NombreTipoTour,
+		Fecha:            tour.Fecha,
+		HoraInicio:       tour.HoraInicio,
+		PrecioBase:       tour.PrecioBase,
+		CupoDisponible:   tour.CupoDisponible,
+		Lineas:           []entidades.LineaCotizacion{},
+	}
+
+	vistos := make(map[int]bool)
+	var totalCentimos int64
+
+	for _, pasaje := range pasajes {
+		// Cada tipo de pasaje debe indicarse una sola vez
+		if vistos[pasaje.IDTipoPasaje] {
+			return nil, errors.New("un tipo de pasaje no puede repetirse en la misma reserva")
+		}
+		vistos[pasaje.IDTipoPasaje] = true
+
+		if pasaje.Cantidad <= 0 {
+			return nil, errors.New("la cantidad de pasajes debe ser mayor a cero")
+		}
+
+		// Verificar que el tipo de pasaje existe
+		tarifa, ok := tarifas[pasaje.IDTipoPasaje]
+		if !ok {
+			return nil, errors.New("uno de los tipos de pasaje especificados no existe")
+		}
+
+		precioCentimos := aCentimos(tarifa.Precio)
+		importeCentimos := precioCentimos * int64(pasaje.Cantidad)
+
+		cotizacion.Lineas = append(cotizacion.Lineas, entidades.LineaCotizacion{
+			IDTipoPasaje:   tarifa.IDTipoPasaje,
+			NombreTipo:     tarifa.NombreTipo,
+			Cantidad:       pasaje.Cantidad,
+			PrecioUnitario: float64(precioCentimos) / 100,
+			Importe:        float64(importeCentimos) / 100,
+		})
+		cotizacion.TotalPasajeros += pasaje.Cantidad
+		totalCentimos += importeCentimos
+	}
+
+	cotizacion.Total = float64(totalCentimos) / 100
+
+	return cotizacion, nil
+}
+
+// aplicarPrecios guarda en cada pasaje solicitado el precio unitario de la cotización
+func aplicarPrecios(pasajes []entidades.PasajeCantidadRequest, cotizacion *entidades.Cotizacion) {
+	for i := range pasajes {
+		pasajes[i].PrecioUnitario = cotizacion.Lineas[i].PrecioUnitario
+	}
+}
+
+// verificarTotal rechaza un total enviado por el cliente que no coincide con el calculado por el servidor.
+// Un total en cero significa que el cliente no lo envió y se usa el calculado.
+func verificarTotal(enviado float64, cotizacion *entidades.Cotizacion) error {
+	if enviado != 0 && aCentimos(enviado) != aCentimos(cotizacion.Total) {
+		return fmt.Errorf("el total enviado (S/ %.2f) no coincide con el precio vigente (S/ %.2f)", enviado, cotizacion.Total)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/utils"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// cantidadCodigosRecuperacion es la cantidad de códigos de recuperación que se entregan al activar el doble factor
+const cantidadCodigosRecuperacion = 10
+
+var (
+	// ErrCodigoDobleFactorInvalido indica que el código TOTP o de recuperación no es válido o ya se usó
+	ErrCodigoDobleFactorInvalido = errors.New("código de verificación inválido")
+	// ErrDobleFactorYaActivo indica que el usuario ya tiene el doble factor activo
+	ErrDobleFactorYaActivo = errors.New("el doble factor ya está activo")
+	// ErrDobleFactorNoConfigurado indica que el usuario no tiene doble factor configurado
+	ErrDobleFactorNoConfigurado = errors.New("el doble factor no está configurado")
+	// ErrDobleFactorObligatorio indica que el rol del usuario no le permite desactivar el doble factor
+	ErrDobleFactorObligatorio = errors.New("el doble factor es obligatorio para este rol")
+)
+
+// DobleFactorService maneja el doble factor (TOTP) de los usuarios del sistema
+type DobleFactorService struct {
+	db                     *sql.DB
+	dobleFactorRepo        *repositorios.DobleFactorRepository
+	codigoRecuperacionRepo *repositorios.CodigoRecuperacionRepository
+	usuarioRepo            *repositorios.UsuarioRepository
+	config                 *config.Config
+}
+
+// NewDobleFactorService crea una nueva instancia de DobleFactorService
+func NewDobleFactorService(
+	db *sql.DB,
+	dobleFactorRepo *repositorios.DobleFactorRepository,
+	codigoRecuperacionRepo *repositorios.CodigoRecuperacionRepository,
+	usuarioRepo *repositorios.UsuarioRepository,
+	config *config.Config,
+) *DobleFactorService {
+	return &DobleFactorService{
+		db:                     db,
+		dobleFactorRepo:        dobleFactorRepo,
+		codigoRecuperacionRepo: codigoRecuperacionRepo,
+		usuarioRepo:            usuarioRepo,
+		config:                 config,
+	}
+}
+
+// Obligatorio indica si el rol debe usar doble factor para iniciar sesión
+func (s *DobleFactorService) Obligatorio(rol string) bool {
+	for _, obligatorio := range s.config.DobleFactorRoles {
+		if obligatorio == rol {
+			return true
+		}
+	}
+	return false
+}
+
+// Activo indica si el usuario tiene el doble factor activo
+func (s *DobleFactorService) Activo(idUsuario int) (bool, error) {
+	return s.dobleFactorRepo.ExisteActivo(idUsuario)
+}
+
+// Enrolar genera un secreto nuevo pendiente de confirmación y devuelve el URI y el QR para la aplicación autenticadora.
+// Llamarlo de nuevo antes de activar reemplaza el secreto anterior.
+func (s *DobleFactorService) Enrolar(idUsuario int) (*entidades.EnrolamientoDobleFactorResponse, error) {
+	usuario, err := s.usuarioRepo.GetByID(idUsuario)
+	if err != nil {
+		return nil, err
+	}
+
+	secreto, err := utils.GenerarSecretoTOTP()
+	if err != nil {
+		return nil, err
+	}
+
+	guardado, err := s.dobleFactorRepo.GuardarPendiente(idUsuario, secreto)
+	if err != nil {
+		return nil, err
+	}
+	if !guardado {
+		return nil, ErrDobleFactorYaActivo
+	}
+
+	uri := utils.URITOTP(s.config.DobleFactorEmisor, usuario.Correo, secreto)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entidades.EnrolamientoDobleFactorResponse{
+		Secreto: secreto,
+		URI:     uri,
+		QR:      base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Activar confirma el enrolamiento con un primer código TOTP y devuelve los códigos de recuperación,
+// que reemplazan a los que hubiera y no vuelven a mostrarse
+func (s *DobleFactorService) Activar(idUsuario int, codigo string) ([]string, error) {
+	codigos, err := utils.GenerarCodigosRecuperacion(cantidadCodigosRecuperacion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		dobleFactorRepo := s.dobleFactorRepo.WithTx(tx)
+		codigoRecuperacionRepo := s.codigoRecuperacionRepo.WithTx(tx)
+
+		dobleFactor, err := dobleFactorRepo.GetByUsuarioForUpdate(idUsuario)
+		if err != nil {
+			return ErrDobleFactorNoConfigurado
+		}
+		if dobleFactor.Activo {
+			return ErrDobleFactorYaActivo
+		}
+
+		paso, ok := utils.VerificarTOTP(dobleFactor.Secreto, codigo, time.Now(), dobleFactor.UltimoPaso)
+		if !ok {
+			return ErrCodigoDobleFactorInvalido
+		}
+
+		if err := dobleFactorRepo.Activar(idUsuario, paso); err != nil {
+			return err
+		}
+
+		if err := codigoRecuperacionRepo.DeleteByUsuario(idUsuario); err != nil {
+			return err
+		}
+		for _, codigoRecuperacion := range codigos {
+			hash := utils.HashToken(utils.NormalizarCodigoRecuperacion(codigoRecuperacion))
+			if err := codigoRecuperacionRepo.Create(idUsuario, hash); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codigos, nil
+}
+
+// Verificar comprueba un código TOTP o, si no lo es, un código de recuperación, que queda usado
+func (s *DobleFactorService) Verificar(idUsuario int, codigo string) error {
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		return s.verificar(tx, idUsuario, codigo)
+	})
+}
+
+// Desactivar elimina el doble factor del propio usuario tras verificar un código.
+// No se permite si su rol lo exige.
+func (s *DobleFactorService) Desactivar(idUsuario int, rol string, codigo string) error {
+	if s.Obligatorio(rol) {
+		return ErrDobleFactorObligatorio
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		if err := s.verificar(tx, idUsuario, codigo); err != nil {
+			return err
+		}
+		return s.eliminar(tx, idUsuario)
+	})
+}
+
+// Restablecer elimina el doble factor de un usuario que perdió su aplicación autenticadora y sus códigos.
+// Lo usa un administrador; si el rol lo exige, el usuario deberá enrolarse de nuevo al iniciar sesión.
+func (s *DobleFactorService) Restablecer(idUsuario int) error {
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		return s.eliminar(tx, idUsuario)
+	})
+}
+
+// verificar comprueba el código dentro de una transacción, bloqueando la fila del doble factor
+func (s *DobleFactorService) verificar(tx *sql.Tx, idUsuario int, codigo string) error {
+	dobleFactorRepo := s.dobleFactorRepo.WithTx(tx)
+
+	dobleFactor, err := dobleFactorRepo.GetByUsuarioForUpdate(idUsuario)
+	if err != nil || !dobleFactor.Activo {
+		return ErrDobleFactorNoConfigurado
+	}
+
+	// Código de la aplicación autenticadora
+	if paso, ok := utils.VerificarTOTP(dobleFactor.Secreto, codigo, time.Now(), dobleFactor.UltimoPaso); ok {
+		return dobleFactorRepo.ActualizarUltimoPaso(idUsuario, paso)
+	}
+
+	// Código de recuperación
+	hash := utils.HashToken(utils.NormalizarCodigoRecuperacion(codigo))
+	usado, err := s.codigoRecuperacionRepo.WithTx(tx).Usar(idUsuario, hash)
+	if err != nil {
+		return err
+	}
+	if !usado {
+		return ErrCodigoDobleFactorInvalido
+	}
+
+	return nil
+}
+
+// eliminar borra el doble factor y los códigos de recuperación de un usuario
+func (s *DobleFactorService) eliminar(tx *sql.Tx, idUsuario int) error {
+	eliminado, err := s.dobleFactorRepo.WithTx(tx).Delete(idUsuario)
+	if err != nil {
+		return err
+	}
+	if !eliminado {
+		return ErrDobleFactorNoConfigurado
+	}
+
+	return s.codigoRecuperacionRepo.WithTx(tx).DeleteByUsuario(idUsuario)
+}
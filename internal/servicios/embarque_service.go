@@ -0,0 +1,130 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/utils"
+)
+
+// EmbarqueService maneja el check-in de las reservas con el código QR del ticket
+type EmbarqueService struct {
+	db                 *sql.DB
+	embarqueRepo       *repositorios.EmbarqueRepository
+	reservaRepo        *repositorios.ReservaRepository
+	tourProgramadoRepo *repositorios.TourProgramadoRepository
+	reservaService     *ReservaService
+	secret             string // Llave con la que se firman los códigos de embarque
+}
+
+// NewEmbarqueService crea una nueva instancia de EmbarqueService
+func NewEmbarqueService(
+	db *sql.DB,
+	embarqueRepo *repositorios.EmbarqueRepository,
+	reservaRepo *repositorios.ReservaRepository,
+	tourProgramadoRepo *repositorios.TourProgramadoRepository,
+	reservaService *ReservaService,
+	secret string,
+) *EmbarqueService {
+	return &EmbarqueService{
+		db:                 db,
+		embarqueRepo:       embarqueRepo,
+		reservaRepo:        reservaRepo,
+		tourProgramadoRepo: tourProgramadoRepo,
+		reservaService:     reservaService,
+		secret:             secret,
+	}
+}
+
+// Registrar valida el código QR escaneado en la salida indicada y registra el embarque de la reserva.
+// La reserva pasa a EMBARCADO con las mismas reglas que un cambio de estado manual.
+func (s *EmbarqueService) Registrar(idTourProgramado int, req *entidades.RegistrarEmbarqueRequest, actor Actor) (*entidades.Embarque, error) {
+	idReserva, idTourCodigo, err := utils.ValidarCodigoEmbarque(req.Codigo, s.secret)
+	if err != nil {
+		return nil, err
+	}
+	if idTourCodigo != idTourProgramado {
+		return nil, errors.New("el código de embarque corresponde a otra salida")
+	}
+
+	if _, err := s.verificarTour(idTourProgramado, actor); err != nil {
+		return nil, err
+	}
+
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para que dos lecturas simultáneas del mismo QR no la embarquen dos veces
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(idReserva)
+		if err != nil {
+			return err
+		}
+
+		// La reserva pudo cambiarse de tour después de emitir el ticket
+		if reserva.IDTourProgramado != idTourProgramado {
+			return errors.New("la reserva ya no corresponde a esta salida")
+		}
+
+		if existente, err := s.embarqueRepo.WithTx(tx).GetByReserva(idReserva); err == nil {
+			return fmt.Errorf("la reserva ya embarcó el %s", existente.FechaEmbarque.Format("02/01/2006 15:04"))
+		}
+		if reserva.Estado == "EMBARCADO" || reserva.Estado == "COMPLETADA" {
+			return errors.New("la reserva ya embarcó")
+		}
+
+		if err := s.reservaService.cambiarEstadoTx(tx, reserva, "EMBARCADO", actor, "Embarque con código QR"); err != nil {
+			return err
+		}
+
+		cantidad, err := s.reservaRepo.WithTx(tx).GetCantidadPasajerosByReserva(idReserva)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.embarqueRepo.WithTx(tx).Create(&entidades.Embarque{
+			IDReserva:         idReserva,
+			IDTourProgramado:  idTourProgramado,
+			IDUsuario:         actor.ID,
+			CantidadPasajeros: cantidad,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.embarqueRepo.GetByReserva(idReserva)
+}
+
+// ListByTourProgramado lista los embarques registrados de una salida
+func (s *EmbarqueService) ListByTourProgramado(idTourProgramado int, actor Actor) ([]*entidades.Embarque, error) {
+	if _, err := s.verificarTour(idTourProgramado, actor); err != nil {
+		return nil, err
+	}
+
+	return s.embarqueRepo.ListByTourProgramado(idTourProgramado)
+}
+
+// Contador devuelve los pasajeros embarcados frente a los reservados de una salida
+func (s *EmbarqueService) Contador(idTourProgramado int, actor Actor) (*entidades.ContadorEmbarque, error) {
+	if _, err := s.verificarTour(idTourProgramado, actor); err != nil {
+		return nil, err
+	}
+
+	return s.embarqueRepo.GetContadorByTourProgramado(idTourProgramado)
+}
+
+// verificarTour comprueba que el tour existe y que el chofer autenticado es el asignado a su embarcación
+func (s *EmbarqueService) verificarTour(idTourProgramado int, actor Actor) (*entidades.TourProgramado, error) {
+	tour, err := s.tourProgramadoRepo.GetByID(idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verificarAccesoTour(tour, actor); err != nil {
+		return nil, err
+	}
+
+	return tour, nil
+}
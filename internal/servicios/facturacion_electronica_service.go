@@ -0,0 +1,432 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/sunat"
+	"time"
+)
+
+// Límites establecidos por SUNAT
+const (
+	maxLineasResumen    = 500 // Líneas por resumen diario o comunicación de baja
+	diasPlazoComunicado = 7   // Días calendario para comunicar la baja de una factura
+)
+
+// FacturacionElectronicaService maneja el envío de comprobantes electrónicos a SUNAT
+type FacturacionElectronicaService struct {
+	db              *sql.DB
+	comprobanteRepo *repositorios.ComprobantePagoRepository
+	notaRepo        *repositorios.NotaCreditoRepository
+	resumenRepo     *repositorios.ResumenSunatRepository
+	emisor          sunat.Emisor
+	firmador        *sunat.Firmador
+	enviador        sunat.Enviador
+}
+
+// NewFacturacionElectronicaService crea una nueva instancia de FacturacionElectronicaService
+func NewFacturacionElectronicaService(
+	db *sql.DB,
+	comprobanteRepo *repositorios.ComprobantePagoRepository,
+	notaRepo *repositorios.NotaCreditoRepository,
+	resumenRepo *repositorios.ResumenSunatRepository,
+	emisor sunat.Emisor,
+	firmador *sunat.Firmador,
+	enviador sunat.Enviador,
+) *FacturacionElectronicaService {
+	return &FacturacionElectronicaService{
+		db:              db,
+		comprobanteRepo: comprobanteRepo,
+		notaRepo:        notaRepo,
+		resumenRepo:     resumenRepo,
+		emisor:          emisor,
+		firmador:        firmador,
+		enviador:        enviador,
+	}
+}
+
+// EnviarComprobante firma y envía una factura a SUNAT y registra la respuesta del CDR
+func (s *FacturacionElectronicaService) EnviarComprobante(id int) error {
+	// Verificar que el comprobante existe
+	comprobante, err := s.comprobanteRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Las boletas se informan mediante el resumen diario
+	if comprobante.Tipo != "FACTURA" {
+		return errors.New("las boletas se informan a SUNAT mediante el resumen diario")
+	}
+
+	// Verificar que no haya sido aceptado antes
+	switch comprobante.EstadoSunat {
+	case sunat.EstadoAceptado, sunat.EstadoObservado, sunat.EstadoBaja:
+		return errors.New("el comprobante ya fue aceptado por SUNAT")
+	case sunat.EstadoRechazado:
+		return errors.New("el comprobante fue rechazado por SUNAT, debe anularse y emitirse uno nuevo")
+	}
+
+	xmlFirmado, err := s.comprobanteRepo.GetXMLFirmado(id)
+	if err != nil {
+		return err
+	}
+
+	return s.enviarDocumento(
+		sunat.NuevoComprobante(s.emisor, comprobante),
+		xmlFirmado,
+		func(xmlFirmado, hash string) error { return s.comprobanteRepo.GuardarFirma(id, xmlFirmado, hash) },
+		func(estado, codigo, descripcion string) error {
+			return s.comprobanteRepo.UpdateEstadoSunat(id, estado, codigo, descripcion)
+		},
+	)
+}
+
+// EnviarNotaCredito firma y envía una nota de crédito a SUNAT y registra la respuesta del CDR
+func (s *FacturacionElectronicaService) EnviarNotaCredito(id int) error {
+	// Verificar que la nota de crédito existe
+	nota, err := s.notaRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Verificar que no haya sido aceptada antes
+	switch nota.EstadoSunat {
+	case sunat.EstadoAceptado, sunat.EstadoObservado:
+		return errors.New("la nota de crédito ya fue aceptada por SUNAT")
+	case sunat.EstadoRechazado:
+		return errors.New("la nota de crédito fue rechazada por SUNAT")
+	}
+
+	// El comprobante modificado debe haber sido informado antes que la nota
+	comprobante, err := s.comprobanteRepo.GetByID(nota.IDComprobante)
+	if err != nil {
+		return err
+	}
+	if comprobante.EstadoSunat != sunat.EstadoAceptado && comprobante.EstadoSunat != sunat.EstadoObservado {
+		return errors.New("el comprobante modificado aún no ha sido aceptado por SUNAT")
+	}
+
+	xmlFirmado, err := s.notaRepo.GetXMLFirmado(id)
+	if err != nil {
+		return err
+	}
+
+	return s.enviarDocumento(
+		sunat.NuevaNotaCredito(s.emisor, nota, comprobante),
+		xmlFirmado,
+		func(xmlFirmado, hash string) error { return s.notaRepo.GuardarFirma(id, xmlFirmado, hash) },
+		func(estado, codigo, descripcion string) error {
+			return s.notaRepo.UpdateEstadoSunat(id, estado, codigo, descripcion)
+		},
+	)
+}
+
+// enviarDocumento firma el documento (solo la primera vez, para que los reenvíos conserven el mismo hash),
+// lo envía a SUNAT y registra la respuesta con las funciones indicadas
+func (s *FacturacionElectronicaService) enviarDocumento(
+	doc *sunat.Documento,
+	xmlFirmado string,
+	guardarFirma func(xmlFirmado, hash string) error,
+	registrarRespuesta func(estado, codigo, descripcion string) error,
+) error {
+	if xmlFirmado == "" {
+		firmado, hash, err := s.firmador.Firmar(doc)
+		if err != nil {
+			return err
+		}
+		xmlFirmado = string(firmado)
+		if err := guardarFirma(xmlFirmado, hash); err != nil {
+			return err
+		}
+	}
+
+	zip, err := sunat.Comprimir(doc.Nombre, []byte(xmlFirmado))
+	if err != nil {
+		return err
+	}
+
+	// Enviar a SUNAT
+	cdr, err := s.enviador.EnviarComprobante(doc.Nombre, zip)
+	if err != nil {
+		var errSunat *sunat.ErrorSunat
+		if errors.As(err, &errSunat) {
+			estado := sunat.EstadoPendiente
+			if errSunat.EsRechazo() {
+				estado = sunat.EstadoRechazado
+			}
+			if errRegistro := registrarRespuesta(estado, errSunat.Codigo, errSunat.Mensaje); errRegistro != nil {
+				return errRegistro
+			}
+		}
+		return err
+	}
+
+	// Registrar respuesta del CDR
+	return registrarRespuesta(cdr.Estado(), cdr.Codigo, cdr.Descripcion)
+}
+
+// GetXMLFirmado obtiene el XML firmado de un comprobante
+func (s *FacturacionElectronicaService) GetXMLFirmado(id int) (string, error) {
+	xmlFirmado, err := s.comprobanteRepo.GetXMLFirmado(id)
+	if err != nil {
+		return "", err
+	}
+	if xmlFirmado == "" {
+		return "", errors.New("el comprobante aún no ha sido firmado")
+	}
+	return xmlFirmado, nil
+}
+
+// GetXMLNotaCredito obtiene el XML firmado de una nota de crédito
+func (s *FacturacionElectronicaService) GetXMLNotaCredito(id int) (string, error) {
+	xmlFirmado, err := s.notaRepo.GetXMLFirmado(id)
+	if err != nil {
+		return "", err
+	}
+	if xmlFirmado == "" {
+		return "", errors.New("la nota de crédito aún no ha sido firmada")
+	}
+	return xmlFirmado, nil
+}
+
+// GenerarResumenDiario genera y envía el resumen diario de las boletas emitidas en una fecha
+func (s *FacturacionElectronicaService) GenerarResumenDiario(fecha time.Time) (int, error) {
+	// Boletas por informar y boletas informadas cuya anulación falta comunicar
+	pendientes, err := s.comprobanteRepo.ListBoletasPendientesResumen(fecha)
+	if err != nil {
+		return 0, err
+	}
+	anuladas, err := s.comprobanteRepo.ListBoletasAnuladasPendientesResumen(fecha)
+	if err != nil {
+		return 0, err
+	}
+
+	items := []sunat.ItemResumen{}
+	for _, c := range pendientes {
+		items = append(items, sunat.ItemResumen{Comprobante: c, Condicion: sunat.CondicionAdicionar})
+	}
+	for _, c := range anuladas {
+		items = append(items, sunat.ItemResumen{Comprobante: c, Condicion: sunat.CondicionAnular})
+	}
+
+	if len(items) == 0 {
+		return 0, errors.New("no hay boletas pendientes de informar para la fecha indicada")
+	}
+
+	// Las boletas restantes se incluyen en un siguiente resumen
+	if len(items) > maxLineasResumen {
+		items = items[:maxLineasResumen]
+	}
+
+	detalle := make([]*entidades.ResumenSunatDetalle, len(items))
+	for i, item := range items {
+		detalle[i] = &entidades.ResumenSunatDetalle{IDComprobante: item.Comprobante.ID, Condicion: item.Condicion}
+	}
+
+	resumen, err := s.registrarResumen("RC", fecha, detalle)
+	if err != nil {
+		return 0, err
+	}
+
+	doc := sunat.NuevoResumenDiario(s.emisor, resumen.Identificador, fecha, resumen.FechaGeneracion, items)
+	return resumen.ID, s.enviarResumen(resumen, doc)
+}
+
+// GenerarComunicacionBaja genera y envía la comunicación de baja de las facturas anuladas emitidas en una fecha
+func (s *FacturacionElectronicaService) GenerarComunicacionBaja(fecha time.Time) (int, error) {
+	// Verificar el plazo para comunicar la baja
+	if time.Since(fecha) > time.Duration(diasPlazoComunicado+1)*24*time.Hour {
+		return 0, fmt.Errorf("el plazo de %d días para comunicar la baja de estas facturas ha vencido", diasPlazoComunicado)
+	}
+
+	facturas, err := s.comprobanteRepo.ListFacturasPendientesBaja(fecha)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(facturas) == 0 {
+		return 0, errors.New("no hay facturas anuladas pendientes de comunicar para la fecha indicada")
+	}
+
+	if len(facturas) > maxLineasResumen {
+		facturas = facturas[:maxLineasResumen]
+	}
+
+	detalle := make([]*entidades.ResumenSunatDetalle, len(facturas))
+	for i, c := range facturas {
+		detalle[i] = &entidades.ResumenSunatDetalle{IDComprobante: c.ID, Condicion: sunat.CondicionAnular}
+	}
+
+	resumen, err := s.registrarResumen("RA", fecha, detalle)
+	if err != nil {
+		return 0, err
+	}
+
+	doc := sunat.NuevaComunicacionBaja(s.emisor, resumen.Identificador, fecha, resumen.FechaGeneracion, facturas)
+	return resumen.ID, s.enviarResumen(resumen, doc)
+}
+
+// ConsultarResumen consulta el ticket de un resumen enviado y actualiza el estado de sus comprobantes
+func (s *FacturacionElectronicaService) ConsultarResumen(id int) error {
+	// Verificar que el resumen existe
+	resumen, err := s.resumenRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Solo los resúmenes enviados tienen un ticket pendiente de respuesta
+	if resumen.Estado != sunat.EstadoEnviado {
+		return fmt.Errorf("el resumen no está pendiente de respuesta (estado %s)", resumen.Estado)
+	}
+
+	cdr, err := s.enviador.ConsultarTicket(resumen.Ticket)
+	if err != nil {
+		return err
+	}
+
+	// SUNAT aún no procesa el ticket
+	if cdr.EnProceso {
+		return nil
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		resumenRepo := s.resumenRepo.WithTx(tx)
+
+		estado := cdr.Estado()
+		if err := resumenRepo.UpdateEnvio(id, resumen.Ticket, estado, cdr.Codigo, cdr.Descripcion); err != nil {
+			return err
+		}
+
+		// Actualizar los comprobantes incluidos
+		if estado == sunat.EstadoAceptado || estado == sunat.EstadoObservado {
+			if resumen.Tipo == "RC" {
+				if err := resumenRepo.UpdateEstadoComprobantes(id, sunat.CondicionAdicionar, estado); err != nil {
+					return err
+				}
+			}
+			return resumenRepo.UpdateEstadoComprobantes(id, sunat.CondicionAnular, sunat.EstadoBaja)
+		}
+
+		// Las boletas de un resumen rechazado vuelven a quedar pendientes
+		if resumen.Tipo == "RC" {
+			return resumenRepo.UpdateEstadoComprobantes(id, sunat.CondicionAdicionar, sunat.EstadoPendiente)
+		}
+
+		return nil
+	})
+}
+
+// GetResumenByID obtiene un resumen con sus comprobantes
+func (s *FacturacionElectronicaService) GetResumenByID(id int) (*entidades.ResumenSunat, error) {
+	resumen, err := s.resumenRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resumen.Detalle, err = s.resumenRepo.ListDetalle(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return resumen, nil
+}
+
+// ListResumenes lista todos los resúmenes
+func (s *FacturacionElectronicaService) ListResumenes() ([]*entidades.ResumenSunat, error) {
+	return s.resumenRepo.List()
+}
+
+// registrarResumen reserva el identificador del resumen y guarda sus comprobantes antes de enviarlo,
+// de modo que un mismo comprobante no se incluya en dos resúmenes simultáneos
+func (s *FacturacionElectronicaService) registrarResumen(tipo string, fecha time.Time, detalle []*entidades.ResumenSunatDetalle) (*entidades.ResumenSunat, error) {
+	var resumen *entidades.ResumenSunat
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		resumenRepo := s.resumenRepo.WithTx(tx)
+
+		ahora := time.Now()
+		numero, err := resumenRepo.SiguienteNumero(tipo, ahora)
+		if err != nil {
+			return err
+		}
+
+		resumen = &entidades.ResumenSunat{
+			Tipo:            tipo,
+			Identificador:   fmt.Sprintf("%s-%s-%d", tipo, ahora.Format("20060102"), numero),
+			Numero:          numero,
+			FechaReferencia: fecha,
+			FechaGeneracion: ahora,
+			Estado:          sunat.EstadoPendiente,
+		}
+
+		resumen.ID, err = resumenRepo.Create(resumen)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range detalle {
+			if err := resumenRepo.CreateDetalle(resumen.ID, item.IDComprobante, item.Condicion); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resumen, nil
+}
+
+// enviarResumen firma y envía un resumen registrado y guarda el ticket o el error obtenido
+func (s *FacturacionElectronicaService) enviarResumen(resumen *entidades.ResumenSunat, doc *sunat.Documento) error {
+	ticket, errEnvio := s.firmarYEnviarResumen(doc)
+
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		resumenRepo := s.resumenRepo.WithTx(tx)
+
+		if errEnvio != nil {
+			// El resumen queda con error y sus comprobantes disponibles para un nuevo resumen
+			codigo := ""
+			var errSunat *sunat.ErrorSunat
+			if errors.As(errEnvio, &errSunat) {
+				codigo = errSunat.Codigo
+			}
+			return resumenRepo.UpdateEnvio(resumen.ID, "", "ERROR", codigo, errEnvio.Error())
+		}
+
+		if err := resumenRepo.UpdateEnvio(resumen.ID, ticket, sunat.EstadoEnviado, "", ""); err != nil {
+			return err
+		}
+
+		if resumen.Tipo == "RC" {
+			return resumenRepo.UpdateEstadoComprobantes(resumen.ID, sunat.CondicionAdicionar, sunat.EstadoEnviado)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return errEnvio
+}
+
+// firmarYEnviarResumen firma, comprime y envía un resumen y devuelve el ticket
+func (s *FacturacionElectronicaService) firmarYEnviarResumen(doc *sunat.Documento) (string, error) {
+	firmado, _, err := s.firmador.Firmar(doc)
+	if err != nil {
+		return "", err
+	}
+
+	zip, err := sunat.Comprimir(doc.Nombre, firmado)
+	if err != nil {
+		return "", err
+	}
+
+	return s.enviador.EnviarResumen(doc.Nombre, zip)
+}
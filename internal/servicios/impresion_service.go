@@ -0,0 +1,234 @@
+package servicios
+
+import (
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/impresion"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/sunat"
+	"sistema-tours/internal/utils"
+	"strings"
+	"time"
+)
+
+// ImpresionService genera los documentos PDF que se entregan al cliente
+type ImpresionService struct {
+	comprobanteRepo    *repositorios.ComprobantePagoRepository
+	reservaRepo        *repositorios.ReservaRepository
+	tourProgramadoRepo *repositorios.TourProgramadoRepository
+	tipoPasajeRepo     *repositorios.TipoPasajeRepository
+	pasajeroRepo       *repositorios.PasajeroRepository
+	emisor             sunat.Emisor
+	secretEmbarque     string // Llave con la que se firman los códigos QR de embarque
+}
+
+// NewImpresionService crea una nueva instancia de ImpresionService
+func NewImpresionService(
+	comprobanteRepo *repositorios.ComprobantePagoRepository,
+	reservaRepo *repositorios.ReservaRepository,
+	tourProgramadoRepo *repositorios.TourProgramadoRepository,
+	tipoPasajeRepo *repositorios.TipoPasajeRepository,
+	pasajeroRepo *repositorios.PasajeroRepository,
+	emisor sunat.Emisor,
+	secretEmbarque string,
+) *ImpresionService {
+	return &ImpresionService{
+		comprobanteRepo:    comprobanteRepo,
+		reservaRepo:        reservaRepo,
+		tourProgramadoRepo: tourProgramadoRepo,
+		tipoPasajeRepo:     tipoPasajeRepo,
+		pasajeroRepo:       pasajeroRepo,
+		emisor:             emisor,
+		secretEmbarque:     secretEmbarque,
+	}
+}
+
+// ComprobantePDF genera la representación impresa de un comprobante y devuelve el PDF y su nombre de archivo
+func (s *ImpresionService) ComprobantePDF(id int) ([]byte, string, error) {
+	// Verificar que el comprobante existe
+	comprobante, err := s.comprobanteRepo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Obtener los pasajes de la reserva para el detalle
+	reserva, err := s.reservaRepo.GetByID(comprobante.IDReserva)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lineas, err := s.lineasComprobante(comprobante, reserva.CantidadPasajes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf, err := impresion.Comprobante(s.emisor, comprobante, lineas)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nombre := fmt.Sprintf("%s-%s-%s.pdf", s.emisor.RUC, sunat.CodigoTipoComprobante(comprobante.Tipo), comprobante.NumeroComprobante)
+	return pdf, nombre, nil
+}
+
+// TicketPDF genera el ticket de embarque de una reserva y devuelve el PDF y su nombre de archivo
+func (s *ImpresionService) TicketPDF(idReserva int) ([]byte, string, error) {
+	// Verificar que la reserva existe
+	reserva, err := s.reservaRepo.GetByID(idReserva)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// No se emiten tickets de reservas canceladas
+	if reserva.Estado == "CANCELADA" {
+		return nil, "", errors.New("no se puede emitir el ticket de una reserva cancelada")
+	}
+	if reserva.Estado == "PENDIENTE_PAGO" {
+		return nil, "", errors.New("no se puede emitir el ticket de una reserva pendiente de pago")
+	}
+
+	// Obtener el tour con su horario y embarcación
+	tour, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
+	if err != nil {
+		return nil, "", err
+	}
+
+	codigo := utils.GenerarCodigoEmbarque(reserva.ID, reserva.IDTourProgramado, s.secretEmbarque)
+	pdf, err := impresion.Ticket(s.emisor, reserva, tour, codigo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pdf, fmt.Sprintf("ticket-reserva-%d.pdf", reserva.ID), nil
+}
+
+// Manifiesto arma la lista de pasajeros de las reservas no canceladas de un tour programado.
+// Las rutas exigen el permiso manifiestos:read o, en /chofer, que el tour esté asignado al usuario.
+func (s *ImpresionService) Manifiesto(idTourProgramado int, actor Actor) (*entidades.ManifiestoTour, error) {
+	// Verificar que el tour programado existe
+	tour, err := s.tourProgramadoRepo.GetByID(idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verificarAccesoTour(tour, actor); err != nil {
+		return nil, err
+	}
+
+	pasajeros, err := s.pasajeroRepo.ListManifiestoByTourProgramado(idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pasajeros {
+		p.Edad = calcularEdad(p.FechaNacimiento, tour.Fecha)
+	}
+
+	totales, err := s.pasajeroRepo.ListTotalesManifiestoByTourProgramado(idTourProgramado)
+	if err != nil {
+		return nil, err
+	}
+
+	manifiesto := &entidades.ManifiestoTour{
+		Tour:           tour,
+		Pasajeros:      pasajeros,
+		TotalesPorTipo: totales,
+		TotalPasajeros: len(pasajeros),
+		GeneradoEn:     time.Now(),
+	}
+	for _, t := range totales {
+		manifiesto.TotalPasajes += t.Pasajes
+	}
+
+	return manifiesto, nil
+}
+
+// ManifiestoPDF genera el manifiesto de pasajeros de un tour programado y devuelve el PDF y su nombre de archivo
+func (s *ImpresionService) ManifiestoPDF(idTourProgramado int, actor Actor) ([]byte, string, error) {
+	manifiesto, err := s.Manifiesto(idTourProgramado, actor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf, err := impresion.Manifiesto(s.emisor, manifiesto)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pdf, nombreManifiesto(manifiesto.Tour, "pdf"), nil
+}
+
+// ManifiestoCSV genera el manifiesto de pasajeros de un tour programado y devuelve el CSV y su nombre de archivo
+func (s *ImpresionService) ManifiestoCSV(idTourProgramado int, actor Actor) ([]byte, string, error) {
+	manifiesto, err := s.Manifiesto(idTourProgramado, actor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	csv, err := impresion.ManifiestoCSV(manifiesto)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return csv, nombreManifiesto(manifiesto.Tour, "csv"), nil
+}
+
+func nombreManifiesto(tour *entidades.TourProgramado, extension string) string {
+	return fmt.Sprintf("manifiesto-tour-%d-%s.%s", tour.ID, tour.Fecha.Format("20060102"), extension)
+}
+
+// lineasComprobante arma una línea por tipo de pasaje repartiendo el total del comprobante
+// en proporción a la tarifa de cada tipo, de modo que las líneas sumen exactamente el total
+func (s *ImpresionService) lineasComprobante(c *entidades.ComprobantePago, pasajes []entidades.PasajeCantidad) ([]impresion.LineaComprobante, error) {
+	if len(pasajes) == 0 {
+		return []impresion.LineaComprobante{{
+			Cantidad:       1,
+			Descripcion:    fmt.Sprintf("SERVICIO DE TOUR %s", strings.ToUpper(c.NombreTour)),
+			PrecioUnitario: c.Total,
+			Importe:        c.Total,
+		}}, nil
+	}
+
+	// Peso de cada línea: tarifa por cantidad (o solo la cantidad si no hay tarifas)
+	pesos := make([]int64, len(pasajes))
+	var totalPesos int64
+	for i, p := range pasajes {
+		precio := p.PrecioUnitario
+		if precio == 0 {
+			// Reservas registradas antes de guardar la tarifa: usar el costo general
+			tipoPasaje, err := s.tipoPasajeRepo.GetByID(p.IDTipoPasaje)
+			if err != nil {
+				return nil, err
+			}
+			precio = tipoPasaje.Costo
+		}
+		pesos[i] = aCentimos(precio) * int64(p.Cantidad)
+		totalPesos += pesos[i]
+	}
+	if totalPesos == 0 {
+		for i, p := range pasajes {
+			pesos[i] = int64(p.Cantidad)
+			totalPesos += pesos[i]
+		}
+	}
+
+	totalCentimos := aCentimos(c.Total)
+	lineas := make([]impresion.LineaComprobante, len(pasajes))
+	var asignado int64
+	for i, p := range pasajes {
+		importe := totalCentimos * pesos[i] / totalPesos
+		if i == len(pasajes)-1 {
+			importe = totalCentimos - asignado
+		}
+		asignado += importe
+
+		lineas[i] = impresion.LineaComprobante{
+			Cantidad:       p.Cantidad,
+			Descripcion:    fmt.Sprintf("TOUR %s - PASAJE %s", strings.ToUpper(c.NombreTour), strings.ToUpper(p.NombreTipo)),
+			PrecioUnitario: float64(importe) / 100 / float64(p.Cantidad),
+			Importe:        float64(importe) / 100,
+		}
+	}
+
+	return lineas, nil
+}
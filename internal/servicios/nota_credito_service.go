@@ -0,0 +1,193 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+)
+
+// Códigos de motivo de nota de crédito (catálogo 09 de SUNAT)
+const (
+	motivoAnulacionOperacion = "01"
+	motivoDisminucionValor   = "09"
+)
+
+// NotaCreditoService maneja la lógica de negocio para notas de crédito
+type NotaCreditoService struct {
+	db              *sql.DB
+	notaRepo        *repositorios.NotaCreditoRepository
+	comprobanteRepo *repositorios.ComprobantePagoRepository
+	reservaRepo     *repositorios.ReservaRepository
+	pagoRepo        *repositorios.PagoRepository
+	metodoPagoRepo  *repositorios.MetodoPagoRepository
+	canalVentaRepo  *repositorios.CanalVentaRepository
+}
+
+// NewNotaCreditoService crea una nueva instancia de NotaCreditoService
+func NewNotaCreditoService(
+	db *sql.DB,
+	notaRepo *repositorios.NotaCreditoRepository,
+	comprobanteRepo *repositorios.ComprobantePagoRepository,
+	reservaRepo *repositorios.ReservaRepository,
+	pagoRepo *repositorios.PagoRepository,
+	metodoPagoRepo *repositorios.MetodoPagoRepository,
+	canalVentaRepo *repositorios.CanalVentaRepository,
+) *NotaCreditoService {
+	return &NotaCreditoService{
+		db:              db,
+		notaRepo:        notaRepo,
+		comprobanteRepo: comprobanteRepo,
+		reservaRepo:     reservaRepo,
+		pagoRepo:        pagoRepo,
+		metodoPagoRepo:  metodoPagoRepo,
+		canalVentaRepo:  canalVentaRepo,
+	}
+}
+
+// Create emite una nota de crédito sobre un comprobante y, si se indica, registra la devolución al cliente
+func (s *NotaCreditoService) Create(req *entidades.NuevaNotaCreditoRequest) (int, error) {
+	// Verificar que el comprobante existe
+	comprobante, err := s.comprobanteRepo.GetByID(req.IDComprobante)
+	if err != nil {
+		return 0, err
+	}
+
+	// Solo se acreditan comprobantes vigentes
+	if comprobante.Estado != "EMITIDO" {
+		return 0, errors.New("solo se pueden emitir notas de crédito sobre comprobantes emitidos")
+	}
+	if comprobante.EstadoSunat == "RECHAZADO" {
+		return 0, errors.New("no se puede emitir una nota de crédito sobre un comprobante rechazado por SUNAT")
+	}
+
+	// Verificar el método de pago y el canal de la devolución
+	if req.IDMetodoPago > 0 {
+		if _, err := s.metodoPagoRepo.GetByID(req.IDMetodoPago); err != nil {
+			return 0, errors.New("el método de pago especificado no existe")
+		}
+		if _, err := s.canalVentaRepo.GetByID(req.IDCanal); err != nil {
+			return 0, errors.New("el canal de venta especificado no existe")
+		}
+	}
+
+	var id int
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para serializar comprobantes, pagos y notas de crédito
+		if _, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(comprobante.IDReserva); err != nil {
+			return err
+		}
+
+		// Volver a leer el comprobante con la reserva bloqueada por si se anuló mientras tanto
+		comprobante, err := s.comprobanteRepo.WithTx(tx).GetByID(req.IDComprobante)
+		if err != nil {
+			return err
+		}
+		if comprobante.Estado != "EMITIDO" {
+			return errors.New("solo se pueden emitir notas de crédito sobre comprobantes emitidos")
+		}
+
+		// Verificar que el monto no exceda el saldo no acreditado del comprobante
+		acreditado, err := s.notaRepo.WithTx(tx).GetTotalByComprobante(comprobante.ID)
+		if err != nil {
+			return err
+		}
+
+		saldoCentimos := aCentimos(comprobante.Total) - aCentimos(acreditado)
+		if saldoCentimos <= 0 {
+			return errors.New("el comprobante ya fue acreditado por completo")
+		}
+
+		montoCentimos := saldoCentimos
+		if req.Monto > 0 {
+			montoCentimos = aCentimos(req.Monto)
+		}
+		if montoCentimos > saldoCentimos {
+			return fmt.Errorf("el monto excede el saldo no acreditado del comprobante (%.2f)", float64(saldoCentimos)/100)
+		}
+
+		// Acreditar todo el comprobante equivale a anular la operación
+		codigoMotivo := motivoDisminucionValor
+		if aCentimos(acreditado) == 0 && montoCentimos == saldoCentimos {
+			codigoMotivo = motivoAnulacionOperacion
+		}
+
+		subtotal, igv, total := calcularMontosComprobante(float64(montoCentimos) / 100)
+
+		// Obtener serie y correlativo propios de las notas de crédito
+		serie, correlativo, err := s.comprobanteRepo.WithTx(tx).SiguienteCorrelativo("NOTA_CREDITO_" + comprobante.Tipo)
+		if err != nil {
+			return err
+		}
+		numeroNota := fmt.Sprintf("%s-%08d", serie, correlativo)
+
+		// Registrar la devolución sin superar lo efectivamente pagado
+		var idPago *int
+		if req.IDMetodoPago > 0 {
+			totalPagado, err := s.pagoRepo.WithTx(tx).GetTotalPagadoByReserva(comprobante.IDReserva)
+			if err != nil {
+				return err
+			}
+			if montoCentimos > aCentimos(totalPagado) {
+				return errors.New("el monto de la devolución excede el total pagado por la reserva")
+			}
+
+			idDevolucion, err := s.pagoRepo.WithTx(tx).CreateDevolucion(comprobante.IDReserva, req.IDMetodoPago, req.IDCanal, total, numeroNota)
+			if err != nil {
+				return err
+			}
+			idPago = &idDevolucion
+		}
+
+		nota := &entidades.NotaCredito{
+			IDComprobante: comprobante.ID,
+			IDPago:        idPago,
+			Serie:         serie,
+			Correlativo:   correlativo,
+			NumeroNota:    numeroNota,
+			CodigoMotivo:  codigoMotivo,
+			Motivo:        req.Motivo,
+			Subtotal:      subtotal,
+			IGV:           igv,
+			Total:         total,
+		}
+
+		// Crear nota de crédito
+		id, err = s.notaRepo.WithTx(tx).Create(nota)
+		if err != nil {
+			return err
+		}
+
+		// La nota disminuye el valor de la venta: el total de la reserva baja en lo acreditado
+		// para que la devolución no vuelva a aparecer como saldo pendiente
+		return s.reservaRepo.WithTx(tx).DescontarTotal(comprobante.IDReserva, total)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetByID obtiene una nota de crédito por su ID
+func (s *NotaCreditoService) GetByID(id int) (*entidades.NotaCredito, error) {
+	return s.notaRepo.GetByID(id)
+}
+
+// List lista todas las notas de crédito
+func (s *NotaCreditoService) List() ([]*entidades.NotaCredito, error) {
+	return s.notaRepo.List()
+}
+
+// ListByComprobante lista las notas de crédito de un comprobante
+func (s *NotaCreditoService) ListByComprobante(idComprobante int) ([]*entidades.NotaCredito, error) {
+	// Verificar que el comprobante existe
+	_, err := s.comprobanteRepo.GetByID(idComprobante)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.notaRepo.ListByComprobante(idComprobante)
+}
@@ -1,2 +1,174 @@
-// Gestión de pagos y comprobantes
 package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"time"
+)
+
+// PagoService maneja la lógica de negocio para pagos
+type PagoService struct {
+	db             *sql.DB
+	pagoRepo       *repositorios.PagoRepository
+	reservaRepo    *repositorios.ReservaRepository
+	metodoPagoRepo *repositorios.MetodoPagoRepository
+	canalVentaRepo *repositorios.CanalVentaRepository
+}
+
+// NewPagoService crea una nueva instancia de PagoService
+func NewPagoService(
+	db *sql.DB,
+	pagoRepo *repositorios.PagoRepository,
+	reservaRepo *repositorios.ReservaRepository,
+	metodoPagoRepo *repositorios.MetodoPagoRepository,
+	canalVentaRepo *repositorios.CanalVentaRepository,
+) *PagoService {
+	return &PagoService{
+		db:             db,
+		pagoRepo:       pagoRepo,
+		reservaRepo:    reservaRepo,
+		metodoPagoRepo: metodoPagoRepo,
+		canalVentaRepo: canalVentaRepo,
+	}
+}
+
+// Create registra un nuevo pago para una reserva
+func (s *PagoService) Create(pago *entidades.NuevoPagoRequest) (int, error) {
+	// Verificar que el monto sea positivo
+	if pago.Monto <= 0 {
+		return 0, errors.New("el monto del pago debe ser mayor a cero")
+	}
+
+	// Verificar que el método de pago existe
+	_, err := s.metodoPagoRepo.GetByID(pago.IDMetodoPago)
+	if err != nil {
+		return 0, errors.New("el método de pago especificado no existe")
+	}
+
+	// Verificar que el canal de venta existe
+	_, err = s.canalVentaRepo.GetByID(pago.IDCanal)
+	if err != nil {
+		return 0, errors.New("el canal de venta especificado no existe")
+	}
+
+	var id int
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para que dos pagos simultáneos no superen el total
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(pago.IDReserva)
+		if err != nil {
+			return errors.New("la reserva especificada no existe")
+		}
+
+		// Verificar que la reserva no esté cancelada
+		if reserva.Estado == "CANCELADA" {
+			return errors.New("no se pueden registrar pagos para una reserva cancelada")
+		}
+
+		// Una retención web vencida ya no admite pagos aunque el barrido aún no la haya liberado
+		if retencionVencida(reserva, time.Now()) {
+			return errors.New("la retención de la reserva ha vencido, debe registrar una nueva reserva")
+		}
+
+		// Verificar que el total pagado no exceda el total de la reserva
+		totalPagado, err := s.pagoRepo.WithTx(tx).GetTotalPagadoByReserva(pago.IDReserva)
+		if err != nil {
+			return err
+		}
+
+		if aCentimos(totalPagado+pago.Monto) > aCentimos(reserva.TotalPagar) {
+			return errors.New("el monto del pago excede el saldo pendiente de la reserva")
+		}
+
+		// Crear pago
+		id, err = s.pagoRepo.WithTx(tx).Create(pago)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetByID obtiene un pago por su ID
+func (s *PagoService) GetByID(id int) (*entidades.Pago, error) {
+	return s.pagoRepo.GetByID(id)
+}
+
+// Anular anula un pago procesado
+func (s *PagoService) Anular(id int) error {
+	// Verificar que el pago existe
+	pago, err := s.pagoRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Verificar que el pago no esté anulado
+	if pago.Estado == "ANULADO" {
+		return errors.New("el pago ya se encuentra anulado")
+	}
+
+	// Las devoluciones quedan vinculadas a su nota de crédito
+	if pago.Tipo == "DEVOLUCION" {
+		return errors.New("las devoluciones vinculadas a una nota de crédito no se pueden anular")
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para serializar la anulación con los pagos y cambios de estado que recalculan el total pagado
+		if _, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(pago.IDReserva); err != nil {
+			return err
+		}
+
+		// Volver a leer el pago con la reserva bloqueada por si otra anulación se adelantó
+		pago, err := s.pagoRepo.WithTx(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+		if pago.Estado == "ANULADO" {
+			return errors.New("el pago ya se encuentra anulado")
+		}
+
+		// Anular pago
+		return s.pagoRepo.WithTx(tx).UpdateEstado(id, "ANULADO")
+	})
+}
+
+// List lista todos los pagos
+func (s *PagoService) List() ([]*entidades.Pago, error) {
+	return s.pagoRepo.List()
+}
+
+// ListByReserva lista todos los pagos de una reserva
+func (s *PagoService) ListByReserva(idReserva int) ([]*entidades.Pago, error) {
+	// Verificar que la reserva existe
+	_, err := s.reservaRepo.GetByID(idReserva)
+	if err != nil {
+		return nil, errors.New("la reserva especificada no existe")
+	}
+
+	return s.pagoRepo.ListByReserva(idReserva)
+}
+
+// ListByFecha lista todos los pagos de una fecha específica
+func (s *PagoService) ListByFecha(fecha time.Time) ([]*entidades.Pago, error) {
+	return s.pagoRepo.ListByFecha(fecha)
+}
+
+// ListByEstado lista todos los pagos por estado
+func (s *PagoService) ListByEstado(estado string) ([]*entidades.Pago, error) {
+	// Verificar que el estado es válido
+	if estado != "PROCESADO" && estado != "ANULADO" {
+		return nil, errors.New("estado de pago inválido")
+	}
+
+	return s.pagoRepo.ListByEstado(estado)
+}
+
+// aCentimos convierte un monto en soles a céntimos para comparar sin errores de redondeo
+func aCentimos(monto float64) int64 {
+	return int64(math.Round(monto * 100))
+}
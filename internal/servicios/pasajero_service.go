@@ -0,0 +1,235 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"time"
+)
+
+// PasajeroService maneja la lógica de negocio para el manifiesto de pasajeros de las reservas
+type PasajeroService struct {
+	db                 *sql.DB
+	pasajeroRepo       *repositorios.PasajeroRepository
+	reservaRepo        *repositorios.ReservaRepository
+	tipoPasajeRepo     *repositorios.TipoPasajeRepository
+	tourProgramadoRepo *repositorios.TourProgramadoRepository
+}
+
+// NewPasajeroService crea una nueva instancia de PasajeroService
+func NewPasajeroService(
+	db *sql.DB,
+	pasajeroRepo *repositorios.PasajeroRepository,
+	reservaRepo *repositorios.ReservaRepository,
+	tipoPasajeRepo *repositorios.TipoPasajeRepository,
+	tourProgramadoRepo *repositorios.TourProgramadoRepository,
+) *PasajeroService {
+	return &PasajeroService{
+		db:                 db,
+		pasajeroRepo:       pasajeroRepo,
+		reservaRepo:        reservaRepo,
+		tipoPasajeRepo:     tipoPasajeRepo,
+		tourProgramadoRepo: tourProgramadoRepo,
+	}
+}
+
+// Create registra un pasajero en una reserva
+func (s *PasajeroService) Create(idReserva int, pasajero *entidades.NuevoPasajeroRequest, actor Actor) (int, error) {
+	var id int
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para que los pasajeros se validen contra sus pasajes actuales
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(idReserva)
+		if err != nil {
+			return err
+		}
+
+		if err := verificarAccesoReserva(reserva, actor); err != nil {
+			return err
+		}
+		if err := verificarManifiestoEditable(reserva); err != nil {
+			return err
+		}
+
+		datos := entidades.ActualizarPasajeroRequest(*pasajero)
+		if err := s.validarPasajero(tx, reserva, &datos, nil); err != nil {
+			return err
+		}
+
+		id, err = s.pasajeroRepo.WithTx(tx).Create(idReserva, pasajero)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetByID obtiene un pasajero por su ID
+func (s *PasajeroService) GetByID(id int, actor Actor) (*entidades.Pasajero, error) {
+	pasajero, err := s.pasajeroRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verificar que el actor puede ver la reserva del pasajero
+	reserva, err := s.reservaRepo.GetByID(pasajero.IDReserva)
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarAccesoReserva(reserva, actor); err != nil {
+		return nil, err
+	}
+
+	return pasajero, nil
+}
+
+// Update actualiza los datos de un pasajero
+func (s *PasajeroService) Update(id int, pasajero *entidades.ActualizarPasajeroRequest, actor Actor) error {
+	// Verificar que el pasajero existe
+	existing, err := s.pasajeroRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(existing.IDReserva)
+		if err != nil {
+			return err
+		}
+
+		if err := verificarAccesoReserva(reserva, actor); err != nil {
+			return err
+		}
+		if err := verificarManifiestoEditable(reserva); err != nil {
+			return err
+		}
+
+		if err := s.validarPasajero(tx, reserva, pasajero, existing); err != nil {
+			return err
+		}
+
+		return s.pasajeroRepo.WithTx(tx).Update(id, pasajero)
+	})
+}
+
+// Delete elimina un pasajero de una reserva
+func (s *PasajeroService) Delete(id int, actor Actor) error {
+	// Verificar que el pasajero existe
+	existing, err := s.pasajeroRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(existing.IDReserva)
+		if err != nil {
+			return err
+		}
+
+		if err := verificarAccesoReserva(reserva, actor); err != nil {
+			return err
+		}
+		if err := verificarManifiestoEditable(reserva); err != nil {
+			return err
+		}
+
+		return s.pasajeroRepo.WithTx(tx).Delete(id)
+	})
+}
+
+// ListByReserva lista los pasajeros de una reserva
+func (s *PasajeroService) ListByReserva(idReserva int, actor Actor) ([]*entidades.Pasajero, error) {
+	// Verificar que la reserva existe y que el actor puede verla
+	reserva, err := s.reservaRepo.GetByID(idReserva)
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarAccesoReserva(reserva, actor); err != nil {
+		return nil, err
+	}
+
+	return s.pasajeroRepo.ListByReserva(idReserva)
+}
+
+// validarPasajero verifica que el pasajero corresponda a un pasaje libre de la reserva,
+// que su edad a la fecha del tour cumpla las reglas del tipo de pasaje y que su documento no esté repetido.
+// anterior es el pasajero que se está actualizando, o nil si es un pasajero nuevo.
+func (s *PasajeroService) validarPasajero(tx *sql.Tx, reserva *entidades.Reserva, pasajero *entidades.ActualizarPasajeroRequest, anterior *entidades.Pasajero) error {
+	// Verificar que la reserva incluye pasajes de ese tipo y que queda alguno sin pasajero
+	cantidades, err := s.reservaRepo.WithTx(tx).GetCantidadesPorTipo(reserva.ID)
+	if err != nil {
+		return err
+	}
+	cantidad, ok := cantidades[pasajero.IDTipoPasaje]
+	if !ok {
+		return errors.New("la reserva no incluye pasajes del tipo indicado")
+	}
+
+	registrados, err := s.pasajeroRepo.WithTx(tx).CountByReservaPorTipo(reserva.ID)
+	if err != nil {
+		return err
+	}
+	if anterior != nil {
+		registrados[anterior.IDTipoPasaje]--
+	}
+	if registrados[pasajero.IDTipoPasaje] >= cantidad {
+		return fmt.Errorf("la reserva ya tiene registrados los %d pasajeros de ese tipo de pasaje", cantidad)
+	}
+
+	// Verificar la edad del pasajero a la fecha del tour
+	tipoPasaje, err := s.tipoPasajeRepo.GetByID(pasajero.IDTipoPasaje)
+	if err != nil {
+		return err
+	}
+	tour, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
+	if err != nil {
+		return err
+	}
+	edad := calcularEdad(pasajero.FechaNacimiento, tour.Fecha)
+	if edad < 0 {
+		return errors.New("la fecha de nacimiento no puede ser posterior a la fecha del tour")
+	}
+	if tipoPasaje.EdadMinima != nil && edad < *tipoPasaje.EdadMinima {
+		return fmt.Errorf("el pasaje %s requiere una edad mínima de %d años", tipoPasaje.Nombre, *tipoPasaje.EdadMinima)
+	}
+	if tipoPasaje.EdadMaxima != nil && edad > *tipoPasaje.EdadMaxima {
+		return fmt.Errorf("el pasaje %s admite una edad máxima de %d años", tipoPasaje.Nombre, *tipoPasaje.EdadMaxima)
+	}
+
+	// Verificar que el documento no esté repetido en la reserva
+	excluirID := 0
+	if anterior != nil {
+		excluirID = anterior.ID
+	}
+	existe, err := s.pasajeroRepo.WithTx(tx).ExisteDocumento(reserva.ID, pasajero.TipoDocumento, pasajero.NumeroDocumento, excluirID)
+	if err != nil {
+		return err
+	}
+	if existe {
+		return errors.New("ya existe un pasajero con ese documento en la reserva")
+	}
+
+	return nil
+}
+
+// verificarManifiestoEditable comprueba que la reserva siga abierta para modificar sus pasajeros
+func verificarManifiestoEditable(reserva *entidades.Reserva) error {
+	switch reserva.Estado {
+	case "CANCELADA", "EMBARCADO", "COMPLETADA", "NO_SHOW":
+		return fmt.Errorf("no se pueden modificar los pasajeros de una reserva en estado %s", reserva.Estado)
+	}
+	return nil
+}
+
+// calcularEdad devuelve los años cumplidos a una fecha dada
+func calcularEdad(nacimiento, fecha time.Time) int {
+	edad := fecha.Year() - nacimiento.Year()
+	if fecha.Month() < nacimiento.Month() || (fecha.Month() == nacimiento.Month() && fecha.Day() < nacimiento.Day()) {
+		edad--
+	}
+	return edad
+}
@@ -0,0 +1,224 @@
+package servicios
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"strings"
+	"time"
+)
+
+// ErrLoginBloqueado indica que la cuenta o la IP deben esperar antes de volver a intentar iniciar sesión.
+// Los controladores lo responden con 429.
+var ErrLoginBloqueado = errors.New("demasiados intentos fallidos de inicio de sesión")
+
+// BloqueoLoginError indica cuánto falta para que se permita el siguiente intento
+type BloqueoLoginError struct {
+	Espera time.Duration
+}
+
+// Error implementa la interfaz error
+func (e *BloqueoLoginError) Error() string {
+	return fmt.Sprintf("%s; intente de nuevo en %s", ErrLoginBloqueado, e.Espera.Round(time.Second))
+}
+
+// Unwrap permite comparar el error con ErrLoginBloqueado
+func (e *BloqueoLoginError) Unwrap() error {
+	return ErrLoginBloqueado
+}
+
+// Motivos de rechazo registrados en la auditoría de inicios de sesión
+const (
+	MotivoLoginCredenciales = "CREDENCIALES"
+	MotivoLoginBloqueado    = "BLOQUEADO"
+	MotivoLoginDesactivado  = "DESACTIVADO"
+	MotivoLoginDobleFactor  = "DOBLE_FACTOR"
+)
+
+// limiteAuditoriaLogin es la cantidad de intentos que devuelve la consulta de auditoría
+const limiteAuditoriaLogin = 100
+
+// ProteccionLoginService limita los intentos de inicio de sesión por cuenta y por IP
+// y registra cada intento en la auditoría
+type ProteccionLoginService struct {
+	controlLoginRepo   *repositorios.ControlLoginRepository
+	auditoriaLoginRepo *repositorios.AuditoriaLoginRepository
+	config             *config.Config
+}
+
+// NewProteccionLoginService crea una nueva instancia de ProteccionLoginService
+func NewProteccionLoginService(
+	controlLoginRepo *repositorios.ControlLoginRepository,
+	auditoriaLoginRepo *repositorios.AuditoriaLoginRepository,
+	config *config.Config,
+) *ProteccionLoginService {
+	return &ProteccionLoginService{
+		controlLoginRepo:   controlLoginRepo,
+		auditoriaLoginRepo: auditoriaLoginRepo,
+		config:             config,
+	}
+}
+
+// Verificar devuelve un *BloqueoLoginError si la cuenta o la IP todavía deben esperar antes de otro intento
+func (s *ProteccionLoginService) Verificar(tipoSujeto, correo, ip string) error {
+	ahora := time.Now()
+
+	espera := s.esperaClave(claveCuenta(tipoSujeto, correo), s.config.LoginMaxFallos, true, ahora)
+	if ip != "" {
+		// Las IP solo se bloquean al llegar al máximo: varios clientes pueden compartir una IP
+		if esperaIP := s.esperaClave(claveIP(ip), s.config.LoginMaxFallosIP, false, ahora); esperaIP > espera {
+			espera = esperaIP
+		}
+	}
+
+	if espera > 0 {
+		return &BloqueoLoginError{Espera: espera}
+	}
+	return nil
+}
+
+// Espera calcula cuánto debe esperar una cuenta o IP antes del próximo intento.
+// Con maxFallos fallos se bloquea por LoginBloqueo; con retraso, desde el segundo fallo se exige
+// una espera de LoginRetrasoBase que se duplica con cada fallo siguiente.
+func (s *ProteccionLoginService) Espera(control *entidades.ControlLogin, maxFallos int, retraso bool, ahora time.Time) time.Duration {
+	if control.BloqueadoHasta != nil && ahora.Before(*control.BloqueadoHasta) {
+		return control.BloqueadoHasta.Sub(ahora)
+	}
+
+	// Sin fallos recientes, o con un bloqueo que ya terminó, no hay espera
+	if !retraso || control.UltimoFallo == nil || control.Fallos < 2 || control.Fallos >= maxFallos {
+		return 0
+	}
+	if ahora.Sub(*control.UltimoFallo) >= s.config.LoginBloqueo {
+		return 0
+	}
+
+	espera := s.config.LoginBloqueo
+	if exponente := control.Fallos - 2; exponente < 30 {
+		if retrasoExponencial := s.config.LoginRetrasoBase << exponente; retrasoExponencial < espera {
+			espera = retrasoExponencial
+		}
+	}
+
+	if fin := control.UltimoFallo.Add(espera); ahora.Before(fin) {
+		return fin.Sub(ahora)
+	}
+	return 0
+}
+
+// RegistrarFallo suma un fallo a la cuenta y a la IP, las bloquea si llegaron al máximo y audita el intento
+func (s *ProteccionLoginService) RegistrarFallo(tipoSujeto, correo, ip string, idSujeto *int, motivo string) {
+	ahora := time.Now()
+
+	s.sumarFallo(claveCuenta(tipoSujeto, correo), s.config.LoginMaxFallos, ahora)
+	if ip != "" {
+		s.sumarFallo(claveIP(ip), s.config.LoginMaxFallosIP, ahora)
+	}
+
+	s.Auditar(tipoSujeto, correo, ip, idSujeto, false, motivo)
+}
+
+// RegistrarExito reinicia los fallos de la cuenta y audita el inicio de sesión
+func (s *ProteccionLoginService) RegistrarExito(tipoSujeto, correo, ip string, idSujeto int) {
+	if _, err := s.controlLoginRepo.Delete(claveCuenta(tipoSujeto, correo)); err != nil {
+		log.Printf("Error al reiniciar los fallos de inicio de sesión de %s: %v", correo, err)
+	}
+
+	s.Auditar(tipoSujeto, correo, ip, &idSujeto, true, "")
+}
+
+// Auditar registra un intento de inicio de sesión. Un error al auditar no impide el inicio de sesión.
+func (s *ProteccionLoginService) Auditar(tipoSujeto, correo, ip string, idSujeto *int, exitoso bool, motivo string) {
+	_, err := s.auditoriaLoginRepo.Create(&entidades.AuditoriaLogin{
+		TipoSujeto: tipoSujeto,
+		Correo:     normalizarCorreo(correo),
+		IDSujeto:   idSujeto,
+		IP:         ip,
+		Exitoso:    exitoso,
+		Motivo:     motivo,
+	})
+	if err != nil {
+		log.Printf("Error al auditar el inicio de sesión de %s: %v", correo, err)
+	}
+}
+
+// Desbloquear elimina los fallos y el bloqueo de una cuenta o una IP
+func (s *ProteccionLoginService) Desbloquear(req *entidades.DesbloquearLoginRequest) error {
+	if req.Correo == "" && req.IP == "" {
+		return errors.New("debe indicar el correo de la cuenta o la IP a desbloquear")
+	}
+	if req.Correo != "" && req.TipoSujeto == "" {
+		return errors.New("debe indicar si el correo es de un USUARIO o de un CLIENTE")
+	}
+
+	desbloqueado := false
+	if req.Correo != "" {
+		eliminado, err := s.controlLoginRepo.Delete(claveCuenta(req.TipoSujeto, req.Correo))
+		if err != nil {
+			return err
+		}
+		desbloqueado = desbloqueado || eliminado
+	}
+	if req.IP != "" {
+		eliminado, err := s.controlLoginRepo.Delete(claveIP(req.IP))
+		if err != nil {
+			return err
+		}
+		desbloqueado = desbloqueado || eliminado
+	}
+
+	if !desbloqueado {
+		return errors.New("no hay intentos fallidos registrados para desbloquear")
+	}
+	return nil
+}
+
+// ListAuditoriaByCorreo lista los intentos de inicio de sesión más recientes de un correo
+func (s *ProteccionLoginService) ListAuditoriaByCorreo(correo string) ([]*entidades.AuditoriaLogin, error) {
+	return s.auditoriaLoginRepo.ListByCorreo(normalizarCorreo(correo), limiteAuditoriaLogin)
+}
+
+// esperaClave consulta el control de una clave y calcula su espera. Si no se puede consultar,
+// se permite el intento para que una falla de la base de datos no bloquee todos los inicios de sesión.
+func (s *ProteccionLoginService) esperaClave(clave string, maxFallos int, retraso bool, ahora time.Time) time.Duration {
+	control, err := s.controlLoginRepo.GetByClave(clave)
+	if err != nil {
+		return 0
+	}
+	return s.Espera(control, maxFallos, retraso, ahora)
+}
+
+// sumarFallo suma un fallo a la clave y la bloquea si llegó al máximo
+func (s *ProteccionLoginService) sumarFallo(clave string, maxFallos int, ahora time.Time) {
+	fallos, err := s.controlLoginRepo.RegistrarFallo(clave, ahora, ahora.Add(-s.config.LoginBloqueo))
+	if err != nil {
+		log.Printf("Error al registrar el fallo de inicio de sesión de %s: %v", clave, err)
+		return
+	}
+
+	if fallos >= maxFallos {
+		if err := s.controlLoginRepo.Bloquear(clave, ahora.Add(s.config.LoginBloqueo)); err != nil {
+			log.Printf("Error al bloquear el inicio de sesión de %s: %v", clave, err)
+			return
+		}
+		log.Printf("Inicio de sesión bloqueado para %s tras %d fallos", clave, fallos)
+	}
+}
+
+// claveCuenta identifica el control de fallos de una cuenta
+func claveCuenta(tipoSujeto, correo string) string {
+	return tipoSujeto + ":" + normalizarCorreo(correo)
+}
+
+// claveIP identifica el control de fallos de una IP
+func claveIP(ip string) string {
+	return "IP:" + ip
+}
+
+// normalizarCorreo evita que variaciones de mayúsculas o espacios cuenten como cuentas distintas
+func normalizarCorreo(correo string) string {
+	return strings.ToLower(strings.TrimSpace(correo))
+}
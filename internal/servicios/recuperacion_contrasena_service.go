@@ -0,0 +1,164 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/correo"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/utils"
+	"time"
+)
+
+// ErrTokenRecuperacionInvalido indica que el token no existe, ya se usó o venció
+var ErrTokenRecuperacionInvalido = errors.New("el enlace de recuperación no es válido o ya venció")
+
+// RecuperacionContrasenaService maneja la recuperación de contraseña por correo electrónico
+type RecuperacionContrasenaService struct {
+	db                    *sql.DB
+	usuarioRepo           *repositorios.UsuarioRepository
+	clienteRepo           *repositorios.ClienteRepository
+	tokenRecuperacionRepo *repositorios.TokenRecuperacionRepository
+	refreshTokenRepo      *repositorios.RefreshTokenRepository
+	enviador              correo.Enviador
+	config                *config.Config
+}
+
+// NewRecuperacionContrasenaService crea una nueva instancia de RecuperacionContrasenaService
+func NewRecuperacionContrasenaService(
+	db *sql.DB,
+	usuarioRepo *repositorios.UsuarioRepository,
+	clienteRepo *repositorios.ClienteRepository,
+	tokenRecuperacionRepo *repositorios.TokenRecuperacionRepository,
+	refreshTokenRepo *repositorios.RefreshTokenRepository,
+	enviador correo.Enviador,
+	config *config.Config,
+) *RecuperacionContrasenaService {
+	return &RecuperacionContrasenaService{
+		db:                    db,
+		usuarioRepo:           usuarioRepo,
+		clienteRepo:           clienteRepo,
+		tokenRecuperacionRepo: tokenRecuperacionRepo,
+		refreshTokenRepo:      refreshTokenRepo,
+		enviador:              enviador,
+		config:                config,
+	}
+}
+
+// SolicitarUsuario envía el enlace de recuperación a un usuario del sistema.
+// Si el correo no corresponde a un usuario activo no se envía nada. El enlace se emite y se envía
+// en segundo plano, así que la respuesta no revela, ni por su resultado ni por su demora, qué correos están registrados.
+func (s *RecuperacionContrasenaService) SolicitarUsuario(correoUsuario string) {
+	usuario, err := s.usuarioRepo.GetByEmail(correoUsuario)
+	if err != nil || !usuario.Estado {
+		return
+	}
+
+	go s.enviarToken(utils.SujetoUsuario, usuario.ID, usuario.Correo, usuario.Nombres)
+}
+
+// SolicitarCliente envía el enlace de recuperación a un cliente.
+// Si el correo no corresponde a un cliente no se envía nada; el envío ocurre en segundo plano.
+func (s *RecuperacionContrasenaService) SolicitarCliente(correoCliente string) {
+	cliente, err := s.clienteRepo.GetByCorreo(correoCliente)
+	if err != nil {
+		return
+	}
+
+	go s.enviarToken(utils.SujetoCliente, cliente.ID, cliente.Correo, cliente.Nombres)
+}
+
+// Restablecer cambia la contraseña con un token de recuperación, que queda usado,
+// y cierra todas las sesiones abiertas del usuario o cliente
+func (s *RecuperacionContrasenaService) Restablecer(req *entidades.RestablecerContrasenaRequest) error {
+	// Hash de la nueva contraseña (fuera de la transacción porque es lento)
+	hashedPassword, err := utils.HashPassword(req.NuevaContrasena)
+	if err != nil {
+		return err
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		tokenRecuperacionRepo := s.tokenRecuperacionRepo.WithTx(tx)
+
+		token, err := tokenRecuperacionRepo.GetByHashForUpdate(utils.HashToken(req.Token))
+		if err != nil {
+			return ErrTokenRecuperacionInvalido
+		}
+		if token.FechaUso != nil || !time.Now().Before(token.FechaExpiracion) {
+			return ErrTokenRecuperacionInvalido
+		}
+
+		// Actualizar contraseña
+		switch token.TipoSujeto {
+		case utils.SujetoUsuario:
+			err = s.usuarioRepo.WithTx(tx).UpdatePassword(token.IDSujeto, hashedPassword)
+		case utils.SujetoCliente:
+			err = s.clienteRepo.WithTx(tx).UpdatePassword(token.IDSujeto, hashedPassword)
+		default:
+			err = ErrTokenRecuperacionInvalido
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tokenRecuperacionRepo.MarcarUsado(token.ID); err != nil {
+			return err
+		}
+
+		// Quien tuviera la contraseña anterior pierde sus sesiones
+		return s.refreshTokenRepo.WithTx(tx).RevocarBySujeto(token.TipoSujeto, token.IDSujeto)
+	})
+}
+
+// enviarToken emite un token nuevo (invalidando los anteriores) y lo envía por correo.
+// Se ejecuta en segundo plano, así que los errores solo se registran en el log.
+func (s *RecuperacionContrasenaService) enviarToken(tipoSujeto string, idSujeto int, destinatario, nombre string) {
+	token, err := utils.GenerarTokenAleatorio(32)
+	if err != nil {
+		log.Printf("Error al generar el token de recuperación de %s %d: %v", tipoSujeto, idSujeto, err)
+		return
+	}
+	vigencia := s.config.RecuperacionVigencia
+
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		tokenRecuperacionRepo := s.tokenRecuperacionRepo.WithTx(tx)
+
+		if err := tokenRecuperacionRepo.InvalidarBySujeto(tipoSujeto, idSujeto); err != nil {
+			return err
+		}
+
+		_, err := tokenRecuperacionRepo.Create(&entidades.TokenRecuperacion{
+			TipoSujeto:      tipoSujeto,
+			IDSujeto:        idSujeto,
+			TokenHash:       utils.HashToken(token),
+			FechaExpiracion: time.Now().Add(vigencia),
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("Error al registrar el token de recuperación de %s %d: %v", tipoSujeto, idSujeto, err)
+		return
+	}
+
+	enlace := s.config.RecuperacionURL + "?token=" + url.QueryEscape(token)
+	mensaje := correo.Mensaje{
+		Para:   destinatario,
+		Asunto: "Recuperación de contraseña",
+		Cuerpo: fmt.Sprintf(
+			"Hola %s:\n\nRecibimos una solicitud para restablecer tu contraseña. "+
+				"Ingresa al siguiente enlace para elegir una nueva:\n\n%s\n\n"+
+				"El enlace vence en %d minutos y solo puede usarse una vez. "+
+				"Si no solicitaste el cambio, ignora este mensaje.\n",
+			nombre, enlace, int(vigencia.Minutes()),
+		),
+	}
+
+	if err := s.enviador.Enviar(mensaje); err != nil {
+		log.Printf("Error al enviar el correo de recuperación a %s: %v", destinatario, err)
+	}
+}
@@ -0,0 +1,283 @@
+package servicios
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"time"
+)
+
+// Actor identifica a quien realiza una operación sobre una reserva.
+// ID es el ID de usuario para el personal, el ID de cliente para los clientes
+// y el ID de la llave de API para las agencias (rol AGENCIA).
+type Actor struct {
+	Rol           string
+	ID            int
+	SoloAsignados bool // Solo opera sobre los tours asignados al usuario, sea cual sea su rol
+	IDCanal       int  // Canal de venta de la llave de API, solo para el rol AGENCIA
+}
+
+// actorSistema identifica los cambios hechos por procesos automáticos
+var actorSistema = Actor{Rol: "SISTEMA"}
+
+// reglaTransicion indica quién puede hacer un cambio de estado: el personal cuyo rol incluye el permiso
+// y, con su nombre, los actores que no son personal (CLIENTE, AGENCIA o SISTEMA)
+type reglaTransicion struct {
+	permiso string
+	actores []string
+}
+
+// transicionesReserva define, para cada estado de entidades.EstadosReserva, los estados a los que puede pasar
+// y quién puede hacer ese cambio
+var transicionesReserva = map[string]map[string]reglaTransicion{
+	"PENDIENTE_PAGO": {
+		"PAGADA":    {entidades.PermisoReservasEditar, []string{"CLIENTE"}},
+		"CANCELADA": {entidades.PermisoReservasEditar, []string{"CLIENTE", "AGENCIA", "SISTEMA"}},
+	},
+	"RESERVADO": {
+		"CONFIRMADA": {entidades.PermisoReservasEditar, nil},
+		"PAGADA":     {entidades.PermisoReservasEditar, nil},
+		"NO_SHOW":    {entidades.PermisoReservasEmbarcar, nil},
+		"CANCELADA":  {entidades.PermisoReservasEditar, []string{"CLIENTE", "AGENCIA"}},
+	},
+	"CONFIRMADA": {
+		"PAGADA":    {entidades.PermisoReservasEditar, nil},
+		"NO_SHOW":   {entidades.PermisoReservasEmbarcar, nil},
+		"CANCELADA": {entidades.PermisoReservasEditar, []string{"CLIENTE", "AGENCIA"}},
+	},
+	"PAGADA": {
+		"EMBARCADO": {entidades.PermisoReservasEmbarcar, nil},
+		"NO_SHOW":   {entidades.PermisoReservasEmbarcar, nil},
+		"CANCELADA": {entidades.PermisoReservasEditar, []string{"CLIENTE", "AGENCIA"}},
+	},
+	"EMBARCADO": {
+		"COMPLETADA": {entidades.PermisoReservasEmbarcar, nil},
+	},
+	"CANCELADA": {
+		"RESERVADO": {entidades.PermisoReservasReabrir, nil},
+	},
+}
+
+// validarTransicion verifica que la reserva pueda pasar del estado actual al nuevo y que el actor pueda hacerlo:
+// los actores que no son personal por su nombre y el personal por los permisos de su rol
+func (s *ReservaService) validarTransicion(actual, nuevo string, actor Actor) error {
+	if !entidades.EsEstadoReserva(nuevo) {
+		return fmt.Errorf("estado de reserva inválido: %s", nuevo)
+	}
+
+	regla, ok := transicionesReserva[actual][nuevo]
+	if !ok {
+		return fmt.Errorf("no se puede cambiar una reserva de %s a %s", actual, nuevo)
+	}
+
+	if rolesReservados[actor.Rol] {
+		for _, permitido := range regla.actores {
+			if permitido == actor.Rol {
+				return nil
+			}
+		}
+	} else {
+		permitido, err := s.rolService.TienePermiso(actor.Rol, regla.permiso)
+		if err != nil {
+			return err
+		}
+		if permitido {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("el rol %s no puede cambiar una reserva de %s a %s", actor.Rol, actual, nuevo)
+}
+
+// cambiarEstadoTx cambia el estado de una reserva ya bloqueada en la transacción:
+// valida la transición y los permisos, aplica sus efectos sobre el cupo y los pagos,
+// actualiza el estado y registra el cambio en el historial
+func (s *ReservaService) cambiarEstadoTx(tx *sql.Tx, reserva *entidades.Reserva, nuevo string, actor Actor, motivo string) error {
+	// Un cliente (o la agencia que reservó por él) solo puede operar sobre sus propias reservas y cancelar antes del límite.
+	// La propiedad se verifica antes que la transición para no revelar el estado de reservas ajenas.
+	if actor.Rol == "CLIENTE" || actor.Rol == "AGENCIA" {
+		if err := s.verificarCambioCliente(reserva, nuevo, actor); err != nil {
+			return err
+		}
+	}
+
+	if err := s.validarTransicion(reserva.Estado, nuevo, actor); err != nil {
+		return err
+	}
+
+	// El embarque y sus cierres solo se registran desde el día del tour
+	if nuevo == "EMBARCADO" || nuevo == "NO_SHOW" || nuevo == "COMPLETADA" {
+		tour, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
+		if err != nil {
+			return err
+		}
+		if err := verificarAccesoTour(tour, actor); err != nil {
+			return err
+		}
+		if time.Now().Before(diaTour(tour)) {
+			return fmt.Errorf("el estado %s solo se puede registrar desde el día del tour", nuevo)
+		}
+	}
+
+	// Para embarcar el manifiesto debe tener un pasajero por cada pasaje
+	if nuevo == "EMBARCADO" {
+		if err := s.verificarManifiestoCompleto(tx, reserva.ID); err != nil {
+			return err
+		}
+	}
+
+	// Efectos sobre el cupo: una reserva cancelada no ocupa cupo
+	if nuevo == "CANCELADA" || reserva.Estado == "CANCELADA" {
+		totalPasajeros, err := s.reservaRepo.WithTx(tx).GetCantidadPasajerosByReserva(reserva.ID)
+		if err != nil {
+			return err
+		}
+
+		if nuevo == "CANCELADA" {
+			err = s.tourProgramadoRepo.WithTx(tx).LiberarCupo(reserva.IDTourProgramado, totalPasajeros)
+		} else {
+			err = s.tourProgramadoRepo.WithTx(tx).DescontarCupo(reserva.IDTourProgramado, totalPasajeros)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Efectos sobre los pagos
+	switch nuevo {
+	case "PAGADA":
+		// Solo se marca como pagada si los pagos cubren el total
+		totalPagado, err := s.pagoRepo.WithTx(tx).GetTotalPagadoByReserva(reserva.ID)
+		if err != nil {
+			return err
+		}
+		if aCentimos(totalPagado) < aCentimos(reserva.TotalPagar) {
+			return errors.New("la reserva aún no ha sido pagada en su totalidad")
+		}
+	case "CANCELADA":
+		if err := s.devolverPagos(tx, reserva); err != nil {
+			return err
+		}
+	}
+
+	// Actualizar estado de la reserva
+	if err := s.reservaRepo.WithTx(tx).UpdateEstado(reserva.ID, nuevo); err != nil {
+		return err
+	}
+
+	return s.registrarHistorial(tx, reserva.ID, reserva.Estado, nuevo, actor, motivo)
+}
+
+// verificarManifiestoCompleto comprueba que la reserva tenga registrados todos sus pasajeros
+func (s *ReservaService) verificarManifiestoCompleto(tx *sql.Tx, idReserva int) error {
+	totalPasajes, err := s.reservaRepo.WithTx(tx).GetCantidadPasajerosByReserva(idReserva)
+	if err != nil {
+		return err
+	}
+
+	registrados, err := s.pasajeroRepo.WithTx(tx).CountByReservaPorTipo(idReserva)
+	if err != nil {
+		return err
+	}
+	totalRegistrados := 0
+	for _, cantidad := range registrados {
+		totalRegistrados += cantidad
+	}
+
+	if totalRegistrados != totalPasajes {
+		return fmt.Errorf("la reserva tiene %d de %d pasajeros registrados en el manifiesto", totalRegistrados, totalPasajes)
+	}
+
+	return nil
+}
+
+// verificarCambioCliente comprueba que la reserva sea del cliente o de la agencia y que la cancelación llegue antes del límite
+func (s *ReservaService) verificarCambioCliente(reserva *entidades.Reserva, nuevo string, actor Actor) error {
+	if err := verificarAccesoReserva(reserva, actor); err != nil {
+		return err
+	}
+
+	// Una retención web se puede soltar en cualquier momento
+	if nuevo != "CANCELADA" || reserva.Estado == "PENDIENTE_PAGO" {
+		return nil
+	}
+
+	tour, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
+	if err != nil {
+		return err
+	}
+	if time.Now().After(inicioTour(tour).Add(-s.limiteCancelacion)) {
+		return fmt.Errorf("las reservas solo se pueden cancelar hasta %.0f horas antes del inicio del tour", s.limiteCancelacion.Hours())
+	}
+
+	return nil
+}
+
+// devolverPagos registra la devolución de lo pagado al cancelar una reserva.
+// Si la reserva tiene un comprobante vigente la devolución debe hacerse con una nota de crédito.
+func (s *ReservaService) devolverPagos(tx *sql.Tx, reserva *entidades.Reserva) error {
+	totalPagado, err := s.pagoRepo.WithTx(tx).GetTotalPagadoByReserva(reserva.ID)
+	if err != nil {
+		return err
+	}
+	if aCentimos(totalPagado) <= 0 {
+		return nil
+	}
+
+	emitidos, err := s.comprobanteRepo.WithTx(tx).CountEmitidosByReserva(reserva.ID)
+	if err != nil {
+		return err
+	}
+	if emitidos > 0 {
+		return errors.New("la reserva tiene un comprobante emitido, debe emitir una nota de crédito con la devolución antes de cancelarla")
+	}
+
+	// Devolver por el mismo medio y canal del último cobro
+	cobro, err := s.pagoRepo.WithTx(tx).GetUltimoCobroByReserva(reserva.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pagoRepo.WithTx(tx).CreateDevolucion(reserva.ID, cobro.IDMetodoPago, cobro.IDCanal, totalPagado, fmt.Sprintf("CANCELACION-%d", reserva.ID))
+	return err
+}
+
+// registrarHistorial guarda en la transacción un cambio de estado de la reserva y quién lo hizo
+func (s *ReservaService) registrarHistorial(tx *sql.Tx, idReserva int, anterior, nuevo string, actor Actor, motivo string) error {
+	historial := &entidades.HistorialEstadoReserva{
+		IDReserva:      idReserva,
+		EstadoAnterior: anterior,
+		EstadoNuevo:    nuevo,
+		Rol:            actor.Rol,
+		Motivo:         motivo,
+	}
+
+	switch actor.Rol {
+	case "SISTEMA":
+		// Los procesos automáticos no tienen usuario ni cliente
+	case "CLIENTE":
+		historial.IDCliente = &actor.ID
+	case "AGENCIA":
+		historial.IDAPIKey = &actor.ID
+	default:
+		historial.IDUsuario = &actor.ID
+	}
+
+	_, err := s.historialRepo.WithTx(tx).Create(historial)
+	return err
+}
+
+// diaTour devuelve el inicio del día de un tour programado en la hora local
+func diaTour(tour *entidades.TourProgramado) time.Time {
+	return time.Date(tour.Fecha.Year(), tour.Fecha.Month(), tour.Fecha.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// inicioTour devuelve la fecha y hora de inicio de un tour programado en la hora local
+func inicioTour(tour *entidades.TourProgramado) time.Time {
+	inicio := diaTour(tour)
+	if hora, err := time.Parse("15:04", tour.HoraInicio); err == nil {
+		inicio = inicio.Add(time.Duration(hora.Hour())*time.Hour + time.Duration(hora.Minute())*time.Minute)
+	}
+	return inicio
+}
@@ -0,0 +1,71 @@
+package servicios
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// Los localizadores usan letras y números sin caracteres que se confundan al dictarlos (0/O, 1/I/L)
+const (
+	prefijoLocalizador    = "TRS-"
+	alfabetoLocalizador   = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	longitudLocalizador   = 6
+	intentosLocalizadores = 5
+)
+
+// generarLocalizador genera un código aleatorio no secuencial como TRS-7KQ2M9
+func generarLocalizador() (string, error) {
+	aleatorios := make([]byte, longitudLocalizador)
+	codigo := make([]byte, longitudLocalizador)
+
+	for i := 0; i < longitudLocalizador; {
+		if _, err := rand.Read(aleatorios); err != nil {
+			return "", err
+		}
+		for _, b := range aleatorios {
+			// Descartar los valores que sesgarían la distribución
+			if int(b) >= 256-256%len(alfabetoLocalizador) {
+				continue
+			}
+			codigo[i] = alfabetoLocalizador[int(b)%len(alfabetoLocalizador)]
+			i++
+			if i == longitudLocalizador {
+				break
+			}
+		}
+	}
+
+	return prefijoLocalizador + string(codigo), nil
+}
+
+// nuevoLocalizador genera un localizador que todavía no usa ninguna reserva.
+// La restricción UNIQUE de la tabla cubre el caso de dos reservas simultáneas con el mismo código.
+func (s *ReservaService) nuevoLocalizador(tx *sql.Tx) (string, error) {
+	for i := 0; i < intentosLocalizadores; i++ {
+		localizador, err := generarLocalizador()
+		if err != nil {
+			return "", err
+		}
+
+		existe, err := s.reservaRepo.WithTx(tx).ExisteLocalizador(localizador)
+		if err != nil {
+			return "", err
+		}
+		if !existe {
+			return localizador, nil
+		}
+	}
+
+	return "", errors.New("no se pudo generar un localizador único para la reserva")
+}
+
+// normalizarLocalizador acepta el localizador en minúsculas, con espacios o sin el prefijo
+func normalizarLocalizador(localizador string) string {
+	localizador = strings.ToUpper(strings.TrimSpace(localizador))
+	if !strings.HasPrefix(localizador, prefijoLocalizador) {
+		localizador = prefijoLocalizador + localizador
+	}
+	return localizador
+}
@@ -1,10 +1,15 @@
 package servicios
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
 	"sistema-tours/internal/entidades"
 	"sistema-tours/internal/repositorios"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,6 +22,14 @@ type ReservaService struct {
 	canalVentaRepo     *repositorios.CanalVentaRepository
 	tipoPasajeRepo     *repositorios.TipoPasajeRepository
 	usuarioRepo        *repositorios.UsuarioRepository
+	pagoRepo           *repositorios.PagoRepository
+	comprobanteRepo    *repositorios.ComprobantePagoRepository
+	historialRepo      *repositorios.HistorialEstadoReservaRepository
+	pasajeroRepo       *repositorios.PasajeroRepository
+	cotizacionService  *CotizacionService
+	rolService         *RolService   // Resuelve los permisos del personal en los cambios de estado y la asignación de vendedor
+	retencion          time.Duration // Tiempo de retención del cupo de las reservas web pendientes de pago
+	limiteCancelacion  time.Duration // Anticipación mínima al inicio del tour para que un cliente cancele
 }
 
 // NewReservaService crea una nueva instancia de ReservaService
@@ -28,6 +41,14 @@ func NewReservaService(
 	canalVentaRepo *repositorios.CanalVentaRepository,
 	tipoPasajeRepo *repositorios.TipoPasajeRepository,
 	usuarioRepo *repositorios.UsuarioRepository,
+	pagoRepo *repositorios.PagoRepository,
+	comprobanteRepo *repositorios.ComprobantePagoRepository,
+	historialRepo *repositorios.HistorialEstadoReservaRepository,
+	pasajeroRepo *repositorios.PasajeroRepository,
+	cotizacionService *CotizacionService,
+	rolService *RolService,
+	retencion time.Duration,
+	limiteCancelacion time.Duration,
 ) *ReservaService {
 	return &ReservaService{
 		db:                 db,
@@ -37,11 +58,41 @@ func NewReservaService(
 		canalVentaRepo:     canalVentaRepo,
 		tipoPasajeRepo:     tipoPasajeRepo,
 		usuarioRepo:        usuarioRepo,
+		pagoRepo:           pagoRepo,
+		comprobanteRepo:    comprobanteRepo,
+		historialRepo:      historialRepo,
+		pasajeroRepo:       pasajeroRepo,
+		cotizacionService:  cotizacionService,
+		rolService:         rolService,
+		retencion:          retencion,
+		limiteCancelacion:  limiteCancelacion,
 	}
 }
 
 // Create crea una nueva reserva
-func (s *ReservaService) Create(reserva *entidades.NuevaReservaRequest) (int, error) {
+func (s *ReservaService) Create(reserva *entidades.NuevaReservaRequest, actor Actor) (int, error) {
+	// Un cliente solo puede reservar a su nombre
+	if actor.Rol == "CLIENTE" && reserva.IDCliente != actor.ID {
+		return 0, fmt.Errorf("%w: un cliente solo puede crear reservas a su nombre", ErrAccesoDenegado)
+	}
+
+	// Una agencia solo puede reservar en el canal de venta de su llave
+	if actor.Rol == "AGENCIA" && reserva.IDCanal != actor.IDCanal {
+		return 0, fmt.Errorf("%w: una agencia solo puede reservar en su canal de venta", ErrAccesoDenegado)
+	}
+
+	// Un cliente siempre reserva por la web y sin vendedor, así que su reserva solo retiene el cupo hasta el pago
+	if actor.Rol == "CLIENTE" {
+		if reserva.IDVendedor != nil {
+			return 0, fmt.Errorf("%w: un cliente no puede asignar un vendedor a su reserva", ErrAccesoDenegado)
+		}
+		web, err := s.canalVentaRepo.GetByNombre("WEB")
+		if err != nil {
+			return 0, errors.New("el canal de venta WEB no está configurado")
+		}
+		reserva.IDCanal = web.ID
+	}
+
 	// Verificar que el cliente existe
 	_, err := s.clienteRepo.GetByID(reserva.IDCliente)
 	if err != nil {
@@ -60,79 +111,118 @@ func (s *ReservaService) Create(reserva *entidades.NuevaReservaRequest) (int, er
 	}
 
 	// Verificar que el canal de venta existe
-	_, err = s.canalVentaRepo.GetByID(reserva.IDCanal)
+	canal, err := s.canalVentaRepo.GetByID(reserva.IDCanal)
 	if err != nil {
 		return 0, errors.New("el canal de venta especificado no existe")
 	}
 
-	// Si se especifica un vendedor, verificar que existe y es vendedor
-	if reserva.IDVendedor != nil {
-		usuario, err := s.usuarioRepo.GetByID(*reserva.IDVendedor)
-		if err != nil {
-			return 0, errors.New("el vendedor especificado no existe")
-		}
-		if usuario.Rol != "VENDEDOR" && usuario.Rol != "ADMIN" {
-			return 0, errors.New("el usuario especificado no es un vendedor")
-		}
-	}
-
-	// Verificar que los tipos de pasaje existen
-	totalPasajeros := 0
-	for _, pasaje := range reserva.CantidadPasajes {
-		_, err := s.tipoPasajeRepo.GetByID(pasaje.IDTipoPasaje)
-		if err != nil {
-			return 0, errors.New("uno de los tipos de pasaje especificados no existe")
-		}
-		totalPasajeros += pasaje.Cantidad
+	// Resolver el vendedor antes de decidir si la reserva es una retención web
+	reserva.IDVendedor, err = s.resolverVendedor(reserva.IDVendedor, actor)
+	if err != nil {
+		return 0, err
 	}
 
-	// Verificar disponibilidad de cupo
-	if totalPasajeros > tourProgramado.CupoDisponible {
-		return 0, errors.New("no hay suficiente cupo disponible para la cantidad de pasajeros solicitada")
+	// Las reservas web sin vendedor solo retienen el cupo hasta que se confirme el pago
+	reserva.Estado = "RESERVADO"
+	reserva.ExpiraEn = nil
+	if strings.EqualFold(canal.Nombre, "WEB") && reserva.IDVendedor == nil {
+		expiraEn := time.Now().Add(s.retencion)
+		reserva.Estado = "PENDIENTE_PAGO"
+		reserva.ExpiraEn = &expiraEn
 	}
 
-	// Iniciar transacción
-	tx, err := s.db.Begin()
+	// Calcular el precio en el servidor a partir de los pasajes
+	cotizacion, err := s.cotizacionService.calcular(tourProgramado, reserva.CantidadPasajes)
 	if err != nil {
 		return 0, err
 	}
-	defer func() {
+	if err := verificarTotal(reserva.TotalPagar, cotizacion); err != nil {
+		return 0, err
+	}
+	reserva.TotalPagar = cotizacion.Total
+	aplicarPrecios(reserva.CantidadPasajes, cotizacion)
+
+	// Descontar el cupo antes de crear la reserva: verifica la disponibilidad y bloquea el tour
+	var id int
+	err = WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		err := s.tourProgramadoRepo.WithTx(tx).DescontarCupo(reserva.IDTourProgramado, cotizacion.TotalPasajeros)
+		if err != nil {
+			return err
+		}
+
+		reserva.Localizador, err = s.nuevoLocalizador(tx)
+		if err != nil {
+			return err
+		}
+
+		// Crear reserva
+		id, err = s.reservaRepo.WithTx(tx).Create(reserva)
 		if err != nil {
-			tx.Rollback()
+			return err
 		}
-	}()
 
-	// Crear reserva
-	id, err := s.reservaRepo.Create(tx, reserva)
+		return s.registrarHistorial(tx, id, "", reserva.Estado, actor, "")
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	// Actualizar cupo disponible del tour programado
-	nuevoCupo := tourProgramado.CupoDisponible - totalPasajeros
-	err = s.tourProgramadoRepo.UpdateCupoDisponible(reserva.IDTourProgramado, nuevoCupo)
-	if err != nil {
-		return 0, err
+	return id, nil
+}
+
+// CreateAgencia registra la reserva de una agencia: identifica al cliente por su documento,
+// lo registra si aún no existe y atribuye la reserva al canal de venta de la llave
+func (s *ReservaService) CreateAgencia(req *entidades.NuevaReservaAgenciaRequest, actor Actor) (int, error) {
+	var idCliente int
+	cliente, err := s.clienteRepo.GetByDocumento(req.Cliente.TipoDocumento, req.Cliente.NumeroDocumento)
+	if err == nil {
+		idCliente = cliente.ID
+	} else {
+		idCliente, err = s.clienteRepo.Create(&entidades.NuevoClienteRequest{
+			TipoDocumento:   req.Cliente.TipoDocumento,
+			NumeroDocumento: req.Cliente.NumeroDocumento,
+			Nombres:         req.Cliente.Nombres,
+			Apellidos:       req.Cliente.Apellidos,
+		})
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	// Commit de la transacción
-	err = tx.Commit()
+	return s.Create(&entidades.NuevaReservaRequest{
+		IDCliente:        idCliente,
+		IDTourProgramado: req.IDTourProgramado,
+		IDCanal:          actor.IDCanal,
+		TotalPagar:       req.TotalPagar,
+		Notas:            req.Notas,
+		CantidadPasajes:  req.CantidadPasajes,
+	}, actor)
+}
+
+// GetByID obtiene una reserva por su ID si el actor tiene acceso a ella
+func (s *ReservaService) GetByID(id int, actor Actor) (*entidades.Reserva, error) {
+	reserva, err := s.reservaRepo.GetByID(id)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return id, nil
-}
+	if err := verificarAccesoReserva(reserva, actor); err != nil {
+		return nil, err
+	}
 
-// GetByID obtiene una reserva por su ID
-func (s *ReservaService) GetByID(id int) (*entidades.Reserva, error) {
-	return s.reservaRepo.GetByID(id)
+	// Calcular saldo y estado de pago
+	if err := s.completarEstadoPago(reserva); err != nil {
+		return nil, err
+	}
+
+	return reserva, nil
 }
 
-// Update actualiza una reserva existente
-func (s *ReservaService) Update(id int, reserva *entidades.ActualizarReservaRequest) error {
+// Update actualiza una reserva existente.
+// Si se indica un estado distinto al actual, el cambio pasa por las mismas reglas que CambiarEstado.
+func (s *ReservaService) Update(id int, reserva *entidades.ActualizarReservaRequest, actor Actor) error {
 	// Verificar que la reserva existe
-	existingReserva, err := s.reservaRepo.GetByID(id)
+	_, err := s.reservaRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
@@ -155,189 +245,369 @@ func (s *ReservaService) Update(id int, reserva *entidades.ActualizarReservaRequ
 		return errors.New("el canal de venta especificado no existe")
 	}
 
-	// Si se especifica un vendedor, verificar que existe y es vendedor
-	if reserva.IDVendedor != nil {
-		usuario, err := s.usuarioRepo.GetByID(*reserva.IDVendedor)
-		if err != nil {
-			return errors.New("el vendedor especificado no existe")
-		}
-		if usuario.Rol != "VENDEDOR" && usuario.Rol != "ADMIN" {
-			return errors.New("el usuario especificado no es un vendedor")
-		}
-	}
-
-	// Verificar que los tipos de pasaje existen
-	totalPasajerosNuevo := 0
-	for _, pasaje := range reserva.CantidadPasajes {
-		_, err := s.tipoPasajeRepo.GetByID(pasaje.IDTipoPasaje)
-		if err != nil {
-			return errors.New("uno de los tipos de pasaje especificados no existe")
-		}
-		totalPasajerosNuevo += pasaje.Cantidad
+	// Resolver el vendedor de la reserva
+	reserva.IDVendedor, err = s.resolverVendedor(reserva.IDVendedor, actor)
+	if err != nil {
+		return err
 	}
 
-	// Obtener la cantidad actual de pasajeros en la reserva
-	totalPasajerosActual, err := s.reservaRepo.GetCantidadPasajerosByReserva(id)
+	// Calcular el precio en el servidor a partir de los pasajes
+	cotizacion, err := s.cotizacionService.calcular(tourProgramado, reserva.CantidadPasajes)
 	if err != nil {
 		return err
 	}
+	if err := verificarTotal(reserva.TotalPagar, cotizacion); err != nil {
+		return err
+	}
+	reserva.TotalPagar = cotizacion.Total
+	aplicarPrecios(reserva.CantidadPasajes, cotizacion)
+	totalPasajerosNuevo := cotizacion.TotalPasajeros
 
-	// Calcular diferencia de pasajeros
-	diferenciaPasajeros := totalPasajerosNuevo - totalPasajerosActual
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva y leer sus pasajeros actuales dentro de la transacción
+		existingReserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
 
-	// Si es el mismo tour programado, verificar disponibilidad de cupo considerando la diferencia
-	if reserva.IDTourProgramado == existingReserva.IDTourProgramado {
-		if diferenciaPasajeros > 0 && diferenciaPasajeros > tourProgramado.CupoDisponible {
-			return errors.New("no hay suficiente cupo disponible para aumentar la cantidad de pasajeros")
+		// Una retención web solo se confirma con el pago o se cancela
+		if existingReserva.Estado == "PENDIENTE_PAGO" {
+			return errors.New("la reserva está pendiente de pago, debe confirmarla o cancelarla antes de modificarla")
 		}
-	} else {
-		// Si es otro tour programado, verificar disponibilidad total
-		if totalPasajerosNuevo > tourProgramado.CupoDisponible {
-			return errors.New("no hay suficiente cupo disponible en el nuevo tour programado")
+
+		// Pagada, embarcada o cerrada ya no se modifican los pasajes ni el total
+		if existingReserva.Estado != "RESERVADO" && existingReserva.Estado != "CONFIRMADA" && existingReserva.Estado != "CANCELADA" {
+			return fmt.Errorf("no se puede modificar una reserva en estado %s", existingReserva.Estado)
 		}
-	}
 
-	// Iniciar transacción
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() {
+		// Primero se actualizan los datos manteniendo el estado; el cambio de estado se aplica al final
+		nuevoEstado := reserva.Estado
+		if nuevoEstado == "" {
+			nuevoEstado = existingReserva.Estado
+		}
+		reserva.Estado = existingReserva.Estado
+
+		totalPasajerosActual, err := s.reservaRepo.WithTx(tx).GetCantidadPasajerosByReserva(id)
 		if err != nil {
-			tx.Rollback()
+			return err
 		}
-	}()
 
-	// Actualizar reserva
-	err = s.reservaRepo.Update(tx, id, reserva)
-	if err != nil {
-		return err
-	}
+		// Calcular el cambio de cupo en cada tour: una reserva cancelada no ocupa cupo
+		ajustes := make(map[int]int)
+		if existingReserva.Estado != "CANCELADA" {
+			ajustes[existingReserva.IDTourProgramado] -= totalPasajerosActual
+			ajustes[reserva.IDTourProgramado] += totalPasajerosNuevo
+		}
 
-	// Si cambió el tour programado, actualizar cupos de ambos tours
-	if reserva.IDTourProgramado != existingReserva.IDTourProgramado {
-		// Liberar cupo en el tour anterior
-		tourAnterior, err := s.tourProgramadoRepo.GetByID(existingReserva.IDTourProgramado)
-		if err != nil {
+		if err := s.ajustarCupos(tx, ajustes); err != nil {
 			return err
 		}
-		nuevoCupoAnterior := tourAnterior.CupoDisponible + totalPasajerosActual
-		err = s.tourProgramadoRepo.UpdateCupoDisponible(existingReserva.IDTourProgramado, nuevoCupoAnterior)
-		if err != nil {
+
+		// Los pasajeros ya registrados deben seguir cabiendo en los nuevos pasajes
+		if err := s.verificarPasajerosRegistrados(tx, id, reserva.CantidadPasajes); err != nil {
 			return err
 		}
 
-		// Reservar cupo en el nuevo tour
-		nuevoCupoNuevo := tourProgramado.CupoDisponible - totalPasajerosNuevo
-		err = s.tourProgramadoRepo.UpdateCupoDisponible(reserva.IDTourProgramado, nuevoCupoNuevo)
-		if err != nil {
+		// Lo ya pagado debe seguir cuadrando con el nuevo total
+		if err := s.verificarTotalPagado(tx, id, reserva.TotalPagar, nuevoEstado); err != nil {
 			return err
 		}
-	} else if diferenciaPasajeros != 0 {
-		// Si es el mismo tour pero cambió la cantidad de pasajeros, actualizar cupo
-		nuevoCupo := tourProgramado.CupoDisponible - diferenciaPasajeros
-		err = s.tourProgramadoRepo.UpdateCupoDisponible(reserva.IDTourProgramado, nuevoCupo)
-		if err != nil {
+
+		// Actualizar reserva
+		if err := s.reservaRepo.WithTx(tx).Update(id, reserva); err != nil {
 			return err
 		}
-	}
 
-	// Commit de la transacción
-	return tx.Commit()
+		if nuevoEstado == existingReserva.Estado {
+			return nil
+		}
+
+		// Aplicar el cambio de estado sobre los datos ya actualizados
+		actualizada, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		return s.cambiarEstadoTx(tx, actualizada, nuevoEstado, actor, "")
+	})
 }
 
-// CambiarEstado cambia el estado de una reserva
-func (s *ReservaService) CambiarEstado(id int, estado string) error {
-	// Verificar que la reserva existe
-	reserva, err := s.reservaRepo.GetByID(id)
+// ConsultarPorLocalizador obtiene una reserva por su localizador verificando el documento del cliente,
+// para que quien no tiene cuenta pueda recuperar su reserva sin conocer su ID
+func (s *ReservaService) ConsultarPorLocalizador(req *entidades.ConsultaReservaRequest) (*entidades.Reserva, error) {
+	// Mismo error si no existe o si el documento no coincide, para no revelar localizadores válidos
+	errNoEncontrada := errors.New("no se encontró una reserva con ese localizador y documento")
+
+	reserva, err := s.reservaRepo.GetByLocalizador(normalizarLocalizador(req.Localizador))
 	if err != nil {
-		return err
+		return nil, errNoEncontrada
 	}
 
-	// Verificar que el estado es válido
-	if estado != "RESERVADO" && estado != "CANCELADA" {
-		return errors.New("estado de reserva inválido")
+	cliente, err := s.clienteRepo.GetByID(reserva.IDCliente)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(req.NumeroDocumento), cliente.NumeroDocumento) {
+		return nil, errNoEncontrada
+	}
+
+	if err := s.completarEstadoPago(reserva); err != nil {
+		return nil, err
 	}
 
-	// Si se está cancelando una reserva, liberar el cupo
-	if estado == "CANCELADA" && reserva.Estado != "CANCELADA" {
-		// Obtener la cantidad de pasajeros en la reserva
-		totalPasajeros, err := s.reservaRepo.GetCantidadPasajerosByReserva(id)
+	return reserva, nil
+}
+
+// CambiarEstado cambia el estado de una reserva según las transiciones permitidas para el rol del actor
+func (s *ReservaService) CambiarEstado(id int, req *entidades.CambiarEstadoReservaRequest, actor Actor) error {
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para que dos cambios simultáneos no muevan el cupo dos veces
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(id)
 		if err != nil {
 			return err
 		}
 
-		// Liberar cupo en el tour programado
-		tourProgramado, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
-		if err != nil {
+		if err := verificarAccesoReserva(reserva, actor); err != nil {
 			return err
 		}
-		nuevoCupo := tourProgramado.CupoDisponible + totalPasajeros
-		err = s.tourProgramadoRepo.UpdateCupoDisponible(reserva.IDTourProgramado, nuevoCupo)
-		if err != nil {
-			return err
+
+		// Si ya tiene ese estado, no hacer nada
+		if reserva.Estado == req.Estado {
+			return nil
+		}
+
+		// Una retención web vencida solo la libera el barrido
+		if retencionVencida(reserva, time.Now()) && req.Estado != "CANCELADA" {
+			return errors.New("la retención de la reserva ha vencido, debe registrar una nueva reserva")
 		}
+
+		return s.cambiarEstadoTx(tx, reserva, req.Estado, actor, req.Motivo)
+	})
+}
+
+// ListHistorial lista los cambios de estado de una reserva
+func (s *ReservaService) ListHistorial(id int) ([]*entidades.HistorialEstadoReserva, error) {
+	// Verificar que la reserva existe
+	_, err := s.reservaRepo.GetByID(id)
+	if err != nil {
+		return nil, err
 	}
 
-	// Si se está reactivando una reserva cancelada, verificar disponibilidad y reservar cupo
-	if estado == "RESERVADO" && reserva.Estado == "CANCELADA" {
-		// Obtener la cantidad de pasajeros en la reserva
-		totalPasajeros, err := s.reservaRepo.GetCantidadPasajerosByReserva(id)
+	return s.historialRepo.ListByReserva(id)
+}
+
+// Delete elimina una reserva
+func (s *ReservaService) Delete(id int) error {
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(id)
 		if err != nil {
 			return err
 		}
 
-		// Verificar disponibilidad de cupo
-		tourProgramado, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
+		// Si la reserva no está cancelada, liberar el cupo
+		if reserva.Estado != "CANCELADA" {
+			totalPasajeros, err := s.reservaRepo.WithTx(tx).GetCantidadPasajerosByReserva(id)
+			if err != nil {
+				return err
+			}
+
+			err = s.tourProgramadoRepo.WithTx(tx).LiberarCupo(reserva.IDTourProgramado, totalPasajeros)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Eliminar reserva
+		return s.reservaRepo.WithTx(tx).Delete(id)
+	})
+}
+
+// Confirmar convierte una retención web PENDIENTE_PAGO en una reserva PAGADA una vez pagada por completo
+func (s *ReservaService) Confirmar(id int, actor Actor) error {
+	return WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		// Bloquear la reserva para que el barrido no la expire mientras se confirma
+		reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(id)
 		if err != nil {
 			return err
 		}
-		if totalPasajeros > tourProgramado.CupoDisponible {
-			return errors.New("no hay suficiente cupo disponible para reactivar la reserva")
+
+		if err := verificarAccesoReserva(reserva, actor); err != nil {
+			return err
+		}
+
+		if reserva.Estado != "PENDIENTE_PAGO" {
+			return errors.New("la reserva no está pendiente de pago")
+		}
+		if retencionVencida(reserva, time.Now()) {
+			return errors.New("la retención de la reserva ha vencido, debe registrar una nueva reserva")
 		}
 
-		// Reservar cupo
-		nuevoCupo := tourProgramado.CupoDisponible - totalPasajeros
-		err = s.tourProgramadoRepo.UpdateCupoDisponible(reserva.IDTourProgramado, nuevoCupo)
+		return s.cambiarEstadoTx(tx, reserva, "PAGADA", actor, "pago confirmado")
+	})
+}
+
+// ExpirarRetenciones cancela las reservas web cuya retención venció sin pago y libera su cupo.
+// Devuelve la cantidad de reservas expiradas.
+func (s *ReservaService) ExpirarRetenciones() (int, error) {
+	ids, err := s.reservaRepo.ListRetencionesVencidas(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	expiradas := 0
+	var primerError error
+	for _, id := range ids {
+		// Cada reserva en su propia transacción para que un fallo no bloquee las demás
+		expirada := false
+		err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+			reserva, err := s.reservaRepo.WithTx(tx).GetByIDForUpdate(id)
+			if err != nil {
+				return err
+			}
+
+			// Pudo confirmarse o cancelarse después de listarla
+			if !retencionVencida(reserva, time.Now()) {
+				return nil
+			}
+
+			expirada = true
+			return s.cambiarEstadoTx(tx, reserva, "CANCELADA", actorSistema, "retención vencida sin pago")
+		})
 		if err != nil {
-			return err
+			if primerError == nil {
+				primerError = fmt.Errorf("error al expirar la reserva %d: %w", id, err)
+			}
+			continue
+		}
+		if expirada {
+			expiradas++
 		}
 	}
 
-	// Actualizar estado de la reserva
-	return s.reservaRepo.UpdateEstado(id, estado)
+	return expiradas, primerError
 }
 
-// Delete elimina una reserva
-func (s *ReservaService) Delete(id int) error {
-	// Verificar que la reserva existe
-	reserva, err := s.reservaRepo.GetByID(id)
+// IniciarBarridoRetenciones expira periódicamente las retenciones vencidas hasta que se cancele el contexto
+func (s *ReservaService) IniciarBarridoRetenciones(ctx context.Context, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expiradas, err := s.ExpirarRetenciones()
+			if err != nil {
+				log.Printf("Error al expirar retenciones de reservas: %v", err)
+			}
+			if expiradas > 0 {
+				log.Printf("Retenciones de reservas expiradas: %d", expiradas)
+			}
+		}
+	}
+}
+
+// retencionVencida indica si la reserva sigue PENDIENTE_PAGO con su retención de cupo ya vencida
+func retencionVencida(reserva *entidades.Reserva, ahora time.Time) bool {
+	return reserva.Estado == "PENDIENTE_PAGO" && reserva.ExpiraEn != nil && !ahora.Before(*reserva.ExpiraEn)
+}
+
+// verificarPasajerosRegistrados comprueba que los pasajeros del manifiesto no superen los pasajes de cada tipo
+func (s *ReservaService) verificarPasajerosRegistrados(tx *sql.Tx, idReserva int, pasajes []entidades.PasajeCantidadRequest) error {
+	registrados, err := s.pasajeroRepo.WithTx(tx).CountByReservaPorTipo(idReserva)
 	if err != nil {
 		return err
 	}
 
-	// Si la reserva no está cancelada, liberar el cupo
-	if reserva.Estado != "CANCELADA" {
-		// Obtener la cantidad de pasajeros en la reserva
-		totalPasajeros, err := s.reservaRepo.GetCantidadPasajerosByReserva(id)
-		if err != nil {
-			return err
+	cantidades := make(map[int]int)
+	for _, pasaje := range pasajes {
+		cantidades[pasaje.IDTipoPasaje] += pasaje.Cantidad
+	}
+
+	for idTipoPasaje, cantidad := range registrados {
+		if cantidad > cantidades[idTipoPasaje] {
+			return fmt.Errorf("la reserva tiene %d pasajeros registrados con el tipo de pasaje %d, elimínelos antes de reducir sus pasajes", cantidad, idTipoPasaje)
 		}
+	}
+
+	return nil
+}
 
-		// Liberar cupo en el tour programado
-		tourProgramado, err := s.tourProgramadoRepo.GetByID(reserva.IDTourProgramado)
+// resolverVendedor devuelve el vendedor de una reserva. El personal sin permiso para asignar vendedores
+// queda como vendedor de lo que registra; si se indica otro usuario, su rol debe poder registrar reservas.
+func (s *ReservaService) resolverVendedor(idVendedor *int, actor Actor) (*int, error) {
+	if !rolesReservados[actor.Rol] {
+		asigna, err := s.rolService.TienePermiso(actor.Rol, entidades.PermisoReservasAsignarVendedor)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if !asigna {
+			id := actor.ID
+			return &id, nil
+		}
+	}
+
+	if idVendedor == nil {
+		return nil, nil
+	}
+
+	usuario, err := s.usuarioRepo.GetByID(*idVendedor)
+	if err != nil {
+		return nil, errors.New("el vendedor especificado no existe")
+	}
+	vende, err := s.rolService.TienePermiso(usuario.Rol, entidades.PermisoReservasCrear)
+	if err != nil {
+		return nil, err
+	}
+	if !vende {
+		return nil, errors.New("el usuario especificado no es un vendedor")
+	}
+
+	return idVendedor, nil
+}
+
+// verificarTotalPagado comprueba que el nuevo total de una reserva no quede por debajo de lo ya pagado
+// y que, si la reserva queda PAGADA, lo pagado cubra exactamente el nuevo total
+func (s *ReservaService) verificarTotalPagado(tx *sql.Tx, idReserva int, total float64, estado string) error {
+	totalPagado, err := s.pagoRepo.WithTx(tx).GetTotalPagadoByReserva(idReserva)
+	if err != nil {
+		return err
+	}
+
+	if aCentimos(totalPagado) > aCentimos(total) {
+		return fmt.Errorf("el nuevo total (%.2f) es menor que lo ya pagado (%.2f), registre la devolución de la diferencia antes de modificar la reserva", total, totalPagado)
+	}
+	if estado == "PAGADA" && aCentimos(totalPagado) != aCentimos(total) {
+		return fmt.Errorf("lo pagado (%.2f) no cubre el nuevo total (%.2f), registre el cobro de la diferencia antes de marcarla como pagada", totalPagado, total)
+	}
+
+	return nil
+}
+
+// ajustarCupos aplica dentro de la transacción el cambio de cupo de cada tour (positivo: ocupar, negativo: liberar).
+// Los tours se procesan en orden de ID para que transacciones concurrentes bloqueen las filas en el mismo orden.
+func (s *ReservaService) ajustarCupos(tx *sql.Tx, ajustes map[int]int) error {
+	ids := make([]int, 0, len(ajustes))
+	for idTour := range ajustes {
+		ids = append(ids, idTour)
+	}
+	sort.Ints(ids)
+
+	for _, idTour := range ids {
+		var err error
+		switch cantidad := ajustes[idTour]; {
+		case cantidad > 0:
+			err = s.tourProgramadoRepo.WithTx(tx).DescontarCupo(idTour, cantidad)
+		case cantidad < 0:
+			err = s.tourProgramadoRepo.WithTx(tx).LiberarCupo(idTour, -cantidad)
 		}
-		nuevoCupo := tourProgramado.CupoDisponible + totalPasajeros
-		err = s.tourProgramadoRepo.UpdateCupoDisponible(reserva.IDTourProgramado, nuevoCupo)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Eliminar reserva
-	return s.reservaRepo.Delete(id)
+	return nil
 }
 
 // List lista todas las reservas
@@ -345,6 +615,16 @@ func (s *ReservaService) List() ([]*entidades.Reserva, error) {
 	return s.reservaRepo.List()
 }
 
+// ListByEstadoPago lista todas las reservas según su estado de pago (PENDIENTE, PARCIAL, PAGADO)
+func (s *ReservaService) ListByEstadoPago(estadoPago string) ([]*entidades.Reserva, error) {
+	// Verificar que el estado de pago es válido
+	if estadoPago != "PENDIENTE" && estadoPago != "PARCIAL" && estadoPago != "PAGADO" {
+		return nil, errors.New("estado de pago inválido, debe ser PENDIENTE, PARCIAL o PAGADO")
+	}
+
+	return s.reservaRepo.ListByEstadoPago(estadoPago)
+}
+
 // ListByCliente lista todas las reservas de un cliente
 func (s *ReservaService) ListByCliente(idCliente int) ([]*entidades.Reserva, error) {
 	// Verificar que el cliente existe
@@ -357,13 +637,18 @@ func (s *ReservaService) ListByCliente(idCliente int) ([]*entidades.Reserva, err
 }
 
 // ListByTourProgramado lista todas las reservas para un tour programado
-func (s *ReservaService) ListByTourProgramado(idTourProgramado int) ([]*entidades.Reserva, error) {
+func (s *ReservaService) ListByTourProgramado(idTourProgramado int, actor Actor) ([]*entidades.Reserva, error) {
 	// Verificar que el tour programado existe
-	_, err := s.tourProgramadoRepo.GetByID(idTourProgramado)
+	tour, err := s.tourProgramadoRepo.GetByID(idTourProgramado)
 	if err != nil {
 		return nil, errors.New("el tour programado especificado no existe")
 	}
 
+	// Un chofer solo ve las reservas de los tours que tiene asignados
+	if err := verificarAccesoTour(tour, actor); err != nil {
+		return nil, err
+	}
+
 	return s.reservaRepo.ListByTourProgramado(idTourProgramado)
 }
 
@@ -375,9 +660,20 @@ func (s *ReservaService) ListByFecha(fecha time.Time) ([]*entidades.Reserva, err
 // ListByEstado lista todas las reservas por estado
 func (s *ReservaService) ListByEstado(estado string) ([]*entidades.Reserva, error) {
 	// Verificar que el estado es válido
-	if estado != "RESERVADO" && estado != "CANCELADA" {
+	if !entidades.EsEstadoReserva(estado) {
 		return nil, errors.New("estado de reserva inválido")
 	}
 
 	return s.reservaRepo.ListByEstado(estado)
 }
+
+// completarEstadoPago calcula el monto pagado, el saldo pendiente y el estado de pago de una reserva
+func (s *ReservaService) completarEstadoPago(reserva *entidades.Reserva) error {
+	montoPagado, err := s.pagoRepo.GetTotalPagadoByReserva(reserva.ID)
+	if err != nil {
+		return err
+	}
+
+	reserva.AplicarPagos(montoPagado)
+	return nil
+}
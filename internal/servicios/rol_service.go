@@ -0,0 +1,224 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sync"
+	"time"
+)
+
+// rolesReservados son los actores que no son roles del personal: clientes, agencias con llave de API
+// y procesos automáticos. Ningún rol puede llamarse así para no heredar sus reglas sobre las reservas.
+var rolesReservados = map[string]bool{
+	"CLIENTE": true,
+	"AGENCIA": true,
+	"SISTEMA": true,
+}
+
+// RolService maneja los roles del personal como conjuntos de permisos y resuelve
+// los permisos de cada petición desde una copia en memoria
+type RolService struct {
+	db          *sql.DB
+	rolRepo     *repositorios.RolRepository
+	permisoRepo *repositorios.PermisoRepository
+	vigencia    time.Duration // Tiempo tras el que se recargan los permisos, por cambios hechos desde otra instancia
+
+	mu        sync.RWMutex
+	permisos  map[string]map[string]bool
+	cargadoEn time.Time
+}
+
+// NewRolService crea una nueva instancia de RolService
+func NewRolService(
+	db *sql.DB,
+	rolRepo *repositorios.RolRepository,
+	permisoRepo *repositorios.PermisoRepository,
+	vigencia time.Duration,
+) *RolService {
+	return &RolService{
+		db:          db,
+		rolRepo:     rolRepo,
+		permisoRepo: permisoRepo,
+		vigencia:    vigencia,
+	}
+}
+
+// TienePermiso indica si el rol incluye el permiso. Lo usa el middleware RequirePermission en cada petición.
+func (s *RolService) TienePermiso(rol, permiso string) (bool, error) {
+	s.mu.RLock()
+	vigente := s.permisos != nil && time.Since(s.cargadoEn) < s.vigencia
+	if vigente {
+		tiene := s.permisos[rol][permiso]
+		s.mu.RUnlock()
+		return tiene, nil
+	}
+	s.mu.RUnlock()
+
+	if err := s.recargar(); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.permisos[rol][permiso], nil
+}
+
+// ListPermisos lista el catálogo de permisos que se pueden asignar a un rol
+func (s *RolService) ListPermisos() ([]*entidades.Permiso, error) {
+	return s.permisoRepo.List()
+}
+
+// List lista los roles con sus permisos
+func (s *RolService) List() ([]*entidades.Rol, error) {
+	return s.rolRepo.List()
+}
+
+// GetByNombre obtiene un rol con sus permisos
+func (s *RolService) GetByNombre(nombre string) (*entidades.Rol, error) {
+	return s.rolRepo.GetByNombre(nombre)
+}
+
+// Create crea un rol nuevo con sus permisos
+func (s *RolService) Create(req *entidades.NuevoRolRequest) error {
+	if rolesReservados[req.Nombre] {
+		return fmt.Errorf("el nombre %s está reservado y no se puede usar para un rol", req.Nombre)
+	}
+	if err := s.validarPermisos(req.Permisos); err != nil {
+		return err
+	}
+
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		rolRepo := s.rolRepo.WithTx(tx)
+
+		existe, err := rolRepo.Exists(req.Nombre)
+		if err != nil {
+			return err
+		}
+		if existe {
+			return errors.New("ya existe un rol con ese nombre")
+		}
+
+		if err := rolRepo.Create(req.Nombre, req.Descripcion); err != nil {
+			return err
+		}
+		return rolRepo.ReemplazarPermisos(req.Nombre, req.Permisos)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidar()
+	return nil
+}
+
+// Update reemplaza la descripción y los permisos de un rol.
+// El rol ADMIN no se puede modificar para que siempre haya quien gestione los roles.
+func (s *RolService) Update(nombre string, req *entidades.ActualizarRolRequest) error {
+	if nombre == entidades.RolAdministrador {
+		return errors.New("el rol ADMIN tiene todos los permisos y no se puede modificar")
+	}
+	if rolesReservados[nombre] {
+		return fmt.Errorf("el nombre %s está reservado y no corresponde a un rol", nombre)
+	}
+	if err := s.validarPermisos(req.Permisos); err != nil {
+		return err
+	}
+
+	err := WithTx(context.Background(), s.db, func(tx *sql.Tx) error {
+		rolRepo := s.rolRepo.WithTx(tx)
+
+		if _, err := rolRepo.GetByNombre(nombre); err != nil {
+			return err
+		}
+
+		if err := rolRepo.UpdateDescripcion(nombre, req.Descripcion); err != nil {
+			return err
+		}
+		return rolRepo.ReemplazarPermisos(nombre, req.Permisos)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidar()
+	return nil
+}
+
+// Delete elimina un rol que no sea de sistema y que ningún usuario activo tenga asignado
+func (s *RolService) Delete(nombre string) error {
+	rol, err := s.rolRepo.GetByNombre(nombre)
+	if err != nil {
+		return err
+	}
+	if rol.Sistema {
+		return errors.New("los roles de sistema no se pueden eliminar")
+	}
+
+	usuarios, err := s.rolRepo.CountUsuarios(nombre)
+	if err != nil {
+		return err
+	}
+	if usuarios > 0 {
+		return fmt.Errorf("el rol está asignado a %d usuario(s) activo(s)", usuarios)
+	}
+
+	if err := s.rolRepo.Delete(nombre); err != nil {
+		return err
+	}
+
+	s.invalidar()
+	return nil
+}
+
+// validarPermisos verifica que todos los permisos existan en el catálogo
+func (s *RolService) validarPermisos(permisos []string) error {
+	catalogo, err := s.permisoRepo.List()
+	if err != nil {
+		return err
+	}
+
+	existentes := make(map[string]bool, len(catalogo))
+	for _, permiso := range catalogo {
+		existentes[permiso.Codigo] = true
+	}
+	for _, permiso := range permisos {
+		if !existentes[permiso] {
+			return fmt.Errorf("permiso desconocido: %s", permiso)
+		}
+	}
+
+	return nil
+}
+
+// recargar lee de la base de datos los permisos de todos los roles
+func (s *RolService) recargar() error {
+	asignaciones, err := s.rolRepo.ListAsignaciones()
+	if err != nil {
+		return err
+	}
+
+	permisos := make(map[string]map[string]bool, len(asignaciones))
+	for rol, codigos := range asignaciones {
+		permisos[rol] = make(map[string]bool, len(codigos))
+		for _, codigo := range codigos {
+			permisos[rol][codigo] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.permisos = permisos
+	s.cargadoEn = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// invalidar descarta la copia en memoria para que la próxima petición vea los cambios
+func (s *RolService) invalidar() {
+	s.mu.Lock()
+	s.permisos = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,99 @@
+package servicios
+
+import (
+	"errors"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+)
+
+// TarifaTourService maneja la lógica de negocio para tarifas por tipo de tour
+type TarifaTourService struct {
+	tarifaRepo     *repositorios.TarifaTourRepository
+	tipoTourRepo   *repositorios.TipoTourRepository
+	tipoPasajeRepo *repositorios.TipoPasajeRepository
+}
+
+// NewTarifaTourService crea una nueva instancia de TarifaTourService
+func NewTarifaTourService(
+	tarifaRepo *repositorios.TarifaTourRepository,
+	tipoTourRepo *repositorios.TipoTourRepository,
+	tipoPasajeRepo *repositorios.TipoPasajeRepository,
+) *TarifaTourService {
+	return &TarifaTourService{
+		tarifaRepo:     tarifaRepo,
+		tipoTourRepo:   tipoTourRepo,
+		tipoPasajeRepo: tipoPasajeRepo,
+	}
+}
+
+// Create crea una nueva tarifa
+func (s *TarifaTourService) Create(tarifa *entidades.NuevaTarifaTourRequest) (int, error) {
+	// Verificar que el tipo de tour existe
+	_, err := s.tipoTourRepo.GetByID(tarifa.IDTipoTour)
+	if err != nil {
+		return 0, errors.New("el tipo de tour especificado no existe")
+	}
+
+	// Verificar que el tipo de pasaje existe
+	_, err = s.tipoPasajeRepo.GetByID(tarifa.IDTipoPasaje)
+	if err != nil {
+		return 0, errors.New("el tipo de pasaje especificado no existe")
+	}
+
+	// Verificar que no exista ya una tarifa para la combinación
+	existe, err := s.tarifaRepo.ExisteTarifa(tarifa.IDTipoTour, tarifa.IDTipoPasaje)
+	if err != nil {
+		return 0, err
+	}
+	if existe {
+		return 0, errors.New("ya existe una tarifa para ese tipo de pasaje en el tipo de tour")
+	}
+
+	// Crear tarifa
+	return s.tarifaRepo.Create(tarifa)
+}
+
+// GetByID obtiene una tarifa por su ID
+func (s *TarifaTourService) GetByID(id int) (*entidades.TarifaTour, error) {
+	return s.tarifaRepo.GetByID(id)
+}
+
+// Update actualiza el precio de una tarifa existente
+func (s *TarifaTourService) Update(id int, tarifa *entidades.ActualizarTarifaTourRequest) error {
+	// Verificar que la tarifa existe
+	_, err := s.tarifaRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Actualizar tarifa
+	return s.tarifaRepo.Update(id, tarifa)
+}
+
+// Delete elimina una tarifa
+func (s *TarifaTourService) Delete(id int) error {
+	// Verificar que la tarifa existe
+	_, err := s.tarifaRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Eliminar tarifa
+	return s.tarifaRepo.Delete(id)
+}
+
+// List lista todas las tarifas
+func (s *TarifaTourService) List() ([]*entidades.TarifaTour, error) {
+	return s.tarifaRepo.List()
+}
+
+// ListVigentesByTipoTour lista el precio aplicable de cada tipo de pasaje en un tipo de tour
+func (s *TarifaTourService) ListVigentesByTipoTour(idTipoTour int) ([]*entidades.TarifaVigente, error) {
+	// Verificar que el tipo de tour existe
+	_, err := s.tipoTourRepo.GetByID(idTipoTour)
+	if err != nil {
+		return nil, errors.New("el tipo de tour especificado no existe")
+	}
+
+	return s.tarifaRepo.ListVigentesByTipoTour(idTipoTour)
+}
@@ -26,6 +26,11 @@ func (s *TipoPasajeService) Create(tipoPasaje *entidades.NuevoTipoPasajeRequest)
 		return 0, errors.New("ya existe un tipo de pasaje con ese nombre")
 	}
 
+	// Verificar que el rango de edad es coherente
+	if err := validarRangoEdad(tipoPasaje.EdadMinima, tipoPasaje.EdadMaxima); err != nil {
+		return 0, err
+	}
+
 	// Crear tipo de pasaje
 	return s.tipoPasajeRepo.Create(tipoPasaje)
 }
@@ -51,6 +56,11 @@ func (s *TipoPasajeService) Update(id int, tipoPasaje *entidades.ActualizarTipoP
 		}
 	}
 
+	// Verificar que el rango de edad es coherente
+	if err := validarRangoEdad(tipoPasaje.EdadMinima, tipoPasaje.EdadMaxima); err != nil {
+		return err
+	}
+
 	// Actualizar tipo de pasaje
 	return s.tipoPasajeRepo.Update(id, tipoPasaje)
 }
@@ -71,3 +81,11 @@ func (s *TipoPasajeService) Delete(id int) error {
 func (s *TipoPasajeService) List() ([]*entidades.TipoPasaje, error) {
 	return s.tipoPasajeRepo.List()
 }
+
+// validarRangoEdad verifica que la edad mínima no sea mayor que la máxima
+func validarRangoEdad(minima, maxima *int) error {
+	if minima != nil && maxima != nil && *minima > *maxima {
+		return errors.New("la edad mínima no puede ser mayor que la edad máxima")
+	}
+	return nil
+}
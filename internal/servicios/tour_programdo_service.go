@@ -13,6 +13,7 @@ type TourProgramadoService struct {
 	tipoTourRepo       *repositorios.TipoTourRepository
 	embarcacionRepo    *repositorios.EmbarcacionRepository
 	horarioTourRepo    *repositorios.HorarioTourRepository
+	tarifaRepo         *repositorios.TarifaTourRepository
 }
 
 // NewTourProgramadoService crea una nueva instancia de TourProgramadoService
@@ -21,12 +22,14 @@ func NewTourProgramadoService(
 	tipoTourRepo *repositorios.TipoTourRepository,
 	embarcacionRepo *repositorios.EmbarcacionRepository,
 	horarioTourRepo *repositorios.HorarioTourRepository,
+	tarifaRepo *repositorios.TarifaTourRepository,
 ) *TourProgramadoService {
 	return &TourProgramadoService{
 		tourProgramadoRepo: tourProgramadoRepo,
 		tipoTourRepo:       tipoTourRepo,
 		embarcacionRepo:    embarcacionRepo,
 		horarioTourRepo:    horarioTourRepo,
+		tarifaRepo:         tarifaRepo,
 	}
 }
 
@@ -95,7 +98,17 @@ func (s *TourProgramadoService) Create(tour *entidades.NuevoTourProgramadoReques
 
 // GetByID obtiene un tour programado por su ID
 func (s *TourProgramadoService) GetByID(id int) (*entidades.TourProgramado, error) {
-	return s.tourProgramadoRepo.GetByID(id)
+	tour, err := s.tourProgramadoRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Agregar las tarifas vigentes del tour
+	if err := s.completarTarifas([]*entidades.TourProgramado{tour}); err != nil {
+		return nil, err
+	}
+
+	return tour, nil
 }
 
 // Update actualiza un tour programado existente
@@ -205,62 +218,6 @@ func (s *TourProgramadoService) CambiarEstado(id int, estado string) error {
 	return s.tourProgramadoRepo.UpdateEstado(id, estado)
 }
 
-// ReservarCupo disminuye el cupo disponible de un tour programado
-func (s *TourProgramadoService) ReservarCupo(id int, cantidad int) error {
-	// Verificar cantidad válida
-	if cantidad <= 0 {
-		return errors.New("la cantidad debe ser mayor a 0")
-	}
-
-	// Verificar que el tour programado existe
-	tour, err := s.tourProgramadoRepo.GetByID(id)
-	if err != nil {
-		return err
-	}
-
-	// Verificar que el tour está programado
-	if tour.Estado != "PROGRAMADO" {
-		return errors.New("no se puede reservar un tour que no está programado")
-	}
-
-	// Verificar disponibilidad
-	if tour.CupoDisponible < cantidad {
-		return errors.New("no hay suficiente cupo disponible")
-	}
-
-	// Actualizar cupo disponible
-	nuevoCupo := tour.CupoDisponible - cantidad
-	return s.tourProgramadoRepo.UpdateCupoDisponible(id, nuevoCupo)
-}
-
-// LiberarCupo aumenta el cupo disponible de un tour programado
-func (s *TourProgramadoService) LiberarCupo(id int, cantidad int) error {
-	// Verificar cantidad válida
-	if cantidad <= 0 {
-		return errors.New("la cantidad debe ser mayor a 0")
-	}
-
-	// Verificar que el tour programado existe
-	tour, err := s.tourProgramadoRepo.GetByID(id)
-	if err != nil {
-		return err
-	}
-
-	// Verificar que el tour está programado
-	if tour.Estado != "PROGRAMADO" {
-		return errors.New("no se puede liberar cupo de un tour que no está programado")
-	}
-
-	// Verificar que no exceda el cupo máximo
-	nuevoCupo := tour.CupoDisponible + cantidad
-	if nuevoCupo > tour.CupoMaximo {
-		return errors.New("el cupo liberado excede el cupo máximo")
-	}
-
-	// Actualizar cupo disponible
-	return s.tourProgramadoRepo.UpdateCupoDisponible(id, nuevoCupo)
-}
-
 // Delete elimina un tour programado
 func (s *TourProgramadoService) Delete(id int) error {
 	// Verificar que el tour programado existe
@@ -327,7 +284,17 @@ func (s *TourProgramadoService) ListByChofer(idChofer int) ([]*entidades.TourPro
 
 // ListToursProgramadosDisponibles lista todos los tours programados disponibles para reservación
 func (s *TourProgramadoService) ListToursProgramadosDisponibles() ([]*entidades.TourProgramado, error) {
-	return s.tourProgramadoRepo.ListToursProgramadosDisponibles()
+	tours, err := s.tourProgramadoRepo.ListToursProgramadosDisponibles()
+	if err != nil {
+		return nil, err
+	}
+
+	// Agregar las tarifas vigentes de cada tour
+	if err := s.completarTarifas(tours); err != nil {
+		return nil, err
+	}
+
+	return tours, nil
 }
 
 // ListByTipoTour lista todos los tours programados por tipo de tour
@@ -343,5 +310,33 @@ func (s *TourProgramadoService) ListByTipoTour(idTipoTour int) ([]*entidades.Tou
 
 // GetDisponibilidadDia retorna la disponibilidad de tours para una fecha específica
 func (s *TourProgramadoService) GetDisponibilidadDia(fecha time.Time) ([]*entidades.TourProgramado, error) {
-	return s.tourProgramadoRepo.GetDisponibilidadDia(fecha)
+	tours, err := s.tourProgramadoRepo.GetDisponibilidadDia(fecha)
+	if err != nil {
+		return nil, err
+	}
+
+	// Agregar las tarifas vigentes de cada tour
+	if err := s.completarTarifas(tours); err != nil {
+		return nil, err
+	}
+
+	return tours, nil
+}
+
+// completarTarifas agrega a cada tour las tarifas vigentes de su tipo de tour
+func (s *TourProgramadoService) completarTarifas(tours []*entidades.TourProgramado) error {
+	porTipoTour := make(map[int][]*entidades.TarifaVigente)
+	for _, tour := range tours {
+		tarifas, ok := porTipoTour[tour.IDTipoTour]
+		if !ok {
+			var err error
+			tarifas, err = s.tarifaRepo.ListVigentesByTipoTour(tour.IDTipoTour)
+			if err != nil {
+				return err
+			}
+			porTipoTour[tour.IDTipoTour] = tarifas
+		}
+		tour.Tarifas = tarifas
+	}
+	return nil
 }
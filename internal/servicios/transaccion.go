@@ -0,0 +1,34 @@
+package servicios
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithTx ejecuta fn dentro de una transacción de base de datos.
+// Hace commit si fn termina sin error y rollback si devuelve un error o entra en pánico
+// (en ese caso el pánico se vuelve a lanzar después del rollback).
+// Los repositorios se enlazan a la transacción con su método WithTx(tx).
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	// Commit de la transacción
+	return tx.Commit()
+}
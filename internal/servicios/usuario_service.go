@@ -5,22 +5,39 @@ import (
 	"sistema-tours/internal/entidades"
 	"sistema-tours/internal/repositorios"
 	"sistema-tours/internal/utils"
+	"time"
 )
 
 // UsuarioService maneja la lógica de negocio para usuarios
 type UsuarioService struct {
-	usuarioRepo *repositorios.UsuarioRepository
+	usuarioRepo       *repositorios.UsuarioRepository
+	refreshTokenRepo  *repositorios.RefreshTokenRepository
+	tokenRevocadoRepo *repositorios.TokenRevocadoRepository
+	rolRepo           *repositorios.RolRepository
 }
 
 // NewUsuarioService crea una nueva instancia de UsuarioService
-func NewUsuarioService(usuarioRepo *repositorios.UsuarioRepository) *UsuarioService {
+func NewUsuarioService(
+	usuarioRepo *repositorios.UsuarioRepository,
+	refreshTokenRepo *repositorios.RefreshTokenRepository,
+	tokenRevocadoRepo *repositorios.TokenRevocadoRepository,
+	rolRepo *repositorios.RolRepository,
+) *UsuarioService {
 	return &UsuarioService{
-		usuarioRepo: usuarioRepo,
+		usuarioRepo:       usuarioRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		tokenRevocadoRepo: tokenRevocadoRepo,
+		rolRepo:           rolRepo,
 	}
 }
 
 // Create crea un nuevo usuario
 func (s *UsuarioService) Create(usuario *entidades.NuevoUsuarioRequest) (int, error) {
+	// Verificar que el rol exista
+	if err := s.ValidarRol(usuario.Rol); err != nil {
+		return 0, err
+	}
+
 	// Verificar si ya existe usuario con el mismo correo
 	existingEmail, err := s.usuarioRepo.GetByEmail(usuario.Correo)
 	if err == nil && existingEmail != nil {
@@ -43,6 +60,21 @@ func (s *UsuarioService) Create(usuario *entidades.NuevoUsuarioRequest) (int, er
 	return s.usuarioRepo.Create(usuario, hashedPassword)
 }
 
+// CrearAdminInicial crea el primer usuario ADMIN del sistema.
+// Solo se permite mientras no exista ningún ADMIN activo; los siguientes se crean desde la API.
+func (s *UsuarioService) CrearAdminInicial(usuario *entidades.NuevoUsuarioRequest) (int, error) {
+	admins, err := s.usuarioRepo.ListByRol("ADMIN")
+	if err != nil {
+		return 0, err
+	}
+	if len(admins) > 0 {
+		return 0, errors.New("ya existe un usuario ADMIN activo; cree los demás usuarios desde la API")
+	}
+
+	usuario.Rol = "ADMIN"
+	return s.Create(usuario)
+}
+
 // GetByID obtiene un usuario por su ID
 func (s *UsuarioService) GetByID(id int) (*entidades.Usuario, error) {
 	return s.usuarioRepo.GetByID(id)
@@ -64,6 +96,13 @@ func (s *UsuarioService) Update(id int, usuario *entidades.Usuario) error {
 		}
 	}
 
+	// Verificar que el nuevo rol exista
+	if usuario.Rol != existing.Rol {
+		if err := s.ValidarRol(usuario.Rol); err != nil {
+			return err
+		}
+	}
+
 	// Verificar si ya existe otro usuario con el mismo documento
 	if usuario.NumeroDocumento != existing.NumeroDocumento || usuario.TipoDocumento != existing.TipoDocumento {
 		existingDoc, err := s.usuarioRepo.GetByDocumento(usuario.TipoDocumento, usuario.NumeroDocumento)
@@ -76,7 +115,16 @@ func (s *UsuarioService) Update(id int, usuario *entidades.Usuario) error {
 	usuario.ID = id
 
 	// Actualizar usuario
-	return s.usuarioRepo.Update(usuario)
+	if err := s.usuarioRepo.Update(usuario); err != nil {
+		return err
+	}
+
+	// Si se desactivó el usuario, cerrar sus sesiones
+	if existing.Estado && !usuario.Estado {
+		return s.cerrarSesiones(id)
+	}
+
+	return nil
 }
 
 // Delete elimina un usuario (borrado lógico)
@@ -88,7 +136,32 @@ func (s *UsuarioService) Delete(id int) error {
 	}
 
 	// Eliminar usuario
-	return s.usuarioRepo.Delete(id)
+	if err := s.usuarioRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return s.cerrarSesiones(id)
+}
+
+// cerrarSesiones revoca los refresh tokens del usuario para que no pueda renovarlos
+// e invalida los tokens de acceso que ya se le emitieron
+func (s *UsuarioService) cerrarSesiones(id int) error {
+	if err := s.refreshTokenRepo.RevocarBySujeto(utils.SujetoUsuario, id); err != nil {
+		return err
+	}
+	return s.tokenRevocadoRepo.RevocarSujeto(utils.SujetoUsuario, id, time.Now())
+}
+
+// ValidarRol verifica que el rol exista en la tabla de roles
+func (s *UsuarioService) ValidarRol(rol string) error {
+	existe, err := s.rolRepo.Exists(rol)
+	if err != nil {
+		return err
+	}
+	if !existe {
+		return errors.New("rol inválido")
+	}
+	return nil
 }
 
 // ListByRol lista usuarios por rol
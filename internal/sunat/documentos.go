@@ -0,0 +1,396 @@
+package sunat
+
+import (
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"strings"
+	"time"
+)
+
+// idFirma identifica la firma digital dentro de los documentos
+const idFirma = "SignatureSP"
+
+// Condiciones de un comprobante dentro de un resumen diario (catálogo 19)
+const (
+	CondicionAdicionar = 1
+	CondicionAnular    = 3
+)
+
+// Emisor contiene los datos de la empresa que emite los comprobantes
+type Emisor struct {
+	RUC             string
+	RazonSocial     string
+	NombreComercial string
+	Direccion       string
+}
+
+// Documento es un documento electrónico listo para firmar
+type Documento struct {
+	Nombre string // Nombre del archivo sin extensión, por ejemplo 20123456789-01-F001-00000001
+	raiz   *elemento
+}
+
+// ItemResumen es una boleta informada en un resumen diario
+type ItemResumen struct {
+	Comprobante *entidades.ComprobantePago
+	Condicion   int
+}
+
+// CodigoTipoComprobante devuelve el código del catálogo 01 para un tipo de comprobante
+func CodigoTipoComprobante(tipo string) string {
+	switch tipo {
+	case "FACTURA":
+		return "01"
+	case "BOLETA":
+		return "03"
+	case "NOTA_CREDITO":
+		return "07"
+	}
+	return ""
+}
+
+// CodigoTipoDocumentoIdentidad devuelve el código del catálogo 06 para un tipo de documento de identidad
+func CodigoTipoDocumentoIdentidad(tipo string) string {
+	switch strings.ToUpper(strings.TrimSpace(tipo)) {
+	case "DNI":
+		return "1"
+	case "CE", "CARNET_EXTRANJERIA", "CARNET DE EXTRANJERIA":
+		return "4"
+	case "RUC":
+		return "6"
+	case "PASAPORTE":
+		return "7"
+	}
+	return "0"
+}
+
+// NuevoComprobante genera el XML UBL 2.1 (Invoice) de una boleta o factura
+func NuevoComprobante(emisor Emisor, c *entidades.ComprobantePago) *Documento {
+	tipoDoc := CodigoTipoComprobante(c.Tipo)
+
+	raiz := nodo("Invoice").agregar(
+		firmaPlaceholder(),
+		valor("cbc:UBLVersionID", "2.1"),
+		valor("cbc:CustomizationID", "2.0"),
+		valor("cbc:ID", c.NumeroComprobante),
+		valor("cbc:IssueDate", c.FechaEmision.Format("2006-01-02")),
+		valor("cbc:IssueTime", c.FechaEmision.Format("15:04:05")),
+		valor("cbc:InvoiceTypeCode", tipoDoc, "listID", "0101"), // Venta interna (catálogo 51)
+		valor("cbc:DocumentCurrencyCode", "PEN"),
+		firmaUBL(emisor),
+		proveedor(emisor),
+		cliente(c),
+	)
+
+	// Forma de pago (obligatoria en facturas)
+	if c.Tipo == "FACTURA" {
+		raiz.agregar(nodo("cac:PaymentTerms",
+			valor("cbc:ID", "FormaPago"),
+			valor("cbc:PaymentMeansID", "Contado"),
+		))
+	}
+
+	raiz.agregar(
+		totalImpuestos(c.Subtotal, c.IGV, false),
+		nodo("cac:LegalMonetaryTotal",
+			valor("cbc:LineExtensionAmount", monto(c.Subtotal), "currencyID", "PEN"),
+			valor("cbc:TaxInclusiveAmount", monto(c.Total), "currencyID", "PEN"),
+			valor("cbc:PayableAmount", monto(c.Total), "currencyID", "PEN"),
+		),
+		linea("cac:InvoiceLine", "cbc:InvoicedQuantity", c.Subtotal, c.IGV, c.Total, descripcionServicio(c)),
+	)
+
+	declararEspacios(raiz, nsInvoice, false)
+
+	return &Documento{
+		Nombre: fmt.Sprintf("%s-%s-%s", emisor.RUC, tipoDoc, c.NumeroComprobante),
+		raiz:   raiz,
+	}
+}
+
+// NuevaNotaCredito genera el XML UBL 2.1 (CreditNote) de una nota de crédito sobre el comprobante indicado
+func NuevaNotaCredito(emisor Emisor, nota *entidades.NotaCredito, c *entidades.ComprobantePago) *Documento {
+	tipoDoc := CodigoTipoComprobante("NOTA_CREDITO")
+
+	raiz := nodo("CreditNote").agregar(
+		firmaPlaceholder(),
+		valor("cbc:UBLVersionID", "2.1"),
+		valor("cbc:CustomizationID", "2.0"),
+		valor("cbc:ID", nota.NumeroNota),
+		valor("cbc:IssueDate", nota.FechaEmision.Format("2006-01-02")),
+		valor("cbc:IssueTime", nota.FechaEmision.Format("15:04:05")),
+		valor("cbc:DocumentCurrencyCode", "PEN"),
+		nodo("cac:DiscrepancyResponse",
+			valor("cbc:ReferenceID", c.NumeroComprobante),
+			valor("cbc:ResponseCode", nota.CodigoMotivo), // Catálogo 09
+			valor("cbc:Description", nota.Motivo),
+		),
+		nodo("cac:BillingReference",
+			nodo("cac:InvoiceDocumentReference",
+				valor("cbc:ID", c.NumeroComprobante),
+				valor("cbc:DocumentTypeCode", CodigoTipoComprobante(c.Tipo)),
+			),
+		),
+		firmaUBL(emisor),
+		proveedor(emisor),
+		cliente(c),
+		totalImpuestos(nota.Subtotal, nota.IGV, false),
+		nodo("cac:LegalMonetaryTotal",
+			valor("cbc:PayableAmount", monto(nota.Total), "currencyID", "PEN"),
+		),
+		linea("cac:CreditNoteLine", "cbc:CreditedQuantity", nota.Subtotal, nota.IGV, nota.Total, descripcionServicio(c)),
+	)
+
+	declararEspacios(raiz, nsCreditNote, false)
+
+	return &Documento{
+		Nombre: fmt.Sprintf("%s-%s-%s", emisor.RUC, tipoDoc, nota.NumeroNota),
+		raiz:   raiz,
+	}
+}
+
+// NuevoResumenDiario genera el XML del resumen diario de boletas (SummaryDocuments)
+func NuevoResumenDiario(emisor Emisor, identificador string, fechaReferencia, fechaEmision time.Time, items []ItemResumen) *Documento {
+	raiz := nodo("SummaryDocuments").agregar(
+		firmaPlaceholder(),
+		valor("cbc:UBLVersionID", "2.0"),
+		valor("cbc:CustomizationID", "1.1"),
+		valor("cbc:ID", identificador),
+		valor("cbc:ReferenceDate", fechaReferencia.Format("2006-01-02")),
+		valor("cbc:IssueDate", fechaEmision.Format("2006-01-02")),
+		firmaUBL(emisor),
+		proveedorResumen(emisor),
+	)
+
+	for i, item := range items {
+		c := item.Comprobante
+		raiz.agregar(nodo("sac:SummaryDocumentsLine",
+			valor("cbc:LineID", fmt.Sprintf("%d", i+1)),
+			valor("cbc:DocumentTypeCode", CodigoTipoComprobante(c.Tipo)),
+			valor("cbc:ID", c.NumeroComprobante),
+			nodo("cac:AccountingCustomerParty",
+				valor("cbc:CustomerAssignedAccountID", documentoCliente(c)),
+				valor("cbc:AdditionalAccountID", CodigoTipoDocumentoIdentidad(c.TipoDocumentoCliente)),
+			),
+			nodo("cac:Status", valor("cbc:ConditionCode", fmt.Sprintf("%d", item.Condicion))),
+			valor("sac:TotalAmount", monto(c.Total), "currencyID", "PEN"),
+			nodo("sac:BillingPayment",
+				valor("cbc:PaidAmount", monto(c.Subtotal), "currencyID", "PEN"),
+				valor("cbc:InstructionID", "01"), // Operaciones gravadas
+			),
+			nodo("cac:TaxTotal",
+				valor("cbc:TaxAmount", monto(c.IGV), "currencyID", "PEN"),
+				nodo("cac:TaxSubtotal",
+					valor("cbc:TaxAmount", monto(c.IGV), "currencyID", "PEN"),
+					nodo("cac:TaxCategory", esquemaIGV()),
+				),
+			),
+		))
+	}
+
+	declararEspacios(raiz, nsSummaryDocuments, true)
+
+	return &Documento{
+		Nombre: fmt.Sprintf("%s-%s", emisor.RUC, identificador),
+		raiz:   raiz,
+	}
+}
+
+// NuevaComunicacionBaja genera el XML de la comunicación de baja de facturas anuladas (VoidedDocuments)
+func NuevaComunicacionBaja(emisor Emisor, identificador string, fechaReferencia, fechaEmision time.Time, comprobantes []*entidades.ComprobantePago) *Documento {
+	raiz := nodo("VoidedDocuments").agregar(
+		firmaPlaceholder(),
+		valor("cbc:UBLVersionID", "2.0"),
+		valor("cbc:CustomizationID", "1.0"),
+		valor("cbc:ID", identificador),
+		valor("cbc:ReferenceDate", fechaReferencia.Format("2006-01-02")),
+		valor("cbc:IssueDate", fechaEmision.Format("2006-01-02")),
+		firmaUBL(emisor),
+		proveedorResumen(emisor),
+	)
+
+	for i, c := range comprobantes {
+		raiz.agregar(nodo("sac:VoidedDocumentsLine",
+			valor("cbc:LineID", fmt.Sprintf("%d", i+1)),
+			valor("cbc:DocumentTypeCode", CodigoTipoComprobante(c.Tipo)),
+			valor("sac:DocumentSerialID", c.Serie),
+			valor("sac:DocumentNumberID", fmt.Sprintf("%d", c.Correlativo)),
+			valor("sac:VoidReasonDescription", c.MotivoAnulacion),
+		))
+	}
+
+	declararEspacios(raiz, nsVoidedDocuments, true)
+
+	return &Documento{
+		Nombre: fmt.Sprintf("%s-%s", emisor.RUC, identificador),
+		raiz:   raiz,
+	}
+}
+
+// ContenidoQR devuelve el texto del código QR de la representación impresa de un comprobante:
+// RUC|TIPO|SERIE|NÚMERO|IGV|TOTAL|FECHA|TIPO DOC. ADQUIRIENTE|NÚM. DOC. ADQUIRIENTE|VALOR RESUMEN|
+func ContenidoQR(emisor Emisor, c *entidades.ComprobantePago) string {
+	return strings.Join([]string{
+		emisor.RUC,
+		CodigoTipoComprobante(c.Tipo),
+		c.Serie,
+		fmt.Sprintf("%08d", c.Correlativo),
+		monto(c.IGV),
+		monto(c.Total),
+		c.FechaEmision.Format("2006-01-02"),
+		CodigoTipoDocumentoIdentidad(c.TipoDocumentoCliente),
+		documentoCliente(c),
+		c.HashCPE,
+	}, "|") + "|"
+}
+
+// declararEspacios agrega al elemento raíz las declaraciones de espacios de nombres
+func declararEspacios(raiz *elemento, nsDocumento string, conSAC bool) {
+	raiz.atributos = append(raiz.atributos,
+		[2]string{"xmlns", nsDocumento},
+		[2]string{"xmlns:cac", nsCAC},
+		[2]string{"xmlns:cbc", nsCBC},
+		[2]string{"xmlns:ds", nsDS},
+		[2]string{"xmlns:ext", nsEXT},
+	)
+	if conSAC {
+		raiz.atributos = append(raiz.atributos, [2]string{"xmlns:sac", nsSAC})
+	}
+}
+
+// firmaPlaceholder es la extensión UBL donde se inserta la firma digital
+func firmaPlaceholder() *elemento {
+	return nodo("ext:UBLExtensions",
+		nodo("ext:UBLExtension",
+			nodo("ext:ExtensionContent"),
+		),
+	)
+}
+
+// firmaUBL referencia la firma digital desde el cuerpo del documento
+func firmaUBL(emisor Emisor) *elemento {
+	return nodo("cac:Signature",
+		valor("cbc:ID", idFirma),
+		nodo("cac:SignatoryParty",
+			nodo("cac:PartyIdentification", valor("cbc:ID", emisor.RUC)),
+			nodo("cac:PartyName", valor("cbc:Name", emisor.RazonSocial)),
+		),
+		nodo("cac:DigitalSignatureAttachment",
+			nodo("cac:ExternalReference", valor("cbc:URI", "#"+idFirma)),
+		),
+	)
+}
+
+// proveedor genera los datos del emisor para boletas y facturas
+func proveedor(emisor Emisor) *elemento {
+	nombreComercial := emisor.NombreComercial
+	if nombreComercial == "" {
+		nombreComercial = emisor.RazonSocial
+	}
+
+	direccion := nodo("cac:RegistrationAddress",
+		valor("cbc:AddressTypeCode", "0000"), // Domicilio fiscal
+	)
+	if emisor.Direccion != "" {
+		direccion.agregar(nodo("cac:AddressLine", valor("cbc:Line", emisor.Direccion)))
+	}
+
+	return nodo("cac:AccountingSupplierParty",
+		nodo("cac:Party",
+			nodo("cac:PartyIdentification", valor("cbc:ID", emisor.RUC, "schemeID", "6")),
+			nodo("cac:PartyName", valor("cbc:Name", nombreComercial)),
+			nodo("cac:PartyLegalEntity",
+				valor("cbc:RegistrationName", emisor.RazonSocial),
+				direccion,
+			),
+		),
+	)
+}
+
+// proveedorResumen genera los datos del emisor para resúmenes y comunicaciones de baja (UBL 2.0)
+func proveedorResumen(emisor Emisor) *elemento {
+	return nodo("cac:AccountingSupplierParty",
+		valor("cbc:CustomerAssignedAccountID", emisor.RUC),
+		valor("cbc:AdditionalAccountID", "6"),
+		nodo("cac:Party",
+			nodo("cac:PartyLegalEntity", valor("cbc:RegistrationName", emisor.RazonSocial)),
+		),
+	)
+}
+
+// cliente genera los datos del adquiriente
+func cliente(c *entidades.ComprobantePago) *elemento {
+	return nodo("cac:AccountingCustomerParty",
+		nodo("cac:Party",
+			nodo("cac:PartyIdentification",
+				valor("cbc:ID", documentoCliente(c), "schemeID", CodigoTipoDocumentoIdentidad(c.TipoDocumentoCliente)),
+			),
+			nodo("cac:PartyLegalEntity",
+				valor("cbc:RegistrationName", strings.TrimSpace(c.NombreCliente+" "+c.ApellidosCliente)),
+			),
+		),
+	)
+}
+
+// linea genera la única línea del documento: el servicio de tour con IGV incluido
+func linea(nombre, nombreCantidad string, subtotal, igv, total float64, descripcion string) *elemento {
+	return nodo(nombre,
+		valor("cbc:ID", "1"),
+		valor(nombreCantidad, "1", "unitCode", "ZZ"), // Servicio
+		valor("cbc:LineExtensionAmount", monto(subtotal), "currencyID", "PEN"),
+		nodo("cac:PricingReference",
+			nodo("cac:AlternativeConditionPrice",
+				valor("cbc:PriceAmount", monto(total), "currencyID", "PEN"),
+				valor("cbc:PriceTypeCode", "01"), // Precio unitario incluye IGV (catálogo 16)
+			),
+		),
+		totalImpuestos(subtotal, igv, true),
+		nodo("cac:Item", valor("cbc:Description", descripcion)),
+		nodo("cac:Price", valor("cbc:PriceAmount", monto(subtotal), "currencyID", "PEN")),
+	)
+}
+
+// totalImpuestos genera el bloque de IGV del documento o de una línea
+func totalImpuestos(subtotal, igv float64, linea bool) *elemento {
+	categoria := nodo("cac:TaxCategory")
+	if linea {
+		categoria.agregar(
+			valor("cbc:Percent", "18.00"),
+			valor("cbc:TaxExemptionReasonCode", "10"), // Gravado - operación onerosa (catálogo 07)
+		)
+	}
+	categoria.agregar(esquemaIGV())
+
+	return nodo("cac:TaxTotal",
+		valor("cbc:TaxAmount", monto(igv), "currencyID", "PEN"),
+		nodo("cac:TaxSubtotal",
+			valor("cbc:TaxableAmount", monto(subtotal), "currencyID", "PEN"),
+			valor("cbc:TaxAmount", monto(igv), "currencyID", "PEN"),
+			categoria,
+		),
+	)
+}
+
+// esquemaIGV identifica el tributo IGV (catálogo 05)
+func esquemaIGV() *elemento {
+	return nodo("cac:TaxScheme",
+		valor("cbc:ID", "1000"),
+		valor("cbc:Name", "IGV"),
+		valor("cbc:TaxTypeCode", "VAT"),
+	)
+}
+
+func descripcionServicio(c *entidades.ComprobantePago) string {
+	return fmt.Sprintf("SERVICIO DE TOUR %s - %s", strings.ToUpper(c.NombreTour), c.FechaTour.Format("02/01/2006"))
+}
+
+func documentoCliente(c *entidades.ComprobantePago) string {
+	if c.DocumentoCliente == "" {
+		return "-"
+	}
+	return c.DocumentoCliente
+}
+
+func monto(m float64) string {
+	return fmt.Sprintf("%.2f", m)
+}
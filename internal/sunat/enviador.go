@@ -0,0 +1,105 @@
+package sunat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Estados de un documento frente a SUNAT
+const (
+	EstadoPendiente = "PENDIENTE"
+	EstadoEnviado   = "ENVIADO"
+	EstadoAceptado  = "ACEPTADO"
+	EstadoObservado = "OBSERVADO"
+	EstadoRechazado = "RECHAZADO"
+	EstadoBaja      = "BAJA"
+)
+
+// Enviador envía documentos electrónicos a SUNAT (o a un sustituto local)
+type Enviador interface {
+	// EnviarComprobante envía una factura o boleta y devuelve la constancia de recepción (CDR)
+	EnviarComprobante(nombreArchivo string, zip []byte) (*CDR, error)
+	// EnviarResumen envía un resumen diario o una comunicación de baja y devuelve el ticket asignado
+	EnviarResumen(nombreArchivo string, zip []byte) (string, error)
+	// ConsultarTicket consulta el resultado de un resumen o comunicación de baja
+	ConsultarTicket(ticket string) (*CDR, error)
+}
+
+// CDR es la constancia de recepción devuelta por SUNAT
+type CDR struct {
+	Codigo      string
+	Descripcion string
+	Notas       []string
+	EnProceso   bool   // El ticket aún no ha sido procesado
+	Zip         []byte // ZIP original del CDR
+}
+
+// Estado interpreta el código de respuesta según las reglas de SUNAT
+func (c *CDR) Estado() string {
+	if c.EnProceso {
+		return EstadoEnviado
+	}
+
+	codigo, err := strconv.Atoi(c.Codigo)
+	if err != nil {
+		return EstadoRechazado
+	}
+
+	switch {
+	case codigo == 0 && len(c.Notas) > 0:
+		return EstadoObservado
+	case codigo == 0:
+		return EstadoAceptado
+	case codigo >= 4000:
+		return EstadoObservado
+	}
+	return EstadoRechazado
+}
+
+// ErrorSunat representa un error (SOAP Fault) devuelto por el servicio de SUNAT
+type ErrorSunat struct {
+	Codigo  string
+	Mensaje string
+}
+
+func (e *ErrorSunat) Error() string {
+	return fmt.Sprintf("SUNAT %s: %s", e.Codigo, e.Mensaje)
+}
+
+// EsRechazo indica si el error corresponde a un rechazo del documento (códigos 2000 al 3999).
+// Los demás códigos son excepciones que no registran el documento y permiten reenviarlo.
+func (e *ErrorSunat) EsRechazo() bool {
+	codigo, err := strconv.Atoi(e.Codigo)
+	return err == nil && codigo >= 2000 && codigo < 4000
+}
+
+// applicationResponse es la estructura mínima del CDR
+type applicationResponse struct {
+	Notas     []string `xml:"Note"`
+	Respuesta struct {
+		Codigo      string `xml:"ResponseCode"`
+		Descripcion string `xml:"Description"`
+	} `xml:"DocumentResponse>Response"`
+}
+
+// LeerCDR interpreta el ZIP del CDR devuelto por SUNAT
+func LeerCDR(zip []byte) (*CDR, error) {
+	contenido, err := Descomprimir(zip)
+	if err != nil {
+		return nil, err
+	}
+
+	var respuesta applicationResponse
+	if err := xml.Unmarshal(contenido, &respuesta); err != nil {
+		return nil, fmt.Errorf("CDR inválido: %w", err)
+	}
+
+	return &CDR{
+		Codigo:      strings.TrimSpace(respuesta.Respuesta.Codigo),
+		Descripcion: strings.TrimSpace(respuesta.Respuesta.Descripcion),
+		Notas:       respuesta.Notas,
+		Zip:         zip,
+	}, nil
+}
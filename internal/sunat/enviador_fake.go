@@ -0,0 +1,96 @@
+package sunat
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EnviadorFake simula el servicio de SUNAT sin conexión: acepta todo lo que recibe y guarda los
+// archivos enviados. Se usa en desarrollo y en pruebas.
+type EnviadorFake struct {
+	// CodigoRespuesta permite simular rechazos u observaciones; por defecto "0" (aceptado)
+	CodigoRespuesta string
+	// Enviados guarda los ZIP recibidos por nombre de archivo
+	Enviados map[string][]byte
+
+	mu       sync.Mutex
+	tickets  map[string]string
+	contador int
+}
+
+// NuevoEnviadorFake crea un enviador local
+func NuevoEnviadorFake() *EnviadorFake {
+	return &EnviadorFake{
+		CodigoRespuesta: "0",
+		Enviados:        make(map[string][]byte),
+		tickets:         make(map[string]string),
+	}
+}
+
+// EnviarComprobante registra el archivo y devuelve un CDR de aceptación
+func (e *EnviadorFake) EnviarComprobante(nombreArchivo string, zip []byte) (*CDR, error) {
+	e.mu.Lock()
+	e.Enviados[nombreArchivo] = zip
+	codigo := e.CodigoRespuesta
+	e.mu.Unlock()
+
+	return e.cdr(nombreArchivo, codigo)
+}
+
+// EnviarResumen registra el archivo y devuelve un ticket
+func (e *EnviadorFake) EnviarResumen(nombreArchivo string, zip []byte) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.contador++
+	ticket := fmt.Sprintf("%013d", e.contador)
+	e.Enviados[nombreArchivo] = zip
+	e.tickets[ticket] = nombreArchivo
+
+	return ticket, nil
+}
+
+// ConsultarTicket devuelve el CDR del resumen asociado al ticket
+func (e *EnviadorFake) ConsultarTicket(ticket string) (*CDR, error) {
+	e.mu.Lock()
+	nombreArchivo, ok := e.tickets[ticket]
+	codigo := e.CodigoRespuesta
+	e.mu.Unlock()
+
+	if !ok {
+		return nil, &ErrorSunat{Codigo: "0127", Mensaje: "El ticket no existe"}
+	}
+
+	return e.cdr(nombreArchivo, codigo)
+}
+
+// cdr genera una constancia de recepción con la misma estructura que la de SUNAT
+func (e *EnviadorFake) cdr(nombreArchivo, codigo string) (*CDR, error) {
+	descripcion := fmt.Sprintf("El documento %s ha sido aceptado", nombreArchivo)
+	if codigo != "0" {
+		descripcion = fmt.Sprintf("El documento %s ha sido rechazado (simulado)", nombreArchivo)
+	}
+
+	respuesta := nodo("ar:ApplicationResponse",
+		valor("cbc:ID", nombreArchivo),
+		nodo("cac:DocumentResponse",
+			nodo("cac:Response",
+				valor("cbc:ReferenceID", nombreArchivo),
+				valor("cbc:ResponseCode", codigo),
+				valor("cbc:Description", descripcion),
+			),
+		),
+	)
+	respuesta.atributos = [][2]string{
+		{"xmlns:ar", "urn:oasis:names:specification:ubl:schema:xsd:ApplicationResponse-2"},
+		{"xmlns:cac", nsCAC},
+		{"xmlns:cbc", nsCBC},
+	}
+
+	zip, err := Comprimir("R-"+nombreArchivo, []byte(respuesta.canonico()))
+	if err != nil {
+		return nil, err
+	}
+
+	return LeerCDR(zip)
+}
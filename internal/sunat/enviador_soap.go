@@ -0,0 +1,155 @@
+package sunat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// URLs del servicio billService de SUNAT
+const (
+	URLBeta       = "https://e-beta.sunat.gob.pe/ol-ti-itcpfegem-beta/billService"
+	URLProduccion = "https://e-factura.sunat.gob.pe/ol-ti-itcpfegem/billService"
+)
+
+// EnviadorSOAP envía los documentos al servicio web billService de SUNAT
+type EnviadorSOAP struct {
+	url     string
+	usuario string // RUC seguido del usuario SOL
+	clave   string
+	cliente *http.Client
+}
+
+// NuevoEnviadorSOAP crea un enviador para el servicio de SUNAT indicado
+func NuevoEnviadorSOAP(url, ruc, usuarioSOL, claveSOL string) *EnviadorSOAP {
+	return &EnviadorSOAP{
+		url:     url,
+		usuario: ruc + usuarioSOL,
+		clave:   claveSOL,
+		cliente: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// respuestaSOAP contiene los posibles resultados de las operaciones de billService
+type respuestaSOAP struct {
+	Body struct {
+		Fault *struct {
+			Codigo  string `xml:"faultcode"`
+			Mensaje string `xml:"faultstring"`
+		} `xml:"Fault"`
+		SendBill struct {
+			ApplicationResponse string `xml:"applicationResponse"`
+		} `xml:"sendBillResponse"`
+		SendSummary struct {
+			Ticket string `xml:"ticket"`
+		} `xml:"sendSummaryResponse"`
+		GetStatus struct {
+			Status struct {
+				Codigo    string `xml:"statusCode"`
+				Contenido string `xml:"content"`
+			} `xml:"status"`
+		} `xml:"getStatusResponse"`
+	} `xml:"Body"`
+}
+
+// EnviarComprobante invoca sendBill
+func (e *EnviadorSOAP) EnviarComprobante(nombreArchivo string, zip []byte) (*CDR, error) {
+	respuesta, err := e.invocar("sendBill", archivoSOAP(nombreArchivo, zip))
+	if err != nil {
+		return nil, err
+	}
+
+	cdr, err := base64.StdEncoding.DecodeString(strings.TrimSpace(respuesta.Body.SendBill.ApplicationResponse))
+	if err != nil {
+		return nil, fmt.Errorf("CDR inválido: %w", err)
+	}
+
+	return LeerCDR(cdr)
+}
+
+// EnviarResumen invoca sendSummary
+func (e *EnviadorSOAP) EnviarResumen(nombreArchivo string, zip []byte) (string, error) {
+	respuesta, err := e.invocar("sendSummary", archivoSOAP(nombreArchivo, zip))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(respuesta.Body.SendSummary.Ticket), nil
+}
+
+// ConsultarTicket invoca getStatus
+func (e *EnviadorSOAP) ConsultarTicket(ticket string) (*CDR, error) {
+	respuesta, err := e.invocar("getStatus", "<ticket>"+escaparTexto(ticket)+"</ticket>")
+	if err != nil {
+		return nil, err
+	}
+
+	status := respuesta.Body.GetStatus.Status
+	// 98: en proceso, 0: procesado correctamente, 99: procesado con errores
+	if strings.TrimSpace(status.Codigo) == "98" {
+		return &CDR{EnProceso: true}, nil
+	}
+
+	cdr, err := base64.StdEncoding.DecodeString(strings.TrimSpace(status.Contenido))
+	if err != nil {
+		return nil, fmt.Errorf("CDR inválido: %w", err)
+	}
+
+	return LeerCDR(cdr)
+}
+
+// invocar envía el sobre SOAP con autenticación WS-Security y devuelve la respuesta interpretada
+func (e *EnviadorSOAP) invocar(operacion, contenido string) (*respuestaSOAP, error) {
+	sobre := `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ser="http://service.sunat.gob.pe" ` +
+		`xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">` +
+		`<soapenv:Header><wsse:Security><wsse:UsernameToken>` +
+		`<wsse:Username>` + escaparTexto(e.usuario) + `</wsse:Username>` +
+		`<wsse:Password>` + escaparTexto(e.clave) + `</wsse:Password>` +
+		`</wsse:UsernameToken></wsse:Security></soapenv:Header>` +
+		`<soapenv:Body><ser:` + operacion + `>` + contenido + `</ser:` + operacion + `></soapenv:Body>` +
+		`</soapenv:Envelope>`
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewBufferString(sobre))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPAction", "urn:"+operacion)
+
+	resp, err := e.cliente.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo conectar con SUNAT: %w", err)
+	}
+	defer resp.Body.Close()
+
+	cuerpo, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var respuesta respuestaSOAP
+	if err := xml.Unmarshal(cuerpo, &respuesta); err != nil {
+		return nil, fmt.Errorf("respuesta inválida de SUNAT (HTTP %d): %w", resp.StatusCode, err)
+	}
+
+	if fault := respuesta.Body.Fault; fault != nil {
+		// El código llega como "soap-env:Client.0151"; se conserva solo el número
+		codigo := fault.Codigo
+		if i := strings.LastIndex(codigo, "."); i >= 0 {
+			codigo = codigo[i+1:]
+		}
+		return nil, &ErrorSunat{Codigo: codigo, Mensaje: strings.TrimSpace(fault.Mensaje)}
+	}
+
+	return &respuesta, nil
+}
+
+func archivoSOAP(nombreArchivo string, zip []byte) string {
+	return "<fileName>" + escaparTexto(nombreArchivo) + ".zip</fileName>" +
+		"<contentFile>" + base64.StdEncoding.EncodeToString(zip) + "</contentFile>"
+}
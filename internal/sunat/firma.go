@@ -0,0 +1,171 @@
+package sunat
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// Firmador firma documentos electrónicos con XML-DSig (firma enveloped, C14N inclusiva, RSA-SHA256)
+type Firmador struct {
+	certificado *x509.Certificate
+	clave       *rsa.PrivateKey
+}
+
+// NuevoFirmador crea un firmador a partir de un certificado y su clave privada
+func NuevoFirmador(certificado *x509.Certificate, clave *rsa.PrivateKey) *Firmador {
+	return &Firmador{
+		certificado: certificado,
+		clave:       clave,
+	}
+}
+
+// CargarFirmador carga el certificado digital desde un archivo PFX/P12 o PEM (certificado y clave en el mismo archivo)
+func CargarFirmador(ruta, contrasena string) (*Firmador, error) {
+	datos, err := os.ReadFile(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el certificado digital: %w", err)
+	}
+
+	ext := strings.ToLower(ruta)
+	if strings.HasSuffix(ext, ".pfx") || strings.HasSuffix(ext, ".p12") {
+		clave, certificado, err := pkcs12.Decode(datos, contrasena)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo abrir el certificado PFX: %w", err)
+		}
+		claveRSA, ok := clave.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("la clave privada del certificado debe ser RSA")
+		}
+		return NuevoFirmador(certificado, claveRSA), nil
+	}
+
+	var certificado *x509.Certificate
+	var claveRSA *rsa.PrivateKey
+	for {
+		var bloque *pem.Block
+		bloque, datos = pem.Decode(datos)
+		if bloque == nil {
+			break
+		}
+		switch bloque.Type {
+		case "CERTIFICATE":
+			if certificado == nil {
+				certificado, err = x509.ParseCertificate(bloque.Bytes)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "RSA PRIVATE KEY":
+			claveRSA, err = x509.ParsePKCS1PrivateKey(bloque.Bytes)
+			if err != nil {
+				return nil, err
+			}
+		case "PRIVATE KEY":
+			clave, err := x509.ParsePKCS8PrivateKey(bloque.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			if claveRSA, ok = clave.(*rsa.PrivateKey); !ok {
+				return nil, errors.New("la clave privada del certificado debe ser RSA")
+			}
+		}
+	}
+
+	if certificado == nil || claveRSA == nil {
+		return nil, errors.New("el archivo PEM debe contener el certificado y la clave privada")
+	}
+
+	return NuevoFirmador(certificado, claveRSA), nil
+}
+
+// FirmadorDePrueba genera un certificado autofirmado en memoria, útil para desarrollo con el enviador local
+func FirmadorDePrueba(ruc string) (*Firmador, error) {
+	clave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	plantilla := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: ruc, Organization: []string{"Certificado de prueba"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, plantilla, &clave.PublicKey, clave)
+	if err != nil {
+		return nil, err
+	}
+
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return NuevoFirmador(certificado, clave), nil
+}
+
+// Firmar firma el documento y devuelve el XML firmado junto con el valor resumen (hash) del documento
+func (f *Firmador) Firmar(doc *Documento) ([]byte, string, error) {
+	// Con la transformación enveloped la firma se calcula sobre el documento sin el nodo Signature
+	canonico := doc.raiz.canonico()
+	if !strings.Contains(canonico, extensionVacia) {
+		return nil, "", errors.New("el documento no tiene la extensión UBL para la firma")
+	}
+
+	resumen := sha256.Sum256([]byte(canonico))
+	digestValue := base64.StdEncoding.EncodeToString(resumen[:])
+
+	signedInfo := nodo("ds:SignedInfo",
+		valor("ds:CanonicalizationMethod", "", "Algorithm", "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"),
+		valor("ds:SignatureMethod", "", "Algorithm", "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"),
+		valor("ds:Reference", "", "URI", "").agregar(
+			nodo("ds:Transforms",
+				valor("ds:Transform", "", "Algorithm", "http://www.w3.org/2000/09/xmldsig#enveloped-signature"),
+			),
+			valor("ds:DigestMethod", "", "Algorithm", "http://www.w3.org/2001/04/xmlenc#sha256"),
+			valor("ds:DigestValue", digestValue),
+		),
+	)
+
+	// SignedInfo se canonicaliza con los espacios de nombres heredados del elemento raíz
+	var b strings.Builder
+	signedInfo.escribir(&b, doc.raiz.espaciosDeNombres())
+	resumenSignedInfo := sha256.Sum256([]byte(b.String()))
+
+	firma, err := rsa.SignPKCS1v15(rand.Reader, f.clave, crypto.SHA256, resumenSignedInfo[:])
+	if err != nil {
+		return nil, "", err
+	}
+
+	signature := valor("ds:Signature", "", "Id", idFirma).agregar(
+		signedInfo,
+		valor("ds:SignatureValue", base64.StdEncoding.EncodeToString(firma)),
+		nodo("ds:KeyInfo",
+			nodo("ds:X509Data",
+				valor("ds:X509Certificate", base64.StdEncoding.EncodeToString(f.certificado.Raw)),
+			),
+		),
+	)
+
+	firmado := strings.Replace(canonico, extensionVacia,
+		"<ext:ExtensionContent>"+signature.canonico()+"</ext:ExtensionContent>", 1)
+
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + firmado), digestValue, nil
+}
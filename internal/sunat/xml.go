@@ -0,0 +1,114 @@
+package sunat
+
+import (
+	"sort"
+	"strings"
+)
+
+// Espacios de nombres usados por los documentos UBL de SUNAT
+const (
+	nsInvoice          = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	nsCreditNote       = "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2"
+	nsSummaryDocuments = "urn:sunat:names:specification:ubl:peru:schema:xsd:SummaryDocuments-1"
+	nsVoidedDocuments  = "urn:sunat:names:specification:ubl:peru:schema:xsd:VoidedDocuments-1"
+	nsCAC              = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	nsCBC              = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+	nsEXT              = "urn:oasis:names:specification:ubl:schema:xsd:CommonExtensionComponents-2"
+	nsSAC              = "urn:sunat:names:specification:ubl:peru:schema:xsd:SunatAggregateComponents-1"
+	nsDS               = "http://www.w3.org/2000/09/xmldsig#"
+	extensionVacia     = "<ext:ExtensionContent></ext:ExtensionContent>"
+)
+
+// elemento es un nodo XML mínimo que se serializa directamente en forma canónica (C14N inclusiva),
+// de modo que el texto generado es el mismo que se firma
+type elemento struct {
+	nombre    string
+	atributos [][2]string
+	texto     string
+	hijos     []*elemento
+}
+
+// nodo crea un elemento con hijos
+func nodo(nombre string, hijos ...*elemento) *elemento {
+	return &elemento{nombre: nombre, hijos: hijos}
+}
+
+// valor crea un elemento con contenido de texto y atributos opcionales en pares nombre, valor
+func valor(nombre, texto string, atributos ...string) *elemento {
+	e := &elemento{nombre: nombre, texto: texto}
+	for i := 0; i+1 < len(atributos); i += 2 {
+		e.atributos = append(e.atributos, [2]string{atributos[i], atributos[i+1]})
+	}
+	return e
+}
+
+// agregar añade hijos al elemento
+func (e *elemento) agregar(hijos ...*elemento) *elemento {
+	e.hijos = append(e.hijos, hijos...)
+	return e
+}
+
+// espaciosDeNombres devuelve las declaraciones xmlns del elemento
+func (e *elemento) espaciosDeNombres() [][2]string {
+	var ns [][2]string
+	for _, a := range e.atributos {
+		if esDeclaracionNS(a[0]) {
+			ns = append(ns, a)
+		}
+	}
+	return ns
+}
+
+// canonico serializa el elemento en forma canónica
+func (e *elemento) canonico() string {
+	var b strings.Builder
+	e.escribir(&b, nil)
+	return b.String()
+}
+
+// escribir serializa el elemento agregando los espacios de nombres heredados indicados
+func (e *elemento) escribir(b *strings.Builder, heredados [][2]string) {
+	atributos := append(append([][2]string{}, heredados...), e.atributos...)
+	ordenarAtributos(atributos)
+
+	b.WriteString("<")
+	b.WriteString(e.nombre)
+	for _, a := range atributos {
+		b.WriteString(" ")
+		b.WriteString(a[0])
+		b.WriteString(`="`)
+		b.WriteString(escaparAtributo(a[1]))
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+	b.WriteString(escaparTexto(e.texto))
+	for _, h := range e.hijos {
+		h.escribir(b, nil)
+	}
+	b.WriteString("</")
+	b.WriteString(e.nombre)
+	b.WriteString(">")
+}
+
+// ordenarAtributos ordena según C14N: primero las declaraciones xmlns por prefijo y luego el resto por nombre
+func ordenarAtributos(atributos [][2]string) {
+	sort.SliceStable(atributos, func(i, j int) bool {
+		nsI, nsJ := esDeclaracionNS(atributos[i][0]), esDeclaracionNS(atributos[j][0])
+		if nsI != nsJ {
+			return nsI
+		}
+		return atributos[i][0] < atributos[j][0]
+	})
+}
+
+func esDeclaracionNS(nombre string) bool {
+	return nombre == "xmlns" || strings.HasPrefix(nombre, "xmlns:")
+}
+
+func escaparTexto(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;").Replace(s)
+}
+
+func escaparAtributo(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;").Replace(s)
+}
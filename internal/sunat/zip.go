@@ -0,0 +1,50 @@
+package sunat
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Comprimir empaqueta el XML firmado en un ZIP con el nombre que exige SUNAT
+func Comprimir(nombre string, xml []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	archivo, err := w.Create(nombre + ".xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := archivo.Write(xml); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Descomprimir devuelve el primer archivo XML contenido en un ZIP (por ejemplo, el CDR)
+func Descomprimir(datos []byte) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(datos), int64(len(datos)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, archivo := range r.File {
+		if !strings.HasSuffix(strings.ToLower(archivo.Name), ".xml") {
+			continue
+		}
+		rc, err := archivo.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	return nil, errors.New("el ZIP no contiene un archivo XML")
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prefijoCodigoEmbarque identifica los códigos QR de embarque emitidos por el sistema
+const prefijoCodigoEmbarque = "EMB"
+
+// GenerarCodigoEmbarque genera el código firmado que se imprime como QR en el ticket de una reserva.
+// El código incluye la reserva y el tour programado, de modo que no sirve para otra salida.
+func GenerarCodigoEmbarque(idReserva, idTourProgramado int, secret string) string {
+	datos := fmt.Sprintf("%s.%d.%d", prefijoCodigoEmbarque, idReserva, idTourProgramado)
+	return datos + "." + firmaEmbarque(datos, secret)
+}
+
+// ValidarCodigoEmbarque verifica la firma de un código de embarque y devuelve la reserva y el tour que contiene
+func ValidarCodigoEmbarque(codigo, secret string) (idReserva int, idTourProgramado int, err error) {
+	partes := strings.Split(strings.TrimSpace(codigo), ".")
+	if len(partes) != 4 || partes[0] != prefijoCodigoEmbarque {
+		return 0, 0, errors.New("código de embarque con formato inválido")
+	}
+
+	datos := strings.Join(partes[:3], ".")
+	if !hmac.Equal([]byte(partes[3]), []byte(firmaEmbarque(datos, secret))) {
+		return 0, 0, errors.New("código de embarque con firma inválida")
+	}
+
+	idReserva, err = strconv.Atoi(partes[1])
+	if err != nil {
+		return 0, 0, errors.New("código de embarque con reserva inválida")
+	}
+	idTourProgramado, err = strconv.Atoi(partes[2])
+	if err != nil {
+		return 0, 0, errors.New("código de embarque con tour inválido")
+	}
+
+	return idReserva, idTourProgramado, nil
+}
+
+// firmaEmbarque calcula la firma HMAC-SHA256 de los datos del código en base64 URL
+func firmaEmbarque(datos, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(datos))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -24,6 +24,12 @@ func CheckPasswordHash(password, hash string) bool {
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -42,3 +48,34 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// GenerarTokenAleatorio genera un valor aleatorio de n bytes codificado en base64 URL,
+// apto para identificadores de tokens y secretos de un solo uso
+func GenerarTokenAleatorio(n int) (string, error) {
+	aleatorios := make([]byte, n)
+	if _, err := rand.Read(aleatorios); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aleatorios), nil
+}
+
+// HashToken calcula el hash SHA-256 en hexadecimal con el que se guarda un token en la base de datos
+func HashToken(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
+
+// hashFicticio es un hash bcrypt con el mismo costo que HashPassword, generado la primera vez que se usa
+var (
+	hashFicticio     string
+	hashFicticioOnce sync.Once
+)
+
+// SimularCheckPassword ejecuta una comparación bcrypt equivalente a CheckPasswordHash contra un hash ficticio,
+// para que un correo inexistente tarde lo mismo que una contraseña incorrecta
+func SimularCheckPassword(password string) {
+	hashFicticioOnce.Do(func() {
+		hashFicticio, _ = HashPassword("contrasena-ficticia")
+	})
+	CheckPasswordHash(password, hashFicticio)
+}
@@ -10,68 +10,128 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// Tipos de sujeto de un token: personal del sistema (tabla usuario) o cliente (tabla cliente)
+const (
+	SujetoUsuario = "USUARIO"
+	SujetoCliente = "CLIENTE"
+)
+
 // TokenClaims define los claims del token JWT
 type TokenClaims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID     int    `json:"user_id"` // id_usuario o id_cliente según TipoSujeto
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	TipoSujeto string `json:"sub_type"`
 	jwt.RegisteredClaims
 }
 
+// Duraciones por defecto si la configuración no las define
+const (
+	duracionAccesoDefecto  = time.Minute * 15
+	duracionRefreshDefecto = time.Hour * 24 * 7
+)
+
+// DuracionAcceso devuelve la vigencia de los tokens de acceso
+func DuracionAcceso(config *config.Config) time.Duration {
+	if config.JWTExpiration > 0 {
+		return config.JWTExpiration
+	}
+	return duracionAccesoDefecto
+}
+
+// DuracionRefresh devuelve la vigencia de los refresh tokens
+func DuracionRefresh(config *config.Config) time.Duration {
+	if config.JWTRefreshExpiration > 0 {
+		return config.JWTRefreshExpiration
+	}
+	return duracionRefreshDefecto
+}
+
 // GenerateJWT genera un nuevo token JWT para el usuario
 func GenerateJWT(usuario *entidades.Usuario, config *config.Config) (string, error) {
-	// Configurar claims estándar
-	claims := TokenClaims{
-		UserID: usuario.ID,
-		Email:  usuario.Correo,
-		Role:   usuario.Rol,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)), // 24 horas
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "sistema-tours",
-			Subject:   fmt.Sprintf("%d", usuario.ID),
-		},
+	claims, err := nuevosClaims(SujetoUsuario, usuario.ID, usuario.Correo, usuario.Rol, DuracionAcceso(config))
+	if err != nil {
+		return "", err
 	}
+	return firmarToken(claims, config.JWTSecret)
+}
 
-	// Crear token con los claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// GenerateRefreshToken genera un token de actualización
+func GenerateRefreshToken(usuario *entidades.Usuario, config *config.Config) (string, error) {
+	claims, err := nuevosClaims(SujetoUsuario, usuario.ID, usuario.Correo, usuario.Rol, DuracionRefresh(config))
+	if err != nil {
+		return "", err
+	}
+	return firmarToken(claims, config.JWTRefreshSecret)
+}
 
-	// Firmar token con la llave secreta
-	tokenString, err := token.SignedString([]byte(config.JWTSecret))
+// GenerateClienteJWT genera un nuevo token JWT para el cliente
+func GenerateClienteJWT(cliente *entidades.Cliente, config *config.Config) (string, error) {
+	claims, err := nuevosClaims(SujetoCliente, cliente.ID, cliente.Correo, "CLIENTE", DuracionAcceso(config))
 	if err != nil {
 		return "", err
 	}
+	return firmarToken(claims, config.JWTSecret)
+}
 
-	return tokenString, nil
+// GenerateClienteRefreshToken genera un token de actualización para el cliente
+func GenerateClienteRefreshToken(cliente *entidades.Cliente, config *config.Config) (string, error) {
+	claims, err := nuevosClaims(SujetoCliente, cliente.ID, cliente.Correo, "CLIENTE", DuracionRefresh(config))
+	if err != nil {
+		return "", err
+	}
+	return firmarToken(claims, config.JWTRefreshSecret)
 }
 
-// GenerateRefreshToken genera un token de actualización
-func GenerateRefreshToken(usuario *entidades.Usuario, config *config.Config) (string, error) {
-	// Configurar claims estándar para refresh token (mayor duración)
-	claims := TokenClaims{
-		UserID: usuario.ID,
-		Email:  usuario.Correo,
-		Role:   usuario.Rol,
+// nuevosClaims arma los claims de un token; el subject incluye el tipo para que los IDs de
+// usuarios y clientes no se confundan. Cada token lleva un jti aleatorio para poder revocarlo
+func nuevosClaims(tipoSujeto string, id int, correo, rol string, duracion time.Duration) (TokenClaims, error) {
+	jti, err := GenerarTokenAleatorio(16)
+	if err != nil {
+		return TokenClaims{}, err
+	}
+
+	ahora := time.Now()
+	return TokenClaims{
+		UserID:     id,
+		Email:      correo,
+		Role:       rol,
+		TipoSujeto: tipoSujeto,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // 7 días
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(ahora.Add(duracion)),
+			IssuedAt:  jwt.NewNumericDate(ahora),
+			NotBefore: jwt.NewNumericDate(ahora),
 			Issuer:    "sistema-tours",
-			Subject:   fmt.Sprintf("%d", usuario.ID),
+			Subject:   fmt.Sprintf("%s:%d", tipoSujeto, id),
 		},
-	}
+	}, nil
+}
 
+// firmarToken firma los claims con la llave indicada
+func firmarToken(claims TokenClaims, secret string) (string, error) {
 	// Crear token con los claims
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Firmar token con la llave secreta
-	tokenString, err := token.SignedString([]byte(config.JWTRefreshSecret))
-	if err != nil {
-		return "", err
-	}
+	return token.SignedString([]byte(secret))
+}
 
-	return tokenString, nil
+// validarSujeto verifica que el tipo de sujeto del token sea conocido y coherente con el rol
+func validarSujeto(claims *TokenClaims) error {
+	switch claims.TipoSujeto {
+	case SujetoUsuario:
+		if claims.Role == "CLIENTE" {
+			return errors.New("rol inválido para un token de usuario")
+		}
+	case SujetoCliente:
+		if claims.Role != "CLIENTE" {
+			return errors.New("rol inválido para un token de cliente")
+		}
+	default:
+		return errors.New("tipo de sujeto del token inválido")
+	}
+	return nil
 }
 
 // ValidateToken valida un token JWT
@@ -102,6 +162,73 @@ func ValidateToken(tokenString string, config *config.Config) (*TokenClaims, err
 		return nil, errors.New("no se pudieron extraer los claims del token")
 	}
 
+	// Verificar tipo de sujeto
+	if err := validarSujeto(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Propósitos de un token de desafío de doble factor
+const (
+	DesafioVerificar = "VERIFICAR_2FA" // La cuenta tiene doble factor: falta el código
+	DesafioEnrolar   = "ENROLAR_2FA"   // El rol exige doble factor y la cuenta aún no lo configuró
+)
+
+// DesafioClaims define los claims del token de desafío que devuelve el login cuando falta el segundo factor.
+// No tiene sub_type, así que ValidateToken lo rechaza y no sirve como token de acceso.
+type DesafioClaims struct {
+	UserID    int    `json:"user_id"`
+	Proposito string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GenerarTokenDesafio genera un token de desafío de corta duración para un usuario
+func GenerarTokenDesafio(idUsuario int, proposito string, duracion time.Duration, config *config.Config) (string, error) {
+	jti, err := GenerarTokenAleatorio(16)
+	if err != nil {
+		return "", err
+	}
+
+	ahora := time.Now()
+	claims := DesafioClaims{
+		UserID:    idUsuario,
+		Proposito: proposito,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(ahora.Add(duracion)),
+			IssuedAt:  jwt.NewNumericDate(ahora),
+			NotBefore: jwt.NewNumericDate(ahora),
+			Issuer:    "sistema-tours",
+			Subject:   fmt.Sprintf("%s:%d", SujetoUsuario, idUsuario),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(config.JWTSecret))
+}
+
+// ValidarTokenDesafio valida un token de desafío y que tenga el propósito indicado
+func ValidarTokenDesafio(tokenString, proposito string, config *config.Config) (*DesafioClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &DesafioClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// Validar algoritmo de firma
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
+		}
+
+		return []byte(config.JWTSecret), nil
+	})
+
+	if err != nil {
+		return nil, errors.New("token de desafío inválido o vencido")
+	}
+
+	claims, ok := token.Claims.(*DesafioClaims)
+	if !ok || !token.Valid || claims.Proposito != proposito {
+		return nil, errors.New("token de desafío inválido o vencido")
+	}
+
 	return claims, nil
 }
 
@@ -133,5 +260,10 @@ func ValidateRefreshToken(tokenString string, config *config.Config) (*TokenClai
 		return nil, errors.New("no se pudieron extraer los claims del token")
 	}
 
+	// Verificar tipo de sujeto
+	if err := validarSujeto(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parámetros TOTP (RFC 6238) compatibles con las aplicaciones autenticadoras habituales
+const (
+	PeriodoTOTP      = 30 // Segundos que dura cada código
+	digitosTOTP      = 6
+	toleranciaTOTP   = 1 // Pasos aceptados antes y después del actual, por desfase de reloj
+	bytesSecretoTOTP = 20
+)
+
+// codificacionTOTP es base32 sin relleno, el formato que esperan las aplicaciones autenticadoras
+var codificacionTOTP = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerarSecretoTOTP genera un secreto aleatorio codificado en base32
+func GenerarSecretoTOTP() (string, error) {
+	aleatorios := make([]byte, bytesSecretoTOTP)
+	if _, err := rand.Read(aleatorios); err != nil {
+		return "", err
+	}
+	return codificacionTOTP.EncodeToString(aleatorios), nil
+}
+
+// PasoTOTP devuelve el número de intervalo al que pertenece un instante
+func PasoTOTP(instante time.Time) int64 {
+	return instante.Unix() / PeriodoTOTP
+}
+
+// CodigoTOTP calcula el código de un paso con el secreto en base32
+func CodigoTOTP(secreto string, paso int64) (string, error) {
+	llave, err := codificacionTOTP.DecodeString(strings.ToUpper(secreto))
+	if err != nil {
+		return "", errors.New("secreto TOTP inválido")
+	}
+
+	var contador [8]byte
+	binary.BigEndian.PutUint64(contador[:], uint64(paso))
+
+	mac := hmac.New(sha1.New, llave)
+	mac.Write(contador[:])
+	suma := mac.Sum(nil)
+
+	// Truncamiento dinámico (RFC 4226, sección 5.3)
+	desplazamiento := suma[len(suma)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(suma[desplazamiento:desplazamiento+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < digitosTOTP; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digitosTOTP, valor%modulo), nil
+}
+
+// VerificarTOTP comprueba un código contra los pasos vecinos al instante indicado.
+// Solo se aceptan pasos posteriores a ultimoPaso, para que un código no sirva dos veces.
+// Devuelve el paso que coincidió.
+func VerificarTOTP(secreto, codigo string, ahora time.Time, ultimoPaso int64) (int64, bool) {
+	codigo = strings.TrimSpace(codigo)
+	if len(codigo) != digitosTOTP {
+		return 0, false
+	}
+
+	actual := PasoTOTP(ahora)
+	for paso := actual - toleranciaTOTP; paso <= actual+toleranciaTOTP; paso++ {
+		if paso <= ultimoPaso {
+			continue
+		}
+		esperado, err := CodigoTOTP(secreto, paso)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) == 1 {
+			return paso, true
+		}
+	}
+	return 0, false
+}
+
+// URITOTP arma el URI otpauth:// que las aplicaciones autenticadoras leen desde un código QR
+func URITOTP(emisor, cuenta, secreto string) string {
+	parametros := url.Values{}
+	parametros.Set("secret", secreto)
+	parametros.Set("issuer", emisor)
+	parametros.Set("algorithm", "SHA1")
+	parametros.Set("digits", fmt.Sprint(digitosTOTP))
+	parametros.Set("period", fmt.Sprint(PeriodoTOTP))
+
+	etiqueta := url.PathEscape(emisor) + ":" + url.PathEscape(cuenta)
+	return "otpauth://totp/" + etiqueta + "?" + parametros.Encode()
+}
+
+// GenerarCodigosRecuperacion genera n códigos de recuperación con el formato xxxxx-xxxxx
+func GenerarCodigosRecuperacion(n int) ([]string, error) {
+	codigos := make([]string, n)
+	for i := range codigos {
+		aleatorios := make([]byte, 7)
+		if _, err := rand.Read(aleatorios); err != nil {
+			return nil, err
+		}
+		codigo := strings.ToLower(codificacionTOTP.EncodeToString(aleatorios))[:10]
+		codigos[i] = codigo[:5] + "-" + codigo[5:]
+	}
+	return codigos, nil
+}
+
+// NormalizarCodigoRecuperacion quita guiones y espacios y pasa a minúsculas,
+// para que el código se acepte como sea que el usuario lo escriba
+func NormalizarCodigoRecuperacion(codigo string) string {
+	codigo = strings.ToLower(strings.TrimSpace(codigo))
+	return strings.NewReplacer("-", "", " ", "").Replace(codigo)
+}
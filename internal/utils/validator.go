@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt" // Add this import
 	"reflect"
+	"sistema-tours/internal/entidades"
 	"strings"
 
 	"github.com/go-playground/locales/es"
@@ -48,6 +49,17 @@ func InitValidator() {
 
 	// Registrar traducciones
 	es_translations.RegisterDefaultTranslations(validate, trans)
+
+	// Validar estados de reserva contra la lista única de estados
+	validate.RegisterValidation("estado_reserva", func(fl validator.FieldLevel) bool {
+		return entidades.EsEstadoReserva(fl.Field().String())
+	})
+	validate.RegisterTranslation("estado_reserva", trans, func(ut ut.Translator) error {
+		return ut.Add("estado_reserva", "{0} debe ser uno de ["+strings.Join(entidades.EstadosReserva, " ")+"]", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("estado_reserva", fe.Field())
+		return t
+	})
 }
 
 // ValidateStruct valida una estructura utilizando etiquetas de validación
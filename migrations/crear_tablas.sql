@@ -85,7 +85,8 @@ CREATE TABLE tour_programado (
     FOREIGN KEY (id_tipo_tour) REFERENCES tipo_tour(id_tipo_tour),
     FOREIGN KEY (id_embarcacion) REFERENCES embarcacion(id_embarcacion),
     FOREIGN KEY (id_horario) REFERENCES horario_tour(id_horario),
-    UNIQUE (id_embarcacion, fecha, id_horario)
+    UNIQUE (id_embarcacion, fecha, id_horario),
+    CHECK (cupo_disponible >= 0 AND cupo_disponible <= cupo_maximo)
 );
 
 -- Tabla de métodos de pago
@@ -126,19 +127,88 @@ CREATE TABLE reserva (
     fecha_reserva TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     total_pagar DECIMAL(10,2) NOT NULL,
     notas TEXT,
-    estado VARCHAR(20) DEFAULT 'RESERVADO', -- RESERVADO, CANCELADA, etc.
+    estado VARCHAR(20) DEFAULT 'RESERVADO', -- PENDIENTE_PAGO, RESERVADO, CONFIRMADA, PAGADA, EMBARCADO, COMPLETADA, NO_SHOW, CANCELADA
+    expira_en TIMESTAMP,       -- Vencimiento de la retención de cupo (solo PENDIENTE_PAGO)
+    localizador VARCHAR(12) NOT NULL UNIQUE, -- Código público no secuencial (ej. TRS-7KQ2M9)
     FOREIGN KEY (id_vendedor) REFERENCES usuario(id_usuario),
     FOREIGN KEY (id_cliente) REFERENCES cliente(id_cliente),
     FOREIGN KEY (id_tour_programado) REFERENCES tour_programado(id_tour_programado),
     FOREIGN KEY (id_canal) REFERENCES canal_venta(id_canal)
 );
 
+-- Llaves de API de agencias y OTAs: acceso de máquina a la disponibilidad y a las reservas
+-- Solo se guarda el hash SHA-256 de la llave; el prefijo permite reconocerla en los listados
+CREATE TABLE api_key (
+    id_api_key SERIAL PRIMARY KEY,
+    nombre VARCHAR(100) NOT NULL,
+    prefijo VARCHAR(16) NOT NULL,
+    clave_hash CHAR(64) NOT NULL UNIQUE,
+    id_canal INT NOT NULL,             -- Canal de venta al que se atribuyen las reservas de la agencia
+    limite_minuto INT NOT NULL,        -- Peticiones permitidas por minuto
+    fecha_expiracion TIMESTAMP,        -- NULL si no expira
+    fecha_revocacion TIMESTAMP,
+    id_usuario_creador INT,
+    fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_canal) REFERENCES canal_venta(id_canal),
+    FOREIGN KEY (id_usuario_creador) REFERENCES usuario(id_usuario)
+);
+
+-- Uso de las llaves: una fila por petición, se inserta antes de atenderla para aplicar el límite por minuto
+-- y se completa con el código de la respuesta y la reserva creada
+CREATE TABLE uso_api_key (
+    id_uso_api_key BIGSERIAL PRIMARY KEY,
+    id_api_key INT NOT NULL,
+    metodo VARCHAR(10) NOT NULL,
+    ruta VARCHAR(255) NOT NULL,
+    codigo_estado INT NOT NULL,        -- 0 mientras la petición está en curso
+    ip VARCHAR(45),
+    id_reserva INT,                    -- Reserva creada con la petición
+    fecha TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_api_key) REFERENCES api_key(id_api_key) ON DELETE CASCADE,
+    FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_uso_api_key_fecha ON uso_api_key (id_api_key, fecha);
+
+-- Historial de estados de reserva
+-- Cada cambio de estado guarda quién lo hizo (usuario del personal, cliente o SISTEMA) y cuándo
+CREATE TABLE historial_estado_reserva (
+    id_historial SERIAL PRIMARY KEY,
+    id_reserva INT NOT NULL,
+    estado_anterior VARCHAR(20),    -- NULL al crear la reserva
+    estado_nuevo VARCHAR(20) NOT NULL,
+    rol VARCHAR(20) NOT NULL,       -- Rol del personal, CLIENTE, AGENCIA o SISTEMA
+    id_usuario INT,
+    id_cliente INT,
+    motivo VARCHAR(255),
+    id_api_key INT,                 -- Llave con la que una agencia hizo el cambio
+    fecha_cambio TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva) ON DELETE CASCADE,
+    FOREIGN KEY (id_usuario) REFERENCES usuario(id_usuario),
+    FOREIGN KEY (id_cliente) REFERENCES cliente(id_cliente),
+    FOREIGN KEY (id_api_key) REFERENCES api_key(id_api_key)
+);
+
 -- Tabla de tipo de pasaje
 CREATE TABLE tipo_pasaje (
     id_tipo_pasaje SERIAL PRIMARY KEY,
     nombre VARCHAR(100) NOT NULL,
     costo DECIMAL(10,2) NOT NULL,
-    edad VARCHAR(50)
+    edad VARCHAR(50),
+    edad_minima INT,  -- Edad mínima en años a la fecha del tour (NULL: sin límite)
+    edad_maxima INT   -- Edad máxima en años a la fecha del tour (NULL: sin límite)
+);
+
+-- Tarifas por tipo de tour: precio de un tipo de pasaje en un tipo de tour específico.
+-- Si no existe tarifa para la combinación se usa el costo general del tipo de pasaje.
+CREATE TABLE tarifa_tour (
+    id_tarifa SERIAL PRIMARY KEY,
+    id_tipo_tour INT NOT NULL,
+    id_tipo_pasaje INT NOT NULL,
+    precio DECIMAL(10,2) NOT NULL CHECK (precio >= 0),
+    FOREIGN KEY (id_tipo_tour) REFERENCES tipo_tour(id_tipo_tour),
+    FOREIGN KEY (id_tipo_pasaje) REFERENCES tipo_pasaje(id_tipo_pasaje),
+    UNIQUE (id_tipo_tour, id_tipo_pasaje)
 );
 
 -- Tabla intermedia para manejar la cantidad de pasajes solicitados en una reserva.
@@ -147,11 +217,44 @@ CREATE TABLE pasajes_cantidad (
     id_reserva INT NOT NULL,
     id_tipo_pasaje INT NOT NULL,
     cantidad INT NOT NULL,
+    precio_unitario DECIMAL(10,2) NOT NULL DEFAULT 0, -- Tarifa vigente al momento de reservar
     FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva),
     FOREIGN KEY (id_tipo_pasaje) REFERENCES tipo_pasaje(id_tipo_pasaje),
     UNIQUE (id_reserva, id_tipo_pasaje)
 );
 
+-- Tabla de pasajeros (manifiesto)
+-- Datos de cada persona que viaja con la reserva; la cantidad por tipo no puede superar pasajes_cantidad
+CREATE TABLE pasajero (
+    id_pasajero SERIAL PRIMARY KEY,
+    id_reserva INT NOT NULL,
+    id_tipo_pasaje INT NOT NULL,
+    nombres VARCHAR(100) NOT NULL,
+    apellidos VARCHAR(100) NOT NULL,
+    tipo_documento VARCHAR(50) NOT NULL,
+    numero_documento VARCHAR(20) NOT NULL,
+    nacionalidad VARCHAR(50) NOT NULL,
+    fecha_nacimiento DATE NOT NULL,
+    FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva) ON DELETE CASCADE,
+    FOREIGN KEY (id_tipo_pasaje) REFERENCES tipo_pasaje(id_tipo_pasaje),
+    UNIQUE (id_reserva, tipo_documento, numero_documento)
+);
+
+-- Tabla de embarques
+-- Registro del check-in de cada reserva al escanear su QR; una reserva solo embarca una vez
+CREATE TABLE embarque (
+    id_embarque SERIAL PRIMARY KEY,
+    id_reserva INT NOT NULL,
+    id_tour_programado INT NOT NULL,
+    id_usuario INT NOT NULL,          -- Chofer o administrador que registró el embarque
+    cantidad_pasajeros INT NOT NULL,
+    fecha_embarque TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva) ON DELETE CASCADE,
+    FOREIGN KEY (id_tour_programado) REFERENCES tour_programado(id_tour_programado),
+    FOREIGN KEY (id_usuario) REFERENCES usuario(id_usuario),
+    UNIQUE (id_reserva)
+);
+
 -- Tabla de pagos
 CREATE TABLE pago (
     id_pago SERIAL PRIMARY KEY,
@@ -162,22 +265,298 @@ CREATE TABLE pago (
     fecha_pago TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     comprobante VARCHAR(100),  -- Número de comprobante o transacción
     estado VARCHAR(20) DEFAULT 'PROCESADO', -- PROCESADO, ANULADO
+    tipo VARCHAR(20) DEFAULT 'COBRO',        -- COBRO, DEVOLUCION
     FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva),
     FOREIGN KEY (id_metodo_pago) REFERENCES metodo_pago(id_metodo_pago),
     FOREIGN KEY (id_canal) REFERENCES canal_venta(id_canal)
 );
 
+-- Tabla de series de comprobantes
+-- Cada serie lleva su propio correlativo; se incrementa dentro de la misma transacción que emite el comprobante
+CREATE TABLE serie_comprobante (
+    serie VARCHAR(4) PRIMARY KEY,    -- Por ejemplo: B001, F001
+    tipo VARCHAR(20) NOT NULL,       -- BOLETA, FACTURA, NOTA_CREDITO_BOLETA, NOTA_CREDITO_FACTURA
+    ultimo_correlativo INT NOT NULL DEFAULT 0,
+    activo BOOLEAN DEFAULT TRUE
+);
+
+INSERT INTO serie_comprobante (serie, tipo) VALUES
+    ('B001', 'BOLETA'),
+    ('F001', 'FACTURA'),
+    ('BC01', 'NOTA_CREDITO_BOLETA'),   -- Notas de crédito que modifican boletas
+    ('FC01', 'NOTA_CREDITO_FACTURA');  -- Notas de crédito que modifican facturas
+
 -- Tabla de comprobantes de pago
 CREATE TABLE comprobante_pago (
     id_comprobante SERIAL PRIMARY KEY,
     id_reserva INT NOT NULL,
     tipo VARCHAR(20) NOT NULL,  -- BOLETA, FACTURA, etc.
-    numero_comprobante VARCHAR(20) NOT NULL,
+    serie VARCHAR(4) NOT NULL,
+    correlativo INT NOT NULL,
+    numero_comprobante VARCHAR(20) NOT NULL,  -- SERIE-CORRELATIVO
     fecha_emision TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     subtotal DECIMAL(10,2) NOT NULL,
     igv DECIMAL(10,2) NOT NULL,
     total DECIMAL(10,2) NOT NULL,
     estado VARCHAR(20) DEFAULT 'EMITIDO', -- EMITIDO, ANULADO
+    motivo_anulacion VARCHAR(255),
+    fecha_anulacion TIMESTAMP,
+    estado_sunat VARCHAR(20) DEFAULT 'PENDIENTE', -- PENDIENTE, ENVIADO, ACEPTADO, OBSERVADO, RECHAZADO, BAJA
+    codigo_sunat VARCHAR(10),       -- Código de respuesta del CDR
+    descripcion_sunat TEXT,         -- Descripción de respuesta del CDR
+    hash_cpe VARCHAR(100),          -- Valor resumen de la firma digital
+    xml_firmado TEXT,
     FOREIGN KEY (id_reserva) REFERENCES reserva(id_reserva),
-    UNIQUE (tipo, numero_comprobante)
-);
\ No newline at end of file
+    FOREIGN KEY (serie) REFERENCES serie_comprobante(serie),
+    UNIQUE (tipo, numero_comprobante),
+    UNIQUE (serie, correlativo)
+);
+
+-- Tabla de notas de crédito
+-- Modifican un comprobante emitido (total o parcialmente) y se vinculan al pago de devolución
+CREATE TABLE nota_credito (
+    id_nota_credito SERIAL PRIMARY KEY,
+    id_comprobante INT NOT NULL,    -- Comprobante que se modifica
+    id_pago INT,                    -- Pago de devolución (NULL si no hubo reembolso)
+    serie VARCHAR(4) NOT NULL,
+    correlativo INT NOT NULL,
+    numero_nota VARCHAR(20) NOT NULL,  -- SERIE-CORRELATIVO
+    fecha_emision TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    codigo_motivo VARCHAR(2) NOT NULL, -- Catálogo 09: 01 anulación de la operación, 09 disminución en el valor
+    motivo VARCHAR(255) NOT NULL,
+    subtotal DECIMAL(10,2) NOT NULL,
+    igv DECIMAL(10,2) NOT NULL,
+    total DECIMAL(10,2) NOT NULL,
+    estado VARCHAR(20) DEFAULT 'EMITIDA',
+    estado_sunat VARCHAR(20) DEFAULT 'PENDIENTE', -- PENDIENTE, ACEPTADO, OBSERVADO, RECHAZADO
+    codigo_sunat VARCHAR(10),
+    descripcion_sunat TEXT,
+    hash_cpe VARCHAR(100),
+    xml_firmado TEXT,
+    FOREIGN KEY (id_comprobante) REFERENCES comprobante_pago(id_comprobante),
+    FOREIGN KEY (id_pago) REFERENCES pago(id_pago),
+    FOREIGN KEY (serie) REFERENCES serie_comprobante(serie),
+    UNIQUE (serie, correlativo)
+);
+
+-- Tabla de resúmenes enviados a SUNAT
+-- RC: resumen diario de boletas, RA: comunicación de baja de facturas
+CREATE TABLE resumen_sunat (
+    id_resumen SERIAL PRIMARY KEY,
+    tipo VARCHAR(2) NOT NULL,               -- RC, RA
+    identificador VARCHAR(20) NOT NULL,     -- Por ejemplo: RC-20240115-1
+    numero INT NOT NULL,                    -- Correlativo del día de generación
+    fecha_referencia DATE NOT NULL,         -- Fecha de emisión de los comprobantes informados
+    fecha_generacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ticket VARCHAR(50),
+    estado VARCHAR(20) DEFAULT 'PENDIENTE', -- PENDIENTE, ENVIADO, ACEPTADO, OBSERVADO, RECHAZADO, ERROR
+    codigo_sunat VARCHAR(10),
+    descripcion_sunat TEXT,
+    UNIQUE (identificador)
+);
+
+-- Comprobantes incluidos en cada resumen
+CREATE TABLE resumen_sunat_detalle (
+    id_resumen_detalle SERIAL PRIMARY KEY,
+    id_resumen INT NOT NULL,
+    id_comprobante INT NOT NULL,
+    condicion INT NOT NULL DEFAULT 1,  -- Catálogo 19: 1 adicionar, 3 anular
+    FOREIGN KEY (id_resumen) REFERENCES resumen_sunat(id_resumen),
+    FOREIGN KEY (id_comprobante) REFERENCES comprobante_pago(id_comprobante),
+    UNIQUE (id_resumen, id_comprobante)
+);
+
+-- Tabla de refresh tokens emitidos
+-- Solo se guarda el hash SHA-256 del token. Cada rotación crea un token nuevo en la misma familia;
+-- si se presenta un token ya usado, se revoca toda la familia (posible robo del token).
+CREATE TABLE refresh_token (
+    id_refresh_token SERIAL PRIMARY KEY,
+    tipo_sujeto VARCHAR(10) NOT NULL,     -- USUARIO, CLIENTE
+    id_sujeto INT NOT NULL,               -- id_usuario o id_cliente según tipo_sujeto
+    token_hash VARCHAR(64) NOT NULL,
+    familia VARCHAR(64) NOT NULL,         -- Identificador común de los tokens de una misma sesión
+    fecha_emision TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fecha_expiracion TIMESTAMP NOT NULL,
+    fecha_uso TIMESTAMP,                  -- Momento en que se rotó por un token nuevo
+    fecha_revocacion TIMESTAMP,
+    UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_refresh_token_familia ON refresh_token (familia);
+CREATE INDEX idx_refresh_token_sujeto ON refresh_token (tipo_sujeto, id_sujeto);
+
+-- Tabla de tokens de acceso revocados antes de su expiración (logout)
+-- Las filas vencidas se eliminan periódicamente porque el token ya no sería aceptado
+CREATE TABLE token_revocado (
+    jti VARCHAR(64) PRIMARY KEY,
+    fecha_expiracion TIMESTAMP NOT NULL
+);
+
+-- Sujetos cuyos tokens de acceso emitidos hasta fecha_revocacion ya no se aceptan (usuario desactivado o eliminado)
+-- Las filas se eliminan cuando ya expiraron todos los tokens emitidos antes de la revocación
+CREATE TABLE sujeto_revocado (
+    tipo_sujeto VARCHAR(10) NOT NULL,     -- USUARIO, CLIENTE
+    id_sujeto INT NOT NULL,
+    fecha_revocacion TIMESTAMP NOT NULL,
+    PRIMARY KEY (tipo_sujeto, id_sujeto)
+);
+
+-- Control de intentos fallidos de inicio de sesión
+-- La clave identifica la cuenta (USUARIO:correo, CLIENTE:correo) o el origen (IP:dirección)
+CREATE TABLE control_login (
+    clave VARCHAR(150) PRIMARY KEY,
+    fallos INT NOT NULL DEFAULT 0,        -- Fallos consecutivos dentro de la ventana
+    ultimo_fallo TIMESTAMP,
+    bloqueado_hasta TIMESTAMP
+);
+
+-- Auditoría de todos los intentos de inicio de sesión, exitosos o no
+CREATE TABLE auditoria_login (
+    id_auditoria_login SERIAL PRIMARY KEY,
+    tipo_sujeto VARCHAR(10) NOT NULL,     -- USUARIO, CLIENTE
+    correo VARCHAR(100) NOT NULL,
+    id_sujeto INT,                        -- NULL si el correo no corresponde a ninguna cuenta
+    ip VARCHAR(45),
+    exitoso BOOLEAN NOT NULL,
+    motivo VARCHAR(100),                  -- Motivo del rechazo: CREDENCIALES, BLOQUEADO, DESACTIVADO, DOBLE_FACTOR
+    fecha TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_auditoria_login_correo ON auditoria_login (correo, fecha);
+
+-- Tokens de recuperación de contraseña enviados por correo
+-- Solo se guarda el hash SHA-256; cada token sirve una sola vez y vence a los pocos minutos
+CREATE TABLE token_recuperacion (
+    id_token_recuperacion SERIAL PRIMARY KEY,
+    tipo_sujeto VARCHAR(10) NOT NULL,     -- USUARIO, CLIENTE
+    id_sujeto INT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    fecha_emision TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fecha_expiracion TIMESTAMP NOT NULL,
+    fecha_uso TIMESTAMP,
+    UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_token_recuperacion_sujeto ON token_recuperacion (tipo_sujeto, id_sujeto);
+
+-- Doble factor (TOTP) del personal del sistema
+-- El secreto queda pendiente (activo = FALSE) hasta que el usuario confirma un primer código
+CREATE TABLE doble_factor (
+    id_usuario INT PRIMARY KEY REFERENCES usuario(id_usuario) ON DELETE CASCADE,
+    secreto VARCHAR(64) NOT NULL,         -- Secreto TOTP en base32
+    activo BOOLEAN NOT NULL DEFAULT FALSE,
+    ultimo_paso BIGINT NOT NULL DEFAULT 0, -- Último intervalo de 30 s aceptado; impide reutilizar un código
+    fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fecha_activacion TIMESTAMP
+);
+
+-- Códigos de recuperación del doble factor; solo se guarda el hash SHA-256 y cada uno sirve una vez
+CREATE TABLE codigo_recuperacion (
+    id_codigo_recuperacion SERIAL PRIMARY KEY,
+    id_usuario INT NOT NULL REFERENCES usuario(id_usuario) ON DELETE CASCADE,
+    codigo_hash VARCHAR(64) NOT NULL,
+    fecha_uso TIMESTAMP,
+    UNIQUE (id_usuario, codigo_hash)
+);
+
+-- Permisos que protegen las rutas del personal; el código los referencia, así que se agregan por migración
+CREATE TABLE permiso (
+    codigo VARCHAR(50) PRIMARY KEY,       -- recurso:acción, por ejemplo reservas:create
+    descripcion VARCHAR(255) NOT NULL
+);
+
+INSERT INTO permiso (codigo, descripcion) VALUES
+    ('usuarios:manage', 'Gestionar usuarios del sistema y restablecer su doble factor'),
+    ('roles:manage', 'Gestionar roles y sus permisos'),
+    ('seguridad:manage', 'Desbloquear inicios de sesión y ver su auditoría'),
+    ('embarcaciones:read', 'Ver embarcaciones'),
+    ('embarcaciones:manage', 'Crear, modificar y eliminar embarcaciones'),
+    ('tipos-tour:read', 'Ver tipos de tour y sus horarios'),
+    ('tipos-tour:manage', 'Crear, modificar y eliminar tipos de tour y sus horarios'),
+    ('horarios-chofer:read', 'Ver horarios de los choferes'),
+    ('horarios-chofer:manage', 'Crear, modificar y eliminar horarios de los choferes'),
+    ('tours:read', 'Ver tours programados y cotizarlos'),
+    ('tours:schedule', 'Programar, modificar, cambiar de estado y eliminar tours'),
+    ('tours:assigned', 'Ver las embarcaciones, horarios y tours asignados al propio usuario'),
+    ('tarifas:read', 'Ver tipos de pasaje y tarifas'),
+    ('tarifas:manage', 'Crear, modificar y eliminar tipos de pasaje y tarifas'),
+    ('metodos-pago:read', 'Ver métodos de pago'),
+    ('metodos-pago:manage', 'Crear, modificar y eliminar métodos de pago'),
+    ('canales-venta:read', 'Ver canales de venta'),
+    ('canales-venta:manage', 'Crear, modificar y eliminar canales de venta'),
+    ('clientes:read', 'Ver clientes'),
+    ('clientes:write', 'Registrar y modificar clientes'),
+    ('clientes:delete', 'Eliminar clientes'),
+    ('reservas:read', 'Ver reservas, su historial y sus tickets'),
+    ('reservas:create', 'Registrar reservas'),
+    ('reservas:update', 'Modificar reservas, sus pasajeros y su estado'),
+    ('reservas:delete', 'Eliminar reservas'),
+    ('reservas:board', 'Marcar reservas como embarcadas, no show o completadas'),
+    ('reservas:reopen', 'Reactivar reservas canceladas'),
+    ('reservas:assign', 'Asignar el vendedor de una reserva; sin este permiso el vendedor es quien la registra'),
+    ('pagos:read', 'Ver pagos'),
+    ('pagos:create', 'Registrar pagos'),
+    ('pagos:annul', 'Anular pagos'),
+    ('comprobantes:read', 'Ver comprobantes y notas de crédito con su XML y PDF'),
+    ('comprobantes:issue', 'Emitir comprobantes, cambiar su estado y enviarlos a SUNAT'),
+    ('notas-credito:issue', 'Emitir notas de crédito y enviarlas a SUNAT'),
+    ('sunat:manage', 'Generar y consultar resúmenes diarios y comunicaciones de baja'),
+    ('manifiestos:read', 'Obtener el manifiesto de pasajeros de cualquier tour'),
+    ('embarques:read', 'Ver los embarques de cualquier tour'),
+    ('embarques:register', 'Registrar embarques con el código QR del ticket'),
+    ('api-keys:manage', 'Emitir, revocar y auditar las llaves de API de las agencias');
+
+-- Roles del personal: conjuntos de permisos editables desde la API
+-- Los roles de sistema no se pueden eliminar porque la lógica de negocio los usa por su nombre
+CREATE TABLE rol (
+    nombre VARCHAR(20) PRIMARY KEY,       -- Coincide con usuario.rol
+    descripcion VARCHAR(255),
+    sistema BOOLEAN NOT NULL DEFAULT FALSE,
+    fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE rol_permiso (
+    rol VARCHAR(20) NOT NULL REFERENCES rol(nombre) ON DELETE CASCADE,
+    permiso VARCHAR(50) NOT NULL REFERENCES permiso(codigo) ON DELETE CASCADE,
+    PRIMARY KEY (rol, permiso)
+);
+
+INSERT INTO rol (nombre, descripcion, sistema) VALUES
+    ('ADMIN', 'Administrador: todos los permisos', TRUE),
+    ('VENDEDOR', 'Venta de reservas, cobros y comprobantes', TRUE),
+    ('CHOFER', 'Conducción de tours y registro de embarques', TRUE);
+
+-- El administrador tiene todos los permisos
+INSERT INTO rol_permiso (rol, permiso) SELECT 'ADMIN', codigo FROM permiso;
+
+INSERT INTO rol_permiso (rol, permiso) VALUES
+    ('VENDEDOR', 'embarcaciones:read'),
+    ('VENDEDOR', 'tipos-tour:read'),
+    ('VENDEDOR', 'horarios-chofer:read'),
+    ('VENDEDOR', 'tours:read'),
+    ('VENDEDOR', 'tarifas:read'),
+    ('VENDEDOR', 'metodos-pago:read'),
+    ('VENDEDOR', 'canales-venta:read'),
+    ('VENDEDOR', 'clientes:read'),
+    ('VENDEDOR', 'clientes:write'),
+    ('VENDEDOR', 'reservas:read'),
+    ('VENDEDOR', 'reservas:create'),
+    ('VENDEDOR', 'reservas:update'),
+    ('VENDEDOR', 'reservas:board'),
+    ('VENDEDOR', 'pagos:read'),
+    ('VENDEDOR', 'pagos:create'),
+    ('VENDEDOR', 'pagos:annul'),
+    ('VENDEDOR', 'comprobantes:read'),
+    ('VENDEDOR', 'comprobantes:issue'),
+    ('VENDEDOR', 'notas-credito:issue'),
+    ('CHOFER', 'tipos-tour:read'),
+    ('CHOFER', 'tours:assigned'),
+    ('CHOFER', 'reservas:board'),
+    ('CHOFER', 'embarques:register');
+
+-- Alcances de cada llave: un subconjunto de los permisos del catálogo
+CREATE TABLE api_key_alcance (
+    id_api_key INT NOT NULL REFERENCES api_key(id_api_key) ON DELETE CASCADE,
+    permiso VARCHAR(50) NOT NULL REFERENCES permiso(codigo) ON DELETE CASCADE,
+    PRIMARY KEY (id_api_key, permiso)
+);
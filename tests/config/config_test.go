@@ -0,0 +1,82 @@
+// Tests para la configuración
+package config
+
+import (
+	"sistema-tours/internal/config"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+func TestValidarLlavesProduccion(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_REFRESH_SECRET", "")
+	t.Setenv("EMBARQUE_SECRET", "")
+
+	cfg := config.LoadConfig()
+
+	cfg.Env = "development"
+	if err := cfg.Validar(); err != nil {
+		t.Errorf("en desarrollo se aceptan las llaves por defecto: %v", err)
+	}
+
+	cfg.Env = "production"
+	if err := cfg.Validar(); err == nil {
+		t.Error("en producción no se deben aceptar las llaves por defecto")
+	}
+
+	cfg.JWTSecret = "k3Qv8nZ2pL7wXc4rT9mB1yH6sD0fG5jA"
+	cfg.JWTRefreshSecret = "R7tY2uI9oP4aS1dF6gH3jK8lZ5xC0vBn"
+	cfg.EmbarqueSecret = "M2nB7vC4xZ9lK1jH6gF3dS8aP5oI0uYt"
+	if err := cfg.Validar(); err != nil {
+		t.Errorf("las llaves configuradas deberían aceptarse: %v", err)
+	}
+
+	cfg.JWTRefreshSecret = cfg.JWTSecret
+	if err := cfg.Validar(); err == nil {
+		t.Error("el token de acceso y el refresh token no deben compartir llave")
+	}
+}
+
+func TestValidarRechazaLlavesPublicas(t *testing.T) {
+	propias := config.Config{
+		Env:              "production",
+		JWTSecret:        "k3Qv8nZ2pL7wXc4rT9mB1yH6sD0fG5jA",
+		JWTRefreshSecret: "R7tY2uI9oP4aS1dF6gH3jK8lZ5xC0vBn",
+		EmbarqueSecret:   "M2nB7vC4xZ9lK1jH6gF3dS8aP5oI0uYt",
+	}
+
+	casos := []struct {
+		nombre  string
+		cambiar func(cfg *config.Config)
+	}{
+		{"llave de acceso del .env de desarrollo", func(cfg *config.Config) { cfg.JWTSecret = "clave-segura-para-desarrollo" }},
+		{"llave de embarque del .env de desarrollo", func(cfg *config.Config) { cfg.EmbarqueSecret = "clave-segura-embarque-para-desarrollo" }},
+		{"llave de ejemplo del .env", func(cfg *config.Config) { cfg.JWTRefreshSecret = "cambiar-por-otra-llave-aleatoria" }},
+		{"llave corta", func(cfg *config.Config) { cfg.EmbarqueSecret = "llave-embarque-produccion" }},
+	}
+	for _, c := range casos {
+		cfg := propias
+		c.cambiar(&cfg)
+		if err := cfg.Validar(); err == nil {
+			t.Errorf("%s: se esperaba rechazar la configuración en producción", c.nombre)
+		}
+	}
+
+	// Ninguna llave del .env del repositorio sirve en producción
+	valores, err := godotenv.Read("../../.env")
+	if err != nil {
+		t.Fatalf("error al leer el .env del repositorio: %v", err)
+	}
+	for nombre, cambiar := range map[string]func(cfg *config.Config, valor string){
+		"JWT_SECRET":         func(cfg *config.Config, valor string) { cfg.JWTSecret = valor },
+		"JWT_REFRESH_SECRET": func(cfg *config.Config, valor string) { cfg.JWTRefreshSecret = valor },
+		"EMBARQUE_SECRET":    func(cfg *config.Config, valor string) { cfg.EmbarqueSecret = valor },
+	} {
+		cfg := propias
+		cambiar(&cfg, valores[nombre])
+		if err := cfg.Validar(); err == nil {
+			t.Errorf("se esperaba rechazar en producción el %s del .env del repositorio", nombre)
+		}
+	}
+}
@@ -1,2 +1,354 @@
 // Tests para controlador de autenticación
 package controladores
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
+	"sistema-tours/internal/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPrincipalPorTipoSujeto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{JWTSecret: "secreto-acceso", JWTRefreshSecret: "secreto-refresh"}
+
+	// Rutas que solo aceptan un tipo de sujeto y devuelven el principal resuelto
+	router := gin.New()
+	responderPrincipal := func(ctx *gin.Context) {
+		principal, _ := middleware.GetPrincipal(ctx)
+		ctx.JSON(http.StatusOK, principal)
+	}
+	protegido := router.Group("/", middleware.AuthMiddleware(cfg, nil))
+	protegido.GET("/usuario", middleware.SubjectMiddleware(utils.SujetoUsuario), responderPrincipal)
+	protegido.GET("/cliente", middleware.SubjectMiddleware(utils.SujetoCliente), responderPrincipal)
+
+	tokenUsuario, err := utils.GenerateJWT(&entidades.Usuario{ID: 7, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token de usuario: %v", err)
+	}
+	tokenCliente, err := utils.GenerateClienteJWT(&entidades.Cliente{ID: 7}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token de cliente: %v", err)
+	}
+
+	casos := []struct {
+		ruta   string
+		token  string
+		codigo int
+	}{
+		{"/usuario", tokenUsuario, http.StatusOK},
+		{"/usuario", tokenCliente, http.StatusForbidden},
+		{"/cliente", tokenCliente, http.StatusOK},
+		{"/cliente", tokenUsuario, http.StatusForbidden},
+		{"/cliente", "", http.StatusUnauthorized},
+	}
+	for _, c := range casos {
+		req := httptest.NewRequest(http.MethodGet, c.ruta, nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != c.codigo {
+			t.Errorf("%s: se esperaba %d, se obtuvo %d (%s)", c.ruta, c.codigo, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// revocadosPrueba es una lista de tokens y sujetos revocados en memoria
+type revocadosPrueba struct {
+	jtis    map[string]bool
+	sujetos map[string]time.Time // clave tipo:id, valor fecha de revocación
+}
+
+func (r revocadosPrueba) Revocado(jti, tipoSujeto string, idSujeto int, emision time.Time) (bool, error) {
+	if r.jtis[jti] {
+		return true, nil
+	}
+	fecha, ok := r.sujetos[fmt.Sprintf("%s:%d", tipoSujeto, idSujeto)]
+	return ok && !fecha.Before(emision), nil
+}
+
+func TestTokenRevocado(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{JWTSecret: "secreto-acceso", JWTRefreshSecret: "secreto-refresh"}
+
+	vigente, err := utils.GenerateJWT(&entidades.Usuario{ID: 7, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	revocado, err := utils.GenerateJWT(&entidades.Usuario{ID: 7, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	claims, err := utils.ValidateToken(revocado, cfg)
+	if err != nil {
+		t.Fatalf("error al validar token: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/", middleware.AuthMiddleware(cfg, revocadosPrueba{jtis: map[string]bool{claims.ID: true}}), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	casos := map[string]int{vigente: http.StatusOK, revocado: http.StatusUnauthorized}
+	for token, codigo := range casos {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != codigo {
+			t.Errorf("se esperaba %d, se obtuvo %d (%s)", codigo, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestSujetoRevocado(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{JWTSecret: "secreto-acceso", JWTRefreshSecret: "secreto-refresh"}
+
+	desactivado, err := utils.GenerateJWT(&entidades.Usuario{ID: 7, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	reactivado, err := utils.GenerateJWT(&entidades.Usuario{ID: 8, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	otro, err := utils.GenerateJWT(&entidades.Usuario{ID: 9, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+
+	// El usuario 7 se desactivó después de recibir su token; el 8 recibió el suyo tras la revocación
+	revocados := revocadosPrueba{sujetos: map[string]time.Time{
+		utils.SujetoUsuario + ":7": time.Now(),
+		utils.SujetoUsuario + ":8": time.Now().Add(-time.Minute),
+	}}
+
+	router := gin.New()
+	router.GET("/", middleware.AuthMiddleware(cfg, revocados), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	casos := map[string]int{desactivado: http.StatusUnauthorized, reactivado: http.StatusOK, otro: http.StatusOK}
+	for token, codigo := range casos {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != codigo {
+			t.Errorf("se esperaba %d, se obtuvo %d (%s)", codigo, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// permisosPrueba asigna permisos a roles en memoria
+type permisosPrueba map[string][]string
+
+func (p permisosPrueba) TienePermiso(rol, permiso string) (bool, error) {
+	for _, codigo := range p[rol] {
+		if codigo == permiso {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{JWTSecret: "secreto-acceso", JWTRefreshSecret: "secreto-refresh"}
+	permisos := permisosPrueba{"SUPERVISOR": {entidades.PermisoPagosAnular}}
+
+	router := gin.New()
+	router.POST("/pagos/:id/anular",
+		middleware.AuthMiddleware(cfg, nil),
+		middleware.RequirePermission(permisos, entidades.PermisoPagosAnular),
+		func(ctx *gin.Context) { ctx.Status(http.StatusOK) },
+	)
+
+	supervisor, err := utils.GenerateJWT(&entidades.Usuario{ID: 7, Rol: "SUPERVISOR"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	chofer, err := utils.GenerateJWT(&entidades.Usuario{ID: 8, Rol: "CHOFER"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	cliente, err := utils.GenerateClienteJWT(&entidades.Cliente{ID: 7}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+
+	casos := map[string]int{supervisor: http.StatusOK, chofer: http.StatusForbidden, cliente: http.StatusForbidden}
+	for token, codigo := range casos {
+		req := httptest.NewRequest(http.MethodPost, "/pagos/1/anular", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != codigo {
+			t.Errorf("se esperaba %d, se obtuvo %d (%s)", codigo, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// clavesPrueba autentica llaves de API en memoria y guarda su uso
+type clavesPrueba struct {
+	mu     sync.Mutex
+	llaves map[string]*entidades.APIKey
+	usos   []*entidades.UsoAPIKey
+}
+
+func (c *clavesPrueba) Autenticar(clave string) (*entidades.APIKey, error) {
+	if apiKey, ok := c.llaves[clave]; ok {
+		return apiKey, nil
+	}
+	return nil, errors.New("la llave de API no es válida")
+}
+
+func (c *clavesPrueba) ReservarPeticion(uso *entidades.UsoAPIKey, limite int) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := 0
+	for _, registrado := range c.usos {
+		if registrado.IDAPIKey == uso.IDAPIKey && registrado.CodigoEstado != http.StatusTooManyRequests {
+			total++
+		}
+	}
+	if total >= limite {
+		return false, nil
+	}
+
+	c.usos = append(c.usos, uso)
+	uso.ID = len(c.usos)
+	return true, nil
+}
+
+func (c *clavesPrueba) RegistrarUso(uso *entidades.UsoAPIKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Las peticiones reservadas ya están en la lista y se completan en el mismo puntero
+	if uso.ID == 0 {
+		c.usos = append(c.usos, uso)
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claves := &clavesPrueba{llaves: map[string]*entidades.APIKey{
+		"stk_lectura":  {ID: 1, IDCanal: 3, LimiteMinuto: 2, Alcances: []string{entidades.PermisoToursVer}},
+		"stk_reservas": {ID: 2, IDCanal: 3, LimiteMinuto: 10, Alcances: []string{entidades.PermisoReservasCrear}},
+	}}
+
+	router := gin.New()
+	agencia := router.Group("/", middleware.APIKeyMiddleware(claves))
+	agencia.GET("/tours", middleware.RequireAlcance(entidades.PermisoToursVer), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	agencia.POST("/reservas", middleware.RequireAlcance(entidades.PermisoReservasCrear), func(ctx *gin.Context) {
+		middleware.AtribuirReserva(ctx, 99)
+		ctx.Status(http.StatusCreated)
+	})
+
+	casos := []struct {
+		nombre string
+		metodo string
+		ruta   string
+		clave  string
+		codigo int
+	}{
+		{"sin llave", http.MethodGet, "/tours", "", http.StatusUnauthorized},
+		{"llave desconocida", http.MethodGet, "/tours", "stk_otra", http.StatusUnauthorized},
+		{"con alcance", http.MethodGet, "/tours", "stk_lectura", http.StatusOK},
+		{"sin alcance", http.MethodPost, "/reservas", "stk_lectura", http.StatusForbidden},
+		{"límite excedido", http.MethodGet, "/tours", "stk_lectura", http.StatusTooManyRequests},
+		{"límite de otra llave", http.MethodPost, "/reservas", "stk_reservas", http.StatusCreated},
+	}
+	for _, c := range casos {
+		req := httptest.NewRequest(c.metodo, c.ruta, nil)
+		if c.clave != "" {
+			req.Header.Set("X-API-Key", c.clave)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != c.codigo {
+			t.Errorf("%s: se esperaba %d, se obtuvo %d (%s)", c.nombre, c.codigo, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Solo se registran las peticiones con llave válida, con el código con el que se respondió
+	if len(claves.usos) != 4 {
+		t.Fatalf("se esperaban 4 usos registrados, se obtuvieron %d", len(claves.usos))
+	}
+	if uso := claves.usos[1]; uso.CodigoEstado != http.StatusForbidden || uso.IDReserva != nil {
+		t.Errorf("uso sin alcance registrado como %d con reserva %v", uso.CodigoEstado, uso.IDReserva)
+	}
+	if uso := claves.usos[3]; uso.IDReserva == nil || *uso.IDReserva != 99 {
+		t.Errorf("la reserva creada debería quedar atribuida a la llave, se obtuvo %v", uso.IDReserva)
+	}
+}
+
+// TestAPIKeyMiddlewareConcurrente verifica que las peticiones simultáneas de una llave no superen su límite
+// aunque ninguna haya terminado de atenderse
+func TestAPIKeyMiddlewareConcurrente(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claves := &clavesPrueba{llaves: map[string]*entidades.APIKey{
+		"stk_lectura": {ID: 1, IDCanal: 3, LimiteMinuto: 2, Alcances: []string{entidades.PermisoToursVer}},
+	}}
+
+	// El handler no responde hasta que se liberan todas las peticiones que lo alcanzaron
+	liberar := make(chan struct{})
+	router := gin.New()
+	router.GET("/tours", middleware.APIKeyMiddleware(claves), func(ctx *gin.Context) {
+		<-liberar
+		ctx.Status(http.StatusOK)
+	})
+
+	const peticiones = 5
+	codigos := make(chan int, peticiones)
+	for i := 0; i < peticiones; i++ {
+		go func() {
+			req := httptest.NewRequest(http.MethodGet, "/tours", nil)
+			req.Header.Set("X-API-Key", "stk_lectura")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			codigos <- rec.Code
+		}()
+	}
+
+	// Las que exceden el límite se rechazan mientras las demás siguen en curso
+	for i := 0; i < peticiones-2; i++ {
+		select {
+		case codigo := <-codigos:
+			if codigo != http.StatusTooManyRequests {
+				t.Errorf("se esperaba %d para una petición sobre el límite, se obtuvo %d", http.StatusTooManyRequests, codigo)
+			}
+		case <-time.After(5 * time.Second):
+			close(liberar)
+			t.Fatal("las peticiones sobre el límite no se rechazaron antes de atender las demás")
+		}
+	}
+
+	close(liberar)
+	for i := 0; i < 2; i++ {
+		if codigo := <-codigos; codigo != http.StatusOK {
+			t.Errorf("se esperaba %d para una petición dentro del límite, se obtuvo %d", http.StatusOK, codigo)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Tests para reserva
+package entidades
+
+import (
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/utils"
+	"testing"
+)
+
+func TestValidacionEstadoReserva(t *testing.T) {
+	for _, estado := range entidades.EstadosReserva {
+		if err := utils.ValidateStruct(&entidades.CambiarEstadoReservaRequest{Estado: estado}); err != nil {
+			t.Errorf("se esperaba aceptar el estado %s: %v", estado, err)
+		}
+	}
+
+	for _, estado := range []string{"", "ANULADA", "pagada"} {
+		if err := utils.ValidateStruct(&entidades.CambiarEstadoReservaRequest{Estado: estado}); err == nil {
+			t.Errorf("se esperaba rechazar el estado %q", estado)
+		}
+	}
+
+	// En la actualización el estado es opcional pero, si se envía, debe ser válido
+	actualizar := entidades.ActualizarReservaRequest{
+		IDCliente:        1,
+		IDTourProgramado: 1,
+		IDCanal:          1,
+		CantidadPasajes:  []entidades.PasajeCantidadRequest{{IDTipoPasaje: 1, Cantidad: 1}},
+	}
+	if err := utils.ValidateStruct(&actualizar); err != nil {
+		t.Errorf("se esperaba aceptar la actualización sin estado: %v", err)
+	}
+	actualizar.Estado = "ANULADA"
+	err := utils.ValidateStruct(&actualizar)
+	if err == nil {
+		t.Fatal("se esperaba rechazar la actualización con un estado inválido")
+	}
+	if errores := utils.FormatValidationErrors(err); len(errores) != 1 || errores[0].Field != "estado" {
+		t.Errorf("se esperaba un error en el campo estado, se obtuvo %v", errores)
+	}
+}
+
+func TestAplicarPagos(t *testing.T) {
+	casos := []struct {
+		nombre string
+		total  float64
+		pagado float64
+		saldo  float64
+		estado string
+	}{
+		{"sin pagos", 50, 0, 50, "PENDIENTE"},
+		{"pago parcial", 50, 20, 30, "PARCIAL"},
+		{"pago completo", 50, 50, 0, "PAGADO"},
+		{"suma con error de coma flotante", 0.3, 0.1 + 0.2, 0, "PAGADO"},
+		{"menos de medio céntimo de diferencia", 10, 9.999, 0, "PAGADO"},
+		{"un céntimo de diferencia", 10, 9.99, 0.01, "PARCIAL"},
+		{"reserva sin costo", 0, 0, 0, "PAGADO"},
+	}
+
+	for _, c := range casos {
+		reserva := entidades.Reserva{TotalPagar: c.total}
+		reserva.AplicarPagos(c.pagado)
+
+		if reserva.EstadoPago != c.estado || reserva.SaldoPendiente != c.saldo || reserva.MontoPagado != c.pagado {
+			t.Errorf("%s: se esperaba %s con saldo %.2f, se obtuvo %s con saldo %v y pagado %v",
+				c.nombre, c.estado, c.saldo, reserva.EstadoPago, reserva.SaldoPendiente, reserva.MontoPagado)
+		}
+	}
+}
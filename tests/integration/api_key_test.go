@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sync"
+	"testing"
+	"time"
+)
+
+// nuevoAPIKeyService arma el servicio de llaves de API con repositorios reales
+func nuevoAPIKeyService(db *sql.DB, cfg *config.Config) *servicios.APIKeyService {
+	return servicios.NewAPIKeyService(
+		db,
+		repositorios.NewAPIKeyRepository(db),
+		repositorios.NewUsoAPIKeyRepository(db),
+		repositorios.NewCanalVentaRepository(db),
+		cfg,
+	)
+}
+
+// TestAPIKeyAgencia verifica que una agencia se autentique con su llave, que sus reservas queden
+// en el canal de la llave y atribuidas a ella, y que la llave deje de servir al revocarla
+func TestAPIKeyAgencia(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	apiKeys := nuevoAPIKeyService(db, cfg)
+	reservas := nuevoReservaService(db)
+	d := crearDatosReserva(t, db, 5)
+
+	_, err := apiKeys.Create(&entidades.NuevaAPIKeyRequest{
+		Nombre:   "OTA prueba",
+		IDCanal:  d.idCanal,
+		Alcances: []string{entidades.PermisoPagosAnular},
+	}, d.idUsuario)
+	if err == nil {
+		t.Fatal("se esperaba un error por alcance no permitido")
+	}
+
+	creada, err := apiKeys.Create(&entidades.NuevaAPIKeyRequest{
+		Nombre:   "OTA prueba",
+		IDCanal:  d.idCanal,
+		Alcances: []string{entidades.PermisoReservasCrear, entidades.PermisoReservasVer},
+	}, d.idUsuario)
+	if err != nil {
+		t.Fatalf("error al emitir la llave: %v", err)
+	}
+	documento := fmt.Sprintf("A%d", time.Now().UnixNano()%1e12)
+	t.Cleanup(func() {
+		// Las reservas de la agencia se eliminan antes que la llave y el cliente que registró
+		db.Exec(`DELETE FROM historial_estado_reserva WHERE id_api_key = $1`, creada.APIKey.ID)
+		db.Exec(`DELETE FROM pasajes_cantidad WHERE id_reserva IN (SELECT id_reserva FROM reserva WHERE id_tour_programado = $1)`, d.idTour)
+		db.Exec(`DELETE FROM reserva WHERE id_tour_programado = $1`, d.idTour)
+		db.Exec(`DELETE FROM cliente WHERE numero_documento = $1`, documento)
+		db.Exec(`DELETE FROM api_key WHERE id_api_key = $1`, creada.APIKey.ID)
+	})
+
+	if creada.APIKey.LimiteMinuto != cfg.APIKeyLimiteMinuto {
+		t.Errorf("se esperaba el límite por defecto %d, se obtuvo %d", cfg.APIKeyLimiteMinuto, creada.APIKey.LimiteMinuto)
+	}
+
+	// La llave se reconoce por su valor en claro, nunca por su prefijo
+	if _, err := apiKeys.Autenticar(creada.APIKey.Prefijo); !errors.Is(err, servicios.ErrAPIKeyInvalida) {
+		t.Errorf("el prefijo no debería autenticar, se obtuvo %v", err)
+	}
+	apiKey, err := apiKeys.Autenticar(creada.Clave)
+	if err != nil {
+		t.Fatalf("error al autenticar la llave: %v", err)
+	}
+
+	agencia := servicios.Actor{Rol: "AGENCIA", ID: apiKey.ID, IDCanal: apiKey.IDCanal}
+	idReserva, err := reservas.CreateAgencia(&entidades.NuevaReservaAgenciaRequest{
+		Cliente: entidades.ClienteAgenciaRequest{
+			TipoDocumento:   "DNI",
+			NumeroDocumento: documento,
+			Nombres:         "Ana",
+			Apellidos:       "Agencia",
+		},
+		IDTourProgramado: d.idTour,
+		CantidadPasajes:  []entidades.PasajeCantidadRequest{{IDTipoPasaje: d.idTipoPasaje, Cantidad: 1}},
+	}, agencia)
+	if err != nil {
+		t.Fatalf("error al crear la reserva de la agencia: %v", err)
+	}
+
+	reserva, err := reservas.GetByID(idReserva, agencia)
+	if err != nil {
+		t.Fatalf("la agencia debería ver su reserva: %v", err)
+	}
+	if reserva.IDCanal != d.idCanal {
+		t.Errorf("la reserva debería quedar en el canal %d de la llave, quedó en %d", d.idCanal, reserva.IDCanal)
+	}
+
+	historial, err := reservas.ListHistorial(idReserva)
+	if err != nil || len(historial) == 0 {
+		t.Fatalf("error al obtener el historial: %v", err)
+	}
+	if historial[0].IDAPIKey == nil || *historial[0].IDAPIKey != apiKey.ID || historial[0].Rol != "AGENCIA" {
+		t.Errorf("la creación debería quedar atribuida a la llave %d: %+v", apiKey.ID, historial[0])
+	}
+
+	// Una agencia de otro canal no accede a la reserva
+	otra := servicios.Actor{Rol: "AGENCIA", ID: apiKey.ID, IDCanal: d.idCanal + 1}
+	if _, err := reservas.GetByID(idReserva, otra); !errors.Is(err, servicios.ErrAccesoDenegado) {
+		t.Errorf("se esperaba acceso denegado para otro canal, se obtuvo %v", err)
+	}
+
+	// El uso registrado cuenta para el límite por minuto, salvo las peticiones ya rechazadas por el límite
+	apiKeys.RegistrarUso(&entidades.UsoAPIKey{IDAPIKey: apiKey.ID, Metodo: http.MethodPost, Ruta: "/api/v1/agencia/reservas", CodigoEstado: http.StatusCreated, IDReserva: &idReserva})
+	apiKeys.RegistrarUso(&entidades.UsoAPIKey{IDAPIKey: apiKey.ID, Metodo: http.MethodGet, Ruta: "/api/v1/agencia/tours/disponibles", CodigoEstado: http.StatusTooManyRequests})
+	reservada, err := apiKeys.ReservarPeticion(&entidades.UsoAPIKey{IDAPIKey: apiKey.ID, Metodo: http.MethodGet, Ruta: "/api/v1/agencia/tours/disponibles"}, 2)
+	if err != nil || !reservada {
+		t.Errorf("se esperaba reservar la segunda petición del minuto con límite 2 (%v)", err)
+	}
+	reservada, err = apiKeys.ReservarPeticion(&entidades.UsoAPIKey{IDAPIKey: apiKey.ID, Metodo: http.MethodGet, Ruta: "/api/v1/agencia/tours/disponibles"}, 2)
+	if err != nil || reservada {
+		t.Errorf("no se esperaba reservar una tercera petición con límite 2 (%v)", err)
+	}
+	usos, err := apiKeys.ListUso(apiKey.ID)
+	if err != nil || len(usos) != 3 {
+		t.Fatalf("se esperaban 3 usos registrados, se obtuvo %d (%v)", len(usos), err)
+	}
+	if usos[2].IDReserva == nil || *usos[2].IDReserva != idReserva {
+		t.Errorf("el uso debería atribuir la reserva %d a la llave, se obtuvo %v", idReserva, usos[2].IDReserva)
+	}
+
+	if err := apiKeys.Revocar(apiKey.ID); err != nil {
+		t.Fatalf("error al revocar la llave: %v", err)
+	}
+	if _, err := apiKeys.Autenticar(creada.Clave); !errors.Is(err, servicios.ErrAPIKeyInvalida) {
+		t.Errorf("una llave revocada no debería autenticar, se obtuvo %v", err)
+	}
+	if err := apiKeys.Revocar(apiKey.ID); err == nil {
+		t.Error("revocar dos veces la misma llave debería fallar")
+	}
+}
+
+// TestAPIKeyLimiteConcurrente verifica que las peticiones simultáneas de una llave reserven su lugar
+// dentro del límite por minuto antes de atenderse, sin que ninguna se cuele por encima del límite
+func TestAPIKeyLimiteConcurrente(t *testing.T) {
+	db := abrirBaseDatos(t)
+	apiKeys := nuevoAPIKeyService(db, config.LoadConfig())
+	d := crearDatosReserva(t, db, 5)
+
+	const limite = 3
+	creada, err := apiKeys.Create(&entidades.NuevaAPIKeyRequest{
+		Nombre:       "OTA concurrente",
+		IDCanal:      d.idCanal,
+		Alcances:     []string{entidades.PermisoToursVer},
+		LimiteMinuto: limite,
+	}, d.idUsuario)
+	if err != nil {
+		t.Fatalf("error al emitir la llave: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM api_key WHERE id_api_key = $1`, creada.APIKey.ID)
+	})
+
+	const peticiones = 10
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reservadas := []*entidades.UsoAPIKey{}
+	for i := 0; i < peticiones; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uso := &entidades.UsoAPIKey{IDAPIKey: creada.APIKey.ID, Metodo: http.MethodGet, Ruta: "/api/v1/agencia/tours/disponibles"}
+			reservada, err := apiKeys.ReservarPeticion(uso, limite)
+			if err != nil {
+				t.Errorf("error al reservar la petición: %v", err)
+				return
+			}
+			if reservada {
+				mu.Lock()
+				reservadas = append(reservadas, uso)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(reservadas) != limite {
+		t.Fatalf("se esperaban %d peticiones reservadas, se obtuvieron %d", limite, len(reservadas))
+	}
+
+	// Al completarse, las reservadas quedan con el código con el que se respondió
+	for _, uso := range reservadas {
+		uso.CodigoEstado = http.StatusOK
+		apiKeys.RegistrarUso(uso)
+	}
+	usos, err := apiKeys.ListUso(creada.APIKey.ID)
+	if err != nil || len(usos) != limite {
+		t.Fatalf("se esperaban %d usos registrados, se obtuvo %d (%v)", limite, len(usos), err)
+	}
+	for _, uso := range usos {
+		if uso.CodigoEstado != http.StatusOK {
+			t.Errorf("el uso %d debería quedar con el código %d, se obtuvo %d", uso.ID, http.StatusOK, uso.CodigoEstado)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/controladores"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/rutas"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/sunat"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestAccesoRutasCliente verifica que cada ruta /cliente con ID de reserva o pasajero
+// solo responda al cliente dueño de la reserva
+func TestAccesoRutasCliente(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	router := nuevoRouterAutorizacion(db, cfg)
+
+	d := crearDatosReserva(t, db, 5)
+	idReserva, idPasajero := crearReservaConPasajero(t, db, d)
+	idOtroCliente := insertarPrueba(t, db, `INSERT INTO cliente (tipo_documento, numero_documento, nombres, apellidos)
+		VALUES ('DNI', '11111111', 'Otro', 'Cliente') RETURNING id_cliente`)
+	t.Cleanup(func() { db.Exec(`DELETE FROM cliente WHERE id_cliente = $1`, idOtroCliente) })
+
+	duenio := tokenPrueba(t, cfg, d.idCliente, "CLIENTE")
+	otro := tokenPrueba(t, cfg, idOtroCliente, "CLIENTE")
+	admin := tokenPrueba(t, cfg, d.idUsuario, "ADMIN")
+
+	pasajero := `{"id_tipo_pasaje": %d, "nombres": "Ana", "apellidos": "Prueba", "tipo_documento": "DNI",
+		"numero_documento": "%s", "nacionalidad": "PERUANA", "fecha_nacimiento": "1990-01-01T00:00:00Z"}`
+
+	casos := []casoAcceso{
+		{"ver reserva propia", "GET", fmt.Sprintf("/api/v1/cliente/reservas/%d", idReserva), duenio, "", http.StatusOK},
+		{"ver reserva ajena", "GET", fmt.Sprintf("/api/v1/cliente/reservas/%d", idReserva), otro, "", http.StatusForbidden},
+		{"cancelar reserva ajena", "POST", fmt.Sprintf("/api/v1/cliente/reservas/%d/estado", idReserva), otro, `{"estado": "CANCELADA"}`, http.StatusForbidden},
+		{"confirmar reserva ajena", "POST", fmt.Sprintf("/api/v1/cliente/reservas/%d/confirmar", idReserva), otro, "", http.StatusForbidden},
+		{"reservar a nombre de otro cliente", "POST", "/api/v1/cliente/reservas", otro,
+			fmt.Sprintf(`{"id_cliente": %d, "id_tour_programado": %d, "id_canal": %d, "cantidad_pasajes": [{"id_tipo_pasaje": %d, "cantidad": 1}]}`,
+				d.idCliente, d.idTour, d.idCanal, d.idTipoPasaje), http.StatusForbidden},
+		{"listar pasajeros propios", "GET", fmt.Sprintf("/api/v1/cliente/reservas/%d/pasajeros", idReserva), duenio, "", http.StatusOK},
+		{"listar pasajeros ajenos", "GET", fmt.Sprintf("/api/v1/cliente/reservas/%d/pasajeros", idReserva), otro, "", http.StatusForbidden},
+		{"agregar pasajero a reserva ajena", "POST", fmt.Sprintf("/api/v1/cliente/reservas/%d/pasajeros", idReserva), otro,
+			fmt.Sprintf(pasajero, d.idTipoPasaje, "22222222"), http.StatusForbidden},
+		{"ver pasajero propio", "GET", fmt.Sprintf("/api/v1/cliente/pasajeros/%d", idPasajero), duenio, "", http.StatusOK},
+		{"ver pasajero ajeno", "GET", fmt.Sprintf("/api/v1/cliente/pasajeros/%d", idPasajero), otro, "", http.StatusForbidden},
+		{"actualizar pasajero ajeno", "PUT", fmt.Sprintf("/api/v1/cliente/pasajeros/%d", idPasajero), otro,
+			fmt.Sprintf(pasajero, d.idTipoPasaje, "33333333"), http.StatusForbidden},
+		{"eliminar pasajero ajeno", "DELETE", fmt.Sprintf("/api/v1/cliente/pasajeros/%d", idPasajero), otro, "", http.StatusForbidden},
+		{"personal en rutas de cliente", "GET", fmt.Sprintf("/api/v1/cliente/reservas/%d", idReserva), admin, "", http.StatusForbidden},
+	}
+
+	probarAccesos(t, router, casos)
+}
+
+// TestAccesoRutasChofer verifica que cada ruta /chofer/mis-tours/:idTourProgramado
+// solo responda al chofer asignado a la embarcación del tour
+func TestAccesoRutasChofer(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	router := nuevoRouterAutorizacion(db, cfg)
+
+	d := crearDatosReserva(t, db, 5)
+	idReserva, _ := crearReservaConPasajero(t, db, d)
+	idOtroChofer := insertarPrueba(t, db, `INSERT INTO usuario (nombres, apellidos, rol, tipo_de_documento, numero_documento)
+		VALUES ('Otro', 'Chofer', 'CHOFER', 'DNI', $1) RETURNING id_usuario`, fmt.Sprintf("O%d", time.Now().UnixNano()%1e12))
+	t.Cleanup(func() { db.Exec(`DELETE FROM usuario WHERE id_usuario = $1`, idOtroChofer) })
+
+	asignado := tokenPrueba(t, cfg, d.idUsuario, "CHOFER")
+	otro := tokenPrueba(t, cfg, idOtroChofer, "CHOFER")
+	vendedor := tokenPrueba(t, cfg, d.idUsuario, "VENDEDOR")
+
+	base := fmt.Sprintf("/api/v1/chofer/mis-tours/%d", d.idTour)
+	codigo := fmt.Sprintf(`{"codigo": %q}`, utils.GenerarCodigoEmbarque(idReserva, d.idTour, cfg.EmbarqueSecret))
+
+	casos := []casoAcceso{
+		{"reservas de tour asignado", "GET", base + "/reservas", asignado, "", http.StatusOK},
+		{"reservas de tour ajeno", "GET", base + "/reservas", otro, "", http.StatusForbidden},
+		{"manifiesto de tour asignado", "GET", base + "/manifiesto", asignado, "", http.StatusOK},
+		{"manifiesto de tour ajeno", "GET", base + "/manifiesto", otro, "", http.StatusForbidden},
+		{"embarques de tour asignado", "GET", base + "/embarques", asignado, "", http.StatusOK},
+		{"embarques de tour ajeno", "GET", base + "/embarques", otro, "", http.StatusForbidden},
+		{"contador de tour asignado", "GET", base + "/embarques/contador", asignado, "", http.StatusOK},
+		{"contador de tour ajeno", "GET", base + "/embarques/contador", otro, "", http.StatusForbidden},
+		{"embarcar en tour ajeno", "POST", base + "/embarques", otro, codigo, http.StatusForbidden},
+		{"reservas con rol sin tours asignados", "GET", base + "/reservas", vendedor, "", http.StatusForbidden},
+		{"embarcar con rol sin permiso de embarque", "POST", base + "/embarques", vendedor, codigo, http.StatusForbidden},
+	}
+
+	probarAccesos(t, router, casos)
+}
+
+// casoAcceso describe una petición autenticada y el código HTTP esperado
+type casoAcceso struct {
+	nombre string
+	metodo string
+	ruta   string
+	token  string
+	cuerpo string
+	codigo int
+}
+
+// probarAccesos ejecuta cada caso contra el router y compara el código de respuesta
+func probarAccesos(t *testing.T, router *gin.Engine, casos []casoAcceso) {
+	t.Helper()
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			req := httptest.NewRequest(caso.metodo, caso.ruta, bytes.NewBufferString(caso.cuerpo))
+			req.Header.Set("Authorization", "Bearer "+caso.token)
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != caso.codigo {
+				t.Errorf("%s %s: se esperaba %d, se obtuvo %d: %s", caso.metodo, caso.ruta, caso.codigo, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+// nuevoRouterAutorizacion arma las rutas con los controladores de reservas, pasajeros, impresión y embarque
+func nuevoRouterAutorizacion(db *sql.DB, cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	reservaRepo := repositorios.NewReservaRepository(db)
+	pasajeroRepo := repositorios.NewPasajeroRepository(db)
+	tipoPasajeRepo := repositorios.NewTipoPasajeRepository(db)
+	tourProgramadoRepo := repositorios.NewTourProgramadoRepository(db)
+
+	reservaService := nuevoReservaService(db)
+	pasajeroService := servicios.NewPasajeroService(db, pasajeroRepo, reservaRepo, tipoPasajeRepo, tourProgramadoRepo)
+	impresionService := servicios.NewImpresionService(
+		repositorios.NewComprobantePagoRepository(db),
+		reservaRepo,
+		tourProgramadoRepo,
+		tipoPasajeRepo,
+		pasajeroRepo,
+		sunat.Emisor{RUC: cfg.SunatRUC, RazonSocial: cfg.SunatRazonSocial},
+		cfg.EmbarqueSecret,
+	)
+	embarqueService := servicios.NewEmbarqueService(
+		db,
+		repositorios.NewEmbarqueRepository(db),
+		reservaRepo,
+		tourProgramadoRepo,
+		reservaService,
+		cfg.EmbarqueSecret,
+	)
+
+	router := gin.New()
+	rutas.SetupRoutes(
+		router,
+		cfg,
+		repositorios.NewTokenRevocadoRepository(db),
+		nuevoRolService(db, cfg),
+		nil,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		controladores.NewReservaController(reservaService),
+		nil, nil, nil, nil,
+		controladores.NewImpresionController(impresionService),
+		nil, nil,
+		controladores.NewPasajeroController(pasajeroService),
+		controladores.NewEmbarqueController(embarqueService),
+		nil, nil, nil, nil, nil,
+	)
+
+	return router
+}
+
+// crearReservaConPasajero crea una reserva del cliente de prueba con un pasajero registrado
+func crearReservaConPasajero(t *testing.T, db *sql.DB, d datosReserva) (int, int) {
+	t.Helper()
+
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+	idReserva, err := nuevoReservaService(db).Create(&entidades.NuevaReservaRequest{
+		IDCliente:        d.idCliente,
+		IDTourProgramado: d.idTour,
+		IDCanal:          d.idCanal,
+		CantidadPasajes: []entidades.PasajeCantidadRequest{
+			{IDTipoPasaje: d.idTipoPasaje, Cantidad: 2},
+		},
+	}, admin)
+	if err != nil {
+		t.Fatalf("error al crear la reserva: %v", err)
+	}
+
+	reservaRepo := repositorios.NewReservaRepository(db)
+	pasajeroRepo := repositorios.NewPasajeroRepository(db)
+	idPasajero, err := servicios.NewPasajeroService(
+		db,
+		pasajeroRepo,
+		reservaRepo,
+		repositorios.NewTipoPasajeRepository(db),
+		repositorios.NewTourProgramadoRepository(db),
+	).Create(idReserva, &entidades.NuevoPasajeroRequest{
+		IDTipoPasaje:    d.idTipoPasaje,
+		Nombres:         "Pasajero",
+		Apellidos:       "Prueba",
+		TipoDocumento:   "DNI",
+		NumeroDocumento: "10000001",
+		Nacionalidad:    "PERUANA",
+		FechaNacimiento: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, admin)
+	if err != nil {
+		t.Fatalf("error al registrar el pasajero: %v", err)
+	}
+
+	return idReserva, idPasajero
+}
+
+// tokenPrueba genera un token de acceso para el rol e ID indicados
+func tokenPrueba(t *testing.T, cfg *config.Config, id int, rol string) string {
+	t.Helper()
+
+	var token string
+	var err error
+	if rol == "CLIENTE" {
+		token, err = utils.GenerateClienteJWT(&entidades.Cliente{ID: id}, cfg)
+	} else {
+		token, err = utils.GenerateJWT(&entidades.Usuario{ID: id, Rol: rol}, cfg)
+	}
+	if err != nil {
+		t.Fatalf("error al generar el token: %v", err)
+	}
+	return token
+}
+
+// insertarPrueba ejecuta un INSERT ... RETURNING y devuelve el ID generado
+func insertarPrueba(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+
+	var id int
+	if err := db.QueryRow(query, args...).Scan(&id); err != nil {
+		t.Fatalf("error al preparar datos: %v", err)
+	}
+	return id
+}
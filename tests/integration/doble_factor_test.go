@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+)
+
+// nuevoDobleFactor arma el servicio de doble factor con repositorios reales
+func nuevoDobleFactor(db *sql.DB, cfg *config.Config) *servicios.DobleFactorService {
+	return servicios.NewDobleFactorService(
+		db,
+		repositorios.NewDobleFactorRepository(db),
+		repositorios.NewCodigoRecuperacionRepository(db),
+		repositorios.NewUsuarioRepository(db),
+		cfg,
+	)
+}
+
+// TestDobleFactorLogin verifica el enrolamiento obligatorio durante el login, que un código TOTP
+// no sirva dos veces, que cada código de recuperación sirva una sola vez y el restablecimiento por un administrador
+func TestDobleFactorLogin(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	cfg.DobleFactorRoles = []string{"VENDEDOR"}
+	dobleFactor := nuevoDobleFactor(db, cfg)
+	authService := servicios.NewAuthService(
+		db,
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewClienteRepository(db),
+		repositorios.NewRefreshTokenRepository(db),
+		repositorios.NewTokenRevocadoRepository(db),
+		nuevoProteccionLogin(db, cfg),
+		dobleFactor,
+		cfg,
+	)
+
+	hash, err := utils.HashPassword("clave-prueba")
+	if err != nil {
+		t.Fatalf("error al generar el hash: %v", err)
+	}
+	sufijo := time.Now().UnixNano() % 1e9
+	correo := fmt.Sprintf("dosfactores%d@test.com", sufijo)
+	idUsuario := insertarPrueba(t, db, `INSERT INTO usuario (nombres, apellidos, correo, rol, tipo_de_documento, numero_documento, contrasena)
+		VALUES ('Doble', 'Factor', $1, 'VENDEDOR', 'DNI', $2, $3) RETURNING id_usuario`, correo, fmt.Sprintf("F%d", sufijo), hash)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM refresh_token WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoUsuario, idUsuario)
+		db.Exec(`DELETE FROM auditoria_login WHERE correo = $1`, correo)
+		db.Exec(`DELETE FROM control_login WHERE clave = $1`, utils.SujetoUsuario+":"+correo)
+		db.Exec(`DELETE FROM usuario WHERE id_usuario = $1`, idUsuario)
+	})
+
+	login := func() *entidades.LoginResponse {
+		t.Helper()
+		loginResp, err := authService.Login(&entidades.LoginRequest{Correo: correo, Contrasena: "clave-prueba"}, "")
+		if err != nil {
+			t.Fatalf("error al iniciar sesión: %v", err)
+		}
+		return loginResp
+	}
+
+	// El rol exige doble factor: el login solo devuelve un desafío de enrolamiento
+	desafio := login()
+	if !desafio.RequiereEnrolamiento || desafio.Token != "" || desafio.TokenDesafio == "" {
+		t.Fatalf("se esperaba un desafío de enrolamiento sin tokens, se obtuvo %+v", desafio)
+	}
+	if _, err := authService.VerificarDobleFactor(&entidades.VerificarDobleFactorRequest{TokenDesafio: desafio.TokenDesafio, Codigo: "000000"}, ""); err == nil {
+		t.Fatal("un desafío de enrolamiento no debe servir para verificar")
+	}
+
+	enrolamiento, err := authService.EnrolarDobleFactorDesafio(desafio.TokenDesafio, "")
+	if err != nil {
+		t.Fatalf("error al enrolar: %v", err)
+	}
+	codigo, err := utils.CodigoTOTP(enrolamiento.Secreto, utils.PasoTOTP(time.Now()))
+	if err != nil {
+		t.Fatalf("error al calcular el código: %v", err)
+	}
+	activado, err := authService.ActivarDobleFactorDesafio(&entidades.VerificarDobleFactorRequest{TokenDesafio: desafio.TokenDesafio, Codigo: codigo}, "")
+	if err != nil {
+		t.Fatalf("error al activar: %v", err)
+	}
+	if activado.Token == "" || len(activado.CodigosRecuperacion) == 0 {
+		t.Fatalf("la activación debe iniciar sesión y entregar códigos de recuperación, se obtuvo %+v", activado)
+	}
+
+	// Con el doble factor activo se pide el código
+	desafio = login()
+	if !desafio.RequiereDobleFactor || desafio.RequiereEnrolamiento || desafio.Token != "" {
+		t.Fatalf("se esperaba un desafío de verificación sin tokens, se obtuvo %+v", desafio)
+	}
+	verificar := func(codigo string) error {
+		_, err := authService.VerificarDobleFactor(&entidades.VerificarDobleFactorRequest{TokenDesafio: desafio.TokenDesafio, Codigo: codigo}, "")
+		return err
+	}
+	if err := verificar(codigo); !errors.Is(err, servicios.ErrCodigoDobleFactorInvalido) {
+		t.Fatalf("un código TOTP ya usado debe rechazarse, se obtuvo %v", err)
+	}
+	if err := verificar(activado.CodigosRecuperacion[0]); err != nil {
+		t.Fatalf("el código de recuperación debe permitir iniciar sesión: %v", err)
+	}
+	if err := verificar(activado.CodigosRecuperacion[0]); !errors.Is(err, servicios.ErrCodigoDobleFactorInvalido) {
+		t.Fatalf("un código de recuperación ya usado debe rechazarse, se obtuvo %v", err)
+	}
+
+	// El administrador restablece el doble factor: se vuelve a pedir el enrolamiento
+	if err := dobleFactor.Restablecer(idUsuario); err != nil {
+		t.Fatalf("error al restablecer: %v", err)
+	}
+	if desafio = login(); !desafio.RequiereEnrolamiento {
+		t.Errorf("tras restablecer se esperaba un desafío de enrolamiento, se obtuvo %+v", desafio)
+	}
+}
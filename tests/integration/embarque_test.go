@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"testing"
+)
+
+// TestContadorEmbarque verifica que el contador del chofer solo espere las reservas que pueden embarcar
+func TestContadorEmbarque(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 20)
+	service := nuevoReservaService(db)
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+
+	// Dos pasajeros por reserva; el estado se fija directamente para cubrir todos los casos
+	for _, estado := range []string{"RESERVADO", "PAGADA", "EMBARCADO", "PENDIENTE_PAGO", "CANCELADA", "NO_SHOW"} {
+		id, err := service.Create(&entidades.NuevaReservaRequest{
+			IDCliente:        d.idCliente,
+			IDTourProgramado: d.idTour,
+			IDCanal:          d.idCanal,
+			CantidadPasajes:  []entidades.PasajeCantidadRequest{{IDTipoPasaje: d.idTipoPasaje, Cantidad: 2}},
+		}, admin)
+		if err != nil {
+			t.Fatalf("error al crear la reserva: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE reserva SET estado = $1 WHERE id_reserva = $2`, estado, id); err != nil {
+			t.Fatalf("error al cambiar el estado de la reserva: %v", err)
+		}
+	}
+
+	contador, err := repositorios.NewEmbarqueRepository(db).GetContadorByTourProgramado(d.idTour)
+	if err != nil {
+		t.Fatalf("error al obtener el contador: %v", err)
+	}
+
+	esperado := entidades.ContadorEmbarque{
+		IDTourProgramado:    d.idTour,
+		ReservasTotales:     3,
+		ReservasEmbarcadas:  1,
+		PasajerosReservados: 6,
+		PasajerosEmbarcados: 2,
+	}
+	if *contador != esperado {
+		t.Errorf("se esperaba el contador %+v, se obtuvo %+v", esperado, *contador)
+	}
+}
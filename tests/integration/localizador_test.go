@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"regexp"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"strings"
+	"testing"
+)
+
+// TestLocalizadorReserva verifica que cada reserva recibe un localizador único y que se puede consultar con el documento
+func TestLocalizadorReserva(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 5)
+	service := nuevoReservaService(db)
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+
+	formato := regexp.MustCompile(`^TRS-[2-9A-HJKMNP-Z]{6}$`)
+	localizadores := map[string]bool{}
+	var ultima *entidades.Reserva
+
+	for i := 0; i < 3; i++ {
+		id, err := service.Create(&entidades.NuevaReservaRequest{
+			IDCliente:        d.idCliente,
+			IDTourProgramado: d.idTour,
+			IDCanal:          d.idCanal,
+			CantidadPasajes: []entidades.PasajeCantidadRequest{
+				{IDTipoPasaje: d.idTipoPasaje, Cantidad: 1},
+			},
+		}, admin)
+		if err != nil {
+			t.Fatalf("error al crear la reserva: %v", err)
+		}
+
+		ultima, err = service.GetByID(id, admin)
+		if err != nil {
+			t.Fatalf("error al obtener la reserva: %v", err)
+		}
+		if !formato.MatchString(ultima.Localizador) {
+			t.Errorf("localizador con formato inválido: %q", ultima.Localizador)
+		}
+		if localizadores[ultima.Localizador] {
+			t.Errorf("localizador repetido: %q", ultima.Localizador)
+		}
+		localizadores[ultima.Localizador] = true
+	}
+
+	// El cliente de prueba tiene el documento 00000000
+	sinPrefijo := strings.ToLower(strings.TrimPrefix(ultima.Localizador, "TRS-"))
+	reserva, err := service.ConsultarPorLocalizador(&entidades.ConsultaReservaRequest{Localizador: sinPrefijo, NumeroDocumento: "00000000"})
+	if err != nil {
+		t.Fatalf("error al consultar por localizador: %v", err)
+	}
+	if reserva.ID != ultima.ID {
+		t.Errorf("se esperaba la reserva %d, se obtuvo %d", ultima.ID, reserva.ID)
+	}
+
+	if _, err := service.ConsultarPorLocalizador(&entidades.ConsultaReservaRequest{Localizador: ultima.Localizador, NumeroDocumento: "99999999"}); err == nil {
+		t.Error("se esperaba rechazar la consulta con otro documento")
+	}
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"database/sql"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sync"
+	"testing"
+)
+
+// nuevoPagoService arma el servicio de pagos con repositorios reales
+func nuevoPagoService(db *sql.DB) *servicios.PagoService {
+	return servicios.NewPagoService(
+		db,
+		repositorios.NewPagoRepository(db),
+		repositorios.NewReservaRepository(db),
+		repositorios.NewMetodoPagoRepository(db),
+		repositorios.NewCanalVentaRepository(db),
+	)
+}
+
+// nuevoComprobanteService arma el servicio de comprobantes con repositorios reales
+func nuevoComprobanteService(db *sql.DB) *servicios.ComprobantePagoService {
+	return servicios.NewComprobantePagoService(
+		db,
+		repositorios.NewComprobantePagoRepository(db),
+		repositorios.NewReservaRepository(db),
+		repositorios.NewClienteRepository(db),
+		repositorios.NewNotaCreditoRepository(db),
+	)
+}
+
+// nuevoNotaCreditoService arma el servicio de notas de crédito con repositorios reales
+func nuevoNotaCreditoService(db *sql.DB) *servicios.NotaCreditoService {
+	return servicios.NewNotaCreditoService(
+		db,
+		repositorios.NewNotaCreditoRepository(db),
+		repositorios.NewComprobantePagoRepository(db),
+		repositorios.NewReservaRepository(db),
+		repositorios.NewPagoRepository(db),
+		repositorios.NewMetodoPagoRepository(db),
+		repositorios.NewCanalVentaRepository(db),
+	)
+}
+
+// comprobantePagado contiene una reserva pagada por completo con su boleta emitida
+type comprobantePagado struct {
+	idReserva     int
+	idComprobante int
+	idMetodoPago  int
+	total         float64
+}
+
+// crearComprobantePagado registra una reserva, la paga por completo y le emite una boleta
+func crearComprobantePagado(t *testing.T, db *sql.DB, d datosReserva) comprobantePagado {
+	t.Helper()
+
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+	idReserva, err := nuevoReservaService(db).Create(&entidades.NuevaReservaRequest{
+		IDCliente:        d.idCliente,
+		IDTourProgramado: d.idTour,
+		IDCanal:          d.idCanal,
+		CantidadPasajes:  []entidades.PasajeCantidadRequest{{IDTipoPasaje: d.idTipoPasaje, Cantidad: 2}},
+	}, admin)
+	if err != nil {
+		t.Fatalf("error al crear la reserva: %v", err)
+	}
+
+	c := comprobantePagado{idReserva: idReserva}
+	c.idMetodoPago = insertarPrueba(t, db, `INSERT INTO metodo_pago (nombre) VALUES ('PRUEBA') RETURNING id_metodo_pago`)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM nota_credito WHERE id_comprobante IN (SELECT id_comprobante FROM comprobante_pago WHERE id_reserva = $1)`, idReserva)
+		db.Exec(`DELETE FROM comprobante_pago WHERE id_reserva = $1`, idReserva)
+		db.Exec(`DELETE FROM pago WHERE id_reserva = $1`, idReserva)
+		db.Exec(`DELETE FROM metodo_pago WHERE id_metodo_pago = $1`, c.idMetodoPago)
+	})
+
+	err = db.QueryRow(`INSERT INTO pago (id_reserva, id_metodo_pago, id_canal, monto)
+		SELECT id_reserva, $2, id_canal, total_pagar FROM reserva WHERE id_reserva = $1
+		RETURNING monto`, idReserva, c.idMetodoPago).Scan(&c.total)
+	if err != nil {
+		t.Fatalf("error al registrar el pago: %v", err)
+	}
+
+	c.idComprobante, err = nuevoComprobanteService(db).Create(&entidades.NuevoComprobantePagoRequest{IDReserva: idReserva, Tipo: "BOLETA"})
+	if err != nil {
+		t.Fatalf("error al emitir la boleta: %v", err)
+	}
+
+	return c
+}
+
+// TestNotaCreditoParcialSinSaldo verifica que una nota de crédito parcial con devolución
+// reduzca el total de la reserva y no deje como saldo pendiente lo devuelto
+func TestNotaCreditoParcialSinSaldo(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 5)
+	c := crearComprobantePagado(t, db, d)
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+
+	_, err := nuevoNotaCreditoService(db).Create(&entidades.NuevaNotaCreditoRequest{
+		IDComprobante: c.idComprobante,
+		Motivo:        "Descuento posterior a la venta",
+		Monto:         5,
+		IDMetodoPago:  c.idMetodoPago,
+		IDCanal:       d.idCanal,
+	})
+	if err != nil {
+		t.Fatalf("error al emitir la nota de crédito: %v", err)
+	}
+
+	reserva, err := nuevoReservaService(db).GetByID(c.idReserva, admin)
+	if err != nil {
+		t.Fatalf("error al obtener la reserva: %v", err)
+	}
+	if reserva.TotalPagar != c.total-5 {
+		t.Errorf("se esperaba el total %.2f tras la nota, se obtuvo %.2f", c.total-5, reserva.TotalPagar)
+	}
+	if reserva.SaldoPendiente != 0 || reserva.EstadoPago != "PAGADO" {
+		t.Errorf("se esperaba la reserva pagada sin saldo, se obtuvo saldo %.2f y estado %s", reserva.SaldoPendiente, reserva.EstadoPago)
+	}
+
+	// Lo devuelto no se puede volver a cobrar como si fuera saldo
+	_, err = nuevoPagoService(db).Create(&entidades.NuevoPagoRequest{
+		IDReserva:    c.idReserva,
+		IDMetodoPago: c.idMetodoPago,
+		IDCanal:      d.idCanal,
+		Monto:        5,
+	})
+	if err == nil {
+		t.Error("se esperaba rechazar un cobro por el monto acreditado")
+	}
+}
+
+// TestAnulacionYNotaCreditoConcurrentes verifica que un comprobante no quede a la vez anulado y acreditado
+// cuando la baja y la nota de crédito se piden al mismo tiempo
+func TestAnulacionYNotaCreditoConcurrentes(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 5)
+	c := crearComprobantePagado(t, db, d)
+	comprobantes := nuevoComprobanteService(db)
+	notas := nuevoNotaCreditoService(db)
+
+	var wg sync.WaitGroup
+	var errAnular, errNota error
+	inicio := make(chan struct{})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-inicio
+		errAnular = comprobantes.CambiarEstado(c.idComprobante, &entidades.CambiarEstadoComprobanteRequest{Estado: "ANULADO", Motivo: "Error en los datos"})
+	}()
+	go func() {
+		defer wg.Done()
+		<-inicio
+		_, errNota = notas.Create(&entidades.NuevaNotaCreditoRequest{IDComprobante: c.idComprobante, Motivo: "Descuento", Monto: 5})
+	}()
+	close(inicio)
+	wg.Wait()
+
+	if (errAnular == nil) == (errNota == nil) {
+		t.Fatalf("se esperaba que solo una operación tuviera éxito: anulación %v, nota de crédito %v", errAnular, errNota)
+	}
+
+	var estado string
+	var notasEmitidas int
+	db.QueryRow(`SELECT estado FROM comprobante_pago WHERE id_comprobante = $1`, c.idComprobante).Scan(&estado)
+	db.QueryRow(`SELECT COUNT(*) FROM nota_credito WHERE id_comprobante = $1`, c.idComprobante).Scan(&notasEmitidas)
+	if estado == "ANULADO" && notasEmitidas > 0 {
+		t.Errorf("el comprobante quedó anulado con %d notas de crédito", notasEmitidas)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"testing"
+	"time"
+)
+
+// TestManifiestoPasajeros verifica que los pasajeros de una reserva respeten sus pasajes y las edades de cada tipo
+func TestManifiestoPasajeros(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 5)
+	if _, err := db.Exec(`UPDATE tipo_pasaje SET edad_minima = 18 WHERE id_tipo_pasaje = $1`, d.idTipoPasaje); err != nil {
+		t.Fatalf("error al preparar el tipo de pasaje: %v", err)
+	}
+
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+	idReserva, err := nuevoReservaService(db).Create(&entidades.NuevaReservaRequest{
+		IDCliente:        d.idCliente,
+		IDTourProgramado: d.idTour,
+		IDCanal:          d.idCanal,
+		CantidadPasajes: []entidades.PasajeCantidadRequest{
+			{IDTipoPasaje: d.idTipoPasaje, Cantidad: 2},
+		},
+	}, admin)
+	if err != nil {
+		t.Fatalf("error al crear la reserva: %v", err)
+	}
+
+	service := servicios.NewPasajeroService(
+		db,
+		repositorios.NewPasajeroRepository(db),
+		repositorios.NewReservaRepository(db),
+		repositorios.NewTipoPasajeRepository(db),
+		repositorios.NewTourProgramadoRepository(db),
+	)
+
+	// pasajero arma un pasajero adulto con el documento indicado
+	pasajero := func(documento string, nacimiento time.Time) *entidades.NuevoPasajeroRequest {
+		return &entidades.NuevoPasajeroRequest{
+			IDTipoPasaje:    d.idTipoPasaje,
+			Nombres:         "Pasajero",
+			Apellidos:       "Prueba",
+			TipoDocumento:   "DNI",
+			NumeroDocumento: documento,
+			Nacionalidad:    "PERUANA",
+			FechaNacimiento: nacimiento,
+		}
+	}
+	adulto := time.Now().AddDate(-30, 0, 0)
+
+	if _, err := service.Create(idReserva, pasajero("10000000", time.Now().AddDate(-10, 0, 0)), admin); err == nil {
+		t.Error("se esperaba rechazar un pasajero menor a la edad mínima del pasaje")
+	}
+	if _, err := service.Create(idReserva, pasajero("10000001", adulto), admin); err != nil {
+		t.Fatalf("error al registrar el primer pasajero: %v", err)
+	}
+	if _, err := service.Create(idReserva, pasajero("10000001", adulto), admin); err == nil {
+		t.Error("se esperaba rechazar un documento repetido en la reserva")
+	}
+	if _, err := service.Create(idReserva, pasajero("10000002", adulto), admin); err != nil {
+		t.Fatalf("error al registrar el segundo pasajero: %v", err)
+	}
+	if _, err := service.Create(idReserva, pasajero("10000003", adulto), admin); err == nil {
+		t.Error("se esperaba rechazar un pasajero que excede los pasajes de la reserva")
+	}
+
+	// Un cliente solo ve los pasajeros de sus propias reservas
+	otroCliente := servicios.Actor{Rol: "CLIENTE", ID: d.idCliente + 1}
+	if _, err := service.ListByReserva(idReserva, otroCliente); err == nil {
+		t.Error("se esperaba rechazar el acceso de otro cliente")
+	}
+
+	pasajeros, err := service.ListByReserva(idReserva, servicios.Actor{Rol: "CLIENTE", ID: d.idCliente})
+	if err != nil {
+		t.Fatalf("error al listar pasajeros: %v", err)
+	}
+	if len(pasajeros) != 2 {
+		t.Errorf("se esperaban 2 pasajeros, se obtuvieron %d", len(pasajeros))
+	}
+}
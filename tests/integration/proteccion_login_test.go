@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+)
+
+// nuevoProteccionLogin arma el servicio de protección de inicio de sesión con repositorios reales
+func nuevoProteccionLogin(db *sql.DB, cfg *config.Config) *servicios.ProteccionLoginService {
+	return servicios.NewProteccionLoginService(
+		repositorios.NewControlLoginRepository(db),
+		repositorios.NewAuditoriaLoginRepository(db),
+		cfg,
+	)
+}
+
+// TestBloqueoLogin verifica que una cuenta se bloquee tras el máximo de fallos, que el bloqueo
+// alcance también a la contraseña correcta y que un administrador pueda desbloquearla
+func TestBloqueoLogin(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	cfg.LoginMaxFallos = 3
+	cfg.LoginRetrasoBase = 0 // Sin retraso entre fallos para no esperar en el test
+	proteccion := nuevoProteccionLogin(db, cfg)
+	authService := servicios.NewAuthService(
+		db,
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewClienteRepository(db),
+		repositorios.NewRefreshTokenRepository(db),
+		repositorios.NewTokenRevocadoRepository(db),
+		proteccion,
+		nuevoDobleFactor(db, cfg),
+		cfg,
+	)
+
+	hash, err := utils.HashPassword("clave-prueba")
+	if err != nil {
+		t.Fatalf("error al generar el hash: %v", err)
+	}
+	correo := fmt.Sprintf("bloqueo%d@test.com", time.Now().UnixNano()%1e9)
+	idCliente := insertarPrueba(t, db, `INSERT INTO cliente (tipo_documento, numero_documento, nombres, apellidos, correo, contrasena)
+		VALUES ('DNI', '66666666', 'Bloqueo', 'Prueba', $1, $2) RETURNING id_cliente`, correo, hash)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM refresh_token WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoCliente, idCliente)
+		db.Exec(`DELETE FROM auditoria_login WHERE correo = $1`, correo)
+		db.Exec(`DELETE FROM control_login WHERE clave = $1`, utils.SujetoCliente+":"+correo)
+		db.Exec(`DELETE FROM cliente WHERE id_cliente = $1`, idCliente)
+	})
+
+	login := func(contrasena string) error {
+		_, err := authService.LoginCliente(&entidades.LoginClienteRequest{Correo: correo, Contrasena: contrasena}, "")
+		return err
+	}
+
+	for i := 0; i < cfg.LoginMaxFallos; i++ {
+		if err := login("incorrecta"); err == nil || errors.Is(err, servicios.ErrLoginBloqueado) {
+			t.Fatalf("fallo %d: se esperaba un error de credenciales, se obtuvo %v", i+1, err)
+		}
+	}
+
+	if err := login("clave-prueba"); !errors.Is(err, servicios.ErrLoginBloqueado) {
+		t.Fatalf("se esperaba la cuenta bloqueada, se obtuvo %v", err)
+	}
+
+	err = proteccion.Desbloquear(&entidades.DesbloquearLoginRequest{TipoSujeto: utils.SujetoCliente, Correo: correo})
+	if err != nil {
+		t.Fatalf("error al desbloquear: %v", err)
+	}
+	if err := login("clave-prueba"); err != nil {
+		t.Fatalf("después del desbloqueo el inicio de sesión debería funcionar: %v", err)
+	}
+
+	auditoria, err := proteccion.ListAuditoriaByCorreo(correo)
+	if err != nil {
+		t.Fatalf("error al listar la auditoría: %v", err)
+	}
+	if len(auditoria) != cfg.LoginMaxFallos+2 {
+		t.Errorf("se esperaban %d intentos auditados, se obtuvieron %d", cfg.LoginMaxFallos+2, len(auditoria))
+	}
+	if !auditoria[0].Exitoso || auditoria[1].Motivo != servicios.MotivoLoginBloqueado {
+		t.Errorf("auditoría inesperada: último exitoso=%v, penúltimo motivo=%q", auditoria[0].Exitoso, auditoria[1].Motivo)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/correo"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+)
+
+// TestRecuperacionContrasena verifica que el token enviado por correo sirva una sola vez,
+// que cambie la contraseña y que cierre las sesiones abiertas del cliente
+func TestRecuperacionContrasena(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	clienteRepo := repositorios.NewClienteRepository(db)
+	refreshTokenRepo := repositorios.NewRefreshTokenRepository(db)
+	enviador := correo.NuevoEnviadorFake()
+	authService := servicios.NewAuthService(
+		db,
+		repositorios.NewUsuarioRepository(db),
+		clienteRepo,
+		refreshTokenRepo,
+		repositorios.NewTokenRevocadoRepository(db),
+		nuevoProteccionLogin(db, cfg),
+		nuevoDobleFactor(db, cfg),
+		cfg,
+	)
+	recuperacionService := servicios.NewRecuperacionContrasenaService(
+		db,
+		repositorios.NewUsuarioRepository(db),
+		clienteRepo,
+		repositorios.NewTokenRecuperacionRepository(db),
+		refreshTokenRepo,
+		enviador,
+		cfg,
+	)
+
+	hash, err := utils.HashPassword("clave-anterior")
+	if err != nil {
+		t.Fatalf("error al generar el hash: %v", err)
+	}
+	correoCliente := fmt.Sprintf("recuperacion%d@test.com", time.Now().UnixNano()%1e9)
+	idCliente := insertarPrueba(t, db, `INSERT INTO cliente (tipo_documento, numero_documento, nombres, apellidos, correo, contrasena)
+		VALUES ('DNI', '55555555', 'Olvido', 'Prueba', $1, $2) RETURNING id_cliente`, correoCliente, hash)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM token_recuperacion WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoCliente, idCliente)
+		db.Exec(`DELETE FROM refresh_token WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoCliente, idCliente)
+		db.Exec(`DELETE FROM auditoria_login WHERE correo = $1`, correoCliente)
+		db.Exec(`DELETE FROM cliente WHERE id_cliente = $1`, idCliente)
+	})
+
+	sesion, err := authService.LoginCliente(&entidades.LoginClienteRequest{Correo: correoCliente, Contrasena: "clave-anterior"}, "")
+	if err != nil {
+		t.Fatalf("error al iniciar sesión: %v", err)
+	}
+
+	// Un correo desconocido no envía mensajes; la búsqueda es lo único que ocurre antes de responder
+	recuperacionService.SolicitarCliente("nadie" + correoCliente)
+	if len(enviador.Enviados()) != 0 {
+		t.Fatal("no se debe enviar correo a una dirección no registrada")
+	}
+
+	// El enlace se envía en segundo plano
+	recuperacionService.SolicitarCliente(correoCliente)
+	mensaje, ok := esperarCorreo(enviador, correoCliente)
+	if !ok {
+		t.Fatal("se esperaba un correo de recuperación")
+	}
+	coincidencia := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(mensaje.Cuerpo)
+	if coincidencia == nil {
+		t.Fatalf("el correo no contiene el enlace con el token: %q", mensaje.Cuerpo)
+	}
+	token, err := url.QueryUnescape(coincidencia[1])
+	if err != nil {
+		t.Fatalf("token mal codificado en el enlace: %v", err)
+	}
+
+	req := &entidades.RestablecerContrasenaRequest{Token: token, NuevaContrasena: "clave-nueva-123"}
+	if err := recuperacionService.Restablecer(req); err != nil {
+		t.Fatalf("error al restablecer la contraseña: %v", err)
+	}
+	if err := recuperacionService.Restablecer(req); !errors.Is(err, servicios.ErrTokenRecuperacionInvalido) {
+		t.Fatalf("se esperaba ErrTokenRecuperacionInvalido al reutilizar el token, se obtuvo %v", err)
+	}
+
+	if _, err := authService.RefreshTokenCliente(sesion.RefreshToken); err == nil {
+		t.Error("restablecer la contraseña debe revocar las sesiones abiertas")
+	}
+	if _, err := authService.LoginCliente(&entidades.LoginClienteRequest{Correo: correoCliente, Contrasena: "clave-nueva-123"}, ""); err != nil {
+		t.Errorf("la nueva contraseña debe permitir iniciar sesión: %v", err)
+	}
+}
+
+// esperarCorreo espera a que el enviador de prueba reciba un mensaje para el destinatario
+func esperarCorreo(enviador *correo.EnviadorFake, para string) (correo.Mensaje, bool) {
+	limite := time.Now().Add(5 * time.Second)
+	for {
+		if mensaje, ok := enviador.Ultimo(para); ok || time.Now().After(limite) {
+			return mensaje, ok
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
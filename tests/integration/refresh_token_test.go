@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/middleware"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRotacionRefreshToken verifica que cada refresh token sirva una sola vez y que reutilizarlo
+// revoque toda la familia, incluido el token que lo reemplazó
+func TestRotacionRefreshToken(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	authService := servicios.NewAuthService(
+		db,
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewClienteRepository(db),
+		repositorios.NewRefreshTokenRepository(db),
+		repositorios.NewTokenRevocadoRepository(db),
+		nuevoProteccionLogin(db, cfg),
+		nuevoDobleFactor(db, cfg),
+		cfg,
+	)
+
+	hash, err := utils.HashPassword("clave-prueba")
+	if err != nil {
+		t.Fatalf("error al generar el hash: %v", err)
+	}
+	correo := fmt.Sprintf("refresh%d@test.com", time.Now().UnixNano()%1e9)
+	idCliente := insertarPrueba(t, db, `INSERT INTO cliente (tipo_documento, numero_documento, nombres, apellidos, correo, contrasena)
+		VALUES ('DNI', '44444444', 'Sesion', 'Prueba', $1, $2) RETURNING id_cliente`, correo, hash)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM refresh_token WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoCliente, idCliente)
+		db.Exec(`DELETE FROM cliente WHERE id_cliente = $1`, idCliente)
+	})
+
+	sesion, err := authService.LoginCliente(&entidades.LoginClienteRequest{Correo: correo, Contrasena: "clave-prueba"}, "")
+	if err != nil {
+		t.Fatalf("error al iniciar sesión: %v", err)
+	}
+
+	rotada, err := authService.RefreshTokenCliente(sesion.RefreshToken)
+	if err != nil {
+		t.Fatalf("error al renovar la sesión: %v", err)
+	}
+	if rotada.RefreshToken == sesion.RefreshToken {
+		t.Fatal("la renovación debe emitir un refresh token distinto")
+	}
+
+	if _, err := authService.RefreshTokenCliente(sesion.RefreshToken); !errors.Is(err, servicios.ErrRefreshTokenReutilizado) {
+		t.Fatalf("se esperaba ErrRefreshTokenReutilizado al reutilizar el token, se obtuvo %v", err)
+	}
+	if _, err := authService.RefreshTokenCliente(rotada.RefreshToken); err == nil {
+		t.Error("la reutilización debe revocar también el token que reemplazó al reutilizado")
+	}
+}
+
+// TestLogoutRevocaTokens verifica que al cerrar sesión el refresh token deje de servir
+// y el token de acceso quede en la lista de revocados
+func TestLogoutRevocaTokens(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	refreshTokenRepo := repositorios.NewRefreshTokenRepository(db)
+	tokenRevocadoRepo := repositorios.NewTokenRevocadoRepository(db)
+	authService := servicios.NewAuthService(
+		db,
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewClienteRepository(db),
+		refreshTokenRepo,
+		tokenRevocadoRepo,
+		nuevoProteccionLogin(db, cfg),
+		nuevoDobleFactor(db, cfg),
+		cfg,
+	)
+
+	hash, err := utils.HashPassword("clave-prueba")
+	if err != nil {
+		t.Fatalf("error al generar el hash: %v", err)
+	}
+	correo := fmt.Sprintf("logout%d@test.com", time.Now().UnixNano()%1e9)
+	idCliente := insertarPrueba(t, db, `INSERT INTO cliente (tipo_documento, numero_documento, nombres, apellidos, correo, contrasena)
+		VALUES ('DNI', '55555555', 'Logout', 'Prueba', $1, $2) RETURNING id_cliente`, correo, hash)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM refresh_token WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoCliente, idCliente)
+		db.Exec(`DELETE FROM cliente WHERE id_cliente = $1`, idCliente)
+	})
+
+	sesion, err := authService.LoginCliente(&entidades.LoginClienteRequest{Correo: correo, Contrasena: "clave-prueba"}, "")
+	if err != nil {
+		t.Fatalf("error al iniciar sesión: %v", err)
+	}
+	claims, err := utils.ValidateToken(sesion.Token, cfg)
+	if err != nil {
+		t.Fatalf("error al validar el token de acceso: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM token_revocado WHERE jti = $1`, claims.ID) })
+
+	if err := authService.LogoutCliente(idCliente, claims.ID, claims.ExpiresAt.Time, sesion.RefreshToken); err != nil {
+		t.Fatalf("error al cerrar sesión: %v", err)
+	}
+
+	if _, err := authService.RefreshTokenCliente(sesion.RefreshToken); err == nil {
+		t.Error("el refresh token no debe servir después del logout")
+	}
+	revocado, err := tokenRevocadoRepo.Exists(claims.ID)
+	if err != nil {
+		t.Fatalf("error al consultar tokens revocados: %v", err)
+	}
+	if !revocado {
+		t.Error("el token de acceso debe quedar revocado después del logout")
+	}
+}
+
+// TestDesactivarUsuarioRevocaAcceso verifica que al desactivar un usuario su token de acceso vigente
+// deje de ser aceptado, sin esperar a que expire
+func TestDesactivarUsuarioRevocaAcceso(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	tokenRevocadoRepo := repositorios.NewTokenRevocadoRepository(db)
+	usuarioService := servicios.NewUsuarioService(
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewRefreshTokenRepository(db),
+		tokenRevocadoRepo,
+		repositorios.NewRolRepository(db),
+	)
+
+	documento := fmt.Sprintf("D%d", time.Now().UnixNano()%1e9)
+	idUsuario := insertarPrueba(t, db, `INSERT INTO usuario (nombres, apellidos, rol, tipo_de_documento, numero_documento)
+		VALUES ('Desactivado', 'Prueba', 'VENDEDOR', 'DNI', $1) RETURNING id_usuario`, documento)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM sujeto_revocado WHERE tipo_sujeto = $1 AND id_sujeto = $2`, utils.SujetoUsuario, idUsuario)
+		db.Exec(`DELETE FROM usuario WHERE id_usuario = $1`, idUsuario)
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", middleware.AuthMiddleware(cfg, tokenRevocadoRepo), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	acceder := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	token := tokenPrueba(t, cfg, idUsuario, "VENDEDOR")
+	if codigo := acceder(token); codigo != http.StatusOK {
+		t.Fatalf("se esperaba aceptar el token del usuario activo, se obtuvo %d", codigo)
+	}
+
+	usuario, err := usuarioService.GetByID(idUsuario)
+	if err != nil {
+		t.Fatalf("error al obtener el usuario: %v", err)
+	}
+	usuario.Estado = false
+	if err := usuarioService.Update(idUsuario, usuario); err != nil {
+		t.Fatalf("error al desactivar el usuario: %v", err)
+	}
+
+	if codigo := acceder(token); codigo != http.StatusUnauthorized {
+		t.Errorf("se esperaba rechazar el token del usuario desactivado, se obtuvo %d", codigo)
+	}
+}
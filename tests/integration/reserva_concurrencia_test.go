@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// abrirBaseDatos conecta con la base de datos de pruebas; omite el test si no está configurada
+func abrirBaseDatos(t *testing.T) *sql.DB {
+	t.Helper()
+
+	if os.Getenv("DB_HOST") == "" || testing.Short() {
+		t.Skip("DB_HOST no configurado: se omiten los tests de integración con base de datos")
+	}
+
+	cfg := config.LoadConfig()
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
+	)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("error al abrir la base de datos: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("error al conectar con la base de datos: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// datosReserva contiene los registros mínimos para reservar en un tour programado
+type datosReserva struct {
+	idUsuario    int
+	idCliente    int
+	idCanal      int
+	idTipoPasaje int
+	idTour       int
+}
+
+// crearDatosReserva inserta un tour programado con el cupo indicado y sus dependencias
+func crearDatosReserva(t *testing.T, db *sql.DB, cupo int) datosReserva {
+	t.Helper()
+
+	// insertar ejecuta un INSERT ... RETURNING y devuelve el ID generado
+	insertar := func(query string, args ...interface{}) int {
+		var id int
+		if err := db.QueryRow(query, args...).Scan(&id); err != nil {
+			t.Fatalf("error al preparar datos: %v", err)
+		}
+		return id
+	}
+
+	documento := fmt.Sprintf("T%d", time.Now().UnixNano()%1e12)
+	idChofer := insertar(`INSERT INTO usuario (nombres, apellidos, rol, tipo_de_documento, numero_documento)
+		VALUES ('Chofer', 'Prueba', 'CHOFER', 'DNI', $1) RETURNING id_usuario`, documento)
+	idEmbarcacion := insertar(`INSERT INTO embarcacion (nombre, capacidad, id_usuario)
+		VALUES ('Prueba', $1, $2) RETURNING id_embarcacion`, cupo, idChofer)
+	idTipoTour := insertar(`INSERT INTO tipo_tour (nombre, duracion_minutos, precio_base, cantidad_pasajeros)
+		VALUES ('Tour prueba', 60, 10, $1) RETURNING id_tipo_tour`, cupo)
+	idHorario := insertar(`INSERT INTO horario_tour (id_tipo_tour, hora_inicio, hora_fin)
+		VALUES ($1, '09:00', '10:00') RETURNING id_horario`, idTipoTour)
+
+	d := datosReserva{
+		idUsuario: idChofer,
+		idTour: insertar(`INSERT INTO tour_programado (id_tipo_tour, id_embarcacion, id_horario, fecha, cupo_maximo, cupo_disponible)
+			VALUES ($1, $2, $3, CURRENT_DATE + 1, $4, $4) RETURNING id_tour_programado`, idTipoTour, idEmbarcacion, idHorario, cupo),
+		idCliente: insertar(`INSERT INTO cliente (tipo_documento, numero_documento, nombres, apellidos)
+			VALUES ('DNI', '00000000', 'Cliente', 'Prueba') RETURNING id_cliente`),
+		idCanal:      insertar(`INSERT INTO canal_venta (nombre) VALUES ('PRUEBA') RETURNING id_canal`),
+		idTipoPasaje: insertar(`INSERT INTO tipo_pasaje (nombre, costo) VALUES ('Adulto prueba', 10) RETURNING id_tipo_pasaje`),
+	}
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM pasajes_cantidad WHERE id_reserva IN (SELECT id_reserva FROM reserva WHERE id_tour_programado = $1)`, d.idTour)
+		db.Exec(`DELETE FROM reserva WHERE id_tour_programado = $1`, d.idTour)
+		db.Exec(`DELETE FROM tour_programado WHERE id_tour_programado = $1`, d.idTour)
+		db.Exec(`DELETE FROM horario_tour WHERE id_horario = $1`, idHorario)
+		db.Exec(`DELETE FROM tipo_tour WHERE id_tipo_tour = $1`, idTipoTour)
+		db.Exec(`DELETE FROM embarcacion WHERE id_embarcacion = $1`, idEmbarcacion)
+		db.Exec(`DELETE FROM usuario WHERE id_usuario = $1`, idChofer)
+		db.Exec(`DELETE FROM cliente WHERE id_cliente = $1`, d.idCliente)
+		db.Exec(`DELETE FROM canal_venta WHERE id_canal = $1`, d.idCanal)
+		db.Exec(`DELETE FROM tipo_pasaje WHERE id_tipo_pasaje = $1`, d.idTipoPasaje)
+	})
+
+	return d
+}
+
+// nuevoReservaService arma el servicio de reservas con repositorios reales
+func nuevoReservaService(db *sql.DB) *servicios.ReservaService {
+	tourProgramadoRepo := repositorios.NewTourProgramadoRepository(db)
+	tarifaRepo := repositorios.NewTarifaTourRepository(db)
+
+	return servicios.NewReservaService(
+		db,
+		repositorios.NewReservaRepository(db),
+		repositorios.NewClienteRepository(db),
+		tourProgramadoRepo,
+		repositorios.NewCanalVentaRepository(db),
+		repositorios.NewTipoPasajeRepository(db),
+		repositorios.NewUsuarioRepository(db),
+		repositorios.NewPagoRepository(db),
+		repositorios.NewComprobantePagoRepository(db),
+		repositorios.NewHistorialEstadoReservaRepository(db),
+		repositorios.NewPasajeroRepository(db),
+		servicios.NewCotizacionService(tourProgramadoRepo, tarifaRepo),
+		nuevoRolService(db, config.LoadConfig()),
+		15*time.Minute,
+		24*time.Hour,
+	)
+}
+
+// TestReservasConcurrentesNoSobrevendenCupo lanza reservas en paralelo sobre un tour
+// con poco cupo y verifica que solo se aceptan las que caben
+func TestReservasConcurrentesNoSobrevendenCupo(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	const cupo = 5
+	const intentos = 20
+
+	d := crearDatosReserva(t, db, cupo)
+	service := nuevoReservaService(db)
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	exitosas := 0
+
+	inicio := make(chan struct{})
+	for i := 0; i < intentos; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-inicio
+
+			_, err := service.Create(&entidades.NuevaReservaRequest{
+				IDCliente:        d.idCliente,
+				IDTourProgramado: d.idTour,
+				IDCanal:          d.idCanal,
+				CantidadPasajes: []entidades.PasajeCantidadRequest{
+					{IDTipoPasaje: d.idTipoPasaje, Cantidad: 1},
+				},
+			}, admin)
+			if err == nil {
+				mu.Lock()
+				exitosas++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(inicio)
+	wg.Wait()
+
+	if exitosas != cupo {
+		t.Errorf("se esperaban %d reservas exitosas, se obtuvieron %d", cupo, exitosas)
+	}
+
+	var cupoDisponible, reservados int
+	err := db.QueryRow(`SELECT cupo_disponible FROM tour_programado WHERE id_tour_programado = $1`, d.idTour).Scan(&cupoDisponible)
+	if err != nil {
+		t.Fatalf("error al leer el cupo: %v", err)
+	}
+	err = db.QueryRow(`
+		SELECT COALESCE(SUM(pc.cantidad), 0)
+		FROM pasajes_cantidad pc
+		INNER JOIN reserva r ON pc.id_reserva = r.id_reserva
+		WHERE r.id_tour_programado = $1`, d.idTour).Scan(&reservados)
+	if err != nil {
+		t.Fatalf("error al contar pasajeros: %v", err)
+	}
+
+	if cupoDisponible != 0 {
+		t.Errorf("se esperaba cupo disponible 0, se obtuvo %d", cupoDisponible)
+	}
+	if reservados != cupo {
+		t.Errorf("se esperaban %d pasajeros reservados, se obtuvieron %d", cupo, reservados)
+	}
+
+	// Cancelar una reserva libera su cupo y volver a activarla lo ocupa de nuevo
+	var idReserva int
+	err = db.QueryRow(`SELECT id_reserva FROM reserva WHERE id_tour_programado = $1 LIMIT 1`, d.idTour).Scan(&idReserva)
+	if err != nil {
+		t.Fatalf("error al obtener una reserva: %v", err)
+	}
+	if err := service.CambiarEstado(idReserva, &entidades.CambiarEstadoReservaRequest{Estado: "CANCELADA"}, admin); err != nil {
+		t.Fatalf("error al cancelar la reserva: %v", err)
+	}
+	db.QueryRow(`SELECT cupo_disponible FROM tour_programado WHERE id_tour_programado = $1`, d.idTour).Scan(&cupoDisponible)
+	if cupoDisponible != 1 {
+		t.Errorf("se esperaba cupo disponible 1 tras cancelar, se obtuvo %d", cupoDisponible)
+	}
+	if err := service.CambiarEstado(idReserva, &entidades.CambiarEstadoReservaRequest{Estado: "RESERVADO"}, admin); err != nil {
+		t.Fatalf("error al reactivar la reserva: %v", err)
+	}
+	db.QueryRow(`SELECT cupo_disponible FROM tour_programado WHERE id_tour_programado = $1`, d.idTour).Scan(&cupoDisponible)
+	if cupoDisponible != 0 {
+		t.Errorf("se esperaba cupo disponible 0 tras reactivar, se obtuvo %d", cupoDisponible)
+	}
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"strings"
+	"testing"
+)
+
+// TestListByEstadoValidaEstados verifica que el filtro por estado acepte todos los estados de reserva y solo esos
+func TestListByEstadoValidaEstados(t *testing.T) {
+	// El estado se valida antes de consultar la base de datos
+	if _, err := (&servicios.ReservaService{}).ListByEstado("ANULADA"); err == nil {
+		t.Error("se esperaba rechazar un estado inexistente")
+	}
+
+	db := abrirBaseDatos(t)
+	service := nuevoReservaService(db)
+
+	for _, estado := range entidades.EstadosReserva {
+		if _, err := service.ListByEstado(estado); err != nil {
+			t.Errorf("se esperaba listar las reservas en estado %s: %v", estado, err)
+		}
+	}
+}
+
+// TestActualizarReservaRespetaLoPagado verifica que una modificación no deje el total por debajo de lo pagado
+// ni marque como pagada una reserva cuyo nuevo total no está cubierto
+func TestActualizarReservaRespetaLoPagado(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 10)
+	service := nuevoReservaService(db)
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+
+	pasajes := func(cantidad int) []entidades.PasajeCantidadRequest {
+		return []entidades.PasajeCantidadRequest{{IDTipoPasaje: d.idTipoPasaje, Cantidad: cantidad}}
+	}
+
+	idReserva, err := service.Create(&entidades.NuevaReservaRequest{
+		IDCliente:        d.idCliente,
+		IDTourProgramado: d.idTour,
+		IDCanal:          d.idCanal,
+		CantidadPasajes:  pasajes(2),
+	}, admin)
+	if err != nil {
+		t.Fatalf("error al crear la reserva: %v", err)
+	}
+
+	// Pagar el total de la reserva
+	var idMetodoPago int
+	err = db.QueryRow(`INSERT INTO metodo_pago (nombre) VALUES ('PRUEBA') RETURNING id_metodo_pago`).Scan(&idMetodoPago)
+	if err != nil {
+		t.Fatalf("error al crear el método de pago: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM pago WHERE id_reserva = $1`, idReserva)
+		db.Exec(`DELETE FROM metodo_pago WHERE id_metodo_pago = $1`, idMetodoPago)
+	})
+	_, err = db.Exec(`INSERT INTO pago (id_reserva, id_metodo_pago, id_canal, monto)
+		SELECT id_reserva, $2, id_canal, total_pagar FROM reserva WHERE id_reserva = $1`, idReserva, idMetodoPago)
+	if err != nil {
+		t.Fatalf("error al registrar el pago: %v", err)
+	}
+
+	actualizar := func(cantidad int, estado string) error {
+		return service.Update(idReserva, &entidades.ActualizarReservaRequest{
+			IDCliente:        d.idCliente,
+			IDTourProgramado: d.idTour,
+			IDCanal:          d.idCanal,
+			Estado:           estado,
+			CantidadPasajes:  pasajes(cantidad),
+		}, admin)
+	}
+
+	// Reducir los pasajes dejaría lo pagado por encima del nuevo total
+	if err := actualizar(1, ""); err == nil || !strings.Contains(err.Error(), "menor que lo ya pagado") {
+		t.Errorf("se esperaba rechazar un total menor que lo pagado, se obtuvo %v", err)
+	}
+
+	// Aumentar los pasajes y marcarla como pagada dejaría un saldo sin cobrar
+	if err := actualizar(3, "PAGADA"); err == nil || !strings.Contains(err.Error(), "no cubre el nuevo total") {
+		t.Errorf("se esperaba rechazar marcar como pagada una reserva con saldo, se obtuvo %v", err)
+	}
+
+	// Con el total cubierto sí se puede marcar como pagada
+	if err := actualizar(2, "PAGADA"); err != nil {
+		t.Errorf("error al marcar como pagada la reserva cubierta: %v", err)
+	}
+}
+
+// TestListByEstadoPago verifica que el filtro por estado de pago, resuelto en la consulta,
+// clasifique igual que el detalle de cada reserva
+func TestListByEstadoPago(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	d := crearDatosReserva(t, db, 10)
+	service := nuevoReservaService(db)
+	admin := servicios.Actor{Rol: "ADMIN", ID: d.idUsuario}
+
+	idMetodoPago := insertarPrueba(t, db, `INSERT INTO metodo_pago (nombre) VALUES ('PRUEBA') RETURNING id_metodo_pago`)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM pago WHERE id_reserva IN (SELECT id_reserva FROM reserva WHERE id_tour_programado = $1)`, d.idTour)
+		db.Exec(`DELETE FROM metodo_pago WHERE id_metodo_pago = $1`, idMetodoPago)
+	})
+
+	// Una reserva por estado de pago, con el monto pagado como fracción del total
+	fracciones := map[string]float64{"PENDIENTE": 0, "PARCIAL": 0.5, "PAGADO": 1}
+	esperadas := map[string]int{}
+	for estadoPago, fraccion := range fracciones {
+		id, err := service.Create(&entidades.NuevaReservaRequest{
+			IDCliente:        d.idCliente,
+			IDTourProgramado: d.idTour,
+			IDCanal:          d.idCanal,
+			CantidadPasajes:  []entidades.PasajeCantidadRequest{{IDTipoPasaje: d.idTipoPasaje, Cantidad: 2}},
+		}, admin)
+		if err != nil {
+			t.Fatalf("error al crear la reserva: %v", err)
+		}
+		esperadas[estadoPago] = id
+
+		if fraccion > 0 {
+			_, err = db.Exec(`INSERT INTO pago (id_reserva, id_metodo_pago, id_canal, monto)
+				SELECT id_reserva, $2, id_canal, total_pagar * $3 FROM reserva WHERE id_reserva = $1`, id, idMetodoPago, fraccion)
+			if err != nil {
+				t.Fatalf("error al registrar el pago: %v", err)
+			}
+		}
+	}
+
+	for estadoPago, id := range esperadas {
+		reservas, err := service.ListByEstadoPago(estadoPago)
+		if err != nil {
+			t.Fatalf("error al listar por estado de pago %s: %v", estadoPago, err)
+		}
+
+		encontradas := map[int]*entidades.Reserva{}
+		for _, reserva := range reservas {
+			if reserva.EstadoPago != estadoPago {
+				t.Errorf("el filtro %s devolvió la reserva %d en estado %s", estadoPago, reserva.ID, reserva.EstadoPago)
+			}
+			encontradas[reserva.ID] = reserva
+		}
+		for otroEstado, otroID := range esperadas {
+			if _, ok := encontradas[otroID]; ok != (otroEstado == estadoPago) {
+				t.Errorf("el filtro %s no clasificó bien la reserva %s", estadoPago, otroEstado)
+			}
+		}
+
+		detalle, err := service.GetByID(id, admin)
+		if err != nil {
+			t.Fatalf("error al obtener la reserva: %v", err)
+		}
+		if listada := encontradas[id]; listada != nil && (listada.MontoPagado != detalle.MontoPagado || listada.SaldoPendiente != detalle.SaldoPendiente) {
+			t.Errorf("el listado y el detalle difieren para %s: %+v / %+v", estadoPago, listada, detalle)
+		}
+	}
+}
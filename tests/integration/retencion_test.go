@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"testing"
+)
+
+// canalWebPrueba obtiene el canal WEB, creándolo para la prueba si la base aún no lo tiene
+func canalWebPrueba(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var idCanal int
+	err := db.QueryRow(`SELECT id_canal FROM canal_venta WHERE nombre = 'WEB' ORDER BY id_canal LIMIT 1`).Scan(&idCanal)
+	if err == nil {
+		return idCanal
+	}
+	if err != sql.ErrNoRows {
+		t.Fatalf("error al buscar el canal WEB: %v", err)
+	}
+
+	idCanal = insertarPrueba(t, db, `INSERT INTO canal_venta (nombre) VALUES ('WEB') RETURNING id_canal`)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM canal_venta WHERE id_canal = $1`, idCanal)
+	})
+	return idCanal
+}
+
+// TestRetencionReservaCliente verifica que la reserva de un cliente solo retenga el cupo en el canal WEB
+// hasta confirmarse el pago, y que el barrido cancele las retenciones vencidas y libere su cupo
+func TestRetencionReservaCliente(t *testing.T) {
+	db := abrirBaseDatos(t)
+
+	idWeb := canalWebPrueba(t, db)
+	d := crearDatosReserva(t, db, 5)
+	service := nuevoReservaService(db)
+	cliente := servicios.Actor{Rol: "CLIENTE", ID: d.idCliente}
+
+	nueva := func(idVendedor *int) *entidades.NuevaReservaRequest {
+		return &entidades.NuevaReservaRequest{
+			IDCliente:        d.idCliente,
+			IDTourProgramado: d.idTour,
+			IDCanal:          d.idCanal, // Un canal distinto de WEB no evita la retención
+			IDVendedor:       idVendedor,
+			CantidadPasajes:  []entidades.PasajeCantidadRequest{{IDTipoPasaje: d.idTipoPasaje, Cantidad: 1}},
+		}
+	}
+
+	// El cliente no puede asignarse un vendedor para saltarse la retención
+	if _, err := service.Create(nueva(&d.idUsuario), cliente); !errors.Is(err, servicios.ErrAccesoDenegado) {
+		t.Errorf("se esperaba acceso denegado al indicar un vendedor, se obtuvo %v", err)
+	}
+
+	idPagada, err := service.Create(nueva(nil), cliente)
+	if err != nil {
+		t.Fatalf("error al crear la reserva del cliente: %v", err)
+	}
+	idVencida, err := service.Create(nueva(nil), cliente)
+	if err != nil {
+		t.Fatalf("error al crear la reserva del cliente: %v", err)
+	}
+
+	reserva, err := service.GetByID(idPagada, cliente)
+	if err != nil {
+		t.Fatalf("error al obtener la reserva: %v", err)
+	}
+	if reserva.Estado != "PENDIENTE_PAGO" || reserva.ExpiraEn == nil || reserva.IDCanal != idWeb || reserva.IDVendedor != nil {
+		t.Fatalf("se esperaba una retención web sin vendedor, se obtuvo estado %s, canal %d, vencimiento %v y vendedor %v",
+			reserva.Estado, reserva.IDCanal, reserva.ExpiraEn, reserva.IDVendedor)
+	}
+
+	var cupoDisponible int
+	cupo := func() int {
+		if err := db.QueryRow(`SELECT cupo_disponible FROM tour_programado WHERE id_tour_programado = $1`, d.idTour).Scan(&cupoDisponible); err != nil {
+			t.Fatalf("error al leer el cupo: %v", err)
+		}
+		return cupoDisponible
+	}
+	if c := cupo(); c != 3 {
+		t.Errorf("se esperaba cupo disponible 3 con dos retenciones, se obtuvo %d", c)
+	}
+
+	// Sin pago la retención no se puede confirmar
+	if err := service.Confirmar(idPagada, cliente); err == nil {
+		t.Error("se esperaba rechazar la confirmación de una reserva sin pagar")
+	}
+
+	idMetodoPago := insertarPrueba(t, db, `INSERT INTO metodo_pago (nombre) VALUES ('PRUEBA') RETURNING id_metodo_pago`)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM pago WHERE id_reserva IN ($1, $2)`, idPagada, idVencida)
+		db.Exec(`DELETE FROM metodo_pago WHERE id_metodo_pago = $1`, idMetodoPago)
+	})
+	_, err = db.Exec(`INSERT INTO pago (id_reserva, id_metodo_pago, id_canal, monto)
+		SELECT id_reserva, $2, id_canal, total_pagar FROM reserva WHERE id_reserva = $1`, idPagada, idMetodoPago)
+	if err != nil {
+		t.Fatalf("error al registrar el pago: %v", err)
+	}
+
+	if err := service.Confirmar(idPagada, cliente); err != nil {
+		t.Fatalf("error al confirmar la reserva pagada: %v", err)
+	}
+	reserva, _ = service.GetByID(idPagada, cliente)
+	if reserva.Estado != "PAGADA" || reserva.ExpiraEn != nil {
+		t.Errorf("se esperaba la reserva PAGADA sin vencimiento, se obtuvo %s con vencimiento %v", reserva.Estado, reserva.ExpiraEn)
+	}
+
+	// Vencer la otra retención y dejar que el barrido la cancele
+	if _, err := db.Exec(`UPDATE reserva SET expira_en = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE id_reserva = $1`, idVencida); err != nil {
+		t.Fatalf("error al vencer la retención: %v", err)
+	}
+	if err := service.Confirmar(idVencida, cliente); err == nil {
+		t.Error("se esperaba rechazar la confirmación de una retención vencida")
+	}
+
+	expiradas, err := service.ExpirarRetenciones()
+	if err != nil {
+		t.Fatalf("error al expirar las retenciones: %v", err)
+	}
+	if expiradas < 1 {
+		t.Errorf("se esperaba expirar al menos una retención, se obtuvieron %d", expiradas)
+	}
+	reserva, _ = service.GetByID(idVencida, cliente)
+	if reserva.Estado != "CANCELADA" {
+		t.Errorf("se esperaba la retención vencida CANCELADA, se obtuvo %s", reserva.Estado)
+	}
+	if c := cupo(); c != 4 {
+		t.Errorf("se esperaba cupo disponible 4 tras expirar la retención, se obtuvo %d", c)
+	}
+
+	// La reserva confirmada no se ve afectada por el barrido
+	reserva, _ = service.GetByID(idPagada, cliente)
+	if reserva.Estado != "PAGADA" {
+		t.Errorf("el barrido no debería cambiar una reserva pagada, se obtuvo %s", reserva.Estado)
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"testing"
+)
+
+// nuevoRolService arma el servicio de roles con repositorios reales
+func nuevoRolService(db *sql.DB, cfg *config.Config) *servicios.RolService {
+	return servicios.NewRolService(
+		db,
+		repositorios.NewRolRepository(db),
+		repositorios.NewPermisoRepository(db),
+		cfg.PermisosVigencia,
+	)
+}
+
+// TestRolesPersonalizados verifica que un rol creado por el administrador otorgue solo sus permisos,
+// que los cambios se vean sin reiniciar y que el rol ADMIN no se pueda modificar
+func TestRolesPersonalizados(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	roles := nuevoRolService(db, cfg)
+
+	const nombre = "SUPERVISOR_CAJA"
+	db.Exec(`DELETE FROM rol WHERE nombre = $1`, nombre)
+	t.Cleanup(func() { db.Exec(`DELETE FROM rol WHERE nombre = $1`, nombre) })
+
+	tienePermiso := func(rol, permiso string) bool {
+		t.Helper()
+		tiene, err := roles.TienePermiso(rol, permiso)
+		if err != nil {
+			t.Fatalf("error al resolver permisos: %v", err)
+		}
+		return tiene
+	}
+
+	// Cargar la copia en memoria antes de crear el rol
+	if tienePermiso(nombre, entidades.PermisoPagosAnular) {
+		t.Fatal("un rol inexistente no debería tener permisos")
+	}
+
+	err := roles.Create(&entidades.NuevoRolRequest{
+		Nombre:   nombre,
+		Permisos: []string{entidades.PermisoPagosVer, entidades.PermisoPagosAnular},
+	})
+	if err != nil {
+		t.Fatalf("error al crear el rol: %v", err)
+	}
+	if !tienePermiso(nombre, entidades.PermisoPagosAnular) {
+		t.Error("el rol creado debería poder anular pagos sin esperar la recarga")
+	}
+	if tienePermiso(nombre, entidades.PermisoReservasEliminar) {
+		t.Error("el rol creado no debería poder eliminar reservas")
+	}
+
+	err = roles.Update(nombre, &entidades.ActualizarRolRequest{Permisos: []string{entidades.PermisoPagosVer}})
+	if err != nil {
+		t.Fatalf("error al actualizar el rol: %v", err)
+	}
+	if tienePermiso(nombre, entidades.PermisoPagosAnular) {
+		t.Error("el permiso retirado no debería seguir vigente")
+	}
+
+	err = roles.Create(&entidades.NuevoRolRequest{Nombre: "OTRO_ROL", Permisos: []string{"pagos:inventado"}})
+	if err == nil {
+		db.Exec(`DELETE FROM rol WHERE nombre = 'OTRO_ROL'`)
+		t.Error("se esperaba un error por permiso desconocido")
+	}
+
+	err = roles.Update(entidades.RolAdministrador, &entidades.ActualizarRolRequest{Permisos: []string{entidades.PermisoPagosVer}})
+	if err == nil {
+		t.Error("el rol ADMIN no debería poder modificarse")
+	}
+	if err := roles.Delete("VENDEDOR"); err == nil {
+		t.Error("los roles de sistema no deberían poder eliminarse")
+	}
+	if err := roles.Delete(nombre); err != nil {
+		t.Errorf("error al eliminar el rol: %v", err)
+	}
+}
+
+// TestRolPersonalizadoEnReservas verifica de punta a punta que un rol creado por el administrador
+// abra las rutas y las transiciones de reserva que sus permisos indican, y solo esas
+func TestRolPersonalizadoEnReservas(t *testing.T) {
+	db := abrirBaseDatos(t)
+	cfg := config.LoadConfig()
+	roles := nuevoRolService(db, cfg)
+
+	// Los roles se crean antes de armar las rutas para que todas las copias en memoria los incluyan
+	const taquilla, supervisor = "TAQUILLA_PRUEBA", "SUPERVISOR_PRUEBA"
+	for nombre, permisos := range map[string][]string{
+		taquilla:   {entidades.PermisoReservasVer, entidades.PermisoReservasCrear, entidades.PermisoReservasEditar},
+		supervisor: {entidades.PermisoReservasVer, entidades.PermisoReservasEditar, entidades.PermisoReservasReabrir},
+	} {
+		db.Exec(`DELETE FROM rol WHERE nombre = $1`, nombre)
+		t.Cleanup(func() { db.Exec(`DELETE FROM rol WHERE nombre = $1`, nombre) })
+		if err := roles.Create(&entidades.NuevoRolRequest{Nombre: nombre, Permisos: permisos}); err != nil {
+			t.Fatalf("error al crear el rol %s: %v", nombre, err)
+		}
+	}
+
+	router := nuevoRouterAutorizacion(db, cfg)
+	d := crearDatosReserva(t, db, 5)
+	idReserva, _ := crearReservaConPasajero(t, db, d)
+
+	tokenTaquilla := tokenPrueba(t, cfg, d.idUsuario, taquilla)
+	tokenSupervisor := tokenPrueba(t, cfg, d.idUsuario, supervisor)
+	reserva := fmt.Sprintf("/api/v1/vendedor/reservas/%d", idReserva)
+
+	casos := []casoAcceso{
+		{"ver reserva", "GET", reserva, tokenTaquilla, "", http.StatusOK},
+		{"eliminar reserva sin permiso", "DELETE", fmt.Sprintf("/api/v1/admin/reservas/%d", idReserva), tokenTaquilla, "", http.StatusForbidden},
+		{"registrar reserva", "POST", "/api/v1/vendedor/reservas", tokenTaquilla,
+			fmt.Sprintf(`{"id_cliente": %d, "id_tour_programado": %d, "id_canal": %d, "cantidad_pasajes": [{"id_tipo_pasaje": %d, "cantidad": 1}]}`,
+				d.idCliente, d.idTour, d.idCanal, d.idTipoPasaje), http.StatusCreated},
+		{"registrar reserva sin permiso", "POST", "/api/v1/vendedor/reservas", tokenSupervisor,
+			fmt.Sprintf(`{"id_cliente": %d, "id_tour_programado": %d, "id_canal": %d, "cantidad_pasajes": [{"id_tipo_pasaje": %d, "cantidad": 1}]}`,
+				d.idCliente, d.idTour, d.idCanal, d.idTipoPasaje), http.StatusForbidden},
+		{"confirmar", "POST", reserva + "/estado", tokenTaquilla, `{"estado": "CONFIRMADA"}`, http.StatusOK},
+		{"cancelar", "POST", reserva + "/estado", tokenTaquilla, `{"estado": "CANCELADA"}`, http.StatusOK},
+		{"reactivar sin permiso", "POST", reserva + "/estado", tokenTaquilla, `{"estado": "RESERVADO"}`, http.StatusBadRequest},
+		{"reactivar con permiso", "POST", reserva + "/estado", tokenSupervisor, `{"estado": "RESERVADO"}`, http.StatusOK},
+	}
+
+	probarAccesos(t, router, casos)
+
+	var estado, rol string
+	err := db.QueryRow(`SELECT r.estado, h.rol FROM reserva r
+		INNER JOIN historial_estado_reserva h ON h.id_reserva = r.id_reserva
+		WHERE r.id_reserva = $1
+		ORDER BY h.id_historial DESC LIMIT 1`, idReserva).Scan(&estado, &rol)
+	if err != nil {
+		t.Fatalf("error al leer el historial: %v", err)
+	}
+	if estado != "RESERVADO" || rol != supervisor {
+		t.Errorf("se esperaba la reserva RESERVADO reactivada por %s, se obtuvo %s por %s", supervisor, estado, rol)
+	}
+
+	// Sin permiso para asignar vendedor, quien registra la reserva queda como su vendedor
+	var idVendedor sql.NullInt64
+	err = db.QueryRow(`SELECT id_vendedor FROM reserva WHERE id_tour_programado = $1 AND id_reserva <> $2`, d.idTour, idReserva).Scan(&idVendedor)
+	if err != nil {
+		t.Fatalf("error al leer la reserva registrada: %v", err)
+	}
+	if !idVendedor.Valid || int(idVendedor.Int64) != d.idUsuario {
+		t.Errorf("se esperaba el vendedor %d, se obtuvo %v", d.idUsuario, idVendedor)
+	}
+}
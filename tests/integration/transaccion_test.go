@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/repositorios"
+	"sistema-tours/internal/servicios"
+	"testing"
+	"time"
+)
+
+// TestWithTx verifica que WithTx confirma los cambios solo cuando la función termina sin error
+func TestWithTx(t *testing.T) {
+	db := abrirBaseDatos(t)
+	canalRepo := repositorios.NewCanalVentaRepository(db)
+
+	// crearCanal inserta un canal dentro de la transacción usando el repositorio enlazado a ella
+	crearCanal := func(tx *sql.Tx, nombre string) error {
+		_, err := canalRepo.WithTx(tx).Create(&entidades.NuevoCanalVentaRequest{Nombre: nombre})
+		return err
+	}
+
+	// existeCanal consulta fuera de la transacción si el canal quedó guardado
+	existeCanal := func(nombre string) bool {
+		canal, err := canalRepo.GetByNombre(nombre)
+		if err == nil {
+			t.Cleanup(func() { canalRepo.Delete(canal.ID) })
+		}
+		return err == nil
+	}
+
+	sufijo := time.Now().UnixNano() % 1e6
+
+	t.Run("commit", func(t *testing.T) {
+		nombre := fmt.Sprintf("TX-OK-%d", sufijo)
+		err := servicios.WithTx(context.Background(), db, func(tx *sql.Tx) error {
+			return crearCanal(tx, nombre)
+		})
+		if err != nil {
+			t.Fatalf("error inesperado: %v", err)
+		}
+		if !existeCanal(nombre) {
+			t.Error("el canal debería haberse guardado")
+		}
+	})
+
+	t.Run("rollback por error", func(t *testing.T) {
+		nombre := fmt.Sprintf("TX-ERR-%d", sufijo)
+		errEsperado := errors.New("fallo de prueba")
+		err := servicios.WithTx(context.Background(), db, func(tx *sql.Tx) error {
+			if err := crearCanal(tx, nombre); err != nil {
+				return err
+			}
+			return errEsperado
+		})
+		if !errors.Is(err, errEsperado) {
+			t.Fatalf("se esperaba el error de la función, se obtuvo %v", err)
+		}
+		if existeCanal(nombre) {
+			t.Error("el canal no debería haberse guardado")
+		}
+	})
+
+	t.Run("rollback por pánico", func(t *testing.T) {
+		nombre := fmt.Sprintf("TX-PANIC-%d", sufijo)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("se esperaba que el pánico se propague")
+				}
+			}()
+			servicios.WithTx(context.Background(), db, func(tx *sql.Tx) error {
+				if err := crearCanal(tx, nombre); err != nil {
+					return err
+				}
+				panic("pánico de prueba")
+			})
+		}()
+		if existeCanal(nombre) {
+			t.Error("el canal no debería haberse guardado")
+		}
+	})
+}
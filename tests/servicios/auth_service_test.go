@@ -1,2 +1,128 @@
 // Tests para autenticación
 package servicios
+
+import (
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func configPrueba() *config.Config {
+	return &config.Config{JWTSecret: "secreto-acceso", JWTRefreshSecret: "secreto-refresh"}
+}
+
+func TestTipoSujetoToken(t *testing.T) {
+	cfg := configPrueba()
+
+	tokenUsuario, err := utils.GenerateJWT(&entidades.Usuario{ID: 5, Correo: "vendedor@test.com", Rol: "VENDEDOR"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token de usuario: %v", err)
+	}
+	tokenCliente, err := utils.GenerateClienteJWT(&entidades.Cliente{ID: 5, Correo: "cliente@test.com"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token de cliente: %v", err)
+	}
+
+	claimsUsuario, err := utils.ValidateToken(tokenUsuario, cfg)
+	if err != nil {
+		t.Fatalf("error al validar token de usuario: %v", err)
+	}
+	claimsCliente, err := utils.ValidateToken(tokenCliente, cfg)
+	if err != nil {
+		t.Fatalf("error al validar token de cliente: %v", err)
+	}
+
+	if claimsUsuario.TipoSujeto != utils.SujetoUsuario || claimsCliente.TipoSujeto != utils.SujetoCliente {
+		t.Errorf("tipos de sujeto inesperados: %q y %q", claimsUsuario.TipoSujeto, claimsCliente.TipoSujeto)
+	}
+	if claimsCliente.Role != "CLIENTE" {
+		t.Errorf("se esperaba rol CLIENTE, se obtuvo %q", claimsCliente.Role)
+	}
+	if claimsUsuario.Subject == claimsCliente.Subject {
+		t.Errorf("un usuario y un cliente con el mismo ID no deben compartir subject (%q)", claimsUsuario.Subject)
+	}
+}
+
+func TestTokenSujetoInvalido(t *testing.T) {
+	cfg := configPrueba()
+
+	casos := map[string]utils.TokenClaims{
+		"sin tipo de sujeto":      {UserID: 1, Role: "ADMIN"},
+		"usuario con rol cliente": {UserID: 1, Role: "CLIENTE", TipoSujeto: utils.SujetoUsuario},
+		"cliente con rol admin":   {UserID: 1, Role: "ADMIN", TipoSujeto: utils.SujetoCliente},
+	}
+	for nombre, claims := range casos {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+		if err != nil {
+			t.Fatalf("%s: error al firmar: %v", nombre, err)
+		}
+		if _, err := utils.ValidateToken(token, cfg); err == nil {
+			t.Errorf("%s: se esperaba un token inválido", nombre)
+		}
+	}
+}
+
+func TestRefreshTokenCruzado(t *testing.T) {
+	cfg := configPrueba()
+	authService := servicios.NewAuthService(nil, nil, nil, nil, nil, nil, nil, cfg)
+
+	refreshCliente, err := utils.GenerateClienteRefreshToken(&entidades.Cliente{ID: 3}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar refresh token de cliente: %v", err)
+	}
+	if _, err := authService.RefreshToken(refreshCliente); err == nil {
+		t.Error("un refresh token de cliente no debe renovar una sesión de usuario")
+	}
+
+	refreshUsuario, err := utils.GenerateRefreshToken(&entidades.Usuario{ID: 3, Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar refresh token de usuario: %v", err)
+	}
+	if _, err := authService.RefreshTokenCliente(refreshUsuario); err == nil {
+		t.Error("un refresh token de usuario no debe renovar una sesión de cliente")
+	}
+	if err := authService.LogoutCliente(3, "", time.Time{}, refreshUsuario); err == nil {
+		t.Error("un cliente no debe cerrar sesión con un refresh token de usuario")
+	}
+}
+
+func TestJTIYVigenciaTokens(t *testing.T) {
+	cfg := configPrueba()
+	cfg.JWTExpiration = time.Minute * 5
+	usuario := &entidades.Usuario{ID: 9, Correo: "admin@test.com", Rol: "ADMIN"}
+
+	primero, err := utils.GenerateJWT(usuario, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+	segundo, err := utils.GenerateJWT(usuario, cfg)
+	if err != nil {
+		t.Fatalf("error al generar token: %v", err)
+	}
+
+	claimsPrimero, err := utils.ValidateToken(primero, cfg)
+	if err != nil {
+		t.Fatalf("error al validar token: %v", err)
+	}
+	claimsSegundo, err := utils.ValidateToken(segundo, cfg)
+	if err != nil {
+		t.Fatalf("error al validar token: %v", err)
+	}
+
+	if claimsPrimero.ID == "" || claimsPrimero.ID == claimsSegundo.ID {
+		t.Errorf("cada token debe tener un jti propio, se obtuvo %q y %q", claimsPrimero.ID, claimsSegundo.ID)
+	}
+	vigencia := claimsPrimero.ExpiresAt.Sub(claimsPrimero.IssuedAt.Time)
+	if vigencia != cfg.JWTExpiration {
+		t.Errorf("se esperaba una vigencia de %s, se obtuvo %s", cfg.JWTExpiration, vigencia)
+	}
+	if utils.HashToken(primero) == utils.HashToken(segundo) || len(utils.HashToken(primero)) != 64 {
+		t.Error("el hash de cada token debe ser distinto y de 64 caracteres hexadecimales")
+	}
+}
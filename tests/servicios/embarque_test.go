@@ -0,0 +1,38 @@
+// Tests para los códigos QR de embarque
+package servicios
+
+import (
+	"sistema-tours/internal/utils"
+	"strings"
+	"testing"
+)
+
+func TestCodigoEmbarque(t *testing.T) {
+	const secret = "secreto-de-prueba"
+	codigo := utils.GenerarCodigoEmbarque(10, 7, secret)
+
+	idReserva, idTour, err := utils.ValidarCodigoEmbarque(codigo, secret)
+	if err != nil {
+		t.Fatalf("error al validar un código válido: %v", err)
+	}
+	if idReserva != 10 || idTour != 7 {
+		t.Errorf("se esperaba reserva 10 y tour 7, se obtuvo reserva %d y tour %d", idReserva, idTour)
+	}
+
+	casos := map[string]string{
+		"otra llave":       codigo,
+		"reserva alterada": strings.Replace(codigo, "EMB.10.", "EMB.11.", 1),
+		"tour alterado":    strings.Replace(codigo, ".7.", ".8.", 1),
+		"sin firma":        "EMB.10.7",
+		"otro prefijo":     strings.Replace(codigo, "EMB.", "TKT.", 1),
+	}
+	for nombre, c := range casos {
+		llave := secret
+		if nombre == "otra llave" {
+			llave = "otra-llave"
+		}
+		if _, _, err := utils.ValidarCodigoEmbarque(c, llave); err == nil {
+			t.Errorf("%s: se esperaba rechazar el código %q", nombre, c)
+		}
+	}
+}
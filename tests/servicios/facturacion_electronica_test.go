@@ -0,0 +1,199 @@
+// Tests para facturación electrónica
+package servicios
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"regexp"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/sunat"
+	"strings"
+	"testing"
+	"time"
+)
+
+var emisorPrueba = sunat.Emisor{RUC: "20000000001", RazonSocial: "SISTEMA TOURS S.A.C."}
+
+func facturaPrueba() *entidades.ComprobantePago {
+	return &entidades.ComprobantePago{
+		ID:                   1,
+		Tipo:                 "FACTURA",
+		Serie:                "F001",
+		Correlativo:          1,
+		NumeroComprobante:    "F001-00000001",
+		FechaEmision:         time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Subtotal:             100,
+		IGV:                  18,
+		Total:                118,
+		NombreCliente:        "Turismo & Viajes",
+		ApellidosCliente:     "S.A.C.",
+		TipoDocumentoCliente: "RUC",
+		DocumentoCliente:     "20123456789",
+		NombreTour:           "Islas Ballestas",
+		FechaTour:            time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFirmaComprobante(t *testing.T) {
+	firmador, err := sunat.FirmadorDePrueba(emisorPrueba.RUC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := sunat.NuevoComprobante(emisorPrueba, facturaPrueba())
+	if doc.Nombre != "20000000001-01-F001-00000001" {
+		t.Fatalf("nombre de archivo inesperado: %s", doc.Nombre)
+	}
+
+	firmado, hash, err := firmador.Firmar(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// El XML debe estar bien formado
+	if err := xml.Unmarshal(firmado, new(struct{})); err != nil {
+		t.Fatalf("XML mal formado: %v", err)
+	}
+
+	contenido := string(firmado)
+	for _, esperado := range []string{
+		`<cbc:InvoiceTypeCode listID="0101">01</cbc:InvoiceTypeCode>`,
+		`<cbc:ID schemeID="6">20123456789</cbc:ID>`,
+		`<cbc:PayableAmount currencyID="PEN">118.00</cbc:PayableAmount>`,
+		`Turismo &amp; Viajes`,
+	} {
+		if !strings.Contains(contenido, esperado) {
+			t.Errorf("el XML no contiene %s", esperado)
+		}
+	}
+
+	// El valor resumen corresponde al documento sin la firma (transformación enveloped)
+	cuerpo := strings.SplitN(contenido, "\n", 2)[1]
+	sinFirma := regexp.MustCompile(`(?s)<ds:Signature .*?</ds:Signature>`).ReplaceAllString(cuerpo, "")
+	resumen := sha256.Sum256([]byte(sinFirma))
+	if base64.StdEncoding.EncodeToString(resumen[:]) != hash {
+		t.Fatal("el valor resumen no corresponde al documento")
+	}
+
+	// La firma corresponde a SignedInfo canonicalizado con los espacios de nombres del documento
+	signedInfo := regexp.MustCompile(`(?s)<ds:SignedInfo>.*?</ds:SignedInfo>`).FindString(cuerpo)
+	declaraciones := regexp.MustCompile(`^<Invoice( [^>]*)>`).FindStringSubmatch(cuerpo)[1]
+	signedInfo = strings.Replace(signedInfo, "<ds:SignedInfo>", "<ds:SignedInfo"+declaraciones+">", 1)
+
+	valorFirma := regexp.MustCompile(`<ds:SignatureValue>(.*?)</ds:SignatureValue>`).FindStringSubmatch(cuerpo)[1]
+	certificadoB64 := regexp.MustCompile(`<ds:X509Certificate>(.*?)</ds:X509Certificate>`).FindStringSubmatch(cuerpo)[1]
+
+	firma, _ := base64.StdEncoding.DecodeString(valorFirma)
+	der, _ := base64.StdEncoding.DecodeString(certificadoB64)
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumenSignedInfo := sha256.Sum256([]byte(signedInfo))
+	if err := rsa.VerifyPKCS1v15(certificado.PublicKey.(*rsa.PublicKey), crypto.SHA256, resumenSignedInfo[:], firma); err != nil {
+		t.Fatalf("firma inválida: %v", err)
+	}
+}
+
+func TestEnviadorFake(t *testing.T) {
+	firmador, err := sunat.FirmadorDePrueba(emisorPrueba.RUC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := sunat.NuevoComprobante(emisorPrueba, facturaPrueba())
+	firmado, _, err := firmador.Firmar(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zip, err := sunat.Comprimir(doc.Nombre, firmado)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enviador := sunat.NuevoEnviadorFake()
+
+	// Comprobante aceptado
+	cdr, err := enviador.EnviarComprobante(doc.Nombre, zip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cdr.Estado() != sunat.EstadoAceptado {
+		t.Fatalf("se esperaba ACEPTADO, se obtuvo %s", cdr.Estado())
+	}
+
+	// El ZIP enviado contiene el XML firmado
+	contenido, err := sunat.Descomprimir(enviador.Enviados[doc.Nombre])
+	if err != nil || string(contenido) != string(firmado) {
+		t.Fatal("el ZIP enviado no contiene el XML firmado")
+	}
+
+	// Comprobante rechazado
+	enviador.CodigoRespuesta = "2017"
+	cdr, err = enviador.EnviarComprobante(doc.Nombre, zip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cdr.Estado() != sunat.EstadoRechazado {
+		t.Fatalf("se esperaba RECHAZADO, se obtuvo %s", cdr.Estado())
+	}
+}
+
+func TestResumenDiarioConTicket(t *testing.T) {
+	firmador, err := sunat.FirmadorDePrueba(emisorPrueba.RUC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boleta := facturaPrueba()
+	boleta.Tipo = "BOLETA"
+	boleta.Serie = "B001"
+	boleta.NumeroComprobante = "B001-00000001"
+	boleta.TipoDocumentoCliente = "DNI"
+	boleta.DocumentoCliente = "12345678"
+
+	fecha := boleta.FechaEmision
+	doc := sunat.NuevoResumenDiario(emisorPrueba, "RC-20240116-1", fecha, fecha.AddDate(0, 0, 1), []sunat.ItemResumen{
+		{Comprobante: boleta, Condicion: sunat.CondicionAdicionar},
+	})
+	if doc.Nombre != "20000000001-RC-20240116-1" {
+		t.Fatalf("nombre de archivo inesperado: %s", doc.Nombre)
+	}
+
+	firmado, _, err := firmador.Firmar(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(firmado), `<cbc:ConditionCode>1</cbc:ConditionCode>`) {
+		t.Fatal("el resumen no contiene la condición de la boleta")
+	}
+
+	zip, err := sunat.Comprimir(doc.Nombre, firmado)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enviador := sunat.NuevoEnviadorFake()
+	ticket, err := enviador.EnviarResumen(doc.Nombre, zip)
+	if err != nil || ticket == "" {
+		t.Fatalf("no se obtuvo ticket: %v", err)
+	}
+
+	cdr, err := enviador.ConsultarTicket(ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cdr.Estado() != sunat.EstadoAceptado {
+		t.Fatalf("se esperaba ACEPTADO, se obtuvo %s", cdr.Estado())
+	}
+
+	// Un ticket desconocido devuelve un error de SUNAT
+	if _, err := enviador.ConsultarTicket("999"); err == nil {
+		t.Fatal("se esperaba error para un ticket inexistente")
+	}
+}
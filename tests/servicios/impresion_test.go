@@ -0,0 +1,151 @@
+// Tests para la generación de documentos imprimibles
+package servicios
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/impresion"
+	"sistema-tours/internal/sunat"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMontoEnLetras(t *testing.T) {
+	casos := map[float64]string{
+		0:          "CERO CON 00/100 SOLES",
+		1:          "UNO CON 00/100 SOLES",
+		21.5:       "VEINTIUNO CON 50/100 SOLES",
+		100:        "CIEN CON 00/100 SOLES",
+		118:        "CIENTO DIECIOCHO CON 00/100 SOLES",
+		1001.01:    "MIL UNO CON 01/100 SOLES",
+		21000:      "VEINTIÚN MIL CON 00/100 SOLES",
+		1234567.89: "UN MILLÓN DOSCIENTOS TREINTA Y CUATRO MIL QUINIENTOS SESENTA Y SIETE CON 89/100 SOLES",
+	}
+
+	for monto, esperado := range casos {
+		if obtenido := impresion.MontoEnLetras(monto); obtenido != esperado {
+			t.Errorf("MontoEnLetras(%.2f) = %q, se esperaba %q", monto, obtenido, esperado)
+		}
+	}
+}
+
+func TestContenidoQR(t *testing.T) {
+	c := facturaPrueba()
+	c.HashCPE = "abc123"
+
+	esperado := "20000000001|01|F001|00000001|18.00|118.00|2024-01-15|6|20123456789|abc123|"
+	if obtenido := sunat.ContenidoQR(emisorPrueba, c); obtenido != esperado {
+		t.Errorf("ContenidoQR = %q, se esperaba %q", obtenido, esperado)
+	}
+}
+
+func TestComprobantePDF(t *testing.T) {
+	lineas := []impresion.LineaComprobante{
+		{Cantidad: 2, Descripcion: "TOUR ISLAS BALLESTAS - PASAJE ADULTO", PrecioUnitario: 50, Importe: 100},
+		{Cantidad: 1, Descripcion: "TOUR ISLAS BALLESTAS - PASAJE NIÑO", PrecioUnitario: 18, Importe: 18},
+	}
+
+	pdf, err := impresion.Comprobante(emisorPrueba, facturaPrueba(), lineas)
+	if err != nil {
+		t.Fatalf("error al generar el PDF: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("el documento generado no es un PDF")
+	}
+}
+
+func TestTicketPDF(t *testing.T) {
+	reserva := &entidades.Reserva{
+		ID:            10,
+		Localizador:   "TRS-7KQ2M9",
+		NombreCliente: "María Pérez",
+		TotalPagar:    118,
+		CantidadPasajes: []entidades.PasajeCantidad{
+			{IDTipoPasaje: 1, NombreTipo: "Adulto", Cantidad: 2},
+			{IDTipoPasaje: 2, NombreTipo: "Niño", Cantidad: 1},
+		},
+	}
+	tour := &entidades.TourProgramado{
+		NombreTipoTour:    "Islas Ballestas",
+		Fecha:             time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+		HoraInicio:        "08:00",
+		NombreEmbarcacion: "Paracas I",
+	}
+
+	pdf, err := impresion.Ticket(emisorPrueba, reserva, tour, "EMB.10.7.firma")
+	if err != nil {
+		t.Fatalf("error al generar el ticket: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("el ticket generado no es un PDF")
+	}
+}
+
+func manifiestoPrueba() *entidades.ManifiestoTour {
+	return &entidades.ManifiestoTour{
+		Tour: &entidades.TourProgramado{
+			ID:                   7,
+			NombreTipoTour:       "Islas Ballestas",
+			Fecha:                time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+			HoraInicio:           "08:00",
+			HoraFin:              "10:00",
+			NombreEmbarcacion:    "Paracas I",
+			CapacidadEmbarcacion: 30,
+			NombreChofer:         "Juan",
+			ApellidosChofer:      "Quispe",
+		},
+		Pasajeros: []*entidades.PasajeroManifiesto{
+			{IDReserva: 10, Nombres: "María", Apellidos: "Pérez", TipoDocumento: "DNI", NumeroDocumento: "12345678",
+				Nacionalidad: "PERUANA", FechaNacimiento: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC), Edad: 33, NombreTipoPasaje: "Adulto"},
+			{IDReserva: 10, Nombres: "Luis", Apellidos: "Pérez", TipoDocumento: "DNI", NumeroDocumento: "87654321",
+				Nacionalidad: "PERUANA", FechaNacimiento: time.Date(2015, 3, 2, 0, 0, 0, 0, time.UTC), Edad: 8, NombreTipoPasaje: "Niño"},
+		},
+		TotalesPorTipo: []*entidades.TotalPasajeManifiesto{
+			{IDTipoPasaje: 1, NombreTipo: "Adulto", Pasajes: 2, Registrados: 1},
+			{IDTipoPasaje: 2, NombreTipo: "Niño", Pasajes: 1, Registrados: 1},
+		},
+		TotalPasajes:   3,
+		TotalPasajeros: 2,
+		GeneradoEn:     time.Date(2024, 1, 20, 7, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestManifiestoPDF(t *testing.T) {
+	pdf, err := impresion.Manifiesto(emisorPrueba, manifiestoPrueba())
+	if err != nil {
+		t.Fatalf("error al generar el manifiesto: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("el manifiesto generado no es un PDF")
+	}
+}
+
+func TestManifiestoCSV(t *testing.T) {
+	contenido, err := impresion.ManifiestoCSV(manifiestoPrueba())
+	if err != nil {
+		t.Fatalf("error al generar el manifiesto: %v", err)
+	}
+
+	// Las secciones del CSV tienen distinta cantidad de columnas
+	lector := csv.NewReader(bytes.NewReader(contenido))
+	lector.FieldsPerRecord = -1
+	if _, err := lector.ReadAll(); err != nil {
+		t.Fatalf("el manifiesto no es un CSV válido: %v", err)
+	}
+
+	texto := string(contenido)
+	for _, esperado := range []string{
+		"embarcacion,Paracas I",
+		"capacidad,30",
+		"chofer,Juan Quispe",
+		"1,Pérez,María,DNI,12345678,PERUANA,1990-05-01,33,Adulto,10",
+		"Adulto,2,1",
+		"TOTAL,3,2",
+	} {
+		if !strings.Contains(texto, esperado) {
+			t.Errorf("el CSV no contiene %q", esperado)
+		}
+	}
+}
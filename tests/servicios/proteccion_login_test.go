@@ -0,0 +1,42 @@
+// Tests para la protección del inicio de sesión
+package servicios
+
+import (
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"testing"
+	"time"
+)
+
+func TestEsperaLogin(t *testing.T) {
+	cfg := &config.Config{LoginMaxFallos: 5, LoginBloqueo: 15 * time.Minute, LoginRetrasoBase: time.Second}
+	proteccion := servicios.NewProteccionLoginService(nil, nil, cfg)
+
+	ahora := time.Now()
+	haceUnSegundo := ahora.Add(-time.Second)
+	haceUnaHora := ahora.Add(-time.Hour)
+	enDiezMinutos := ahora.Add(10 * time.Minute)
+
+	casos := []struct {
+		nombre  string
+		control entidades.ControlLogin
+		retraso bool
+		espera  time.Duration
+	}{
+		{"primer fallo sin espera", entidades.ControlLogin{Fallos: 1, UltimoFallo: &haceUnSegundo}, true, 0},
+		{"segundo fallo espera la base", entidades.ControlLogin{Fallos: 2, UltimoFallo: &ahora}, true, time.Second},
+		{"cuarto fallo duplica dos veces", entidades.ControlLogin{Fallos: 4, UltimoFallo: &haceUnSegundo}, true, 3 * time.Second},
+		{"sin retraso exponencial", entidades.ControlLogin{Fallos: 4, UltimoFallo: &ahora}, false, 0},
+		{"fallos fuera de la ventana", entidades.ControlLogin{Fallos: 4, UltimoFallo: &haceUnaHora}, true, 0},
+		{"bloqueo vigente", entidades.ControlLogin{Fallos: 5, UltimoFallo: &ahora, BloqueadoHasta: &enDiezMinutos}, false, 10 * time.Minute},
+		{"bloqueo vencido", entidades.ControlLogin{Fallos: 5, UltimoFallo: &haceUnaHora, BloqueadoHasta: &haceUnSegundo}, true, 0},
+	}
+
+	for _, c := range casos {
+		espera := proteccion.Espera(&c.control, cfg.LoginMaxFallos, c.retraso, ahora)
+		if espera != c.espera {
+			t.Errorf("%s: se esperaba %s, se obtuvo %s", c.nombre, c.espera, espera)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Tests para roles
+package servicios
+
+import (
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/servicios"
+	"testing"
+)
+
+// TestRolesReservados verifica que los nombres de los actores que no son personal no se puedan usar como roles.
+// El nombre se valida antes de consultar la base de datos.
+func TestRolesReservados(t *testing.T) {
+	roles := servicios.NewRolService(nil, nil, nil, 0)
+
+	for _, nombre := range []string{"CLIENTE", "AGENCIA", "SISTEMA"} {
+		err := roles.Create(&entidades.NuevoRolRequest{Nombre: nombre, Permisos: []string{entidades.PermisoReservasVer}})
+		if err == nil {
+			t.Errorf("se esperaba rechazar la creación del rol %s", nombre)
+		}
+
+		err = roles.Update(nombre, &entidades.ActualizarRolRequest{Permisos: []string{entidades.PermisoReservasVer}})
+		if err == nil {
+			t.Errorf("se esperaba rechazar la modificación del rol %s", nombre)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package utils_test
+
+import (
+	"encoding/base32"
+	"sistema-tours/internal/config"
+	"sistema-tours/internal/entidades"
+	"sistema-tours/internal/utils"
+	"testing"
+	"time"
+)
+
+// TestCodigoTOTP verifica el vector de prueba del RFC 6238 (SHA-1, secreto "12345678901234567890")
+func TestCodigoTOTP(t *testing.T) {
+	secreto := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	casos := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for segundos, esperado := range casos {
+		codigo, err := utils.CodigoTOTP(secreto, utils.PasoTOTP(time.Unix(segundos, 0)))
+		if err != nil {
+			t.Fatalf("error al calcular el código: %v", err)
+		}
+		if codigo != esperado {
+			t.Errorf("T=%d: se esperaba %s, se obtuvo %s", segundos, esperado, codigo)
+		}
+	}
+}
+
+// TestVerificarTOTP verifica la tolerancia de un paso y que no se acepten pasos ya usados
+func TestVerificarTOTP(t *testing.T) {
+	secreto, err := utils.GenerarSecretoTOTP()
+	if err != nil {
+		t.Fatalf("error al generar el secreto: %v", err)
+	}
+	ahora := time.Unix(1700000000, 0)
+	paso := utils.PasoTOTP(ahora)
+
+	anterior, _ := utils.CodigoTOTP(secreto, paso-1)
+	if aceptado, ok := utils.VerificarTOTP(secreto, anterior, ahora, 0); !ok || aceptado != paso-1 {
+		t.Errorf("el código del paso anterior debe aceptarse por desfase de reloj")
+	}
+
+	lejano, _ := utils.CodigoTOTP(secreto, paso-3)
+	if _, ok := utils.VerificarTOTP(secreto, lejano, ahora, 0); ok {
+		t.Errorf("un código fuera de la tolerancia no debe aceptarse")
+	}
+
+	actual, _ := utils.CodigoTOTP(secreto, paso)
+	if _, ok := utils.VerificarTOTP(secreto, actual, ahora, paso); ok {
+		t.Errorf("un código de un paso ya usado no debe aceptarse")
+	}
+}
+
+// TestTokenDesafioNoEsAcceso verifica que el token de desafío no sirva como token de acceso ni para otro propósito
+func TestTokenDesafioNoEsAcceso(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secreto-prueba", JWTRefreshSecret: "secreto-refresh-prueba"}
+
+	desafio, err := utils.GenerarTokenDesafio(7, utils.DesafioVerificar, time.Minute, cfg)
+	if err != nil {
+		t.Fatalf("error al generar el desafío: %v", err)
+	}
+	if _, err := utils.ValidateToken(desafio, cfg); err == nil {
+		t.Error("el token de desafío no debe aceptarse como token de acceso")
+	}
+	if _, err := utils.ValidarTokenDesafio(desafio, utils.DesafioEnrolar, cfg); err == nil {
+		t.Error("el token de desafío no debe aceptarse con otro propósito")
+	}
+	claims, err := utils.ValidarTokenDesafio(desafio, utils.DesafioVerificar, cfg)
+	if err != nil || claims.UserID != 7 {
+		t.Errorf("el token de desafío debe validarse con su propósito, se obtuvo %v", err)
+	}
+
+	acceso, err := utils.GenerateJWT(&entidades.Usuario{ID: 7, Correo: "a@b.com", Rol: "ADMIN"}, cfg)
+	if err != nil {
+		t.Fatalf("error al generar el token de acceso: %v", err)
+	}
+	if _, err := utils.ValidarTokenDesafio(acceso, utils.DesafioVerificar, cfg); err == nil {
+		t.Error("un token de acceso no debe aceptarse como desafío")
+	}
+}